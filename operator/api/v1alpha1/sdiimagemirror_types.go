@@ -0,0 +1,129 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SDIImageMirrorSpec defines the desired state of SDIImageMirror.
+type SDIImageMirrorSpec struct {
+	// The SAP DI version to mirror. It is used as the tag of the images listed without one.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^[0-9]+(\\.[0-9]+)+$"
+	Version string `json:"version"`
+	// The registry to mirror the images from.
+	// +kubebuilder:default="73554900100900006891.dockersrv.repositories.sap.ondemand.com"
+	SourceRegistry string `json:"sourceRegistry,omitempty"`
+	// The registry to mirror the images to, optionally followed by a path prefix. For example:
+	// container-image-registry-sdi-observer.apps.example.com/sdi
+	// Either targetRegistry or registryRef must be set.
+	// +kubebuilder:validation:Optional
+	TargetRegistry string `json:"targetRegistry,omitempty"`
+	// An SDIRegistry in the namespace of the SDIImageMirror to mirror the images to. The job pushes to the
+	// registry service trusting the service CA and authenticating with the generated credential; the images are
+	// reported and redirected to by the hostname of the registry route. The mirroring waits for the registry to
+	// be ready. Either targetRegistry or registryRef must be set.
+	// +kubebuilder:validation:Optional
+	RegistryRef *corev1.LocalObjectReference `json:"registryRef,omitempty"`
+	// The SAP images to mirror given as repositories relative to the source registry with an optional tag.
+	// For example: com.sap.datahub.linuxx86_64/vsystem
+	// +kubebuilder:validation:MinItems=1
+	Images []string `json:"images"`
+	// Secret of type kubernetes.io/dockerconfigjson with the credentials for both the source and the target
+	// registries. With registryRef, only the credential of the source registry is needed.
+	// +kubebuilder:validation:Optional
+	AuthSecretRef *corev1.LocalObjectReference `json:"authSecretRef,omitempty"`
+	// Skip the verification of the target registry's certificate.
+	// +kubebuilder:validation:Optional
+	InsecureTargetRegistry bool `json:"insecureTargetRegistry,omitempty"`
+	// The image containing the skopeo binary used by the mirroring job. Defaults to RELATED_IMAGE_MIRROR of the
+	// operator.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// Do not create the ImageContentSourcePolicy redirecting the pulls to the target registry.
+	// +kubebuilder:validation:Optional
+	SkipImageContentSourcePolicy bool `json:"skipImageContentSourcePolicy,omitempty"`
+}
+
+// SDIImageMirrorImageStatus describes a mirrored image.
+type SDIImageMirrorImageStatus struct {
+	// The pull specification of the source image.
+	Source string `json:"source"`
+	// The pull specification of the mirrored image.
+	Target string `json:"target"`
+	// The tag of the mirrored image unless mirrored by digest.
+	Tag string `json:"tag,omitempty"`
+	// The manifest digest of the mirrored image.
+	Digest string `json:"digest,omitempty"`
+}
+
+// SDIImageMirrorStatus defines the observed state of SDIImageMirror.
+type SDIImageMirrorStatus struct {
+	// Used condition types:
+	// - Progressing - true while the mirroring job is running
+	// - Mirrored - true when all the images have been mirrored
+	// - Ready - a consolidated condition being true when the images are mirrored and the
+	//   ImageContentSourcePolicy is created
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions"`
+	// The generation of the spec the status corresponds to.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The name of the mirroring job.
+	JobName string `json:"jobName,omitempty"`
+	// The number of images to mirror.
+	Total int `json:"total,omitempty"`
+	// The number of images mirrored so far.
+	Mirrored int `json:"mirrored,omitempty"`
+	// The mirrored images.
+	Images []SDIImageMirrorImageStatus `json:"images,omitempty"`
+	// The name of the ImageContentSourcePolicy created for the mirrored repositories.
+	ImageContentSourcePolicyName string `json:"imageContentSourcePolicyName,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+//+kubebuilder:printcolumn:name="Mirrored",type=integer,JSONPath=`.status.mirrored`
+//+kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.total`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// SDIImageMirror is the Schema for the sdiimagemirrors API. It mirrors SAP DI images to a registry
+// reachable from a disconnected cluster.
+type SDIImageMirror struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SDIImageMirrorSpec   `json:"spec,omitempty"`
+	Status SDIImageMirrorStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SDIImageMirrorList contains a list of SDIImageMirror
+type SDIImageMirrorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SDIImageMirror `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SDIImageMirror{}, &SDIImageMirrorList{})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionMaintenancePending is set on resources whose disruptive changes are deferred until the next
+// maintenance window.
+const ConditionMaintenancePending = "MaintenancePending"
+
+// ConditionReasonOutsideMaintenanceWindow indicates that a disruptive change is deferred.
+const ConditionReasonOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+
+// SDIMaintenanceWindowSpecWindow describes a recurring time window.
+type SDIMaintenanceWindowSpecWindow struct {
+	// Days of the week when the window opens. Every day if empty.
+	// +kubebuilder:validation:Optional
+	Days []SDIMaintenanceWindowDay `json:"days,omitempty"`
+	// Time of the day when the window opens in the 24-hour format HH:MM.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^([01][0-9]|2[0-3]):[0-5][0-9]$"
+	Start string `json:"start"`
+	// How long the window stays open.
+	// +kubebuilder:validation:Required
+	Duration metav1.Duration `json:"duration"`
+}
+
+// SDIMaintenanceWindowDay is a day of the week.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type SDIMaintenanceWindowDay string
+
+// SDIShutdownAction is the run level the operator sets on the DataHub.
+// +kubebuilder:validation:Enum=Stop;Start
+type SDIShutdownAction string
+
+const (
+	SDIShutdownActionStop  SDIShutdownAction = "Stop"
+	SDIShutdownActionStart SDIShutdownAction = "Start"
+)
+
+// SDIMaintenanceWindowSpecShutdown schedules the periods when SDI is stopped.
+type SDIMaintenanceWindowSpecShutdown struct {
+	// The SDI namespace. Defaults to the sdiNamespace of the SDIObserver in the same namespace.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// SDI is stopped when any of the windows opens and started again when it closes. The start times are
+	// evaluated in the time zone of the maintenance window.
+	// +kubebuilder:validation:MinItems=1
+	Windows []SDIMaintenanceWindowSpecWindow `json:"windows"`
+}
+
+// SDIMaintenanceWindowSpec defines the desired state of SDIMaintenanceWindow.
+type SDIMaintenanceWindowSpec struct {
+	// IANA time zone of the window start times. For example: Europe/Prague
+	// +kubebuilder:default="UTC"
+	TimeZone string `json:"timeZone,omitempty"`
+	// Disruptive changes of the resources in the same namespace are allowed only while one of the windows
+	// is open.
+	// +kubebuilder:validation:MinItems=1
+	Windows []SDIMaintenanceWindowSpecWindow `json:"windows"`
+	// Allow the disruptive changes at any time. Useful for an unplanned maintenance.
+	// +kubebuilder:validation:Optional
+	Suspend bool `json:"suspend,omitempty"`
+	// Stop SDI in the given windows (e.g. nights and weekends of development clusters) and start it again
+	// afterwards. The run level of the DataHub is changed so that the SAP operators scale the workloads down and
+	// up in the right order.
+	// +kubebuilder:validation:Optional
+	Shutdown *SDIMaintenanceWindowSpecShutdown `json:"shutdown,omitempty"`
+}
+
+// SDIShutdownActionStatus records a change of the run level of SDI.
+type SDIShutdownActionStatus struct {
+	Action SDIShutdownAction `json:"action"`
+	Time   metav1.Time       `json:"time"`
+}
+
+// SDIMaintenanceWindowShutdownStatus describes the scheduled shutdown of SDI.
+type SDIMaintenanceWindowShutdownStatus struct {
+	// The DataHub instance stopped and started by the schedule.
+	DataHub string `json:"dataHub,omitempty"`
+	// The last run level change done by the operator.
+	LastAction *SDIShutdownActionStatus `json:"lastAction,omitempty"`
+	// The next scheduled run level change.
+	NextAction *SDIShutdownActionStatus `json:"nextAction,omitempty"`
+}
+
+// SDIMaintenanceWindowStatus defines the observed state of SDIMaintenanceWindow.
+type SDIMaintenanceWindowStatus struct {
+	// Used condition types:
+	// - Open - true while the disruptive changes are allowed
+	// - Degraded - true when the spec cannot be evaluated
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions"`
+	// When the next window opens.
+	NextStart *metav1.Time `json:"nextStart,omitempty"`
+	// When the currently open window closes.
+	CurrentEnd *metav1.Time `json:"currentEnd,omitempty"`
+	// References to the resources in the namespace with deferred disruptive changes.
+	PendingChanges []string `json:"pendingChanges,omitempty"`
+	// The state of the scheduled shutdown of SDI.
+	Shutdown *SDIMaintenanceWindowShutdownStatus `json:"shutdown,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Open",type=string,JSONPath=`.status.conditions[?(@.type=="Open")].status`
+//+kubebuilder:printcolumn:name="Next Start",type=string,JSONPath=`.status.nextStart`
+
+// SDIMaintenanceWindow is the Schema for the sdimaintenancewindows API. It restricts disruptive changes
+// done by the operator (e.g. restarting SDI workloads or rebooting nodes) to the given time windows.
+type SDIMaintenanceWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SDIMaintenanceWindowSpec   `json:"spec,omitempty"`
+	Status SDIMaintenanceWindowStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SDIMaintenanceWindowList contains a list of SDIMaintenanceWindow
+type SDIMaintenanceWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SDIMaintenanceWindow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SDIMaintenanceWindow{}, &SDIMaintenanceWindowList{})
+}
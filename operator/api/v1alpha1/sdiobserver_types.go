@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -37,6 +38,10 @@ const (
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // SDIObserverSpecRoute allows to control route management for an SDI service.
+// +kubebuilder:validation:XValidation:rule="!has(self.hostname) || self.exposure in ['route', 'serviceMesh']",message="hostname requires the route or serviceMesh exposure"
+// +kubebuilder:validation:XValidation:rule="!has(self.certificate) || has(self.hostname)",message="certificate requires a custom hostname"
+// +kubebuilder:validation:XValidation:rule="!has(self.serviceMesh) || self.exposure == 'serviceMesh'",message="serviceMesh settings require the serviceMesh exposure"
+// +kubebuilder:validation:XValidation:rule="!has(self.service) || self.exposure in ['loadBalancer', 'nodePort']",message="service settings require the loadBalancer or nodePort exposure"
 type SDIObserverSpecRoute struct {
 	// +kubebuilder:default="Managed"
 	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
@@ -44,6 +49,621 @@ type SDIObserverSpecRoute struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Pattern="[[:alnum:]]+(-[[:alnum:]]+)*(\\.[[:alnum:]]+(-[[:alnum:]]+)*)*"
 	Hostname string `json:"hostname,omitempty"`
+	// Serving certificate of the route. Only honored for the vsystem route with a custom hostname.
+	// +kubebuilder:validation:Optional
+	Certificate *SDIObserverSpecRouteCertificate `json:"certificate,omitempty"`
+	// How the service is published. With serviceMesh, Istio Gateway, VirtualService and DestinationRule
+	// objects are created instead of the route. The SDI namespace must be a member of the Service Mesh.
+	// With loadBalancer or nodePort, a Service of the respective type passes the TCP connections through to
+	// vsystem instead of the route, e.g. for the clients unable to use the router. Only honored for the vsystem
+	// route.
+	// +kubebuilder:default="route"
+	// +kubebuilder:validation:Enum=route;serviceMesh;loadBalancer;nodePort
+	Exposure string `json:"exposure,omitempty"`
+	// Settings of the serviceMesh exposure.
+	// +kubebuilder:validation:Optional
+	ServiceMesh *SDIObserverSpecRouteServiceMesh `json:"serviceMesh,omitempty"`
+	// Settings of the loadBalancer and nodePort exposures.
+	// +kubebuilder:validation:Optional
+	Service *SDIObserverSpecRouteService `json:"service,omitempty"`
+	// Secret in the SDI namespace holding the PEM encoded CA bundle trusted for the connections of the router
+	// to the service, e.g. for a vsystem certificate issued by a custom CA. Defaults to the ca-bundle.pem key of
+	// the ca-bundle.pem secret created by the SDI installation. Only honored for the vsystem route.
+	// +kubebuilder:validation:Optional
+	DestinationCASecretRef *SecretKeySelector `json:"destinationCASecretRef,omitempty"`
+	// Keep the route, the service mesh objects and the certificate when the vsystem service or the DataHub
+	// resource is deleted, e.g. on the uninstallation of SDI. By default, they are deleted along with SDI.
+	// +kubebuilder:validation:Optional
+	OrphanOnDelete bool `json:"orphanOnDelete,omitempty"`
+}
+
+const (
+	// RouteExposureRoute publishes the service with an OpenShift route.
+	RouteExposureRoute = "route"
+	// RouteExposureServiceMesh publishes the service through the ingress gateway of a Service Mesh.
+	RouteExposureServiceMesh = "serviceMesh"
+	// RouteExposureLoadBalancer publishes the service with a Service of the LoadBalancer type.
+	RouteExposureLoadBalancer = "loadBalancer"
+	// RouteExposureNodePort publishes the service with a Service of the NodePort type.
+	RouteExposureNodePort = "nodePort"
+)
+
+// SDIObserverSpecRouteService configures the Service created for the loadBalancer and nodePort exposures.
+type SDIObserverSpecRouteService struct {
+	// The port of the Service forwarded to the vsystem port.
+	// +kubebuilder:default=443
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// The port opened on every node. Allocated by the cluster if unset.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	NodePort int32 `json:"nodePort,omitempty"`
+	// Annotations of the Service, e.g. to configure the load balancer of the cloud provider.
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// The client CIDRs allowed to connect through the load balancer.
+	// +kubebuilder:validation:Optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+}
+
+// SDIObserverSpecServiceRoute controls the re-encrypting route of an optional service of the SDI namespace.
+type SDIObserverSpecServiceRoute struct {
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The host of the route. Generated by the ingress controller if unset.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern="[[:alnum:]]+(-[[:alnum:]]+)*(\\.[[:alnum:]]+(-[[:alnum:]]+)*)*"
+	Hostname string `json:"hostname,omitempty"`
+	// The name of the service in the SDI namespace. Defaults to the service of the component.
+	// +kubebuilder:validation:Optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// The name or number of the service port. Defaults to the first port of the service.
+	// +kubebuilder:validation:Optional
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+	// TLS settings of the route.
+	// +kubebuilder:validation:Optional
+	TLS SDIObserverSpecServiceRouteTLS `json:"tls,omitempty"`
+}
+
+// SDIObserverSpecServiceRouteTLS configures the TLS of a re-encrypting route.
+type SDIObserverSpecServiceRouteTLS struct {
+	// What happens to the plain HTTP connections.
+	// +kubebuilder:default="Redirect"
+	// +kubebuilder:validation:Enum=Redirect;None
+	InsecureEdgeTerminationPolicy string `json:"insecureEdgeTerminationPolicy,omitempty"`
+	// Secret in the SDI namespace holding the PEM encoded CA bundle trusted for the connections of the router
+	// to the service. Defaults to the ca-bundle.pem key of the ca-bundle.pem secret.
+	// +kubebuilder:validation:Optional
+	DestinationCASecretRef *SecretKeySelector `json:"destinationCASecretRef,omitempty"`
+}
+
+// SDIObserverSpecRouteServiceMesh configures the publishing through an existing Service Mesh control plane.
+type SDIObserverSpecRouteServiceMesh struct {
+	// Labels selecting the ingress gateway pods of the control plane.
+	// +kubebuilder:default={"istio":"ingressgateway"}
+	GatewaySelector map[string]string `json:"gatewaySelector,omitempty"`
+	// Name of the secret with the serving certificate in the namespace of the ingress gateway.
+	// +kubebuilder:validation:Required
+	CredentialName string `json:"credentialName"`
+}
+
+// SDIObserverSpecRouteCertificate configures how the serving certificate of a route is obtained.
+type SDIObserverSpecRouteCertificate struct {
+	// Reference to a cert-manager Issuer or ClusterIssuer. If set, a cert-manager Certificate is created
+	// for the route hostname and the issued certificate and key are set on the route. The route is updated
+	// whenever the certificate is renewed.
+	// +kubebuilder:validation:Optional
+	IssuerRef *SDIObserverSpecRouteIssuerRef `json:"issuerRef,omitempty"`
+}
+
+// SDIObserverSpecRouteIssuerRef references a cert-manager issuer.
+type SDIObserverSpecRouteIssuerRef struct {
+	// Name of the issuer. An Issuer must live in the SDI namespace.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+	// +kubebuilder:default="Issuer"
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+	// +kubebuilder:default="cert-manager.io"
+	Group string `json:"group,omitempty"`
+}
+
+// SDIObserverSpecAlerts controls the PrometheusRule with alerts for the managed SDI components.
+type SDIObserverSpecAlerts struct {
+	// +kubebuilder:default="Managed"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Fire a warning when the vsystem route certificate, the serving certificate of the vsystem or SLCB route
+	// or the vsystem CA bundle expires in less than the given duration.
+	// +kubebuilder:default="720h"
+	CertificateExpiryWarning *metav1.Duration `json:"certificateExpiryWarning,omitempty"`
+	// Fire a critical alert when any of the above certificates expires in less than the given duration.
+	// +kubebuilder:default="168h"
+	CertificateExpiryCritical *metav1.Duration `json:"certificateExpiryCritical,omitempty"`
+	// How long the vsystem route may stay unexposed before alerting.
+	// +kubebuilder:default="10m"
+	RouteUnreachableFor *metav1.Duration `json:"routeUnreachableFor,omitempty"`
+	// How long the DataHub resource may stay degraded before alerting.
+	// +kubebuilder:default="15m"
+	DataHubDegradedFor *metav1.Duration `json:"dataHubDegradedFor,omitempty"`
+	// How long a drift of the fluentd patch may persist before alerting.
+	// +kubebuilder:default="30m"
+	FluentdPatchDriftFor *metav1.Duration `json:"fluentdPatchDriftFor,omitempty"`
+}
+
+// SDIObserverSpecNetworkPolicies controls the NetworkPolicies isolating the SDI and SLCB namespaces.
+type SDIObserverSpecNetworkPolicies struct {
+	// When Managed, only the traffic within the namespace, from the SLCB namespace and from the ingress
+	// routers to vsystem and SLC Bridge is allowed into the namespaces.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Block the outgoing traffic as well except for DNS and the traffic within the namespace.
+	// +kubebuilder:validation:Optional
+	RestrictEgress bool `json:"restrictEgress,omitempty"`
+}
+
+// SDIObserverSpecProxyInjection controls the injection of the cluster-wide proxy settings and the trusted CA
+// bundle into the SDI workloads.
+type SDIObserverSpecProxyInjection struct {
+	// When Managed, the HTTP(S)_PROXY and NO_PROXY variables of the cluster Proxy object and the trusted CA
+	// bundle are injected into the listed deployments. The update is deferred until a maintenance window is
+	// open.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Names of the deployments in the SDI namespace. Missing deployments are skipped.
+	// +kubebuilder:default={"vsystem","vflow"}
+	Deployments []string `json:"deployments,omitempty"`
+}
+
+// SDIObserverSpecRegistry describes the container image registry the SDI images are pulled from.
+type SDIObserverSpecRegistry struct {
+	// When Managed, the registry is merged into the cluster image config (images.config.openshift.io/cluster)
+	// so that the nodes can pull from it: into the allowedRegistries (if an allow-list is in use), the
+	// insecureRegistries and the additionalTrustedCA config map. No ContainerRuntimeConfig is needed, the
+	// Machine Config Operator renders the registry settings of the image config for all the pools. When
+	// Removed, the entries added by the operator are removed again. Unmanaged keeps them as they are.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The registry host optionally followed by a port (e.g. registry.example.com:5000).
+	// +kubebuilder:validation:Optional
+	Hostname string `json:"hostname,omitempty"`
+	// Mark the registry as insecure (plain HTTP or a certificate that cannot be verified).
+	// +kubebuilder:validation:Optional
+	Insecure bool `json:"insecure,omitempty"`
+	// PEM encoded CA certificate(s) signing the registry's certificate. It is added to the config map
+	// referenced by the additionalTrustedCA of the cluster image config.
+	// +kubebuilder:validation:Optional
+	CACertificate string `json:"caCertificate,omitempty"`
+}
+
+// SDIObserverSpecNodeConfig controls how the SDI nodes are prepared (kernel modules and sysctls).
+type SDIObserverSpecNodeConfig struct {
+	// With machineConfig, the nodes are configured by the Machine Config Operator (see the
+	// --manage-kernel-modules flag of the operator). With daemonset, a privileged DaemonSet loads the kernel
+	// modules and applies the sysctls on the selected nodes, which suits clusters without the Machine Config
+	// Operator or where node reboots are too disruptive.
+	// +kubebuilder:default="machineConfig"
+	// +kubebuilder:validation:Enum=machineConfig;daemonset
+	Strategy string `json:"strategy,omitempty"`
+	// Selects the SDI nodes the DaemonSet runs on.
+	// +kubebuilder:default={"node-role.kubernetes.io/sdi":""}
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Sysctls applied by the DaemonSet, e.g. {"vm.max_map_count": "262144"}.
+	// +kubebuilder:validation:Optional
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+	// The image of the DaemonSet pods. It needs to provide chroot. Defaults to RELATED_IMAGE_NODE_CONFIGURATOR
+	// of the operator.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+}
+
+const (
+	// NodeConfigStrategyMachineConfig leaves the node configuration to the Machine Config Operator.
+	NodeConfigStrategyMachineConfig = "machineConfig"
+	// NodeConfigStrategyDaemonSet configures the nodes with a privileged DaemonSet.
+	NodeConfigStrategyDaemonSet = "daemonset"
+)
+
+// SDIObserverSpecNodeTuning controls the Tuned profiles for the nodes running the hana and vora pods.
+type SDIObserverSpecNodeTuning struct {
+	// When Managed, a Tuned object is maintained for the Node Tuning Operator, which applies the sysctls and
+	// renders the MachineConfig with the hugepages kernel arguments for each listed pool. The profiles are
+	// removed when Removed or once SDI is uninstalled.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The tuned node pools. Defaults to the sdi pool with the default settings.
+	// +kubebuilder:validation:Optional
+	Pools []SDIObserverSpecNodeTuningPool `json:"pools,omitempty"`
+}
+
+// SDIObserverSpecCompliance controls the tailored profile of the Compliance Operator documenting the deviations
+// required by SDI.
+type SDIObserverSpecCompliance struct {
+	// When Managed and the Compliance Operator is installed, a TailoredProfile extending the profile exempts the
+	// rules violated by design of SDI (the privileged diagnostics fluentd, the SCCs granted to the SDI service
+	// accounts and the privileged Pod Security level of the SDI namespaces) with the rationale. Removed deletes
+	// the objects.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The namespace of the Compliance Operator.
+	// +kubebuilder:default="openshift-compliance"
+	Namespace string `json:"namespace,omitempty"`
+	// The profile extended by the tailored profile.
+	// +kubebuilder:default="ocp4-cis"
+	Profile string `json:"profile,omitempty"`
+	// Manual turns the exempted rules into manual checks documenting the rationale in the scan results, Disable
+	// leaves them out of the scans.
+	// +kubebuilder:default="Manual"
+	// +kubebuilder:validation:Enum=Manual;Disable
+	RuleAction string `json:"ruleAction,omitempty"`
+	// Additional exempted rules, e.g. those of other profiles.
+	// +kubebuilder:validation:Optional
+	AdditionalRules []SDIObserverComplianceRule `json:"additionalRules,omitempty"`
+	// When set, the tailored profile is bound to the ScanSetting, e.g. default, to be scanned periodically.
+	// +kubebuilder:validation:Optional
+	ScanSetting string `json:"scanSetting,omitempty"`
+}
+
+// SDIObserverComplianceRule is a rule of the Compliance Operator exempted for SDI.
+type SDIObserverComplianceRule struct {
+	// The name of the Rule object, e.g. ocp4-scc-limit-privileged-containers.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Why SDI deviates from the rule.
+	// +kubebuilder:validation:MinLength=1
+	Rationale string `json:"rationale"`
+}
+
+// SDIObserverSpecNodeTuningPool is the tuning profile of a MachineConfigPool.
+type SDIObserverSpecNodeTuningPool struct {
+	// The machineconfiguration.openshift.io/role label of the MachineConfigPool.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+	// The vm.max_map_count sysctl. HANA needs a high number of memory map areas.
+	// +kubebuilder:default=2147483647
+	MaxMapCount int64 `json:"maxMapCount,omitempty"`
+	// The number of 2Mi hugepages allocated on boot. Zero disables the allocation.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	HugePages2Mi int32 `json:"hugePages2Mi,omitempty"`
+	// Additional sysctls, e.g. the net.core settings.
+	// +kubebuilder:default={"net.core.somaxconn":"4096","net.core.netdev_max_backlog":"5000"}
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+}
+
+// SDIObserverSpecVRep controls the patching of the vsystem-vrep StatefulSet.
+type SDIObserverSpecVRep struct {
+	// When Managed, the /exports directory of vsystem-vrep is mounted from an emptyDir volume because the
+	// NFS exports do not work on the overlay filesystem of RHCOS. The change is re-applied whenever the
+	// StatefulSet drifts. It restarts vsystem-vrep and is deferred until a maintenance window is open.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged
+	ExportsMask string `json:"exportsMask,omitempty"`
+}
+
+// SDIObserverSpecDiagnostics controls the patches of the SDI diagnostics components.
+type SDIObserverSpecDiagnostics struct {
+	// When Managed, the diagnostics-fluentd DaemonSet is patched if the profile requires it: up to SDI 3.2,
+	// the Docker log directory volumes are removed and the fluentd container is made privileged to read the
+	// CRI-O logs. The change restarts the fluentd pods and is deferred until a maintenance window is open.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged
+	Fluentd string `json:"fluentd,omitempty"`
+}
+
+// SDIObserverSpecPipelineModeler controls the verification of the Pipeline Modeler (vflow) configuration.
+type SDIObserverSpecPipelineModeler struct {
+	// The Pipeline Modeler must build the images with kaniko on OpenShift. With Verify, a Degraded condition is
+	// reported when kaniko is not enabled in the DataHub resource. Managed enables it in addition. Unmanaged
+	// skips the check.
+	// +kubebuilder:default="Verify"
+	// +kubebuilder:validation:Enum=Managed;Verify;Unmanaged
+	Kaniko string `json:"kaniko,omitempty"`
+}
+
+// SDIObserverSpecSCC controls the bindings of the SecurityContextConstraints needed by the SDI service
+// accounts.
+type SDIObserverSpecSCC struct {
+	// When Managed, all the service accounts of the SDI namespace are allowed to use the anyuid SCC and the
+	// privileged service accounts the privileged SCC. This replaces the manual "oc adm policy add-scc-to-*"
+	// steps. Removed deletes the bindings.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Service accounts in the SDI namespace allowed to use the privileged SCC. The $(NAMESPACE) reference is
+	// expanded to the SDI namespace. Defaults to the service accounts of elasticsearch, fluentd, vflow,
+	// vsystem, vsystem-vrep and the ML deployment API.
+	// +kubebuilder:validation:Optional
+	PrivilegedServiceAccounts []string `json:"privilegedServiceAccounts,omitempty"`
+}
+
+// SDIObserverSpecSCCReport controls the report comparing the SCCs granted to the SDI service accounts with the
+// SCCs their pods run with.
+type SDIObserverSpecSCCReport struct {
+	// When Managed, the SCCs the service accounts of the SDI namespace may use are compared with the SCCs
+	// admitting their pods (the openshift.io/scc annotation). The report is kept in the <name>-scc-report
+	// ConfigMap in the namespace of the SDIObserver and the recommended tighter bindings are listed in the status.
+	// The SCCs usable by every service account are not reported. Removed deletes the ConfigMap.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// How often the report is refreshed. The pods not running at the time are not accounted for.
+	// +kubebuilder:default="6h"
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// SDIObserverSpecPodSecurity controls the Pod Security Admission labels of the SDI namespaces.
+type SDIObserverSpecPodSecurity struct {
+	// When Managed, the pod-security.kubernetes.io enforce, audit and warn labels of the namespaces are set to
+	// the level and the label synchronization of OpenShift is disabled for them. The SDI workloads need the
+	// privileged level on OpenShift 4.12 and newer. Removed deletes the labels set by the operator.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The Pod Security Standard enforced in the namespaces.
+	// +kubebuilder:default="privileged"
+	// +kubebuilder:validation:Enum=privileged;baseline;restricted
+	Level string `json:"level,omitempty"`
+	// The labeled namespaces. Defaults to the SDI and SLCB namespaces.
+	// +kubebuilder:validation:Optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// SDIObserverSpecServiceAccountTokens controls the bound service account tokens issued for the SDI integrations,
+// e.g. for the vflow kaniko builds pushing to the internal registry.
+type SDIObserverSpecServiceAccountTokens struct {
+	// When Managed, a bound token is requested for each entry and stored under the "token" key of the secret in
+	// the SDI namespace. The token is requested anew once 80% of its lifetime elapsed. The integrations should
+	// mount the secret instead of the long-lived legacy token secrets of the service account. Removed deletes
+	// the secrets.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The issued tokens.
+	// +kubebuilder:validation:Optional
+	Tokens []SDIObserverServiceAccountToken `json:"tokens,omitempty"`
+}
+
+// SDIObserverServiceAccountToken describes a bound token of a service account of the SDI namespace.
+type SDIObserverServiceAccountToken struct {
+	// The service account in the SDI namespace the token is issued for.
+	ServiceAccount string `json:"serviceAccount"`
+	// The name of the secret holding the token. Defaults to <serviceAccount>-bound-token.
+	// +kubebuilder:validation:Optional
+	SecretName string `json:"secretName,omitempty"`
+	// The intended audiences of the token. Defaults to the audiences of the API server.
+	// +kubebuilder:validation:Optional
+	Audiences []string `json:"audiences,omitempty"`
+	// The requested lifetime of the token. The API server may shorten it; it must be at least 10m.
+	// +kubebuilder:default="24h"
+	Expiration *metav1.Duration `json:"expiration,omitempty"`
+}
+
+// SDIObserverSpecImagePullSecret references a pull secret to be propagated to the SDI and SLCB namespaces.
+type SDIObserverSpecImagePullSecret struct {
+	// When Managed, the secret is copied into the SDI and SLCB namespaces, kept in sync with the source and
+	// linked to the service accounts for pulling. Removed unlinks and deletes the copies.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Name of the source secret of type kubernetes.io/dockerconfigjson.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// Namespace of the source secret. Defaults to the namespace of the SDIObserver.
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+	// Service accounts to link the secret to. The $(NAMESPACE) reference is expanded to the target namespace.
+	// Service accounts that do not exist (yet) are skipped.
+	// +kubebuilder:default={"default","vora-vsystem-$(NAMESPACE)","vora-vflow-server"}
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// SDIObserverSpecScheduling controls the placement of the SDI workloads, e.g. on tainted dedicated nodes.
+type SDIObserverSpecScheduling struct {
+	// When Managed, the tolerations and the node affinity are injected into the pod templates of all the
+	// Deployments, StatefulSets and DaemonSets in the SDI namespace and re-applied on drift. The update is
+	// deferred until a maintenance window is open. Removed reverts the injected settings.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Tolerations added to the SDI pods.
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Node affinity replacing the one of the SDI pods.
+	// +kubebuilder:validation:Optional
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
+}
+
+// SDIObserverSpecPriorityClass assigns a PriorityClass to the critical SDI components.
+type SDIObserverSpecPriorityClass struct {
+	// When Managed, the PriorityClass is assigned to the pod templates of the listed workloads. The update is
+	// deferred until a maintenance window is open. Removed restores the original priority classes.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Name of the PriorityClass.
+	// +kubebuilder:default="sdi-critical"
+	Name string `json:"name,omitempty"`
+	// If set, the PriorityClass is created by the operator with this value. Otherwise, it must exist.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Maximum=1000000000
+	Value *int32 `json:"value,omitempty"`
+	// Names of the deployments and statefulsets in the SDI namespace. Missing workloads are skipped.
+	// +kubebuilder:default={"hana","vora-dlog","vora-disk","vora-tx-coordinator","vsystem","vsystem-vrep"}
+	Workloads []string `json:"workloads,omitempty"`
+}
+
+// SDIObserverSpecResourceOverrides overrides the resources of the SDI components, typically of the
+// diagnostics stack (fluentd, elasticsearch, prometheus).
+type SDIObserverSpecResourceOverrides struct {
+	// When Managed, the resources are patched into the workloads and re-applied on drift. The update is
+	// deferred until a maintenance window is open. Removed restores the original resources.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Resource overrides keyed by the name of the Deployment, StatefulSet or DaemonSet in the SDI namespace
+	// (e.g. diagnostics-fluentd) applying to all its containers, or by <workload>/<container> applying to a
+	// single container. The given requests and limits replace the corresponding ones of the containers, the
+	// others are kept.
+	// +kubebuilder:validation:Optional
+	Components map[string]corev1.ResourceRequirements `json:"components,omitempty"`
+}
+
+// SDIObserverSpecDashboard controls the Grafana dashboard of the managed SDI components.
+type SDIObserverSpecDashboard struct {
+	// When Managed, a dashboard covering the route availability, DataHub readiness, reconcile errors and
+	// certificate expiry is maintained in the namespace of the SDIObserver. A GrafanaDashboard resource is
+	// created if the Grafana operator is installed, otherwise a ConfigMap labeled for the dashboard sidecar.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Labels of the dashboard resource, e.g. to match the dashboardLabelSelector of the Grafana instance.
+	// +kubebuilder:validation:Optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// SDIObserverSpecInsights controls the health report gathered by the OpenShift Insights.
+type SDIObserverSpecInsights struct {
+	// When Managed, a summary of the observed state is kept in an annotation of the managed DataHub resource,
+	// which the Insights Operator gathers on the SAP clusters. The summary contains the operator and SDI
+	// versions, the health and the condition reasons; no messages, names or addresses. Removed deletes the
+	// annotation.
+	// +kubebuilder:default="Managed"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+}
+
+// SDIObserverSpecVersionSkew controls the reaction to an OpenShift release not supporting the SDI release.
+type SDIObserverSpecVersionSkew struct {
+	// Defer the disruptive changes (those subject to the maintenance windows) while the VersionSkewDetected
+	// condition is True. They are applied once the releases are compatible again.
+	// +kubebuilder:validation:Optional
+	PauseDisruptiveChanges bool `json:"pauseDisruptiveChanges,omitempty"`
+}
+
+// SDIObserverSpecBackupHook runs the commands in the pods of a workload around the Velero backup of its
+// volumes.
+type SDIObserverSpecBackupHook struct {
+	// Name of the deployment or statefulset in the SDI namespace. A missing workload is skipped.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Workload string `json:"workload"`
+	// Container to run the commands in. Velero picks the first container if unset.
+	// +kubebuilder:validation:Optional
+	Container string `json:"container,omitempty"`
+	// Command run before the volumes are backed up, e.g. to quiesce the database.
+	// +kubebuilder:validation:Optional
+	Pre []string `json:"pre,omitempty"`
+	// Command run after the volumes are backed up.
+	// +kubebuilder:validation:Optional
+	Post []string `json:"post,omitempty"`
+	// Whether a failed pre command fails the backup.
+	// +kubebuilder:default="Continue"
+	// +kubebuilder:validation:Enum=Continue;Fail
+	OnError string `json:"onError,omitempty"`
+	// How long Velero waits for the pre command.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// SDIObserverSpecBackupSchedule describes the Velero Schedule of the SDI namespace.
+type SDIObserverSpecBackupSchedule struct {
+	// Cron expression of the backups, e.g. "0 1 * * *".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Cron string `json:"cron"`
+	// Namespace of Velero where the Schedule is created.
+	// +kubebuilder:default="openshift-adp"
+	Namespace string `json:"namespace,omitempty"`
+	// How long the backups are kept.
+	// +kubebuilder:default="720h"
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// SDIObserverSpecBackup prepares the SDI namespace for the backup with Velero or the OADP operator.
+type SDIObserverSpecBackup struct {
+	// When Managed, the hooks are annotated on the pod templates of the workloads, the cache volumes are
+	// excluded from the backup and the optional Schedule is created. The update of the pod templates is deferred
+	// until a maintenance window is open. Removed reverts all of it.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// The backup hooks. If empty, a savepoint of the hana statefulset is taken before the backup using the
+	// hdbuserstore key BACKUP of the hana container.
+	// +kubebuilder:validation:Optional
+	Hooks []SDIObserverSpecBackupHook `json:"hooks,omitempty"`
+	// Regular expressions matching the names of the persistent volume claims left out of the backup, typically
+	// the caches rebuilt by SDI.
+	// +kubebuilder:default={"^layers-volume-","^(storage-)?diagnostics-"}
+	ExcludedClaims []string `json:"excludedClaims,omitempty"`
+	// The Velero Schedule backing up the SDI namespace. None is created if unset.
+	// +kubebuilder:validation:Optional
+	Schedule *SDIObserverSpecBackupSchedule `json:"schedule,omitempty"`
+}
+
+// SDIObserverSpecWebhook is an endpoint receiving the notifications.
+type SDIObserverSpecWebhook struct {
+	// Name of the webhook used in the logs and events.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Secret in the namespace of the SDIObserver holding the URL of the webhook. The key defaults to "url".
+	// +kubebuilder:validation:Required
+	URLSecretRef SecretKeySelector `json:"urlSecretRef"`
+	// Generic posts a JSON object with the fields of the notification. Slack posts a message compatible with
+	// the Slack incoming webhooks.
+	// +kubebuilder:default="Generic"
+	// +kubebuilder:validation:Enum=Generic;Slack
+	Format string `json:"format,omitempty"`
+}
+
+// SDIObserverSpecNotifications configures the webhooks notified about the state transitions.
+type SDIObserverSpecNotifications struct {
+	// The webhooks to post to whenever the Ready, Degraded or DataHubReady condition changes its status or the
+	// certificate of a managed route is about to expire.
+	// +kubebuilder:validation:Optional
+	Webhooks []SDIObserverSpecWebhook `json:"webhooks,omitempty"`
+	// How long before the expiry of a route certificate to notify.
+	// +kubebuilder:default="336h"
+	// +kubebuilder:validation:Optional
+	CertificateExpiryThreshold *metav1.Duration `json:"certificateExpiryThreshold,omitempty"`
+}
+
+// SDIObserverSpecDiscovery controls the discovery of the DataHub resources across the cluster.
+type SDIObserverSpecDiscovery struct {
+	// When Managed, the SDIObserver becomes a template not managing any namespace itself. A copy named
+	// <name>-<namespace> is created for each namespace with a DataHub resource and deleted once the DataHub
+	// disappears. Removed deletes the copies. Requires the operator to run with --discover-datahubs.
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+}
+
+// SDIObserverSpecVSystemHealth controls the polling of the vsystem API for the tenant and application health.
+type SDIObserverSpecVSystemHealth struct {
+	// Managed enables the polling. Removed clears the reported health. Unmanaged by default.
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Name of the secret in the namespace of the SDIObserver holding the credentials of a tenant user under
+	// the "username" and "password" keys. The "tenant" key defaults to "default".
+	// +kubebuilder:validation:Optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// The vsystem applications whose health is checked.
+	// +kubebuilder:default={"datahub-app-launchpad","pipeline-modeler"}
+	// +kubebuilder:validation:Optional
+	Applications []string `json:"applications,omitempty"`
+	// How often the vsystem API is polled.
+	// +kubebuilder:default="5m"
+	Interval *metav1.Duration `json:"interval,omitempty"`
 }
 
 // SDIObserverSpec defines the desired state of SDIObserver
@@ -65,6 +685,106 @@ type SDIObserverSpec struct {
 
 	VSystemRoute SDIObserverSpecRoute `json:"vsystemRoute"`
 	SLCBRoute    SDIObserverSpecRoute `json:"slcbRoute"`
+	// Route of the Grafana of the SDI diagnostics.
+	// +kubebuilder:validation:Optional
+	DiagnosticsGrafanaRoute SDIObserverSpecServiceRoute `json:"diagnosticsGrafanaRoute,omitempty"`
+	// Route of the Kibana of the SDI diagnostics.
+	// +kubebuilder:validation:Optional
+	DiagnosticsKibanaRoute SDIObserverSpecServiceRoute `json:"diagnosticsKibanaRoute,omitempty"`
+	// Route of the Vora tools serving the vora-cluster management UI.
+	// +kubebuilder:validation:Optional
+	VoraToolsRoute SDIObserverSpecServiceRoute `json:"voraToolsRoute,omitempty"`
+	// Alerts generated for the managed components.
+	// +kubebuilder:validation:Optional
+	Alerts SDIObserverSpecAlerts `json:"alerts,omitempty"`
+	// How often the vsystem and slcb route endpoints are probed. Set to 0s to disable the probing.
+	// +kubebuilder:default="5m"
+	RouteProbeInterval *metav1.Duration `json:"routeProbeInterval,omitempty"`
+	// NetworkPolicies hardening the SDI and SLCB namespaces.
+	// +kubebuilder:validation:Optional
+	NetworkPolicies SDIObserverSpecNetworkPolicies `json:"networkPolicies,omitempty"`
+	// Injection of the cluster proxy settings into the SDI workloads.
+	// +kubebuilder:validation:Optional
+	ProxyInjection SDIObserverSpecProxyInjection `json:"proxyInjection,omitempty"`
+	// Trust settings of the registry hosting the SDI images.
+	// +kubebuilder:validation:Optional
+	Registry SDIObserverSpecRegistry `json:"registry,omitempty"`
+	// Preparation of the SDI nodes.
+	// +kubebuilder:validation:Optional
+	NodeConfig SDIObserverSpecNodeConfig `json:"nodeConfig,omitempty"`
+	// Tuning of the nodes running the hana and vora pods.
+	// +kubebuilder:validation:Optional
+	NodeTuning SDIObserverSpecNodeTuning `json:"nodeTuning,omitempty"`
+	// Tailored profile of the Compliance Operator documenting the deviations required by SDI.
+	// +kubebuilder:validation:Optional
+	Compliance SDIObserverSpecCompliance `json:"compliance,omitempty"`
+	// Patches of the vsystem-vrep StatefulSet.
+	// +kubebuilder:validation:Optional
+	VRep SDIObserverSpecVRep `json:"vRep,omitempty"`
+	// Verification of the Pipeline Modeler configuration.
+	// +kubebuilder:validation:Optional
+	PipelineModeler SDIObserverSpecPipelineModeler `json:"pipelineModeler,omitempty"`
+	// SecurityContextConstraints granted to the SDI service accounts.
+	// +kubebuilder:validation:Optional
+	SecurityContextConstraints SDIObserverSpecSCC `json:"securityContextConstraints,omitempty"`
+	// Pod Security Admission labels of the SDI and SLCB namespaces.
+	// +kubebuilder:validation:Optional
+	PodSecurity SDIObserverSpecPodSecurity `json:"podSecurity,omitempty"`
+	// Report of the SCCs used by the SDI service accounts for the security reviews.
+	// +kubebuilder:validation:Optional
+	SCCReport SDIObserverSpecSCCReport `json:"sccReport,omitempty"`
+	// Image pull secret for the SDI service accounts, e.g. for pulling from a private mirror.
+	// +kubebuilder:validation:Optional
+	ImagePullSecret SDIObserverSpecImagePullSecret `json:"imagePullSecret,omitempty"`
+	// Bound service account tokens for the SDI integrations rotated before their expiration.
+	// +kubebuilder:validation:Optional
+	ServiceAccountTokens SDIObserverSpecServiceAccountTokens `json:"serviceAccountTokens,omitempty"`
+	// Tolerations and node affinity of the SDI workloads.
+	// +kubebuilder:validation:Optional
+	Scheduling SDIObserverSpecScheduling `json:"scheduling,omitempty"`
+	// PriorityClass of the critical SDI components protecting them from node-pressure evictions.
+	// +kubebuilder:validation:Optional
+	PriorityClass SDIObserverSpecPriorityClass `json:"priorityClass,omitempty"`
+	// Resource requests and limits of the SDI components.
+	// +kubebuilder:validation:Optional
+	ResourceOverrides SDIObserverSpecResourceOverrides `json:"resourceOverrides,omitempty"`
+	// Grafana dashboard of the managed SDI components.
+	// +kubebuilder:validation:Optional
+	Dashboard SDIObserverSpecDashboard `json:"dashboard,omitempty"`
+	// Polling of the vsystem API for the health of the tenant and the core applications.
+	// +kubebuilder:validation:Optional
+	VSystemHealth SDIObserverSpecVSystemHealth `json:"vsystemHealth,omitempty"`
+	// The number of the concurrent reconciliations of the controller managing the SDI namespace. Overrides the
+	// --namespaced-max-concurrent-reconciles flag of the operator. The controller is restarted on a change.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentReconciles *int32 `json:"maxConcurrentReconciles,omitempty"`
+	// Discovery of the DataHub resources across the cluster.
+	// +kubebuilder:validation:Optional
+	Discovery SDIObserverSpecDiscovery `json:"discovery,omitempty"`
+	// Health report for the OpenShift Insights.
+	// +kubebuilder:validation:Optional
+	Insights SDIObserverSpecInsights `json:"insights,omitempty"`
+	// Reaction to an unsupported combination of the OpenShift and SDI releases.
+	// +kubebuilder:validation:Optional
+	VersionSkew SDIObserverSpecVersionSkew `json:"versionSkew,omitempty"`
+	// The set of the patches suited to the SDI release. Auto selects it by the version of the DataHub
+	// resource. SDI-3.2 covers the releases up to 3.2, SDI-3.3 the later ones.
+	// +kubebuilder:default="Auto"
+	// +kubebuilder:validation:Enum=Auto;SDI-3.2;SDI-3.3
+	Profile string `json:"profile,omitempty"`
+	// Patches of the diagnostics components.
+	// +kubebuilder:validation:Optional
+	Diagnostics SDIObserverSpecDiagnostics `json:"diagnostics,omitempty"`
+	// Webhook notifications about the state transitions for the teams without Alertmanager.
+	// +kubebuilder:validation:Optional
+	Notifications SDIObserverSpecNotifications `json:"notifications,omitempty"`
+	// Integration with Velero and the OADP operator.
+	// +kubebuilder:validation:Optional
+	Backup SDIObserverSpecBackup `json:"backup,omitempty"`
+	// Suspends the management of the SDI namespace. The managed objects are left as they are until resumed.
+	// +kubebuilder:validation:Optional
+	Paused bool `json:"paused,omitempty"`
 
 	// TODO: add
 	//nodeSelector map[string]string
@@ -82,12 +802,67 @@ type SDIObserverRouteStatus struct {
 	// - Degraded
 	//     True when the desired state cannot be achieved (route is not admitted with Managed or route cannot
 	//     be removed).
+	// - Reachable
+	//     True when the last probe of the route endpoint succeeded (TLS handshake, certificate chain and
+	//     HTTP status).
+	// - DestinationCAVerified
+	//     True when the serving certificate of the service verifies against the rotated destination CA bundle
+	//     of the route.
 	// +optional
 	// +patchMergeKey=type
 	// +patchStrategy=merge
 	Conditions []metav1.Condition `json:"conditions"`
+	// When the route endpoint was probed the last time.
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+	// The error of the last failed probe. Empty if the last probe succeeded.
+	LastProbeError string `json:"lastProbeError,omitempty"`
+	// SHA-256 fingerprint of the destination CA bundle applied to the route.
+	DestinationCAFingerprint string `json:"destinationCAFingerprint,omitempty"`
+	// When a rotated destination CA bundle was applied to the route the last time.
+	LastDestinationCARotationTime *metav1.Time `json:"lastDestinationCARotationTime,omitempty"`
+	// The address of the Service of the loadBalancer or nodePort exposure. Unset with the other exposures.
+	Service *SDIObserverRouteServiceStatus `json:"service,omitempty"`
+}
+
+// SDIObserverRouteServiceStatus is the address of the Service publishing vsystem.
+type SDIObserverRouteServiceStatus struct {
+	// Name of the Service.
+	Name string             `json:"name"`
+	Type corev1.ServiceType `json:"type"`
+	// The IP addresses or hostnames of the load balancer. Empty until the load balancer is provisioned and with
+	// the nodePort exposure.
+	Hosts []string `json:"hosts,omitempty"`
+	// The port of the Service.
+	Port int32 `json:"port,omitempty"`
+	// The port opened on every node.
+	NodePort int32 `json:"nodePort,omitempty"`
 }
 
+// ConditionDataHubReady mirrors the state of the managed DataHub resource.
+const ConditionDataHubReady = "DataHubReady"
+
+// ConditionDuplicateDataHubs is True when the SDI namespace contains more than one DataHub resource. Only the
+// one named default (or the first one by name) is managed.
+const ConditionDuplicateDataHubs = "DuplicateDataHubs"
+
+// ConditionVersionSkewDetected is True when the OpenShift release does not support the installed SDI release.
+const ConditionVersionSkewDetected = "VersionSkewDetected"
+
+// ConditionRouteAPIAvailable is False when the cluster does not serve the route.openshift.io API and the routes
+// are not managed.
+const ConditionRouteAPIAvailable = "RouteAPIAvailable"
+
+// ConditionClusterScopeAvailable is False when the operator runs with the namespace-scoped RBAC and the features
+// needing the cluster-scoped permissions are disabled.
+const ConditionClusterScopeAvailable = "ClusterScopeAvailable"
+
+// ConditionPodSecurityLabeled is False when the Pod Security Admission labels cannot be set on the namespaces,
+// e.g. due to the cluster policy.
+const ConditionPodSecurityLabeled = "PodSecurityLabeled"
+
+// ConditionPaused is True while the management of the SDI namespace is suspended with spec.paused.
+const ConditionPaused = "Paused"
+
 const (
 	// ConditionReasonNotFound indicates that no DataHub instance exists in the configured SDINamespace.
 	ConditionReasonNotFound       = "NotFound"
@@ -103,8 +878,88 @@ const (
 	// ConditionReasonBackup indicates that the desired spec is not being worked on because the there is
 	// another active instance managing the SDI namespace.
 	ConditionReasonBackup = "Backup"
+	// ConditionReasonNamespaceNotFound indicates that the configured SDINamespace does not exist or is being
+	// deleted. The management resumes once the namespace is created again.
+	ConditionReasonNamespaceNotFound = "NamespaceNotFound"
 )
 
+// SDIObserverDataHubStatus summarizes the status of the managed DataHub resource.
+type SDIObserverDataHubStatus struct {
+	// The state reported by the DataHub resource (e.g. Ready, Installing, Failed).
+	State string `json:"state,omitempty"`
+	// The message accompanying the state.
+	Message string `json:"message,omitempty"`
+	// The installed SDI version.
+	Version string `json:"version,omitempty"`
+	// Number of the ready components out of TotalComponents reported by the DataHub resource.
+	ReadyComponents int32 `json:"readyComponents,omitempty"`
+	// Number of the components reported by the DataHub resource.
+	TotalComponents int32 `json:"totalComponents,omitempty"`
+	// Names of the components that are not ready.
+	NotReadyComponents []string `json:"notReadyComponents,omitempty"`
+}
+
+// SDIObserverVSystemHealthStatus informs about the health of SDI as seen through the vsystem API.
+type SDIObserverVSystemHealthStatus struct {
+	// Condition types:
+	// - TenantAvailable
+	//     True when the tenant user could log in to vsystem.
+	// - ApplicationsHealthy
+	//     True when all the checked applications respond without a server error.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions"`
+	// The tenant the health is checked for.
+	Tenant string `json:"tenant,omitempty"`
+	// When the vsystem API was polled the last time.
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Names of the applications that failed the last check.
+	UnhealthyApplications []string `json:"unhealthyApplications,omitempty"`
+}
+
+// SDIObserverSCCReportStatus summarizes the last SCC usage report.
+type SDIObserverSCCReportStatus struct {
+	// When the report was refreshed the last time.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+	// The name of the ConfigMap with the full report.
+	ConfigMap string `json:"configMap,omitempty"`
+	// The tighter SCC bindings recommended for the SDI service accounts.
+	Recommendations []string `json:"recommendations,omitempty"`
+}
+
+// SDIObserverServiceAccountTokenStatus describes an issued bound service account token.
+type SDIObserverServiceAccountTokenStatus struct {
+	ServiceAccount string `json:"serviceAccount"`
+	// The secret holding the token.
+	SecretName string `json:"secretName"`
+	// When the token expires.
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+	// When the token is going to be requested anew.
+	RenewTime *metav1.Time `json:"renewTime,omitempty"`
+	// The long-lived legacy token secrets of the service account still present. The integrations using them
+	// should be switched to the bound token.
+	LegacySecrets []string `json:"legacySecrets,omitempty"`
+}
+
+// SDIObserverPermissionsStatus is the result of the self-check of the permissions the operator needs in the SDI
+// and SLCB namespaces.
+type SDIObserverPermissionsStatus struct {
+	// When the permissions were reviewed the last time.
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// The rules the operator is not granted in the form "<verbs> <resource>[.<group>] in <namespace>".
+	MissingRules []string `json:"missingRules,omitempty"`
+}
+
+// SDIObserverHealth summarizes the conditions in the terms of the Argo CD health assessment.
+type SDIObserverHealth struct {
+	// Healthy, Progressing, Degraded or Suspended (for a Backup or paused instance).
+	// +kubebuilder:validation:Enum=Healthy;Progressing;Degraded;Suspended
+	Status string `json:"status"`
+	// Explanation of the status taken from the deciding condition.
+	Message string `json:"message,omitempty"`
+}
+
 // SDIObserverStatus defines the observed state of SDIObserver.
 type SDIObserverStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -114,6 +969,13 @@ type SDIObserverStatus struct {
 	// - Progressing
 	// - Ready - a consolidated condition being true when all the dependencies are fulfilled
 	// - Backup - if true, there is another SDIObserver instance managing the target SDINamespace
+	// - MaintenancePending - if true, the injection of the proxy settings or the vsystem-vrep patch waits for
+	//   a maintenance window
+	// - DataHubReady - mirrors whether the managed DataHub resource reports the Ready state
+	// - DuplicateDataHubs - if true, the SDI namespace contains more DataHub resources and only one is managed
+	// - VersionSkewDetected - if true, the OpenShift release does not support the installed SDI release
+	// - RouteAPIAvailable - if false, the cluster does not serve the route API and the routes are not managed
+	// - Paused - if true, the SDI namespace is not managed because of spec.paused
 	// +optional
 	// +patchMergeKey=type
 	// +patchStrategy=merge
@@ -121,14 +983,62 @@ type SDIObserverStatus struct {
 	// Reference to the DataHub resource found in the configured SDINamespace. It is left unset if the
 	// resource does not exist or another instance is managing it.
 	ManagedDataHubRef *corev1.ObjectReference `json:"managedDataHubs,omitempty"`
+	// Summary of the status of the managed DataHub resource.
+	DataHub *SDIObserverDataHubStatus `json:"dataHub,omitempty"`
+	// Health of the tenant and the core applications reported by the vsystem API. Unset when not managed.
+	VSystemHealth *SDIObserverVSystemHealthStatus `json:"vsystemHealth,omitempty"`
+	// Summary of the SCC usage report. Unset when not managed.
+	SCCReport *SDIObserverSCCReportStatus `json:"sccReport,omitempty"`
+	// The result of the periodic review of the permissions in the SDI and SLCB namespaces. The missing ones make
+	// the instance Degraded.
+	Permissions *SDIObserverPermissionsStatus `json:"permissions,omitempty"`
+	// The bound service account tokens issued for the SDI integrations.
+	ServiceAccountTokens []SDIObserverServiceAccountTokenStatus `json:"serviceAccountTokens,omitempty"`
 	// Status of the vsystem route. Conditions will be empty when not managed.
 	VSystemRoute SDIObserverRouteStatus `json:"vsystemRoute,omitempty"`
 	// Status of the slcb route. Conditions will be empty when not managed.
 	SLCBRoute SDIObserverRouteStatus `json:"slcbRoute,omitempty"`
+	// Status of the route of the diagnostics Grafana. Unset when not managed.
+	DiagnosticsGrafanaRoute *SDIObserverRouteStatus `json:"diagnosticsGrafanaRoute,omitempty"`
+	// Status of the route of the diagnostics Kibana. Unset when not managed.
+	DiagnosticsKibanaRoute *SDIObserverRouteStatus `json:"diagnosticsKibanaRoute,omitempty"`
+	// Status of the route of the Vora tools. Unset when not managed.
+	VoraToolsRoute *SDIObserverRouteStatus `json:"voraToolsRoute,omitempty"`
+	// The namespaces with a DataHub resource managed by the copies of this SDIObserver. Set only with the
+	// discovery Managed.
+	DiscoveredNamespaces []string `json:"discoveredNamespaces,omitempty"`
+	// The health derived from the conditions. It can be mapped 1:1 by the Argo CD health check.
+	Health *SDIObserverHealth `json:"health,omitempty"`
+	// The profile of the patches in effect.
+	Profile string `json:"profile,omitempty"`
+	// The objects managed by the SDIObserver without an owner reference, e.g. those in other namespaces or
+	// cluster-scoped. Refreshed periodically by the orphan collector.
+	Inventory []ManagedObjectReference `json:"inventory,omitempty"`
+	// The URL of vsystem. Set once the route is admitted or the load balancer is provisioned.
+	VSystemURL string `json:"vsystemURL,omitempty"`
+	// The URL of the SLC Bridge. Set once its route is admitted.
+	SLCBURL string `json:"slcbURL,omitempty"`
+	// The URL of the SDI Registry deployed in the namespace of the SDIObserver. Set once its route is admitted.
+	RegistryURL string `json:"registryURL,omitempty"`
+}
+
+// ManagedObjectReference identifies an object managed by the operator.
+type ManagedObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Empty for the cluster-scoped objects.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="SDI Namespace",type=string,JSONPath=`.spec.sdiNamespace`
+//+kubebuilder:printcolumn:name="DataHub",type=string,JSONPath=`.status.dataHub.state`
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.dataHub.version`
+//+kubebuilder:printcolumn:name="Health",type=string,JSONPath=`.status.health.status`
+//+kubebuilder:printcolumn:name="VSystem URL",type=string,JSONPath=`.status.vsystemURL`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // SDIObserver is the Schema for the sdiobservers API.
 type SDIObserver struct {
@@ -0,0 +1,257 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The storage backends of the registry.
+const (
+	SDIRegistryStorageBackendPVC               = "PVC"
+	SDIRegistryStorageBackendObjectBucketClaim = "ObjectBucketClaim"
+	SDIRegistryStorageBackendS3                = "S3"
+)
+
+// ConditionCredentialsPublished is true when the credential secrets are up to date in all the namespaces.
+const ConditionCredentialsPublished = "CredentialsPublished"
+
+// ConditionStorageMigrationBlocked is true while the storage backend of the registry is not switched to the
+// desired one because the migration has not been allowed.
+const ConditionStorageMigrationBlocked = "StorageMigrationBlocked"
+
+// SDIRegistrySpec defines the desired state of SDIRegistry.
+type SDIRegistrySpec struct {
+	// The image of the container image registry (docker distribution). Defaults to RELATED_IMAGE_REGISTRY of the
+	// operator.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+	// The storage of the registry.
+	// +kubebuilder:validation:Optional
+	Storage SDIRegistrySpecStorage `json:"storage,omitempty"`
+	// The htpasswd authentication of the registry.
+	// +kubebuilder:validation:Optional
+	Auth SDIRegistrySpecAuth `json:"auth,omitempty"`
+	// Controls the exposure of the registry via a reencrypt route.
+	// +kubebuilder:validation:Optional
+	Route SDIRegistrySpecRoute `json:"route,omitempty"`
+	// Schedules the garbage collection of the blobs no longer referenced by any image.
+	// +kubebuilder:validation:Optional
+	GarbageCollection SDIRegistrySpecGarbageCollection `json:"garbageCollection,omitempty"`
+	// Controls the PrometheusRule with the storage and garbage collection alerts of the registry.
+	// +kubebuilder:validation:Optional
+	Alerts SDIRegistrySpecAlerts `json:"alerts,omitempty"`
+}
+
+// SDIRegistrySpecAlerts configures the alerts of the registry. The storage usage is taken from the kubelet
+// volume statistics of the PVC; the namespace of the SDIRegistry needs to be monitored.
+type SDIRegistrySpecAlerts struct {
+	// +kubebuilder:default="Managed"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Fire a warning when the registry volume is used above the given percentage.
+	// +kubebuilder:default=80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	StorageUsageWarningPercent int32 `json:"storageUsageWarningPercent,omitempty"`
+	// Fire a critical alert when the registry volume is used above the given percentage.
+	// +kubebuilder:default=90
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	StorageUsageCriticalPercent int32 `json:"storageUsageCriticalPercent,omitempty"`
+}
+
+// SDIRegistrySpecGarbageCollection configures the cron job running "registry garbage-collect". The registry is
+// switched to read-only mode for the duration of the job, rejecting pushes, and restarted twice; the schedule
+// should fall into a period without pipeline builds.
+type SDIRegistrySpecGarbageCollection struct {
+	// The schedule of the cron job in the cron format. Unless set, the garbage collection is disabled.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:example="0 3 * * 0"
+	Schedule string `json:"schedule,omitempty"`
+	// Delete the manifests not referenced by any tag as well. The images pulled by digest only are lost.
+	// +kubebuilder:validation:Optional
+	DeleteUntagged bool `json:"deleteUntagged,omitempty"`
+}
+
+// SDIRegistrySpecRoute controls the route of the registry. The registry serves a certificate issued by the
+// service CA, which the route trusts as the destination CA.
+type SDIRegistrySpecRoute struct {
+	// +kubebuilder:default="Managed"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// Unless set, the hostname is generated by the router.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern="[[:alnum:]]+(-[[:alnum:]]+)*(\\.[[:alnum:]]+(-[[:alnum:]]+)*)*"
+	Hostname string `json:"hostname,omitempty"`
+	// Do not add the route hostname and the ingress CA to the cluster image config. Unless skipped, the nodes
+	// trust the registry.
+	// +kubebuilder:validation:Optional
+	SkipClusterTrust bool `json:"skipClusterTrust,omitempty"`
+}
+
+// SDIRegistrySpecAuth configures the htpasswd file of the registry and the publishing of its credential.
+type SDIRegistrySpecAuth struct {
+	// A secret in the namespace of the SDIRegistry with the htpasswd file under the htpasswd key to use instead
+	// of the generated one. If it contains a <user>:<password> line under the .htpasswd.raw key as well, the
+	// credential is published.
+	// +kubebuilder:validation:Optional
+	HtpasswdSecretRef *corev1.LocalObjectReference `json:"htpasswdSecretRef,omitempty"`
+	// Any new value regenerates the credential of the generated htpasswd file. The former credential is accepted
+	// by the registry until the published secrets carry the new one.
+	// +kubebuilder:validation:Optional
+	RotateCredentials string `json:"rotateCredentials,omitempty"`
+	// The namespaces to publish the kubernetes.io/dockerconfigjson secret with the pull and push credential to.
+	// Defaults to the SDI and SLCB namespaces of the operator.
+	// +kubebuilder:validation:Optional
+	CredentialNamespaces []string `json:"credentialNamespaces,omitempty"`
+	// The name of the published credential secrets.
+	// +kubebuilder:default="container-image-registry-credentials"
+	CredentialSecretName string `json:"credentialSecretName,omitempty"`
+}
+
+// SDIRegistrySpecStorage selects and configures the storage backend of the registry.
+type SDIRegistrySpecStorage struct {
+	// Either PVC for a persistent volume, ObjectBucketClaim for an S3 bucket provisioned by ODF or S3 for an
+	// existing bucket of an S3 compatible object storage.
+	// +kubebuilder:default="PVC"
+	// +kubebuilder:validation:Enum=PVC;ObjectBucketClaim;S3
+	Backend string `json:"backend,omitempty"`
+	// The persistent volume claim used by the PVC backend.
+	// +kubebuilder:validation:Optional
+	PVC SDIRegistrySpecPVC `json:"pvc,omitempty"`
+	// The object bucket claim used by the ObjectBucketClaim backend.
+	// +kubebuilder:validation:Optional
+	ObjectBucketClaim SDIRegistrySpecObjectBucketClaim `json:"objectBucketClaim,omitempty"`
+	// The bucket used by the S3 backend.
+	// +kubebuilder:validation:Optional
+	S3 SDIRegistrySpecS3 `json:"s3,omitempty"`
+	// Allow switching the backend of a deployed registry. The stored images are not copied to the new backend;
+	// they need to be mirrored again. The storage of the former backend is kept until the SDIRegistry is deleted.
+	// +kubebuilder:validation:Optional
+	AllowMigration bool `json:"allowMigration,omitempty"`
+}
+
+// SDIRegistrySpecPVC configures the persistent volume claim of the registry.
+type SDIRegistrySpecPVC struct {
+	// The requested size of the volume. It can be increased later if the storage class allows volume expansion.
+	// +kubebuilder:default="120Gi"
+	Size *resource.Quantity `json:"size,omitempty"`
+	// The storage class of the volume. Unless set, the default storage class is used.
+	// +kubebuilder:validation:Optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// With ReadWriteOnce, the registry is restarted with the Recreate strategy. Cannot be changed once the claim
+	// exists.
+	// +kubebuilder:default="ReadWriteOnce"
+	// +kubebuilder:validation:Enum=ReadWriteMany;ReadWriteOnce
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+}
+
+// SDIRegistrySpecObjectBucketClaim configures the object bucket claim of the registry.
+type SDIRegistrySpecObjectBucketClaim struct {
+	// The storage class of the bucket provisioner.
+	// +kubebuilder:default="openshift-storage.noobaa.io"
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+// SDIRegistrySpecS3 configures the bucket of an S3 compatible object storage like ODF RGW, MinIO or AWS S3. The
+// endpoint, the bucket and the credentials secret are required by the S3 backend.
+type SDIRegistrySpecS3 struct {
+	// The URL of the endpoint. For example: https://rgw.example.com:443
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern="^https?://[^/]+/?$"
+	Endpoint string `json:"endpoint,omitempty"`
+	// The existing bucket to store the images in.
+	// +kubebuilder:validation:Optional
+	Bucket string `json:"bucket,omitempty"`
+	// The region of the bucket.
+	// +kubebuilder:default="us-east-1"
+	Region string `json:"region,omitempty"`
+	// A secret in the namespace of the SDIRegistry with the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys,
+	// like the one created for an object bucket claim. The registry is restarted when the keys change.
+	// +kubebuilder:validation:Optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+	// The PEM encoded CA certificates to verify the endpoint with in addition to the system ones.
+	// +kubebuilder:validation:Optional
+	CACertificate string `json:"caCertificate,omitempty"`
+	// Skip the verification of the certificate of an https endpoint.
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// SDIRegistryStatus defines the observed state of SDIRegistry.
+type SDIRegistryStatus struct {
+	// Used condition types:
+	// - CredentialsPublished - true when the credential secrets are up to date in all the namespaces
+	// - Degraded - a consolidated failure condition giving a hint on the failed component
+	// - Progressing - true while the registry deployment is being rolled out
+	// - Ready - true when the registry is available and exposed as desired
+	// - RouteAPIAvailable - if false, the cluster does not serve the route API and the route is not managed
+	// - StorageMigrationBlocked - true if the storage backend has been changed without allowing the migration
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions"`
+	// The generation of the spec the status corresponds to.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The storage backend the registry is deployed with.
+	StorageBackend string `json:"storageBackend,omitempty"`
+	// The address of the registry service within the cluster.
+	ServiceAddress string `json:"serviceAddress,omitempty"`
+	// The hostname of the admitted route.
+	Hostname string `json:"hostname,omitempty"`
+	// The secret with the htpasswd file mounted by the registry.
+	HtpasswdSecretName string `json:"htpasswdSecretName,omitempty"`
+	// The credential secrets published by the operator as <namespace>/<name>.
+	PublishedCredentials []string `json:"publishedCredentials,omitempty"`
+	// True while the registry is switched to read-only mode for the garbage collection.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// The completion time of the last successful garbage collection.
+	LastGarbageCollectionTime *metav1.Time `json:"lastGarbageCollectionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.status.storageBackend`
+//+kubebuilder:printcolumn:name="Hostname",type=string,JSONPath=`.status.hostname`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// SDIRegistry is the Schema for the sdiregistries API. It deploys a container image registry in its namespace
+// suitable to host the SAP DI images and the images built by the Pipeline Modeler; it replaces the
+// deploy-registry.sh script.
+type SDIRegistry struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SDIRegistrySpec   `json:"spec,omitempty"`
+	Status SDIRegistryStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SDIRegistryList contains a list of SDIRegistry
+type SDIRegistryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SDIRegistry `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SDIRegistry{}, &SDIRegistryList{})
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretKeySelector references a key of a secret in the same namespace as the referring object.
+type SecretKeySelector struct {
+	// Name of the secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key within the secret. A reasonable default is chosen by the consumer when unset.
+	// +kubebuilder:validation:Optional
+	Key string `json:"key,omitempty"`
+}
+
+// SDIStorageValidationSpecVolume describes the persistent volume claim to provision for the test.
+type SDIStorageValidationSpecVolume struct {
+	// Storage class to provision the test volume from. The cluster default is used when unset.
+	// +kubebuilder:validation:Optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// Access mode of the test volume. SAP DI requires ReadWriteMany for the vsystem-vrep and the pipeline
+	// modeler.
+	// +kubebuilder:default="ReadWriteMany"
+	// +kubebuilder:validation:Enum=ReadWriteMany;ReadWriteOnce
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+	// Requested size of the test volume.
+	// +kubebuilder:validation:Optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// Image used by the probe pod that mounts the volume and measures write and read latency. Defaults to
+	// RELATED_IMAGE_STORAGE_PROBE of the operator.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+}
+
+// SDIStorageValidationSpecAccessTest configures the job testing the permissions on the checkpoint store.
+type SDIStorageValidationSpecAccessTest struct {
+	// Image of the job. It must contain the operator binary. Defaults to RELATED_IMAGE_CHECKPOINT_PROBE of the
+	// operator.
+	// +kubebuilder:validation:Optional
+	Image string `json:"image,omitempty"`
+}
+
+// SDIStorageValidationSpecCheckpointStore describes an S3 compatible object storage used as the checkpoint
+// store of SAP DI.
+type SDIStorageValidationSpecCheckpointStore struct {
+	// URL of the S3 endpoint. For example: https://s3.us-east-1.amazonaws.com
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern="^https?://"
+	Endpoint string `json:"endpoint"`
+	// Name of the bucket that must exist and be accessible with the given credentials.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=3
+	Bucket string `json:"bucket"`
+	// +kubebuilder:default="us-east-1"
+	Region string `json:"region,omitempty"`
+	// Secret with the access key id. Defaults to the key "AWS_ACCESS_KEY_ID".
+	AccessKeyIDSecretRef SecretKeySelector `json:"accessKeyIDSecretRef"`
+	// Secret with the secret access key. Defaults to the key "AWS_SECRET_ACCESS_KEY".
+	SecretAccessKeySecretRef SecretKeySelector `json:"secretAccessKeySecretRef"`
+	// Skip the verification of the endpoint's certificate.
+	// +kubebuilder:validation:Optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+	// Put, get and delete a test object and read the versioning configuration of the bucket in a job running
+	// in the namespace of the resource. If unset, the operator only looks up the bucket.
+	// +kubebuilder:validation:Optional
+	AccessTest *SDIStorageValidationSpecAccessTest `json:"accessTest,omitempty"`
+}
+
+// SDIStorageValidationSpec defines the desired state of SDIStorageValidation.
+type SDIStorageValidationSpec struct {
+	// The test volume to provision. If unset, no volume is provisioned.
+	// +kubebuilder:validation:Optional
+	Volume *SDIStorageValidationSpecVolume `json:"volume,omitempty"`
+	// The checkpoint store to verify. If unset, no object storage is verified.
+	// +kubebuilder:validation:Optional
+	CheckpointStore *SDIStorageValidationSpecCheckpointStore `json:"checkpointStore,omitempty"`
+	// How long to wait for the volume to become bound and the probe to finish before giving up.
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Keep the test volume and the probe pod after the validation completes.
+	// +kubebuilder:validation:Optional
+	KeepTestObjects bool `json:"keepTestObjects,omitempty"`
+}
+
+const (
+	// ConditionReasonValidating indicates that the validation has not finished yet.
+	ConditionReasonValidating = "Validating"
+	// ConditionReasonTimeout indicates that the validation did not finish in time.
+	ConditionReasonTimeout = "Timeout"
+	// ConditionReasonUnreachable indicates that the remote endpoint cannot be reached.
+	ConditionReasonUnreachable = "Unreachable"
+	// ConditionReasonForbidden indicates that the remote endpoint refused the credentials.
+	ConditionReasonForbidden = "Forbidden"
+	// ConditionReasonNotConfigured indicates that the validated component is not specified.
+	ConditionReasonNotConfigured = "NotConfigured"
+)
+
+// SDIStorageValidationVolumeStatus reports the results of the volume validation.
+type SDIStorageValidationVolumeStatus struct {
+	// Name of the provisioned test claim.
+	ClaimName string `json:"claimName,omitempty"`
+	// Time it took for the claim to become bound.
+	BindDuration *metav1.Duration `json:"bindDuration,omitempty"`
+	// Time it took to write the probe file to the volume.
+	WriteDuration *metav1.Duration `json:"writeDuration,omitempty"`
+	// Time it took to read the probe file back from the volume.
+	ReadDuration *metav1.Duration `json:"readDuration,omitempty"`
+}
+
+// SDIStorageValidationCheckpointStoreStatus reports the results of the checkpoint store validation.
+type SDIStorageValidationCheckpointStoreStatus struct {
+	// Round trip time of the request verifying the bucket.
+	Latency *metav1.Duration `json:"latency,omitempty"`
+	// HTTP status code returned by the endpoint.
+	HTTPStatusCode int `json:"httpStatusCode,omitempty"`
+	// Name of the access test job.
+	AccessTestJobName string `json:"accessTestJobName,omitempty"`
+	// The operations passed by the access test job.
+	PassedOperations []string `json:"passedOperations,omitempty"`
+	// Versioning status of the bucket reported by the access test job. Empty if it has never been enabled.
+	Versioning string `json:"versioning,omitempty"`
+}
+
+// SDIStorageValidationStatus defines the observed state of SDIStorageValidation.
+type SDIStorageValidationStatus struct {
+	// Used condition types:
+	// - VolumeValidated - true when the test volume could be bound, written and read
+	// - CheckpointStoreValidated - true when the bucket is reachable with the given credentials
+	// - CheckpointStoreAccessValidated - true when the access test job could put, get and delete an object
+	// - Ready - a consolidated condition being true when all the validations passed
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions"`
+	// The generation of the spec the results correspond to.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// When the current validation round started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// When the last validation round finished.
+	LastValidationTime *metav1.Time                               `json:"lastValidationTime,omitempty"`
+	Volume             *SDIStorageValidationVolumeStatus          `json:"volume,omitempty"`
+	CheckpointStore    *SDIStorageValidationCheckpointStoreStatus `json:"checkpointStore,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+
+// SDIStorageValidation is the Schema for the sdistoragevalidations API. It verifies that the storage
+// prerequisites of SAP DI are fulfilled before or alongside the installation.
+type SDIStorageValidation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SDIStorageValidationSpec   `json:"spec,omitempty"`
+	Status SDIStorageValidationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SDIStorageValidationList contains a list of SDIStorageValidation
+type SDIStorageValidationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SDIStorageValidation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SDIStorageValidation{}, &SDIStorageValidationList{})
+}
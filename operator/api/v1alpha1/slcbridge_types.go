@@ -0,0 +1,139 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SLCBridgeSpec defines the desired state of SLCBridge.
+type SLCBridgeSpec struct {
+	// K8s namespace where the SAP Software Lifecycle Container Bridge shall run. The namespace is created
+	// unless it exists.
+	// +kubebuilder:default="sap-slcbridge"
+	// +kubebuilder:validation:MinLength=2
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern="[[:alnum:]]+(-[[:alnum:]]+)*"
+	Namespace string `json:"namespace,omitempty"`
+	// The slcbridgebase image. For example:
+	// <registry>/com.sap.sl.cbpod/slcbridgebase:1.1.72
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+	// +kubebuilder:default="IfNotPresent"
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Secrets in the bridge namespace used to pull the image from the SAP registry.
+	// +kubebuilder:validation:Optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Controls the exposure of the bridge service via a passthrough route. The hostname defaults to
+	// <namespace>.<cluster apps domain>.
+	// +kubebuilder:validation:Optional
+	Route SDIObserverSpecRoute `json:"route,omitempty"`
+	// Network settings of the bridge service.
+	// +kubebuilder:validation:Optional
+	Service SLCBridgeSpecService `json:"service,omitempty"`
+}
+
+// SLCBridgeSpecService configures the bridge service on IPv6 and dual-stack clusters.
+type SLCBridgeSpecService struct {
+	// The IP families of the service, e.g. [IPv6] or [IPv4, IPv6]. Unset, the cluster default applies.
+	// Note that the primary family of an existing service cannot be changed.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxItems=2
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+	// One of SingleStack, PreferDualStack or RequireDualStack. Unset, the cluster default applies.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=SingleStack;PreferDualStack;RequireDualStack
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+	// An additional NodePort or LoadBalancer service making the bridge reachable by slcb without a route.
+	// +kubebuilder:validation:Optional
+	External SLCBridgeSpecExternalService `json:"external,omitempty"`
+}
+
+// SLCBridgeSpecExternalService configures the service publishing the bridge on the nodes or a load balancer.
+// Its selector and ports follow the bridge service.
+// +kubebuilder:validation:XValidation:rule="!has(self.loadBalancerSourceRanges) || self.type == 'LoadBalancer'",message="loadBalancerSourceRanges require the LoadBalancer type"
+type SLCBridgeSpecExternalService struct {
+	// +kubebuilder:default="Unmanaged"
+	// +kubebuilder:validation:Enum=Managed;Unmanaged;Removed
+	ManagementState string `json:"managementState,omitempty"`
+	// +kubebuilder:default="NodePort"
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// The static port opened on every node for the bridge port. Allocated by the cluster if unset.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	NodePort int32 `json:"nodePort,omitempty"`
+	// Annotations of the service, e.g. to configure the load balancer of the cloud provider.
+	// +kubebuilder:validation:Optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// The client CIDRs allowed to connect through the load balancer.
+	// +kubebuilder:validation:Optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+}
+
+// SLCBridgeStatus defines the observed state of SLCBridge.
+type SLCBridgeStatus struct {
+	// Used condition types:
+	// - Degraded - a consolidated failure condition giving a hint on the failed component
+	// - Progressing - true while the bridge deployment is being rolled out
+	// - Ready - true when the bridge is available and exposed as desired
+	// - RouteAPIAvailable - if false, the cluster does not serve the route API and the route is not managed
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions"`
+	// The generation of the spec the status corresponds to.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The namespace where the bridge components are deployed.
+	Namespace string `json:"namespace,omitempty"`
+	// The URL of the bridge web UI. Empty unless the route is admitted.
+	URL string `json:"url,omitempty"`
+	// The address of the external bridge service. Unset unless managed.
+	ExternalService *SDIObserverRouteServiceStatus `json:"externalService,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.url`
+
+// SLCBridge is the Schema for the slcbridges API. It deploys and keeps up to date the SAP Software
+// Lifecycle Container Bridge which is otherwise installed with "slcb init".
+type SLCBridge struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SLCBridgeSpec   `json:"spec,omitempty"`
+	Status SLCBridgeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SLCBridgeList contains a list of SLCBridge
+type SLCBridgeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SLCBridge `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SLCBridge{}, &SLCBridgeList{})
+}
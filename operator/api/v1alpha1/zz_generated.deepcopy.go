@@ -21,32 +21,1863 @@ limitations under the License.
 package v1alpha1
 
 import (
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedObjectReference) DeepCopyInto(out *ManagedObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedObjectReference.
+func (in *ManagedObjectReference) DeepCopy() *ManagedObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIImageMirror) DeepCopyInto(out *SDIImageMirror) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIImageMirror.
+func (in *SDIImageMirror) DeepCopy() *SDIImageMirror {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIImageMirror)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIImageMirror) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIImageMirrorImageStatus) DeepCopyInto(out *SDIImageMirrorImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIImageMirrorImageStatus.
+func (in *SDIImageMirrorImageStatus) DeepCopy() *SDIImageMirrorImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIImageMirrorImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIImageMirrorList) DeepCopyInto(out *SDIImageMirrorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SDIImageMirror, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIImageMirrorList.
+func (in *SDIImageMirrorList) DeepCopy() *SDIImageMirrorList {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIImageMirrorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIImageMirrorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIImageMirrorSpec) DeepCopyInto(out *SDIImageMirrorSpec) {
+	*out = *in
+	if in.RegistryRef != nil {
+		in, out := &in.RegistryRef, &out.RegistryRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuthSecretRef != nil {
+		in, out := &in.AuthSecretRef, &out.AuthSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIImageMirrorSpec.
+func (in *SDIImageMirrorSpec) DeepCopy() *SDIImageMirrorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIImageMirrorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIImageMirrorStatus) DeepCopyInto(out *SDIImageMirrorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]SDIImageMirrorImageStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIImageMirrorStatus.
+func (in *SDIImageMirrorStatus) DeepCopy() *SDIImageMirrorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIImageMirrorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindow) DeepCopyInto(out *SDIMaintenanceWindow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindow.
+func (in *SDIMaintenanceWindow) DeepCopy() *SDIMaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIMaintenanceWindow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindowList) DeepCopyInto(out *SDIMaintenanceWindowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SDIMaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindowList.
+func (in *SDIMaintenanceWindowList) DeepCopy() *SDIMaintenanceWindowList {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIMaintenanceWindowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindowShutdownStatus) DeepCopyInto(out *SDIMaintenanceWindowShutdownStatus) {
+	*out = *in
+	if in.LastAction != nil {
+		in, out := &in.LastAction, &out.LastAction
+		*out = new(SDIShutdownActionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextAction != nil {
+		in, out := &in.NextAction, &out.NextAction
+		*out = new(SDIShutdownActionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindowShutdownStatus.
+func (in *SDIMaintenanceWindowShutdownStatus) DeepCopy() *SDIMaintenanceWindowShutdownStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindowShutdownStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindowSpec) DeepCopyInto(out *SDIMaintenanceWindowSpec) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]SDIMaintenanceWindowSpecWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(SDIMaintenanceWindowSpecShutdown)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindowSpec.
+func (in *SDIMaintenanceWindowSpec) DeepCopy() *SDIMaintenanceWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindowSpecShutdown) DeepCopyInto(out *SDIMaintenanceWindowSpecShutdown) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]SDIMaintenanceWindowSpecWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindowSpecShutdown.
+func (in *SDIMaintenanceWindowSpecShutdown) DeepCopy() *SDIMaintenanceWindowSpecShutdown {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindowSpecShutdown)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindowSpecWindow) DeepCopyInto(out *SDIMaintenanceWindowSpecWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]SDIMaintenanceWindowDay, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindowSpecWindow.
+func (in *SDIMaintenanceWindowSpecWindow) DeepCopy() *SDIMaintenanceWindowSpecWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindowSpecWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIMaintenanceWindowStatus) DeepCopyInto(out *SDIMaintenanceWindowStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextStart != nil {
+		in, out := &in.NextStart, &out.NextStart
+		*out = (*in).DeepCopy()
+	}
+	if in.CurrentEnd != nil {
+		in, out := &in.CurrentEnd, &out.CurrentEnd
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingChanges != nil {
+		in, out := &in.PendingChanges, &out.PendingChanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(SDIMaintenanceWindowShutdownStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIMaintenanceWindowStatus.
+func (in *SDIMaintenanceWindowStatus) DeepCopy() *SDIMaintenanceWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIMaintenanceWindowStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SDIObserver) DeepCopyInto(out *SDIObserver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserver.
+func (in *SDIObserver) DeepCopy() *SDIObserver {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIObserver) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverComplianceRule) DeepCopyInto(out *SDIObserverComplianceRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverComplianceRule.
+func (in *SDIObserverComplianceRule) DeepCopy() *SDIObserverComplianceRule {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverComplianceRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverDataHubStatus) DeepCopyInto(out *SDIObserverDataHubStatus) {
+	*out = *in
+	if in.NotReadyComponents != nil {
+		in, out := &in.NotReadyComponents, &out.NotReadyComponents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverDataHubStatus.
+func (in *SDIObserverDataHubStatus) DeepCopy() *SDIObserverDataHubStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverDataHubStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverHealth) DeepCopyInto(out *SDIObserverHealth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverHealth.
+func (in *SDIObserverHealth) DeepCopy() *SDIObserverHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverList) DeepCopyInto(out *SDIObserverList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SDIObserver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverList.
+func (in *SDIObserverList) DeepCopy() *SDIObserverList {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIObserverList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverPermissionsStatus) DeepCopyInto(out *SDIObserverPermissionsStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.MissingRules != nil {
+		in, out := &in.MissingRules, &out.MissingRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverPermissionsStatus.
+func (in *SDIObserverPermissionsStatus) DeepCopy() *SDIObserverPermissionsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverPermissionsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverRouteServiceStatus) DeepCopyInto(out *SDIObserverRouteServiceStatus) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverRouteServiceStatus.
+func (in *SDIObserverRouteServiceStatus) DeepCopy() *SDIObserverRouteServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverRouteServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverRouteStatus) DeepCopyInto(out *SDIObserverRouteStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDestinationCARotationTime != nil {
+		in, out := &in.LastDestinationCARotationTime, &out.LastDestinationCARotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(SDIObserverRouteServiceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverRouteStatus.
+func (in *SDIObserverRouteStatus) DeepCopy() *SDIObserverRouteStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverRouteStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSCCReportStatus) DeepCopyInto(out *SDIObserverSCCReportStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSCCReportStatus.
+func (in *SDIObserverSCCReportStatus) DeepCopy() *SDIObserverSCCReportStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSCCReportStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverServiceAccountToken) DeepCopyInto(out *SDIObserverServiceAccountToken) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Expiration != nil {
+		in, out := &in.Expiration, &out.Expiration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverServiceAccountToken.
+func (in *SDIObserverServiceAccountToken) DeepCopy() *SDIObserverServiceAccountToken {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverServiceAccountToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverServiceAccountTokenStatus) DeepCopyInto(out *SDIObserverServiceAccountTokenStatus) {
+	*out = *in
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.RenewTime != nil {
+		in, out := &in.RenewTime, &out.RenewTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LegacySecrets != nil {
+		in, out := &in.LegacySecrets, &out.LegacySecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverServiceAccountTokenStatus.
+func (in *SDIObserverServiceAccountTokenStatus) DeepCopy() *SDIObserverServiceAccountTokenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverServiceAccountTokenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpec) DeepCopyInto(out *SDIObserverSpec) {
+	*out = *in
+	in.VSystemRoute.DeepCopyInto(&out.VSystemRoute)
+	in.SLCBRoute.DeepCopyInto(&out.SLCBRoute)
+	in.DiagnosticsGrafanaRoute.DeepCopyInto(&out.DiagnosticsGrafanaRoute)
+	in.DiagnosticsKibanaRoute.DeepCopyInto(&out.DiagnosticsKibanaRoute)
+	in.VoraToolsRoute.DeepCopyInto(&out.VoraToolsRoute)
+	in.Alerts.DeepCopyInto(&out.Alerts)
+	if in.RouteProbeInterval != nil {
+		in, out := &in.RouteProbeInterval, &out.RouteProbeInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	out.NetworkPolicies = in.NetworkPolicies
+	in.ProxyInjection.DeepCopyInto(&out.ProxyInjection)
+	out.Registry = in.Registry
+	in.NodeConfig.DeepCopyInto(&out.NodeConfig)
+	in.NodeTuning.DeepCopyInto(&out.NodeTuning)
+	in.Compliance.DeepCopyInto(&out.Compliance)
+	out.VRep = in.VRep
+	out.PipelineModeler = in.PipelineModeler
+	in.SecurityContextConstraints.DeepCopyInto(&out.SecurityContextConstraints)
+	in.PodSecurity.DeepCopyInto(&out.PodSecurity)
+	in.SCCReport.DeepCopyInto(&out.SCCReport)
+	in.ImagePullSecret.DeepCopyInto(&out.ImagePullSecret)
+	in.ServiceAccountTokens.DeepCopyInto(&out.ServiceAccountTokens)
+	in.Scheduling.DeepCopyInto(&out.Scheduling)
+	in.PriorityClass.DeepCopyInto(&out.PriorityClass)
+	in.ResourceOverrides.DeepCopyInto(&out.ResourceOverrides)
+	in.Dashboard.DeepCopyInto(&out.Dashboard)
+	in.VSystemHealth.DeepCopyInto(&out.VSystemHealth)
+	if in.MaxConcurrentReconciles != nil {
+		in, out := &in.MaxConcurrentReconciles, &out.MaxConcurrentReconciles
+		*out = new(int32)
+		**out = **in
+	}
+	out.Discovery = in.Discovery
+	out.Insights = in.Insights
+	out.VersionSkew = in.VersionSkew
+	out.Diagnostics = in.Diagnostics
+	in.Notifications.DeepCopyInto(&out.Notifications)
+	in.Backup.DeepCopyInto(&out.Backup)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpec.
+func (in *SDIObserverSpec) DeepCopy() *SDIObserverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecAlerts) DeepCopyInto(out *SDIObserverSpecAlerts) {
+	*out = *in
+	if in.CertificateExpiryWarning != nil {
+		in, out := &in.CertificateExpiryWarning, &out.CertificateExpiryWarning
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CertificateExpiryCritical != nil {
+		in, out := &in.CertificateExpiryCritical, &out.CertificateExpiryCritical
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RouteUnreachableFor != nil {
+		in, out := &in.RouteUnreachableFor, &out.RouteUnreachableFor
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DataHubDegradedFor != nil {
+		in, out := &in.DataHubDegradedFor, &out.DataHubDegradedFor
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.FluentdPatchDriftFor != nil {
+		in, out := &in.FluentdPatchDriftFor, &out.FluentdPatchDriftFor
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecAlerts.
+func (in *SDIObserverSpecAlerts) DeepCopy() *SDIObserverSpecAlerts {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecAlerts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecBackup) DeepCopyInto(out *SDIObserverSpecBackup) {
+	*out = *in
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]SDIObserverSpecBackupHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExcludedClaims != nil {
+		in, out := &in.ExcludedClaims, &out.ExcludedClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(SDIObserverSpecBackupSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecBackup.
+func (in *SDIObserverSpecBackup) DeepCopy() *SDIObserverSpecBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecBackupHook) DeepCopyInto(out *SDIObserverSpecBackupHook) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecBackupHook.
+func (in *SDIObserverSpecBackupHook) DeepCopy() *SDIObserverSpecBackupHook {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecBackupHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecBackupSchedule) DeepCopyInto(out *SDIObserverSpecBackupSchedule) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecBackupSchedule.
+func (in *SDIObserverSpecBackupSchedule) DeepCopy() *SDIObserverSpecBackupSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecBackupSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecCompliance) DeepCopyInto(out *SDIObserverSpecCompliance) {
+	*out = *in
+	if in.AdditionalRules != nil {
+		in, out := &in.AdditionalRules, &out.AdditionalRules
+		*out = make([]SDIObserverComplianceRule, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecCompliance.
+func (in *SDIObserverSpecCompliance) DeepCopy() *SDIObserverSpecCompliance {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecCompliance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecDashboard) DeepCopyInto(out *SDIObserverSpecDashboard) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecDashboard.
+func (in *SDIObserverSpecDashboard) DeepCopy() *SDIObserverSpecDashboard {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecDashboard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecDiagnostics) DeepCopyInto(out *SDIObserverSpecDiagnostics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecDiagnostics.
+func (in *SDIObserverSpecDiagnostics) DeepCopy() *SDIObserverSpecDiagnostics {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecDiagnostics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecDiscovery) DeepCopyInto(out *SDIObserverSpecDiscovery) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecDiscovery.
+func (in *SDIObserverSpecDiscovery) DeepCopy() *SDIObserverSpecDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecImagePullSecret) DeepCopyInto(out *SDIObserverSpecImagePullSecret) {
+	*out = *in
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecImagePullSecret.
+func (in *SDIObserverSpecImagePullSecret) DeepCopy() *SDIObserverSpecImagePullSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecImagePullSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecInsights) DeepCopyInto(out *SDIObserverSpecInsights) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecInsights.
+func (in *SDIObserverSpecInsights) DeepCopy() *SDIObserverSpecInsights {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecInsights)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecNetworkPolicies) DeepCopyInto(out *SDIObserverSpecNetworkPolicies) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecNetworkPolicies.
+func (in *SDIObserverSpecNetworkPolicies) DeepCopy() *SDIObserverSpecNetworkPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecNetworkPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecNodeConfig) DeepCopyInto(out *SDIObserverSpecNodeConfig) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecNodeConfig.
+func (in *SDIObserverSpecNodeConfig) DeepCopy() *SDIObserverSpecNodeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecNodeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecNodeTuning) DeepCopyInto(out *SDIObserverSpecNodeTuning) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]SDIObserverSpecNodeTuningPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecNodeTuning.
+func (in *SDIObserverSpecNodeTuning) DeepCopy() *SDIObserverSpecNodeTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecNodeTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecNodeTuningPool) DeepCopyInto(out *SDIObserverSpecNodeTuningPool) {
+	*out = *in
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecNodeTuningPool.
+func (in *SDIObserverSpecNodeTuningPool) DeepCopy() *SDIObserverSpecNodeTuningPool {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecNodeTuningPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecNotifications) DeepCopyInto(out *SDIObserverSpecNotifications) {
+	*out = *in
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]SDIObserverSpecWebhook, len(*in))
+		copy(*out, *in)
+	}
+	if in.CertificateExpiryThreshold != nil {
+		in, out := &in.CertificateExpiryThreshold, &out.CertificateExpiryThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecNotifications.
+func (in *SDIObserverSpecNotifications) DeepCopy() *SDIObserverSpecNotifications {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecNotifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecPipelineModeler) DeepCopyInto(out *SDIObserverSpecPipelineModeler) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecPipelineModeler.
+func (in *SDIObserverSpecPipelineModeler) DeepCopy() *SDIObserverSpecPipelineModeler {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecPipelineModeler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecPodSecurity) DeepCopyInto(out *SDIObserverSpecPodSecurity) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecPodSecurity.
+func (in *SDIObserverSpecPodSecurity) DeepCopy() *SDIObserverSpecPodSecurity {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecPodSecurity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecPriorityClass) DeepCopyInto(out *SDIObserverSpecPriorityClass) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecPriorityClass.
+func (in *SDIObserverSpecPriorityClass) DeepCopy() *SDIObserverSpecPriorityClass {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecPriorityClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecProxyInjection) DeepCopyInto(out *SDIObserverSpecProxyInjection) {
+	*out = *in
+	if in.Deployments != nil {
+		in, out := &in.Deployments, &out.Deployments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecProxyInjection.
+func (in *SDIObserverSpecProxyInjection) DeepCopy() *SDIObserverSpecProxyInjection {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecProxyInjection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecRegistry) DeepCopyInto(out *SDIObserverSpecRegistry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRegistry.
+func (in *SDIObserverSpecRegistry) DeepCopy() *SDIObserverSpecRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecResourceOverrides) DeepCopyInto(out *SDIObserverSpecResourceOverrides) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[string]v1.ResourceRequirements, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecResourceOverrides.
+func (in *SDIObserverSpecResourceOverrides) DeepCopy() *SDIObserverSpecResourceOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecResourceOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecRoute) DeepCopyInto(out *SDIObserverSpecRoute) {
+	*out = *in
+	if in.Certificate != nil {
+		in, out := &in.Certificate, &out.Certificate
+		*out = new(SDIObserverSpecRouteCertificate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMesh != nil {
+		in, out := &in.ServiceMesh, &out.ServiceMesh
+		*out = new(SDIObserverSpecRouteServiceMesh)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(SDIObserverSpecRouteService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DestinationCASecretRef != nil {
+		in, out := &in.DestinationCASecretRef, &out.DestinationCASecretRef
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRoute.
+func (in *SDIObserverSpecRoute) DeepCopy() *SDIObserverSpecRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecRouteCertificate) DeepCopyInto(out *SDIObserverSpecRouteCertificate) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(SDIObserverSpecRouteIssuerRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRouteCertificate.
+func (in *SDIObserverSpecRouteCertificate) DeepCopy() *SDIObserverSpecRouteCertificate {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecRouteCertificate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecRouteIssuerRef) DeepCopyInto(out *SDIObserverSpecRouteIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRouteIssuerRef.
+func (in *SDIObserverSpecRouteIssuerRef) DeepCopy() *SDIObserverSpecRouteIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecRouteIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecRouteService) DeepCopyInto(out *SDIObserverSpecRouteService) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRouteService.
+func (in *SDIObserverSpecRouteService) DeepCopy() *SDIObserverSpecRouteService {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecRouteService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecRouteServiceMesh) DeepCopyInto(out *SDIObserverSpecRouteServiceMesh) {
+	*out = *in
+	if in.GatewaySelector != nil {
+		in, out := &in.GatewaySelector, &out.GatewaySelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRouteServiceMesh.
+func (in *SDIObserverSpecRouteServiceMesh) DeepCopy() *SDIObserverSpecRouteServiceMesh {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecRouteServiceMesh)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecSCC) DeepCopyInto(out *SDIObserverSpecSCC) {
+	*out = *in
+	if in.PrivilegedServiceAccounts != nil {
+		in, out := &in.PrivilegedServiceAccounts, &out.PrivilegedServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecSCC.
+func (in *SDIObserverSpecSCC) DeepCopy() *SDIObserverSpecSCC {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecSCC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecSCCReport) DeepCopyInto(out *SDIObserverSpecSCCReport) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecSCCReport.
+func (in *SDIObserverSpecSCCReport) DeepCopy() *SDIObserverSpecSCCReport {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecSCCReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecScheduling) DeepCopyInto(out *SDIObserverSpecScheduling) {
+	*out = *in
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeAffinity != nil {
+		in, out := &in.NodeAffinity, &out.NodeAffinity
+		*out = new(v1.NodeAffinity)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecScheduling.
+func (in *SDIObserverSpecScheduling) DeepCopy() *SDIObserverSpecScheduling {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecScheduling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecServiceAccountTokens) DeepCopyInto(out *SDIObserverSpecServiceAccountTokens) {
+	*out = *in
+	if in.Tokens != nil {
+		in, out := &in.Tokens, &out.Tokens
+		*out = make([]SDIObserverServiceAccountToken, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecServiceAccountTokens.
+func (in *SDIObserverSpecServiceAccountTokens) DeepCopy() *SDIObserverSpecServiceAccountTokens {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecServiceAccountTokens)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecServiceRoute) DeepCopyInto(out *SDIObserverSpecServiceRoute) {
+	*out = *in
+	if in.TargetPort != nil {
+		in, out := &in.TargetPort, &out.TargetPort
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	in.TLS.DeepCopyInto(&out.TLS)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecServiceRoute.
+func (in *SDIObserverSpecServiceRoute) DeepCopy() *SDIObserverSpecServiceRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecServiceRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecServiceRouteTLS) DeepCopyInto(out *SDIObserverSpecServiceRouteTLS) {
+	*out = *in
+	if in.DestinationCASecretRef != nil {
+		in, out := &in.DestinationCASecretRef, &out.DestinationCASecretRef
+		*out = new(SecretKeySelector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecServiceRouteTLS.
+func (in *SDIObserverSpecServiceRouteTLS) DeepCopy() *SDIObserverSpecServiceRouteTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecServiceRouteTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecVRep) DeepCopyInto(out *SDIObserverSpecVRep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecVRep.
+func (in *SDIObserverSpecVRep) DeepCopy() *SDIObserverSpecVRep {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecVRep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecVSystemHealth) DeepCopyInto(out *SDIObserverSpecVSystemHealth) {
+	*out = *in
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecVSystemHealth.
+func (in *SDIObserverSpecVSystemHealth) DeepCopy() *SDIObserverSpecVSystemHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecVSystemHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecVersionSkew) DeepCopyInto(out *SDIObserverSpecVersionSkew) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecVersionSkew.
+func (in *SDIObserverSpecVersionSkew) DeepCopy() *SDIObserverSpecVersionSkew {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecVersionSkew)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverSpecWebhook) DeepCopyInto(out *SDIObserverSpecWebhook) {
+	*out = *in
+	out.URLSecretRef = in.URLSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecWebhook.
+func (in *SDIObserverSpecWebhook) DeepCopy() *SDIObserverSpecWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverSpecWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverStatus) DeepCopyInto(out *SDIObserverStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedDataHubRef != nil {
+		in, out := &in.ManagedDataHubRef, &out.ManagedDataHubRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.DataHub != nil {
+		in, out := &in.DataHub, &out.DataHub
+		*out = new(SDIObserverDataHubStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VSystemHealth != nil {
+		in, out := &in.VSystemHealth, &out.VSystemHealth
+		*out = new(SDIObserverVSystemHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SCCReport != nil {
+		in, out := &in.SCCReport, &out.SCCReport
+		*out = new(SDIObserverSCCReportStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = new(SDIObserverPermissionsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccountTokens != nil {
+		in, out := &in.ServiceAccountTokens, &out.ServiceAccountTokens
+		*out = make([]SDIObserverServiceAccountTokenStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.VSystemRoute.DeepCopyInto(&out.VSystemRoute)
+	in.SLCBRoute.DeepCopyInto(&out.SLCBRoute)
+	if in.DiagnosticsGrafanaRoute != nil {
+		in, out := &in.DiagnosticsGrafanaRoute, &out.DiagnosticsGrafanaRoute
+		*out = new(SDIObserverRouteStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiagnosticsKibanaRoute != nil {
+		in, out := &in.DiagnosticsKibanaRoute, &out.DiagnosticsKibanaRoute
+		*out = new(SDIObserverRouteStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VoraToolsRoute != nil {
+		in, out := &in.VoraToolsRoute, &out.VoraToolsRoute
+		*out = new(SDIObserverRouteStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiscoveredNamespaces != nil {
+		in, out := &in.DiscoveredNamespaces, &out.DiscoveredNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(SDIObserverHealth)
+		**out = **in
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]ManagedObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverStatus.
+func (in *SDIObserverStatus) DeepCopy() *SDIObserverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIObserverVSystemHealthStatus) DeepCopyInto(out *SDIObserverVSystemHealthStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UnhealthyApplications != nil {
+		in, out := &in.UnhealthyApplications, &out.UnhealthyApplications
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverVSystemHealthStatus.
+func (in *SDIObserverVSystemHealthStatus) DeepCopy() *SDIObserverVSystemHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIObserverVSystemHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistry) DeepCopyInto(out *SDIRegistry) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistry.
+func (in *SDIRegistry) DeepCopy() *SDIRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIRegistry) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistryList) DeepCopyInto(out *SDIRegistryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SDIRegistry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistryList.
+func (in *SDIRegistryList) DeepCopy() *SDIRegistryList {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SDIRegistryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpec) DeepCopyInto(out *SDIRegistrySpec) {
+	*out = *in
+	in.Storage.DeepCopyInto(&out.Storage)
+	in.Auth.DeepCopyInto(&out.Auth)
+	out.Route = in.Route
+	out.GarbageCollection = in.GarbageCollection
+	out.Alerts = in.Alerts
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpec.
+func (in *SDIRegistrySpec) DeepCopy() *SDIRegistrySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecAlerts) DeepCopyInto(out *SDIRegistrySpecAlerts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecAlerts.
+func (in *SDIRegistrySpecAlerts) DeepCopy() *SDIRegistrySpecAlerts {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecAlerts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecAuth) DeepCopyInto(out *SDIRegistrySpecAuth) {
+	*out = *in
+	if in.HtpasswdSecretRef != nil {
+		in, out := &in.HtpasswdSecretRef, &out.HtpasswdSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CredentialNamespaces != nil {
+		in, out := &in.CredentialNamespaces, &out.CredentialNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecAuth.
+func (in *SDIRegistrySpecAuth) DeepCopy() *SDIRegistrySpecAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecGarbageCollection) DeepCopyInto(out *SDIRegistrySpecGarbageCollection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecGarbageCollection.
+func (in *SDIRegistrySpecGarbageCollection) DeepCopy() *SDIRegistrySpecGarbageCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecGarbageCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecObjectBucketClaim) DeepCopyInto(out *SDIRegistrySpecObjectBucketClaim) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecObjectBucketClaim.
+func (in *SDIRegistrySpecObjectBucketClaim) DeepCopy() *SDIRegistrySpecObjectBucketClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecObjectBucketClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecPVC) DeepCopyInto(out *SDIRegistrySpecPVC) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecPVC.
+func (in *SDIRegistrySpecPVC) DeepCopy() *SDIRegistrySpecPVC {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecPVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecRoute) DeepCopyInto(out *SDIRegistrySpecRoute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecRoute.
+func (in *SDIRegistrySpecRoute) DeepCopy() *SDIRegistrySpecRoute {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecRoute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecS3) DeepCopyInto(out *SDIRegistrySpecS3) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecS3.
+func (in *SDIRegistrySpecS3) DeepCopy() *SDIRegistrySpecS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistrySpecStorage) DeepCopyInto(out *SDIRegistrySpecStorage) {
+	*out = *in
+	in.PVC.DeepCopyInto(&out.PVC)
+	out.ObjectBucketClaim = in.ObjectBucketClaim
+	in.S3.DeepCopyInto(&out.S3)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistrySpecStorage.
+func (in *SDIRegistrySpecStorage) DeepCopy() *SDIRegistrySpecStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistrySpecStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIRegistryStatus) DeepCopyInto(out *SDIRegistryStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PublishedCredentials != nil {
+		in, out := &in.PublishedCredentials, &out.PublishedCredentials
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastGarbageCollectionTime != nil {
+		in, out := &in.LastGarbageCollectionTime, &out.LastGarbageCollectionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIRegistryStatus.
+func (in *SDIRegistryStatus) DeepCopy() *SDIRegistryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIRegistryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIShutdownActionStatus) DeepCopyInto(out *SDIShutdownActionStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIShutdownActionStatus.
+func (in *SDIShutdownActionStatus) DeepCopy() *SDIShutdownActionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIShutdownActionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIStorageValidation) DeepCopyInto(out *SDIStorageValidation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserver.
-func (in *SDIObserver) DeepCopy() *SDIObserver {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidation.
+func (in *SDIStorageValidation) DeepCopy() *SDIStorageValidation {
 	if in == nil {
 		return nil
 	}
-	out := new(SDIObserver)
+	out := new(SDIStorageValidation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SDIObserver) DeepCopyObject() runtime.Object {
+func (in *SDIStorageValidation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -54,31 +1885,56 @@ func (in *SDIObserver) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SDIObserverList) DeepCopyInto(out *SDIObserverList) {
+func (in *SDIStorageValidationCheckpointStoreStatus) DeepCopyInto(out *SDIStorageValidationCheckpointStoreStatus) {
+	*out = *in
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PassedOperations != nil {
+		in, out := &in.PassedOperations, &out.PassedOperations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationCheckpointStoreStatus.
+func (in *SDIStorageValidationCheckpointStoreStatus) DeepCopy() *SDIStorageValidationCheckpointStoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIStorageValidationCheckpointStoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIStorageValidationList) DeepCopyInto(out *SDIStorageValidationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]SDIObserver, len(*in))
+		*out = make([]SDIStorageValidation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverList.
-func (in *SDIObserverList) DeepCopy() *SDIObserverList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationList.
+func (in *SDIStorageValidationList) DeepCopy() *SDIStorageValidationList {
 	if in == nil {
 		return nil
 	}
-	out := new(SDIObserverList)
+	out := new(SDIStorageValidationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SDIObserverList) DeepCopyObject() runtime.Object {
+func (in *SDIStorageValidationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -86,84 +1942,339 @@ func (in *SDIObserverList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SDIObserverRouteStatus) DeepCopyInto(out *SDIObserverRouteStatus) {
+func (in *SDIStorageValidationSpec) DeepCopyInto(out *SDIStorageValidationSpec) {
+	*out = *in
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(SDIStorageValidationSpecVolume)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CheckpointStore != nil {
+		in, out := &in.CheckpointStore, &out.CheckpointStore
+		*out = new(SDIStorageValidationSpecCheckpointStore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationSpec.
+func (in *SDIStorageValidationSpec) DeepCopy() *SDIStorageValidationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIStorageValidationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIStorageValidationSpecAccessTest) DeepCopyInto(out *SDIStorageValidationSpecAccessTest) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationSpecAccessTest.
+func (in *SDIStorageValidationSpecAccessTest) DeepCopy() *SDIStorageValidationSpecAccessTest {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIStorageValidationSpecAccessTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIStorageValidationSpecCheckpointStore) DeepCopyInto(out *SDIStorageValidationSpecCheckpointStore) {
+	*out = *in
+	out.AccessKeyIDSecretRef = in.AccessKeyIDSecretRef
+	out.SecretAccessKeySecretRef = in.SecretAccessKeySecretRef
+	if in.AccessTest != nil {
+		in, out := &in.AccessTest, &out.AccessTest
+		*out = new(SDIStorageValidationSpecAccessTest)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationSpecCheckpointStore.
+func (in *SDIStorageValidationSpecCheckpointStore) DeepCopy() *SDIStorageValidationSpecCheckpointStore {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIStorageValidationSpecCheckpointStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIStorageValidationSpecVolume) DeepCopyInto(out *SDIStorageValidationSpecVolume) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationSpecVolume.
+func (in *SDIStorageValidationSpecVolume) DeepCopy() *SDIStorageValidationSpecVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(SDIStorageValidationSpecVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SDIStorageValidationStatus) DeepCopyInto(out *SDIStorageValidationStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastValidationTime != nil {
+		in, out := &in.LastValidationTime, &out.LastValidationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(SDIStorageValidationVolumeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CheckpointStore != nil {
+		in, out := &in.CheckpointStore, &out.CheckpointStore
+		*out = new(SDIStorageValidationCheckpointStoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverRouteStatus.
-func (in *SDIObserverRouteStatus) DeepCopy() *SDIObserverRouteStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationStatus.
+func (in *SDIStorageValidationStatus) DeepCopy() *SDIStorageValidationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SDIObserverRouteStatus)
+	out := new(SDIStorageValidationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SDIObserverSpec) DeepCopyInto(out *SDIObserverSpec) {
+func (in *SDIStorageValidationVolumeStatus) DeepCopyInto(out *SDIStorageValidationVolumeStatus) {
 	*out = *in
-	out.VSystemRoute = in.VSystemRoute
-	out.SLCBRoute = in.SLCBRoute
+	if in.BindDuration != nil {
+		in, out := &in.BindDuration, &out.BindDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.WriteDuration != nil {
+		in, out := &in.WriteDuration, &out.WriteDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ReadDuration != nil {
+		in, out := &in.ReadDuration, &out.ReadDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpec.
-func (in *SDIObserverSpec) DeepCopy() *SDIObserverSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIStorageValidationVolumeStatus.
+func (in *SDIStorageValidationVolumeStatus) DeepCopy() *SDIStorageValidationVolumeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SDIObserverSpec)
+	out := new(SDIStorageValidationVolumeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SDIObserverSpecRoute) DeepCopyInto(out *SDIObserverSpecRoute) {
+func (in *SLCBridge) DeepCopyInto(out *SLCBridge) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverSpecRoute.
-func (in *SDIObserverSpecRoute) DeepCopy() *SDIObserverSpecRoute {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLCBridge.
+func (in *SLCBridge) DeepCopy() *SLCBridge {
 	if in == nil {
 		return nil
 	}
-	out := new(SDIObserverSpecRoute)
+	out := new(SLCBridge)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SLCBridge) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SDIObserverStatus) DeepCopyInto(out *SDIObserverStatus) {
+func (in *SLCBridgeList) DeepCopyInto(out *SLCBridgeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SLCBridge, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLCBridgeList.
+func (in *SLCBridgeList) DeepCopy() *SLCBridgeList {
+	if in == nil {
+		return nil
+	}
+	out := new(SLCBridgeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SLCBridgeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLCBridgeSpec) DeepCopyInto(out *SLCBridgeSpec) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	in.Route.DeepCopyInto(&out.Route)
+	in.Service.DeepCopyInto(&out.Service)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLCBridgeSpec.
+func (in *SLCBridgeSpec) DeepCopy() *SLCBridgeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SLCBridgeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLCBridgeSpecExternalService) DeepCopyInto(out *SLCBridgeSpecExternalService) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLCBridgeSpecExternalService.
+func (in *SLCBridgeSpecExternalService) DeepCopy() *SLCBridgeSpecExternalService {
+	if in == nil {
+		return nil
+	}
+	out := new(SLCBridgeSpecExternalService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLCBridgeSpecService) DeepCopyInto(out *SLCBridgeSpecService) {
+	*out = *in
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicyType)
+		**out = **in
+	}
+	in.External.DeepCopyInto(&out.External)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLCBridgeSpecService.
+func (in *SLCBridgeSpecService) DeepCopy() *SLCBridgeSpecService {
+	if in == nil {
+		return nil
+	}
+	out := new(SLCBridgeSpecService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SLCBridgeStatus) DeepCopyInto(out *SLCBridgeStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.ManagedDataHubRef != nil {
-		in, out := &in.ManagedDataHubRef, &out.ManagedDataHubRef
-		*out = new(corev1.ObjectReference)
-		**out = **in
+	if in.ExternalService != nil {
+		in, out := &in.ExternalService, &out.ExternalService
+		*out = new(SDIObserverRouteServiceStatus)
+		(*in).DeepCopyInto(*out)
 	}
-	in.VSystemRoute.DeepCopyInto(&out.VSystemRoute)
-	in.SLCBRoute.DeepCopyInto(&out.SLCBRoute)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SDIObserverStatus.
-func (in *SDIObserverStatus) DeepCopy() *SDIObserverStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SLCBridgeStatus.
+func (in *SLCBridgeStatus) DeepCopy() *SLCBridgeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(SDIObserverStatus)
+	out := new(SLCBridgeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeySelector.
+func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeySelector)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,341 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-sdi is a kubectl and oc plugin for the common operations on the SDIObserver resources. Installed on
+// the PATH, it is invoked as "kubectl sdi" or "oc sdi".
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+const usage = `Usage: kubectl sdi COMMAND [OPTIONS] [ARGS]
+
+Commands:
+  status [NAME]            Show the aggregated status of the SDIObservers or the conditions of one.
+  routes [NAME]            Show the state of the vsystem and slcb routes.
+  pause NAME               Suspend the management of the SDI namespace.
+  resume NAME              Resume the management of the SDI namespace.
+  adopt SDI_NAMESPACE      Create an SDIObserver managing the existing SDI namespace.
+
+Run "kubectl sdi COMMAND -h" for the options of the command.
+`
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(sdiv1alpha1.AddToScheme(scheme))
+}
+
+// command holds the options common to all the commands.
+type command struct {
+	flags         *flag.FlagSet
+	kubeconfig    string
+	namespace     string
+	allNamespaces bool
+	client        client.Client
+	out           io.Writer
+}
+
+func newCommand(name string) *command {
+	cmd := &command{flags: flag.NewFlagSet(name, flag.ExitOnError), out: os.Stdout}
+	cmd.flags.StringVar(&cmd.kubeconfig, "kubeconfig", "", "Path to the kubeconfig. Defaults to KUBECONFIG.")
+	for _, name := range []string{"n", "namespace"} {
+		cmd.flags.StringVar(&cmd.namespace, name, "",
+			"The namespace of the SDIObservers. Defaults to the namespace of the current context.")
+	}
+	return cmd
+}
+
+// parse parses the arguments and connects to the cluster. It returns the positional arguments.
+func (cmd *command) parse(args []string) ([]string, error) {
+	if err := cmd.flags.Parse(args); err != nil {
+		return nil, err
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = cmd.kubeconfig
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	cfg, err := cc.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd.namespace) == 0 {
+		if cmd.namespace, _, err = cc.Namespace(); err != nil {
+			return nil, err
+		}
+	}
+	cmd.client, err = client.New(cfg, client.Options{Scheme: scheme})
+	return cmd.flags.Args(), err
+}
+
+// list returns the SDIObservers of the namespace or all of them, sorted by the namespaced name.
+func (cmd *command) list(ctx context.Context) ([]sdiv1alpha1.SDIObserver, error) {
+	obss := &sdiv1alpha1.SDIObserverList{}
+	var opts []client.ListOption
+	if !cmd.allNamespaces {
+		opts = append(opts, client.InNamespace(cmd.namespace))
+	}
+	if err := cmd.client.List(ctx, obss, opts...); err != nil {
+		return nil, err
+	}
+	sort.Slice(obss.Items, func(i, j int) bool {
+		a, b := obss.Items[i], obss.Items[j]
+		return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Name < b.Name)
+	})
+	return obss.Items, nil
+}
+
+// get returns the single SDIObserver named by the arguments or all the SDIObservers listed.
+func (cmd *command) get(ctx context.Context, args []string) ([]sdiv1alpha1.SDIObserver, error) {
+	switch len(args) {
+	case 0:
+		return cmd.list(ctx)
+	case 1:
+		obs := sdiv1alpha1.SDIObserver{}
+		err := cmd.client.Get(ctx, client.ObjectKey{Namespace: cmd.namespace, Name: args[0]}, &obs)
+		return []sdiv1alpha1.SDIObserver{obs}, err
+	}
+	return nil, fmt.Errorf("expected at most one SDIObserver name")
+}
+
+func sdiNamespaceOf(obs *sdiv1alpha1.SDIObserver) string {
+	if len(obs.Spec.SDINamespace) > 0 {
+		return obs.Spec.SDINamespace
+	}
+	return obs.Namespace
+}
+
+func conditionStatus(conditions []metav1.Condition, cType string) string {
+	if c := meta.FindStatusCondition(conditions, cType); c != nil {
+		return string(c.Status)
+	}
+	return "-"
+}
+
+func orDash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
+
+func runStatus(ctx context.Context, args []string) error {
+	cmd := newCommand("status")
+	cmd.flags.BoolVar(&cmd.allNamespaces, "A", false, "List the SDIObservers in all namespaces.")
+	args, err := cmd.parse(args)
+	if err != nil {
+		return err
+	}
+	obss, err := cmd.get(ctx, args)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(cmd.out, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	if len(args) == 1 {
+		obs := &obss[0]
+		health, message := "-", ""
+		if obs.Status.Health != nil {
+			health, message = obs.Status.Health.Status, obs.Status.Health.Message
+		}
+		fmt.Fprintf(tw, "SDIObserver:\t%s/%s\n", obs.Namespace, obs.Name)
+		fmt.Fprintf(tw, "SDI namespace:\t%s\n", sdiNamespaceOf(obs))
+		fmt.Fprintf(tw, "Health:\t%s\t%s\n", health, message)
+		fmt.Fprintf(tw, "Paused:\t%t\n", obs.Spec.Paused)
+		if dh := obs.Status.DataHub; dh != nil {
+			fmt.Fprintf(tw, "DataHub:\t%s %s (%d/%d components ready)\n", orDash(dh.State), dh.Version,
+				dh.ReadyComponents, dh.TotalComponents)
+			if len(dh.NotReadyComponents) > 0 {
+				fmt.Fprintf(tw, "Not ready:\t%s\n", strings.Join(dh.NotReadyComponents, ", "))
+			}
+		}
+		fmt.Fprintln(tw, "\nTYPE\tSTATUS\tREASON\tMESSAGE")
+		for _, c := range obs.Status.Conditions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason,
+				strings.ReplaceAll(c.Message, "\n", "; "))
+		}
+		return nil
+	}
+	fmt.Fprintln(tw, "NAMESPACE\tNAME\tSDI NAMESPACE\tDATAHUB\tVERSION\tHEALTH\tPAUSED")
+	for i := range obss {
+		obs := &obss[i]
+		state, version, health := "-", "-", "-"
+		if obs.Status.DataHub != nil {
+			state, version = orDash(obs.Status.DataHub.State), orDash(obs.Status.DataHub.Version)
+		}
+		if obs.Status.Health != nil {
+			health = obs.Status.Health.Status
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%t\n", obs.Namespace, obs.Name, sdiNamespaceOf(obs), state,
+			version, health, obs.Spec.Paused)
+	}
+	return nil
+}
+
+func runRoutes(ctx context.Context, args []string) error {
+	cmd := newCommand("routes")
+	cmd.flags.BoolVar(&cmd.allNamespaces, "A", false, "List the routes of the SDIObservers in all namespaces.")
+	args, err := cmd.parse(args)
+	if err != nil {
+		return err
+	}
+	obss, err := cmd.get(ctx, args)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(cmd.out, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintln(tw, "NAMESPACE\tSDIOBSERVER\tROUTE\tMANAGEMENT\tEXPOSED\tREACHABLE\tLAST PROBE\tERROR")
+	for i := range obss {
+		obs := &obss[i]
+		for _, route := range []struct {
+			name   string
+			spec   sdiv1alpha1.SDIObserverSpecRoute
+			status sdiv1alpha1.SDIObserverRouteStatus
+		}{
+			{"vsystem", obs.Spec.VSystemRoute, obs.Status.VSystemRoute},
+			{"slcb", obs.Spec.SLCBRoute, obs.Status.SLCBRoute},
+		} {
+			probed := "-"
+			if t := route.status.LastProbeTime; t != nil {
+				probed = time.Since(t.Time).Round(time.Second).String() + " ago"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", obs.Namespace, obs.Name, route.name,
+				orDash(route.spec.ManagementState), conditionStatus(route.status.Conditions, "Exposed"),
+				conditionStatus(route.status.Conditions, "Reachable"), probed, orDash(route.status.LastProbeError))
+		}
+	}
+	return nil
+}
+
+func runSetPaused(ctx context.Context, name string, paused bool, args []string) error {
+	cmd := newCommand(name)
+	args, err := cmd.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("expected the name of the SDIObserver")
+	}
+	obs := &sdiv1alpha1.SDIObserver{}
+	if err := cmd.client.Get(ctx, client.ObjectKey{Namespace: cmd.namespace, Name: args[0]}, obs); err != nil {
+		return err
+	}
+	verb := map[bool]string{true: "paused", false: "resumed"}[paused]
+	if obs.Spec.Paused == paused {
+		fmt.Fprintf(cmd.out, "sdiobserver %s/%s is already %s\n", obs.Namespace, obs.Name, verb)
+		return nil
+	}
+	patch := client.MergeFrom(obs.DeepCopy())
+	obs.Spec.Paused = paused
+	if err := cmd.client.Patch(ctx, obs, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.out, "sdiobserver %s/%s %s\n", obs.Namespace, obs.Name, verb)
+	return nil
+}
+
+func runAdopt(ctx context.Context, args []string) error {
+	cmd := newCommand("adopt")
+	var name string
+	cmd.flags.StringVar(&name, "name", "", "The name of the new SDIObserver. Defaults to sdi-SDI_NAMESPACE.")
+	args, err := cmd.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("expected the SDI namespace")
+	}
+	sdiNamespace := args[0]
+	if len(name) == 0 {
+		name = "sdi-" + sdiNamespace
+	}
+
+	// only one SDIObserver manages a namespace, the others would be backups
+	cmd.allNamespaces = true
+	obss, err := cmd.list(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range obss {
+		if sdiNamespaceOf(&obss[i]) == sdiNamespace {
+			return fmt.Errorf("the SDI namespace %s is already observed by %s/%s", sdiNamespace,
+				obss[i].Namespace, obss[i].Name)
+		}
+	}
+
+	obs := &sdiv1alpha1.SDIObserver{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cmd.namespace, Name: name},
+		// the defaults of the CRD apply to the rest of the spec
+		Spec: sdiv1alpha1.SDIObserverSpec{SDINamespace: sdiNamespace},
+	}
+	if err := cmd.client.Create(ctx, obs); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.out, "sdiobserver %s/%s created for the SDI namespace %s\n", obs.Namespace, obs.Name,
+		sdiNamespace)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	ctx := context.Background()
+	var err error
+	switch args := os.Args[2:]; os.Args[1] {
+	case "status":
+		err = runStatus(ctx, args)
+	case "routes":
+		err = runRoutes(ctx, args)
+	case "pause":
+		err = runSetPaused(ctx, "pause", true, args)
+	case "resume":
+		err = runSetPaused(ctx, "resume", false, args)
+	case "adopt":
+		err = runAdopt(ctx, args)
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
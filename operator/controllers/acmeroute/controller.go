@@ -0,0 +1,221 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package acmeroute
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/certmanager"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// AnnotationTLSAcme is set on the routes that shall be secured with an ACME (Let's Encrypt)
+	// certificate. The SDI Registry deployment script sets it when exposed with letsencrypt.
+	AnnotationTLSAcme = "kubernetes.io/tls-acme"
+	// Per-route overrides of the default issuer. The same annotations are understood by cert-manager.
+	annotationIssuer        = "cert-manager.io/issuer"
+	annotationClusterIssuer = "cert-manager.io/cluster-issuer"
+
+	certificateSuffix = "-acme"
+	secretSuffix      = "-acme-tls"
+
+	// how long to wait before checking again whether cert-manager has been installed
+	noCertManagerRequeueDelay = time.Minute * 5
+)
+
+// Reconciler requests certificates for the annotated routes from cert-manager and sets them on the routes.
+// Renewed certificates are propagated to the routes as well.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// DefaultIssuer is used for routes without an issuer annotation.
+	DefaultIssuer certmanager.IssuerRef
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme, defaultIssuer certmanager.IssuerRef) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme, DefaultIssuer: defaultIssuer}
+}
+
+// ParseIssuer parses the issuer reference in the form [Kind/]name. The kind defaults to ClusterIssuer.
+func ParseIssuer(value string) (certmanager.IssuerRef, error) {
+	ref := certmanager.IssuerRef{Kind: "ClusterIssuer"}
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) == 2 {
+		ref.Kind, parts = parts[0], parts[1:]
+	}
+	ref.Name = parts[0]
+	if len(ref.Name) == 0 {
+		return ref, fmt.Errorf("missing issuer name in %q", value)
+	}
+	if ref.Kind != "Issuer" && ref.Kind != "ClusterIssuer" {
+		return ref, fmt.Errorf("unsupported issuer kind %q, expected Issuer or ClusterIssuer", ref.Kind)
+	}
+	return ref, nil
+}
+
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile ensures a Certificate for the route host and sets the issued certificate on the route.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	route := &routev1.Route{}
+	if err = r.Get(ctx, req.NamespacedName, route); err != nil {
+		return rs, client.IgnoreNotFound(err)
+	}
+
+	cert := certmanager.NewCertificate(route.Namespace, route.Name+certificateSuffix)
+	if route.Annotations[AnnotationTLSAcme] != "true" {
+		return rs, r.deleteCertificate(ctx, route, cert)
+	}
+	if len(route.Spec.Host) == 0 {
+		tracer.Info("route has no host yet")
+		return
+	}
+	if route.Spec.TLS != nil && route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		tracer.Info("cannot set a certificate on a passthrough route", "route", req.NamespacedName)
+		return
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, cert, func() error {
+		certmanager.SetCertificateSpec(cert, route.Name+secretSuffix, route.Spec.Host, r.getIssuer(route))
+		primaryresource.SetManagedBy(cert)
+		return controllerutil.SetControllerReference(route, cert, r.Scheme)
+	})
+	if meta.IsNoMatchError(err) {
+		tracer.Info("cert-manager is not installed, cannot request a certificate", "route", req.NamespacedName)
+		rs.RequeueAfter = noCertManagerRequeueDelay
+		return rs, nil
+	}
+	if err != nil {
+		tracer.Error(err, "failed to manage certificate", "route", req.NamespacedName)
+		return
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed certificate", "name", cert.GetName(), "operation", op)
+	}
+
+	issued, err := certmanager.GetIssued(ctx, r.Client, route.Namespace, route.Name+secretSuffix)
+	if notIssued, ok := err.(*certmanager.ErrNotIssued); ok {
+		// the secret watch triggers a new reconciliation once issued
+		tracer.Info("certificate not issued yet", "route", req.NamespacedName, "reason", notIssued.Reason)
+		return rs, nil
+	}
+	if err != nil {
+		return
+	}
+	if err = issued.Check(route.Name + secretSuffix); err != nil {
+		// the secret watch triggers a new reconciliation once reissued
+		tracer.Info("rejecting the issued certificate", "route", req.NamespacedName, "error", err)
+		return rs, nil
+	}
+
+	tls := &routev1.TLSConfig{
+		Termination:                   routev1.TLSTerminationEdge,
+		InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+	}
+	if route.Spec.TLS != nil {
+		tls = route.Spec.TLS.DeepCopy()
+	}
+	issued.ApplyToRoute(tls)
+	if reflect.DeepEqual(tls, route.Spec.TLS) {
+		tracer.V(2).Info("route certificate is up to date", "route", req.NamespacedName)
+		return
+	}
+	route.Spec.TLS = tls
+	tracer.Info("updating route certificate", "route", req.NamespacedName)
+	return rs, r.Update(ctx, route)
+}
+
+func (r *Reconciler) getIssuer(route *routev1.Route) certmanager.IssuerRef {
+	if name := route.Annotations[annotationIssuer]; len(name) > 0 {
+		return certmanager.IssuerRef{Name: name, Kind: "Issuer"}
+	}
+	if name := route.Annotations[annotationClusterIssuer]; len(name) > 0 {
+		return certmanager.IssuerRef{Name: name, Kind: "ClusterIssuer"}
+	}
+	return r.DefaultIssuer
+}
+
+// deleteCertificate removes the Certificate once the route is no longer annotated. The certificate
+// already set on the route is left intact.
+func (r *Reconciler) deleteCertificate(ctx context.Context, route *routev1.Route, cert client.Object) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	err := r.Get(ctx, client.ObjectKeyFromObject(cert), cert)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(cert, route) {
+		return nil
+	}
+	tracer.Info("deleting certificate of a route no longer annotated", "name", cert.GetName())
+	return client.IgnoreNotFound(r.Delete(ctx, cert))
+}
+
+// enqueueRoute maps the secret with the issued certificate to its route.
+func enqueueRoute(obj client.Object) []reconcile.Request {
+	if !strings.HasSuffix(obj.GetName(), secretSuffix) {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: obj.GetNamespace(),
+		Name:      strings.TrimSuffix(obj.GetName(), secretSuffix),
+	}}}
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("acmeroute").
+		For(&routev1.Route{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(enqueueRoute),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return strings.HasSuffix(obj.GetName(), secretSuffix)
+			}))).
+		Complete(r)
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package imageconfig
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+// the management states of the registries of the SDIObservers
+var (
+	reUnmanaged = regexp.MustCompile(`^(\s*|(?i)Unmanaged)$`)
+	reRemoved   = regexp.MustCompile(`^(?i)removed?$`)
+)
+
+const (
+	clusterImageConfigName = "cluster"
+	// the namespace of the config maps referenced by the cluster configuration
+	openshiftConfigNamespace = "openshift-config"
+	// used unless the image config already references a config map
+	defaultRegistryCAConfigMapName = "trusted-registry-cabundles"
+
+	// Comma separated lists of the entries added by the operator. Only these are ever removed again.
+	annotationAllowedRegistries  = "di.sap-cop.redhat.com/allowed-registries"
+	annotationInsecureRegistries = "di.sap-cop.redhat.com/insecure-registries"
+	annotationCAKeys             = "di.sap-cop.redhat.com/registry-ca-keys"
+	// Set on the image config if the operator referenced the CA config map created by itself.
+	annotationTrustedCA = "di.sap-cop.redhat.com/additional-trusted-ca"
+
+	// the CA signing the default ingress certificate and thus the routes of the registries
+	ingressCANamespace = "openshift-config-managed"
+	ingressCAName      = "default-ingress-cert"
+	ingressCAKey       = "ca-bundle.crt"
+
+	// the image config is cluster scoped and thus not covered by the (namespaced) cache
+	resyncInterval = time.Minute * 10
+)
+
+// Reconciler merges the registries of all the SDIObserver instances and the routes of the SDIRegistry
+// instances into the cluster image config (images.config.openshift.io/cluster) and the config map of its
+// additionalTrustedCA. Entries added by others are left intact. Entries added by the operator are removed once
+// no longer desired.
+type Reconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	apiReader client.Reader
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme, apiReader client.Reader) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme, apiReader: apiReader}
+}
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiobservers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiregistries,verbs=get;list;watch
+//+kubebuilder:rbac:groups=config.openshift.io,resources=images,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+// desiredState holds the registry entries requested by the SDIObserver instances. The entries of the
+// retained registries (of unmanaged instances) are neither added nor removed.
+type desiredState struct {
+	registries []string
+	insecure   []string
+	caBundles  map[string]string
+	retained   []string
+}
+
+// registryCAKey returns the key of the additionalTrustedCA config map for the registry. The port is separated
+// with two dots instead of a colon.
+func registryCAKey(hostname string) string {
+	return strings.ReplaceAll(hostname, ":", "..")
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func splitAnnotation(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func setAnnotation(obj metav1.Object, key string, values []string) {
+	annotations := obj.GetAnnotations()
+	if len(values) == 0 {
+		delete(annotations, key)
+	} else {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		sort.Strings(values)
+		annotations[key] = strings.Join(values, ",")
+	}
+	obj.SetAnnotations(annotations)
+}
+
+// mergeList adds the desired entries missing in the list and removes the owned entries that are neither
+// desired nor retained. It returns the new list and the entries owned afterwards.
+func mergeList(list, owned, desired, retained []string) ([]string, []string) {
+	var result, newOwned []string
+	for _, entry := range list {
+		if contains(owned, entry) {
+			if !contains(desired, entry) && !contains(retained, entry) {
+				continue
+			}
+			newOwned = append(newOwned, entry)
+		}
+		result = append(result, entry)
+	}
+	for _, entry := range desired {
+		if !contains(result, entry) {
+			result = append(result, entry)
+			newOwned = append(newOwned, entry)
+		}
+	}
+	return result, newOwned
+}
+
+func (r *Reconciler) getDesiredState(ctx context.Context) (*desiredState, error) {
+	observers := &sdiv1alpha1.SDIObserverList{}
+	if err := r.List(ctx, observers); err != nil {
+		return nil, err
+	}
+	state := &desiredState{caBundles: make(map[string]string)}
+	for _, obs := range observers.Items {
+		spec := obs.Spec.Registry
+		if len(spec.Hostname) == 0 || reRemoved.MatchString(spec.ManagementState) ||
+			!obs.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if reUnmanaged.MatchString(spec.ManagementState) {
+			state.retained = append(state.retained, spec.Hostname, registryCAKey(spec.Hostname))
+			continue
+		}
+		state.registries = append(state.registries, spec.Hostname)
+		if spec.Insecure {
+			state.insecure = append(state.insecure, spec.Hostname)
+		}
+		if ca := strings.TrimSpace(spec.CACertificate); len(ca) > 0 {
+			// the SDIObserver reports the rejected CA in its Degraded condition
+			if err := fips.CheckCertificates("registry CA certificate", ca); err != nil {
+				log.FromContext(ctx).Info("skipping the registry CA", "hostname", spec.Hostname, "error", err)
+				continue
+			}
+			state.caBundles[registryCAKey(spec.Hostname)] = ca + "\n"
+		}
+	}
+	if err := r.addRegistryRoutes(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// addRegistryRoutes adds the routes of the SDIRegistry instances to the desired state. The routes are signed by
+// the ingress CA which the nodes do not trust by default.
+func (r *Reconciler) addRegistryRoutes(ctx context.Context, state *desiredState) error {
+	registries := &sdiv1alpha1.SDIRegistryList{}
+	if err := r.List(ctx, registries); err != nil {
+		return err
+	}
+	var ingressCA string
+	for _, reg := range registries.Items {
+		hostname := reg.Status.Hostname
+		if len(hostname) == 0 || reg.Spec.Route.SkipClusterTrust || !reg.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if len(ingressCA) == 0 {
+			cm := &corev1.ConfigMap{}
+			err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: ingressCANamespace, Name: ingressCAName}, cm)
+			if err != nil {
+				return fmt.Errorf("failed to get the ingress CA: %v", err)
+			}
+			ingressCA = strings.TrimSpace(cm.Data[ingressCAKey])
+			if len(ingressCA) == 0 {
+				return fmt.Errorf("config map %s/%s has no %s key", ingressCANamespace, ingressCAName, ingressCAKey)
+			}
+		}
+		state.registries = append(state.registries, hostname)
+		state.caBundles[registryCAKey(hostname)] = ingressCA + "\n"
+	}
+	return nil
+}
+
+// Reconcile merges the registries of all the SDIObserver instances regardless of the request.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	rs.RequeueAfter = resyncInterval
+	state, err := r.getDesiredState(ctx)
+	if err != nil {
+		return rs, err
+	}
+
+	image := &configv1.Image{}
+	if err = r.apiReader.Get(ctx, types.NamespacedName{Name: clusterImageConfigName}, image); err != nil {
+		return rs, fmt.Errorf("failed to get the cluster image config: %v", err)
+	}
+	orig := image.DeepCopy()
+	sources := &image.Spec.RegistrySources
+	annotations := image.GetAnnotations()
+
+	var owned []string
+	sources.InsecureRegistries, owned = mergeList(sources.InsecureRegistries,
+		splitAnnotation(annotations[annotationInsecureRegistries]), state.insecure, state.retained)
+	setAnnotation(image, annotationInsecureRegistries, owned)
+	// an empty allow-list allows all the registries, the registries are added only to an existing list
+	desiredAllowed := state.registries
+	ownedAllowed := splitAnnotation(annotations[annotationAllowedRegistries])
+	if len(sources.AllowedRegistries) == len(ownedAllowed) {
+		desiredAllowed = nil
+	}
+	sources.AllowedRegistries, owned = mergeList(sources.AllowedRegistries, ownedAllowed, desiredAllowed,
+		state.retained)
+	setAnnotation(image, annotationAllowedRegistries, owned)
+
+	cmName := image.Spec.AdditionalTrustedCA.Name
+	if len(cmName) == 0 && len(state.caBundles) > 0 {
+		cmName = defaultRegistryCAConfigMapName
+		image.Spec.AdditionalTrustedCA.Name = cmName
+		setAnnotation(image, annotationTrustedCA, []string{cmName})
+	}
+	if len(cmName) > 0 {
+		createdByOperator := image.GetAnnotations()[annotationTrustedCA] == cmName
+		deleted, err := r.manageCAConfigMap(ctx, cmName, state, createdByOperator)
+		if err != nil {
+			return rs, err
+		}
+		if deleted && createdByOperator {
+			tracer.Info("unsetting the additional trusted CA created by the operator", "name", cmName)
+			image.Spec.AdditionalTrustedCA.Name = ""
+			setAnnotation(image, annotationTrustedCA, nil)
+		}
+	}
+
+	if equalImage(orig, image) {
+		return rs, nil
+	}
+	tracer.Info("updating the cluster image config",
+		"allowedRegistries", sources.AllowedRegistries,
+		"insecureRegistries", sources.InsecureRegistries,
+		"additionalTrustedCA", image.Spec.AdditionalTrustedCA.Name)
+	if err = r.Update(ctx, image); err != nil {
+		return rs, fmt.Errorf("failed to update the cluster image config: %v", err)
+	}
+	return rs, nil
+}
+
+func equalImage(a, b *configv1.Image) bool {
+	return strings.Join(a.Spec.RegistrySources.AllowedRegistries, ",") ==
+		strings.Join(b.Spec.RegistrySources.AllowedRegistries, ",") &&
+		strings.Join(a.Spec.RegistrySources.InsecureRegistries, ",") ==
+			strings.Join(b.Spec.RegistrySources.InsecureRegistries, ",") &&
+		a.Spec.AdditionalTrustedCA == b.Spec.AdditionalTrustedCA &&
+		a.Annotations[annotationAllowedRegistries] == b.Annotations[annotationAllowedRegistries] &&
+		a.Annotations[annotationInsecureRegistries] == b.Annotations[annotationInsecureRegistries] &&
+		a.Annotations[annotationTrustedCA] == b.Annotations[annotationTrustedCA]
+}
+
+// manageCAConfigMap sets the desired CA keys and removes the owned keys no longer desired. The config map
+// created by the operator is deleted once empty. It returns true if the config map does not exist (anymore).
+func (r *Reconciler) manageCAConfigMap(
+	ctx context.Context,
+	name string,
+	state *desiredState,
+	createdByOperator bool,
+) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	cm := &corev1.ConfigMap{}
+	err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: openshiftConfigNamespace, Name: name}, cm)
+	create := errors.IsNotFound(err)
+	if create {
+		if len(state.caBundles) == 0 {
+			return true, nil
+		}
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: openshiftConfigNamespace, Name: name}}
+		err = nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get config map %s/%s: %v", openshiftConfigNamespace, name, err)
+	}
+
+	changed := false
+	var owned []string
+	for _, key := range splitAnnotation(cm.GetAnnotations()[annotationCAKeys]) {
+		if _, ok := state.caBundles[key]; ok || contains(state.retained, key) {
+			owned = append(owned, key)
+			continue
+		}
+		if _, ok := cm.Data[key]; ok {
+			delete(cm.Data, key)
+			changed = true
+		}
+	}
+	for key, ca := range state.caBundles {
+		current, exists := cm.Data[key]
+		if exists && !contains(owned, key) {
+			// added by someone else
+			continue
+		}
+		if !contains(owned, key) {
+			owned = append(owned, key)
+		}
+		if current != ca {
+			if cm.Data == nil {
+				cm.Data = make(map[string]string)
+			}
+			cm.Data[key] = ca
+			changed = true
+		}
+	}
+	origOwned := cm.GetAnnotations()[annotationCAKeys]
+	setAnnotation(cm, annotationCAKeys, owned)
+	changed = changed || origOwned != cm.GetAnnotations()[annotationCAKeys]
+
+	if create {
+		tracer.Info("creating registry CA config map", "name", name, "keys", owned)
+		return false, r.Create(ctx, cm)
+	}
+	if createdByOperator && len(cm.Data) == 0 && len(owned) == 0 {
+		tracer.Info("deleting the emptied registry CA config map", "name", name)
+		return true, client.IgnoreNotFound(r.Delete(ctx, cm))
+	}
+	if !changed {
+		return false, nil
+	}
+	tracer.Info("updating registry CA config map", "name", name, "keys", owned)
+	return false, r.Update(ctx, cm)
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("imageconfig").
+		For(&sdiv1alpha1.SDIObserver{}).
+		Watches(&source.Kind{Type: &sdiv1alpha1.SDIRegistry{}}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machineconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/upgradeable"
+)
+
+const (
+	// The name used by the SAP DI installation guide. A MachineConfig created from the documentation is
+	// adopted.
+	machineConfigName = "75-worker-sap-data-intelligence"
+	roleLabelKey      = "machineconfiguration.openshift.io/role"
+
+	modulesLoadPath = "/etc/modules-load.d/sdi-dependencies.conf"
+	ignitionVersion = "3.2.0"
+
+	// MachineConfig objects are cluster scoped and thus not covered by the (namespaced) cache. Instead of
+	// watching, the object is checked periodically.
+	resyncInterval = time.Minute * 10
+)
+
+// KernelModules are loaded on the SDI nodes. They are needed by the vsystem-vrep (NFS) and the iptables
+// based network setup of SDI pods.
+var KernelModules = []string{
+	"nfsd",
+	"nfsv4",
+	"ip_tables",
+	"ipt_REDIRECT",
+	"ipt_owner",
+	"iptable_nat",
+	"iptable_filter",
+}
+
+var machineConfigGVK = schema.GroupVersionKind{
+	Group:   "machineconfiguration.openshift.io",
+	Version: "v1",
+	Kind:    "MachineConfig",
+}
+
+var machineConfigPoolGVK = schema.GroupVersionKind{
+	Group:   "machineconfiguration.openshift.io",
+	Version: "v1",
+	Kind:    "MachineConfigPool",
+}
+
+// Reconciler renders the MachineConfig loading the kernel modules required by SDI on the nodes of the SDI
+// MachineConfigPool. When disabled, the MachineConfig previously created by the operator is removed.
+type Reconciler struct {
+	client.Client
+	// Enabled selects whether the MachineConfig is ensured or cleaned up.
+	Enabled bool
+	// Role is the machineconfiguration.openshift.io/role label selecting the MachineConfigPool.
+	Role string
+	// UpgradeGate blocks the operator upgrade while the MachineConfigPool rolls out the MachineConfig.
+	// Optional.
+	UpgradeGate *upgradeable.Gate
+}
+
+// NewReconciler returns a reconciler using an uncached client.
+func NewReconciler(mgr manager.Manager, enabled bool, role string) (*Reconciler, error) {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return &Reconciler{Client: dryrun.Wrap(c), Enabled: enabled, Role: role}, nil
+}
+
+//+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigpools,verbs=get;list;watch
+// the SDI nodes are verified by the check subcommand
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list
+
+func newMachineConfig() *unstructured.Unstructured {
+	mc := &unstructured.Unstructured{}
+	mc.SetGroupVersionKind(machineConfigGVK)
+	mc.SetName(machineConfigName)
+	return mc
+}
+
+func makeIgnitionConfig() map[string]interface{} {
+	contents := strings.Join(KernelModules, "\n") + "\n"
+	return map[string]interface{}{
+		"ignition": map[string]interface{}{"version": ignitionVersion},
+		"storage": map[string]interface{}{
+			"files": []interface{}{
+				map[string]interface{}{
+					"path":      modulesLoadPath,
+					"mode":      int64(0644),
+					"overwrite": true,
+					"contents": map[string]interface{}{
+						"source": "data:text/plain;charset=utf-8;base64," +
+							base64.StdEncoding.EncodeToString([]byte(contents)),
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *Reconciler) rolloutAction() string {
+	return "machineconfig/" + r.Role
+}
+
+// isPoolUpdating returns true if the MachineConfigPool of the role rolls out a new configuration to its nodes.
+func (r *Reconciler) isPoolUpdating(ctx context.Context) (bool, error) {
+	pool := &unstructured.Unstructured{}
+	pool.SetGroupVersionKind(machineConfigPoolGVK)
+	if err := r.Get(ctx, client.ObjectKey{Name: r.Role}, pool); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	conditions, _, err := unstructured.NestedSlice(pool.Object, "status", "conditions")
+	if err != nil {
+		return false, err
+	}
+	for _, c := range conditions {
+		if c, ok := c.(map[string]interface{}); ok && c["type"] == "Updating" {
+			return c["status"] == "True", nil
+		}
+	}
+	return false, nil
+}
+
+// trackRollout blocks the operator upgrade from the change of the MachineConfig until the MachineConfigPool
+// finishes updating its nodes.
+func (r *Reconciler) trackRollout(ctx context.Context, changed bool) error {
+	if changed {
+		r.UpgradeGate.Hold(ctx, r.rolloutAction(), "rollout of MachineConfigPool "+r.Role)
+		return nil
+	}
+	if !r.UpgradeGate.Holds(r.rolloutAction()) {
+		return nil
+	}
+	updating, err := r.isPoolUpdating(ctx)
+	if err != nil {
+		return err
+	}
+	if !updating {
+		r.UpgradeGate.Release(ctx, r.rolloutAction())
+	}
+	return nil
+}
+
+// Reconcile ensures or removes the MachineConfig.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	mc := newMachineConfig()
+	if !r.Enabled {
+		err := r.Get(ctx, client.ObjectKeyFromObject(mc), mc)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return r.trackRollout(ctx, false)
+		}
+		if err != nil {
+			return err
+		}
+		if mc.GetLabels()[primaryresource.ManagedByLabelKey] != primaryresource.ManagedByLabelValue {
+			tracer.V(2).Info("leaving alone MachineConfig not managed by the operator", "name", mc.GetName())
+			return nil
+		}
+		tracer.Info("deleting kernel modules MachineConfig", "name", mc.GetName())
+		if err := r.Delete(ctx, mc); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		return r.trackRollout(ctx, true)
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, mc, func() error {
+		labels := mc.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[roleLabelKey] = r.Role
+		labels[primaryresource.ManagedByLabelKey] = primaryresource.ManagedByLabelValue
+		mc.SetLabels(labels)
+		return unstructured.SetNestedMap(mc.Object, makeIgnitionConfig(), "spec", "config")
+	})
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("MachineConfig API is not available, is this an OpenShift cluster? %v", err)
+	}
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed kernel modules MachineConfig", "name", mc.GetName(), "role", r.Role, "operation", op)
+	}
+	return r.trackRollout(ctx, op != controllerutil.OperationResultNone)
+}
+
+// Start reconciles the MachineConfig periodically until the context is done. A rollout in progress is checked
+// more often.
+func (r *Reconciler) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("machineconfig").WithValues(λ.ComponentKey, "machineconfig")
+	for {
+		if err := r.Reconcile(log.IntoContext(ctx, logger)); err != nil {
+			logger.Error(err, "failed to reconcile kernel modules MachineConfig")
+		}
+		interval := resyncInterval
+		if r.UpgradeGate.Holds(r.rolloutAction()) {
+			interval = upgradeable.PollInterval
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// SetupWithManager adds the reconciler to the manager. It runs only on the leader.
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(r)
+}
@@ -0,0 +1,246 @@
+// Package orphans collects the objects left behind by the deleted operator resources. The objects in other
+// namespaces and the cluster-scoped objects cannot refer to their owner with an owner reference, so the garbage
+// collector of Kubernetes does not remove them. They are annotated with their primary resource instead and
+// labeled as managed. The collector lists the labeled objects periodically, deletes those whose primary resource
+// no longer exists and records the rest in the inventory of the owning SDIObservers.
+package orphans
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+// DefaultInterval is the default period of the collection.
+const DefaultInterval = time.Hour
+
+// managedKinds are the kinds of the objects annotated with their primary resource. The namespaces labeled for
+// the SLC Bridge and the image content source policies are left out, their deletion is too disruptive to be
+// triggered by a missing annotation target.
+var managedKinds = []schema.GroupVersionKind{
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+	{Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	{Group: "scheduling.k8s.io", Version: "v1", Kind: "PriorityClass"},
+	{Group: "route.openshift.io", Version: "v1", Kind: "Route"},
+	{Group: "tuned.openshift.io", Version: "v1", Kind: "Tuned"},
+	{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"},
+	{Group: "velero.io", Version: "v1", Kind: "Schedule"},
+	{Group: "networking.istio.io", Version: "v1beta1", Kind: "Gateway"},
+	{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"},
+	{Group: "networking.istio.io", Version: "v1beta1", Kind: "DestinationRule"},
+	{Group: "compliance.openshift.io", Version: "v1alpha1", Kind: "TailoredProfile"},
+	{Group: "compliance.openshift.io", Version: "v1alpha1", Kind: "ScanSettingBinding"},
+}
+
+// Collector deletes the managed objects whose primary resource is gone and refreshes the inventories of the
+// SDIObservers.
+type Collector struct {
+	Client client.Client
+	// an uncached reader; the managed objects span the namespaces and kinds not covered by the cache
+	Reader   client.Reader
+	Interval time.Duration
+}
+
+var _ manager.Runnable = &Collector{}
+
+// Start collects the orphans right away and then periodically until the context is done.
+func (c *Collector) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("orphans")
+	ctx = log.IntoContext(ctx, logger)
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Collect(ctx); err != nil {
+			logger.Error(err, "failed to collect the orphaned objects")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetupWithManager adds the collector to the manager. It runs only on the leader.
+func (c *Collector) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(c)
+}
+
+type owner struct {
+	kind string
+	key  types.NamespacedName
+}
+
+// getOwner parses the primary resource annotations of the object. False is returned for the objects without
+// or with a foreign primary resource.
+func getOwner(obj client.Object) (owner, bool) {
+	annotations := obj.GetAnnotations()
+	gk := schema.ParseGroupKind(annotations[primaryresource.TypeAnnotationKey])
+	if gk.Group != sdiv1alpha1.GroupVersion.Group || len(gk.Kind) == 0 {
+		return owner{}, false
+	}
+	parts := strings.SplitN(annotations[primaryresource.AnnotationKey], "/", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return owner{}, false
+	}
+	return owner{kind: gk.Kind, key: types.NamespacedName{Namespace: parts[0], Name: parts[1]}}, true
+}
+
+// Collect runs a single collection.
+func (c *Collector) Collect(ctx context.Context) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	exists := map[owner]bool{}
+	ownerExists := func(o owner) (bool, error) {
+		if found, ok := exists[o]; ok {
+			return found, nil
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(sdiv1alpha1.GroupVersion.WithKind(o.kind))
+		err := c.Reader.Get(ctx, o.key, obj)
+		switch {
+		case err == nil:
+			exists[o] = obj.GetDeletionTimestamp() == nil
+		case errors.IsNotFound(err):
+			exists[o] = false
+		case meta.IsNoMatchError(err):
+			// not a kind of this operator version, keep the object
+			exists[o] = true
+		default:
+			return false, err
+		}
+		return exists[o], nil
+	}
+
+	inventories := map[types.NamespacedName][]sdiv1alpha1.ManagedObjectReference{}
+	var errs []string
+	for _, gvk := range managedKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		err := c.Reader.List(ctx, list, client.MatchingLabels{
+			primaryresource.ManagedLabelKey: primaryresource.ManagedLabelValue,
+		})
+		if meta.IsNoMatchError(err) || errors.IsNotFound(err) {
+			tracer.V(2).Info("the kind is not served, skipping", "kind", gvk.Kind)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("failed to list %s: %v", gvk.Kind, err))
+			continue
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			o, ok := getOwner(obj)
+			if !ok || obj.GetDeletionTimestamp() != nil {
+				continue
+			}
+			found, err := ownerExists(o)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("failed to get %s %s: %v", o.kind, o.key, err))
+				continue
+			}
+			if found {
+				if o.kind == "SDIObserver" {
+					inventories[o.key] = append(inventories[o.key], sdiv1alpha1.ManagedObjectReference{
+						APIVersion: gvk.GroupVersion().String(),
+						Kind:       gvk.Kind,
+						Namespace:  obj.GetNamespace(),
+						Name:       obj.GetName(),
+					})
+				}
+				continue
+			}
+			tracer.Info("deleting the orphaned object", "kind", gvk.Kind, "namespace", obj.GetNamespace(),
+				"name", obj.GetName(), "owner", o.kind+" "+o.key.String())
+			uid := obj.GetUID()
+			// the precondition protects a recreated object of the same name
+			if err := c.Client.Delete(ctx, obj, client.Preconditions{UID: &uid}); err != nil &&
+				!errors.IsNotFound(err) && !errors.IsConflict(err) {
+				errs = append(errs, fmt.Sprintf("failed to delete %s %s/%s: %v", gvk.Kind, obj.GetNamespace(),
+					obj.GetName(), err))
+			}
+		}
+	}
+
+	if err := c.updateInventories(ctx, inventories); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// updateInventories records the managed objects in the status of every SDIObserver.
+func (c *Collector) updateInventories(
+	ctx context.Context,
+	inventories map[types.NamespacedName][]sdiv1alpha1.ManagedObjectReference,
+) error {
+	observers := &sdiv1alpha1.SDIObserverList{}
+	if err := c.Client.List(ctx, observers); err != nil {
+		return fmt.Errorf("failed to list the SDIObservers: %v", err)
+	}
+	var errs []string
+	for i := range observers.Items {
+		obs := &observers.Items[i]
+		inventory := inventories[client.ObjectKeyFromObject(obs)]
+		sort.Slice(inventory, func(i, j int) bool {
+			a, b := inventory[i], inventory[j]
+			if a.APIVersion != b.APIVersion {
+				return a.APIVersion < b.APIVersion
+			}
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			if a.Namespace != b.Namespace {
+				return a.Namespace < b.Namespace
+			}
+			return a.Name < b.Name
+		})
+		err := updates.Status(ctx, c.Client, obs, func() (bool, error) {
+			if len(inventory) == 0 && len(obs.Status.Inventory) == 0 ||
+				equality.Semantic.DeepEqual(inventory, obs.Status.Inventory) {
+				return false, nil
+			}
+			obs.Status.Inventory = inventory
+			return true, nil
+		})
+		if err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Sprintf("failed to update the inventory of SDIObserver %s: %v",
+				client.ObjectKeyFromObject(obs), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
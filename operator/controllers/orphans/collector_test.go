@@ -0,0 +1,86 @@
+package orphans_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/orphans"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+var _ = Describe("Orphan collector", func() {
+	ctx := context.Background()
+	var c client.Client
+	var collector *orphans.Collector
+	obs := &sdiv1alpha1.SDIObserver{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi-observer", Name: "sdi"}}
+	gone := &sdiv1alpha1.SDIObserver{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi-observer", Name: "gone"}}
+
+	mkSecret := func(name string, owner *sdiv1alpha1.SDIObserver) *corev1.Secret {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: name}}
+		if owner != nil {
+			primaryresource.Set(secret, owner, "SDIObserver")
+		}
+		return secret
+	}
+	exists := func(obj client.Object) bool {
+		err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if errors.IsNotFound(err) {
+			return false
+		}
+		Ω(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Ω(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Ω(sdiv1alpha1.AddToScheme(scheme)).To(Succeed())
+		unlabeled := mkSecret("unlabeled", gone)
+		delete(unlabeled.Labels, primaryresource.ManagedLabelKey)
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-config", Name: "trusted-ca"}}
+		primaryresource.Set(cm, obs, "SDIObserver")
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			obs.DeepCopy(),
+			mkSecret("owned", obs),
+			mkSecret("orphaned", gone),
+			mkSecret("foreign", nil),
+			unlabeled,
+			cm,
+		).Build()
+		collector = &orphans.Collector{Client: c, Reader: c}
+	})
+
+	It("deletes the objects of the deleted owner", func() {
+		Ω(collector.Collect(ctx)).To(Succeed())
+		Ω(exists(mkSecret("orphaned", nil))).To(BeFalse())
+		Ω(exists(mkSecret("owned", nil))).To(BeTrue())
+		Ω(exists(mkSecret("foreign", nil))).To(BeTrue())
+		// only the labeled objects are collected
+		Ω(exists(mkSecret("unlabeled", nil))).To(BeTrue())
+	})
+
+	It("records the inventory of the SDIObserver", func() {
+		Ω(collector.Collect(ctx)).To(Succeed())
+		current := &sdiv1alpha1.SDIObserver{}
+		Ω(c.Get(ctx, client.ObjectKeyFromObject(obs), current)).To(Succeed())
+		Ω(current.Status.Inventory).To(Equal([]sdiv1alpha1.ManagedObjectReference{
+			{APIVersion: "v1", Kind: "ConfigMap", Namespace: "openshift-config", Name: "trusted-ca"},
+			{APIVersion: "v1", Kind: "Secret", Namespace: "sdi", Name: "owned"},
+		}))
+
+		Ω(c.Delete(ctx, mkSecret("owned", nil))).To(Succeed())
+		Ω(collector.Collect(ctx)).To(Succeed())
+		Ω(c.Get(ctx, client.ObjectKeyFromObject(obs), current)).To(Succeed())
+		Ω(current.Status.Inventory).To(HaveLen(1))
+	})
+})
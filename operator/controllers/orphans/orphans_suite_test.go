@@ -0,0 +1,13 @@
+package orphans_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestOrphans(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Orphans Suite")
+}
@@ -0,0 +1,377 @@
+package protection
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+const (
+	// DefaultCertSecretName is the name of the secret holding the self-managed CA and serving certificate.
+	DefaultCertSecretName = "sdi-observer-webhook-cert"
+
+	caCertKey         = "ca.crt"
+	caKeyKey          = "ca.key"
+	previousCACertKey = "ca-previous.crt"
+
+	caValidity      = time.Hour * 24 * 365 * 2
+	servingValidity = time.Hour * 24 * 90
+	// the certificates are renewed after two thirds of their validity
+	renewalFraction = 3
+	// tolerates the clock skew between the nodes
+	notBeforeSkew = time.Hour
+	checkInterval = time.Hour
+)
+
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;update;patch
+
+// CertRotator issues the serving certificate of the webhook server from a self-signed CA kept in a secret of the
+// operator namespace. It renews the certificates before they expire, writes the serving certificate to the
+// certificate directory of the webhook server and injects the CA bundle into the webhook configurations pointing
+// to the webhook service. Every replica writes its own files, the secret is shared.
+type CertRotator struct {
+	// an uncached client; the certificate must be in place before the manager starts
+	Client      client.Client
+	Namespace   string
+	ServiceName string
+	SecretName  string
+	// the certificate directory of the webhook server
+	CertDir string
+	// the time source, replaced by the tests
+	Now func() time.Time
+}
+
+var _ manager.Runnable = &CertRotator{}
+var _ manager.LeaderElectionRunnable = &CertRotator{}
+
+// NeedLeaderElection returns false because the standby replicas serve the webhook as well.
+func (r *CertRotator) NeedLeaderElection() bool {
+	return false
+}
+
+// Start renews the certificates periodically until the context is done.
+func (r *CertRotator) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("webhook-cert")
+	ctx = log.IntoContext(ctx, logger)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := r.Ensure(ctx); err != nil {
+				logger.Error(err, "failed to rotate the webhook serving certificate")
+			}
+		}
+	}
+}
+
+// SetupWithManager adds the rotator to the manager.
+func (r *CertRotator) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(r)
+}
+
+// Ensure issues or renews the certificates as needed, writes the serving certificate and injects the CA bundle.
+func (r *CertRotator) Ensure(ctx context.Context) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	var secret *corev1.Secret
+	if err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		// another replica has created or updated the secret concurrently
+		return errors.IsConflict(err) || errors.IsAlreadyExists(err)
+	}, func() (err error) {
+		secret, err = r.reconcileSecret(ctx)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to reconcile the webhook certificate secret: %v", err)
+	}
+	if err := writeFileIfChanged(filepath.Join(r.CertDir, corev1.TLSPrivateKeyKey),
+		secret.Data[corev1.TLSPrivateKeyKey]); err != nil {
+		return err
+	}
+	if err := writeFileIfChanged(filepath.Join(r.CertDir, corev1.TLSCertKey),
+		secret.Data[corev1.TLSCertKey]); err != nil {
+		return err
+	}
+	return r.injectCABundle(ctx, caBundle(secret, r.now()))
+}
+
+func (r *CertRotator) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// reconcileSecret creates or updates the secret unless its certificates are valid.
+func (r *CertRotator) reconcileSecret(ctx context.Context) (*corev1.Secret, error) {
+	logger := log.FromContext(ctx)
+	now := r.now()
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: r.SecretName}, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+	if !exists {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: r.Namespace,
+				Name:      r.SecretName,
+			},
+			Type: corev1.SecretTypeTLS,
+		}
+	}
+	// persisted along with the next change of the certificates
+	primaryresource.SetManagedBy(secret)
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	dnsNames := []string{
+		fmt.Sprintf("%s.%s.svc", r.ServiceName, r.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", r.ServiceName, r.Namespace),
+	}
+	ca, caKey, err := parsePair(secret.Data[caCertKey], secret.Data[caKeyKey])
+	renewCA := err != nil || needsRenewal(ca, now)
+	if renewCA {
+		if ca != nil && now.Before(ca.NotAfter) {
+			// the peers may not have picked up the new bundle yet
+			secret.Data[previousCACertKey] = secret.Data[caCertKey]
+		} else {
+			delete(secret.Data, previousCACertKey)
+		}
+		if ca, caKey, err = newCA(r.ServiceName, now); err != nil {
+			return nil, err
+		}
+		secret.Data[caCertKey] = encodeCert(ca)
+		if secret.Data[caKeyKey], err = encodeKey(caKey); err != nil {
+			return nil, err
+		}
+		logger.Info("issued a new webhook CA", "notAfter", ca.NotAfter)
+	}
+	cert, _, err := parsePair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if !renewCA && err == nil && !needsRenewal(cert, now) && cert.CheckSignatureFrom(ca) == nil &&
+		hasDNSNames(cert, dnsNames) {
+		return secret, nil
+	}
+	cert, key, err := newServingCert(ca, caKey, dnsNames, now)
+	if err != nil {
+		return nil, err
+	}
+	secret.Data[corev1.TLSCertKey] = encodeCert(cert)
+	if secret.Data[corev1.TLSPrivateKeyKey], err = encodeKey(key); err != nil {
+		return nil, err
+	}
+	logger.Info("issued a new webhook serving certificate", "notAfter", cert.NotAfter)
+	if exists {
+		return secret, r.Client.Update(ctx, secret)
+	}
+	return secret, r.Client.Create(ctx, secret)
+}
+
+// injectCABundle sets the CA bundle of the webhooks calling the webhook service.
+func (r *CertRotator) injectCABundle(ctx context.Context, bundle []byte) error {
+	logger := log.FromContext(ctx)
+	configs := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, configs); err != nil {
+		if rbacscope.IsForbidden(err) {
+			logger.Info("cannot inject the CA bundle into the webhook configurations in the namespace-scoped RBAC" +
+				" mode")
+			return nil
+		}
+		return err
+	}
+	for i := range configs.Items {
+		config := &configs.Items[i]
+		orig := config.DeepCopy()
+		changed := false
+		for j := range config.Webhooks {
+			clientConfig := &config.Webhooks[j].ClientConfig
+			svc := clientConfig.Service
+			if svc != nil && svc.Namespace == r.Namespace && svc.Name == r.ServiceName &&
+				!bytes.Equal(clientConfig.CABundle, bundle) {
+				clientConfig.CABundle = bundle
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		logger.Info("injecting the CA bundle", "webhookConfiguration", config.Name)
+		if err := r.Client.Patch(ctx, config, client.MergeFrom(orig)); err != nil {
+			return fmt.Errorf("failed to inject the CA bundle into %s: %v", config.Name, err)
+		}
+	}
+	return nil
+}
+
+// caBundle returns the current CA followed by the previous one until it expires.
+func caBundle(secret *corev1.Secret, now time.Time) []byte {
+	bundle := append([]byte{}, secret.Data[caCertKey]...)
+	if previous, err := parseCert(secret.Data[previousCACertKey]); err == nil && now.Before(previous.NotAfter) {
+		bundle = append(bundle, secret.Data[previousCACertKey]...)
+	}
+	return bundle
+}
+
+func needsRenewal(cert *x509.Certificate, now time.Time) bool {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return now.After(cert.NotBefore.Add(lifetime - lifetime/renewalFraction))
+}
+
+func hasDNSNames(cert *x509.Certificate, dnsNames []string) bool {
+	for _, name := range dnsNames {
+		if cert.VerifyHostname(name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func newCA(serviceName string, now time.Time) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-ca@%d", serviceName, now.Unix())},
+		NotBefore:             now.Add(-notBeforeSkew),
+		NotAfter:              now.Add(caValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return sign(template, nil, nil)
+}
+
+func newServingCert(
+	ca *x509.Certificate,
+	caKey *ecdsa.PrivateKey,
+	dnsNames []string,
+	now time.Time,
+) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		NotBefore:   now.Add(-notBeforeSkew),
+		NotAfter:    now.Add(servingValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	return sign(template, ca, caKey)
+}
+
+// sign issues the certificate with a new P-256 key. The certificate is self-signed unless the parent is given.
+func sign(
+	template *x509.Certificate,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func parseCert(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func parsePair(certData, keyData []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	cert, err := parseCert(certData)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(keyData)
+	if block == nil {
+		return nil, nil, fmt.Errorf("no PEM encoded private key found")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !key.PublicKey.Equal(cert.PublicKey) {
+		return nil, nil, fmt.Errorf("the private key does not match the certificate")
+	}
+	return cert, key, nil
+}
+
+func encodeCert(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// writeFileIfChanged replaces the file atomically so that the certificate watcher of the webhook server never
+// reads a partially written file.
+func writeFileIfChanged(path string, data []byte) error {
+	if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,122 @@
+package protection_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/protection"
+)
+
+var _ = Describe("Webhook certificate rotator", func() {
+	ctx := context.Background()
+	var c client.Client
+	var r *protection.CertRotator
+	var now time.Time
+	secretKey := client.ObjectKey{Namespace: "sdi-observer", Name: protection.DefaultCertSecretName}
+
+	mkConfig := func(name, namespace string) *admissionregistrationv1.ValidatingWebhookConfiguration {
+		return &admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name: "managed-objects.di.sap-cop.redhat.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{
+						Namespace: namespace,
+						Name:      "operator-webhook-service",
+					},
+				},
+			}},
+		}
+	}
+	getBundle := func(name string) []byte {
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		Ω(c.Get(ctx, client.ObjectKey{Name: name}, config)).To(Succeed())
+		return config.Webhooks[0].ClientConfig.CABundle
+	}
+	getSecret := func() *corev1.Secret {
+		secret := &corev1.Secret{}
+		Ω(c.Get(ctx, secretKey, secret)).To(Succeed())
+		return secret
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Ω(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			mkConfig("operator-validating-webhook-configuration", "sdi-observer"),
+			mkConfig("other", "other-operator"),
+		).Build()
+		dir, err := os.MkdirTemp("", "webhook-cert")
+		Ω(err).NotTo(HaveOccurred())
+		now = time.Now()
+		r = &protection.CertRotator{
+			Client:      c,
+			Namespace:   "sdi-observer",
+			ServiceName: "operator-webhook-service",
+			SecretName:  protection.DefaultCertSecretName,
+			CertDir:     dir,
+			Now:         func() time.Time { return now },
+		}
+	})
+
+	AfterEach(func() {
+		Ω(os.RemoveAll(r.CertDir)).To(Succeed())
+	})
+
+	It("Should issue the certificate and inject the CA bundle", func() {
+		Ω(r.Ensure(ctx)).To(Succeed())
+		secret := getSecret()
+		Ω(secret.Data).To(HaveKey("ca.crt"))
+		Ω(secret.Data).NotTo(HaveKey("ca-previous.crt"))
+		for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+			data, err := os.ReadFile(filepath.Join(r.CertDir, key))
+			Ω(err).NotTo(HaveOccurred())
+			Ω(data).To(Equal(secret.Data[key]))
+		}
+		Ω(getBundle("operator-validating-webhook-configuration")).To(Equal(secret.Data["ca.crt"]))
+		Ω(getBundle("other")).To(BeEmpty())
+
+		// nothing changes until the renewal
+		Ω(r.Ensure(ctx)).To(Succeed())
+		Ω(getSecret().Data).To(Equal(secret.Data))
+	})
+
+	It("Should renew the serving certificate", func() {
+		Ω(r.Ensure(ctx)).To(Succeed())
+		issued := getSecret()
+		now = now.Add(time.Hour * 24 * 70)
+		Ω(r.Ensure(ctx)).To(Succeed())
+		renewed := getSecret()
+		Ω(renewed.Data["ca.crt"]).To(Equal(issued.Data["ca.crt"]))
+		Ω(renewed.Data[corev1.TLSCertKey]).NotTo(Equal(issued.Data[corev1.TLSCertKey]))
+		data, err := os.ReadFile(filepath.Join(r.CertDir, corev1.TLSCertKey))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(data).To(Equal(renewed.Data[corev1.TLSCertKey]))
+	})
+
+	It("Should keep the previous CA in the bundle after its renewal", func() {
+		Ω(r.Ensure(ctx)).To(Succeed())
+		issued := getSecret()
+		now = now.Add(time.Hour * 24 * 500)
+		Ω(r.Ensure(ctx)).To(Succeed())
+		renewed := getSecret()
+		Ω(renewed.Data["ca.crt"]).NotTo(Equal(issued.Data["ca.crt"]))
+		Ω(renewed.Data["ca-previous.crt"]).To(Equal(issued.Data["ca.crt"]))
+		bundle := getBundle("operator-validating-webhook-configuration")
+		Ω(bytes.HasPrefix(bundle, renewed.Data["ca.crt"])).To(BeTrue())
+		Ω(bytes.HasSuffix(bundle, issued.Data["ca.crt"])).To(BeTrue())
+	})
+})
@@ -0,0 +1,13 @@
+package protection_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestProtection(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Protection Suite")
+}
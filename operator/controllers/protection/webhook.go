@@ -0,0 +1,164 @@
+// Package protection guards the objects managed by the operator against manual changes. The operator reverts
+// such changes on the next reconciliation which tends to confuse the administrators who edited e.g. the vsystem
+// route by hand. The validating webhook warns about or denies the updates and deletions of the managed objects
+// unless the object carries the override annotation.
+package protection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// Path is the path of the webhook served by the manager.
+	Path = "/validate-managed-objects"
+
+	// OverrideAnnotationKey allows the manual changes of the annotated managed object when set to "true".
+	OverrideAnnotationKey = "di.sap-cop.redhat.com/allow-manual-changes"
+
+	// ModeWarn admits the manual changes with a warning.
+	ModeWarn = "Warn"
+	// ModeDeny rejects the manual changes.
+	ModeDeny = "Deny"
+
+	serviceAccountPrefix = "system:serviceaccount:"
+)
+
+// the users of the cluster components updating the managed objects on their own, e.g. the garbage collector
+// removing the owner references or the service CA operator injecting the CA bundle
+var trustedUserPrefixes = []string{
+	serviceAccountPrefix + "kube-system:",
+	serviceAccountPrefix + "openshift-",
+	"system:kube-",
+}
+
+// The objectSelector on primaryresource.ManagedByLabelKey, which the marker cannot express, is added by
+// config/webhook/objectselector_patch.yaml so that the API server calls the webhook only for the managed objects.
+//+kubebuilder:webhook:path=/validate-managed-objects,mutating=false,failurePolicy=ignore,sideEffects=None,groups="";apps;batch;route.openshift.io;rbac.authorization.k8s.io;networking.k8s.io;monitoring.coreos.com,resources=configmaps;secrets;services;serviceaccounts;persistentvolumeclaims;deployments;daemonsets;cronjobs;routes;rolebindings;networkpolicies;prometheusrules;servicemonitors,verbs=update;delete,versions=v1,name=managed-objects.di.sap-cop.redhat.com,admissionReviewVersions=v1
+
+// Validator admits the changes of the managed objects.
+type Validator struct {
+	// the changes made by the service accounts of the operator namespace are admitted
+	Namespace string
+	// ModeWarn or ModeDeny
+	Mode string
+}
+
+var _ admission.Handler = &Validator{}
+
+// ValidateMode returns an error unless the mode is known.
+func ValidateMode(mode string) error {
+	if mode != ModeWarn && mode != ModeDeny {
+		return fmt.Errorf("unknown mode %q, expected %s or %s", mode, ModeWarn, ModeDeny)
+	}
+	return nil
+}
+
+// Handle warns about or denies the update or deletion of a managed object.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update && req.Operation != admissionv1.Delete {
+		return admission.Allowed("")
+	}
+	if v.isTrusted(req.UserInfo) {
+		return admission.Allowed("")
+	}
+	old, err := decode(req.OldObject.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	manager := GetManager(old)
+	if len(manager) == 0 {
+		return admission.Allowed("")
+	}
+	if isOverridden(old) {
+		return admission.Allowed("manual changes allowed by " + OverrideAnnotationKey)
+	}
+	if req.Operation == admissionv1.Update {
+		obj, err := decode(req.Object.Raw)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if isOverridden(obj) {
+			return admission.Allowed("manual changes allowed by " + OverrideAnnotationKey)
+		}
+	}
+
+	action := "modification"
+	if req.Operation == admissionv1.Delete {
+		action = "deletion"
+	}
+	name := req.Name
+	if len(req.Namespace) > 0 {
+		name = req.Namespace + "/" + name
+	}
+	msg := fmt.Sprintf("%s %s is managed by %s, the manual %s will be reverted; annotate it with %s=true to"+
+		" keep the change", strings.ToLower(req.Kind.Kind), name, manager, action, OverrideAnnotationKey)
+	log.FromContext(ctx).Info("manual change of a managed object", "kind", req.Kind.Kind, "name", name,
+		"user", req.UserInfo.Username, "operation", req.Operation)
+	if v.Mode == ModeDeny {
+		return admission.Denied(msg)
+	}
+	return admission.Allowed("").WithWarnings(msg)
+}
+
+func (v *Validator) isTrusted(user authenticationv1.UserInfo) bool {
+	if strings.HasPrefix(user.Username, serviceAccountPrefix+v.Namespace+":") {
+		return true
+	}
+	for _, prefix := range trustedUserPrefixes {
+		if strings.HasPrefix(user.Username, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func decode(raw []byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if len(raw) == 0 {
+		return obj, nil
+	}
+	if err := json.Unmarshal(raw, &obj.Object); err != nil {
+		return nil, fmt.Errorf("failed to decode the object: %v", err)
+	}
+	return obj, nil
+}
+
+func isOverridden(obj metav1.Object) bool {
+	return obj.GetAnnotations()[OverrideAnnotationKey] == "true"
+}
+
+// GetManager describes the operator resource managing the object. An empty string is returned for the objects
+// not managed by the operator.
+func GetManager(obj metav1.Object) string {
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == sdiv1alpha1.GroupVersion.Group && ref.Controller != nil && *ref.Controller {
+			return fmt.Sprintf("%s %s", ref.Kind, ref.Name)
+		}
+	}
+	annotations := obj.GetAnnotations()
+	if owner := annotations[primaryresource.AnnotationKey]; len(owner) > 0 &&
+		strings.HasSuffix(annotations[primaryresource.TypeAnnotationKey], "."+sdiv1alpha1.GroupVersion.Group) {
+		kind := strings.SplitN(annotations[primaryresource.TypeAnnotationKey], ".", 2)[0]
+		return fmt.Sprintf("%s %s", kind, owner)
+	}
+	if obj.GetLabels()[primaryresource.ManagedByLabelKey] == primaryresource.ManagedByLabelValue {
+		return "the SDI operator"
+	}
+	return ""
+}
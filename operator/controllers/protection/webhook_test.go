@@ -0,0 +1,111 @@
+package protection_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/protection"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+func mkRequest(op admissionv1.Operation, user string, old, obj *corev1.ConfigMap) admission.Request {
+	encode := func(cm *corev1.ConfigMap) runtime.RawExtension {
+		if cm == nil {
+			return runtime.RawExtension{}
+		}
+		raw, err := json.Marshal(cm)
+		Ω(err).NotTo(HaveOccurred())
+		return runtime.RawExtension{Raw: raw}
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: op,
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Namespace: old.Namespace,
+		Name:      old.Name,
+		UserInfo:  authenticationv1.UserInfo{Username: user},
+		OldObject: encode(old),
+		Object:    encode(obj),
+	}}
+}
+
+var _ = Describe("Managed objects validator", func() {
+	ctx := context.Background()
+	controller := true
+	owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "owned",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "di.sap-cop.redhat.com/v1alpha1",
+			Kind: "SDIRegistry", Name: "registry", Controller: &controller}}}}
+	annotated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "annotated",
+		Annotations: map[string]string{
+			primaryresource.AnnotationKey:     "sdi-observer/sdi",
+			primaryresource.TypeAnnotationKey: "SDIObserver.di.sap-cop.redhat.com",
+		}}}
+	labeled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "labeled",
+		Labels: map[string]string{"app.kubernetes.io/managed-by": "sdi-observer"}}}
+	unmanaged := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "unmanaged"}}
+	admin := "kube:admin"
+
+	It("Should recognize the managed objects", func() {
+		Ω(protection.GetManager(owned)).To(Equal("SDIRegistry registry"))
+		Ω(protection.GetManager(annotated)).To(Equal("SDIObserver sdi-observer/sdi"))
+		Ω(protection.GetManager(labeled)).NotTo(BeEmpty())
+		Ω(protection.GetManager(unmanaged)).To(BeEmpty())
+	})
+
+	It("Should warn about the manual changes by default", func() {
+		v := &protection.Validator{Namespace: "sdi-observer", Mode: protection.ModeWarn}
+		for _, cm := range []*corev1.ConfigMap{owned, annotated, labeled} {
+			resp := v.Handle(ctx, mkRequest(admissionv1.Update, admin, cm, cm))
+			Ω(resp.Allowed).To(BeTrue())
+			Ω(resp.Warnings).To(HaveLen(1))
+			Ω(resp.Warnings[0]).To(ContainSubstring("modification will be reverted"))
+		}
+		resp := v.Handle(ctx, mkRequest(admissionv1.Delete, admin, owned, nil))
+		Ω(resp.Allowed).To(BeTrue())
+		Ω(resp.Warnings[0]).To(ContainSubstring("deletion will be reverted"))
+	})
+
+	It("Should deny the manual changes in the deny mode", func() {
+		v := &protection.Validator{Namespace: "sdi-observer", Mode: protection.ModeDeny}
+		resp := v.Handle(ctx, mkRequest(admissionv1.Delete, admin, annotated, nil))
+		Ω(resp.Allowed).To(BeFalse())
+		Ω(string(resp.Result.Reason)).To(ContainSubstring(protection.OverrideAnnotationKey))
+
+		resp = v.Handle(ctx, mkRequest(admissionv1.Update, admin, unmanaged, unmanaged))
+		Ω(resp.Allowed).To(BeTrue())
+		Ω(resp.Warnings).To(BeEmpty())
+	})
+
+	It("Should admit the changes of the overridden objects and trusted users", func() {
+		v := &protection.Validator{Namespace: "sdi-observer", Mode: protection.ModeDeny}
+		overridden := owned.DeepCopy()
+		overridden.Annotations = map[string]string{protection.OverrideAnnotationKey: "true"}
+		Ω(v.Handle(ctx, mkRequest(admissionv1.Update, admin, owned, overridden)).Allowed).To(BeTrue())
+		Ω(v.Handle(ctx, mkRequest(admissionv1.Delete, admin, overridden, nil)).Allowed).To(BeTrue())
+
+		for _, user := range []string{
+			"system:serviceaccount:sdi-observer:sdi-observer-operator",
+			"system:serviceaccount:kube-system:generic-garbage-collector",
+			"system:serviceaccount:openshift-service-ca:service-ca",
+			"system:kube-controller-manager",
+		} {
+			Ω(v.Handle(ctx, mkRequest(admissionv1.Update, user, owned, owned)).Allowed).To(BeTrue())
+		}
+		Ω(v.Handle(ctx, mkRequest(admissionv1.Update,
+			"system:serviceaccount:sdi:default", owned, owned)).Allowed).To(BeFalse())
+	})
+
+	It("Should validate the mode", func() {
+		Ω(protection.ValidateMode(protection.ModeWarn)).To(Succeed())
+		Ω(protection.ValidateMode(protection.ModeDeny)).To(Succeed())
+		Ω(protection.ValidateMode("Block")).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,595 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdiimagemirror
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/images"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+const (
+	containerName   = "mirror"
+	authMountPath   = "/auth"
+	certMountPath   = "/certs"
+	pollInterval    = time.Second * 10
+	jobBackoffLimit = 2
+
+	conditionMirrored = "Mirrored"
+
+	finalizerName = "di.sap-cop.redhat.com/sdiimagemirror-cleanup"
+	kind          = "SDIImageMirror"
+)
+
+// mirrorScript copies each "<source> <target> [<reference>]" line given in the IMAGES variable and prints the
+// manifest digest of each mirrored image to the standard output where the controller picks it up. The image is
+// reported by the reference, if given, instead of the target it has been pushed to.
+const mirrorScript = `set -u
+args=( --retry-times 3 )
+inspectArgs=()
+if [[ -e "` + authMountPath + `/.dockerconfigjson" ]]; then
+    args+=( --authfile "` + authMountPath + `/.dockerconfigjson" )
+    inspectArgs+=( --authfile "` + authMountPath + `/.dockerconfigjson" )
+fi
+if [[ "${INSECURE_TARGET:-false}" == true ]]; then
+    args+=( --dest-tls-verify=false )
+    inspectArgs+=( --tls-verify=false )
+fi
+if [[ -n "${TARGET_CERT_DIR:-}" ]]; then
+    args+=( --dest-cert-dir "$TARGET_CERT_DIR" )
+    inspectArgs+=( --cert-dir "$TARGET_CERT_DIR" )
+fi
+rc=0
+while read -r src dst ref; do
+    [[ -z "${src:-}" ]] && continue
+    ref="${ref:-$dst}"
+    if ! skopeo copy --all "${args[@]}" "docker://$src" "docker://$dst" >&2; then
+        printf 'failed %s %s\n' "$src" "$ref"
+        rc=1
+        continue
+    fi
+    digest="$(skopeo inspect --raw "${inspectArgs[@]}" "docker://$dst" | sha256sum | cut -d ' ' -f 1)"
+    printf 'mirrored %s %s sha256:%s\n' "$src" "$ref" "$digest"
+done <<<"$IMAGES"
+exit "$rc"
+`
+
+// Reconciler reconciles SDIImageMirror objects.
+type Reconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	kubeClient kubernetes.Interface
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme}
+}
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiimagemirrors,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiimagemirrors/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiimagemirrors/finalizers,verbs=update
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods/log,verbs=get
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiregistries,verbs=get;list;watch
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=imagecontentsourcepolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows,verbs=get;list;watch
+
+// Reconcile runs a mirroring job for each generation of the SDIImageMirror spec and creates the
+// ImageContentSourcePolicy once the job succeeds.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	im := &sdiv1alpha1.SDIImageMirror{}
+	if err = r.Get(ctx, req.NamespacedName, im); err != nil {
+		return rs, client.IgnoreNotFound(err)
+	}
+
+	if im.DeletionTimestamp != nil {
+		if !controllerutil.ContainsFinalizer(im, finalizerName) {
+			return
+		}
+		if err = r.deleteImageContentSourcePolicy(ctx, im); err != nil {
+			return
+		}
+		return rs, updates.Object(ctx, r.Client, im, func() (bool, error) {
+			if !controllerutil.ContainsFinalizer(im, finalizerName) {
+				return false, nil
+			}
+			controllerutil.RemoveFinalizer(im, finalizerName)
+			return true, nil
+		})
+	}
+	var finalizerErr error
+	if !skipPolicy(im) && !controllerutil.ContainsFinalizer(im, finalizerName) {
+		finalizerErr = updates.Object(ctx, r.Client, im, func() (bool, error) {
+			if controllerutil.ContainsFinalizer(im, finalizerName) {
+				return false, nil
+			}
+			controllerutil.AddFinalizer(im, finalizerName)
+			return true, nil
+		})
+		if finalizerErr != nil && !updates.IsPermanent(finalizerErr) {
+			return rs, finalizerErr
+		}
+	}
+
+	pairs := getImagePairs(im)
+	if im.Status.ObservedGeneration != im.Generation {
+		tracer.Info("starting a new mirroring round", "generation", im.Generation)
+		if len(im.Status.JobName) > 0 {
+			if err = r.deleteJob(ctx, im.Namespace, im.Status.JobName); err != nil {
+				return
+			}
+		}
+		im.Status = sdiv1alpha1.SDIImageMirrorStatus{
+			ObservedGeneration: im.Generation,
+			JobName:            fmt.Sprintf("%s-%d", im.Name, im.Generation),
+			Total:              len(pairs),
+		}
+	}
+
+	requeue, err := r.manageJob(ctx, im, pairs)
+	if err == nil && !requeue {
+		rs.RequeueAfter, err = r.manageImageContentSourcePolicy(ctx, im)
+	}
+	setReadyCondition(im)
+	var disabled []string
+	if !im.Spec.SkipImageContentSourcePolicy {
+		disabled = append(disabled, "ImageContentSourcePolicy")
+	}
+	rbacscope.SetCondition(&im.Status.Conditions, im.Generation, disabled)
+	if finalizerErr != nil {
+		updates.SetDegraded(&im.Status.Conditions, im.Generation, "the finalizer", finalizerErr)
+	}
+	if requeue {
+		rs.RequeueAfter = pollInterval
+	}
+	status := im.Status.DeepCopy()
+	updErr := updates.Status(ctx, r.Client, im, func() (bool, error) {
+		im.Status = *status
+		return true, nil
+	})
+	if updates.IsPermanent(updErr) {
+		// retrying would not help
+		tracer.Error(updErr, "SDIImageMirror status update has been refused")
+	} else if updErr != nil {
+		tracer.Error(updErr, "failed to update SDIImageMirror status")
+		return rs, updErr
+	}
+	return rs, err
+}
+
+func setCondition(im *sdiv1alpha1.SDIImageMirror, cType string, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&im.Status.Conditions, metav1.Condition{
+		Type:               cType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: im.Generation,
+	})
+}
+
+// imagePair is an image to mirror. The path is the repository with the tag or digest suffix, relative to the
+// target registry.
+type imagePair struct {
+	source string
+	path   string
+}
+
+// splitRepository splits the image reference into the repository and the tag or digest suffix including
+// the separator.
+func splitRepository(image string) (string, string) {
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		return image[:i], image[i:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i:]
+	}
+	return image, ""
+}
+
+func getImagePairs(im *sdiv1alpha1.SDIImageMirror) []imagePair {
+	source := strings.TrimSuffix(im.Spec.SourceRegistry, "/")
+	pairs := make([]imagePair, 0, len(im.Spec.Images))
+	for _, image := range im.Spec.Images {
+		repo, suffix := splitRepository(strings.Trim(strings.TrimSpace(image), "/"))
+		if len(repo) == 0 {
+			continue
+		}
+		if len(suffix) == 0 {
+			suffix = ":" + im.Spec.Version
+		}
+		pairs = append(pairs, imagePair{
+			source: source + "/" + repo + suffix,
+			path:   repo + suffix,
+		})
+	}
+	return pairs
+}
+
+// manageJob creates the mirroring job unless it exists and updates the progress. It returns true if the
+// job is still running.
+func (r *Reconciler) manageJob(ctx context.Context, im *sdiv1alpha1.SDIImageMirror, pairs []imagePair) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if c := meta.FindStatusCondition(im.Status.Conditions, conditionMirrored); c != nil &&
+		c.Status != metav1.ConditionUnknown && c.ObservedGeneration == im.Generation {
+		return false, nil
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: im.Namespace, Name: im.Status.JobName}, job)
+	if errors.IsNotFound(err) {
+		var target *mirrorTarget
+		var poll bool
+		if target, poll, err = r.getMirrorTarget(ctx, im); target == nil && err == nil {
+			return poll, nil
+		}
+		if err == nil {
+			job, err = r.createJob(ctx, im, pairs, target)
+		}
+	}
+	if err != nil {
+		setCondition(im, conditionMirrored, metav1.ConditionUnknown, "FailedCreate",
+			fmt.Sprintf("failed to create the mirroring job: %v", err))
+		return false, err
+	}
+
+	if err := r.updateProgress(ctx, im, job); err != nil {
+		// the logs are not essential, the progress will be updated on the next attempt
+		tracer.Info("failed to read the mirroring job logs", "error", err)
+	}
+
+	progress := fmt.Sprintf("%d/%d images mirrored", im.Status.Mirrored, im.Status.Total)
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			setCondition(im, "Progressing", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected, progress)
+			setCondition(im, conditionMirrored, metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+				progress)
+			return false, nil
+		case batchv1.JobFailed:
+			setCondition(im, "Progressing", metav1.ConditionFalse, "JobFailed", progress)
+			setCondition(im, conditionMirrored, metav1.ConditionFalse, "JobFailed",
+				fmt.Sprintf("the mirroring job failed (%s): %s", progress, c.Message))
+			return false, nil
+		}
+	}
+	setCondition(im, "Progressing", metav1.ConditionTrue, "Mirroring", progress)
+	setCondition(im, conditionMirrored, metav1.ConditionUnknown, "Mirroring", progress)
+	return true, nil
+}
+
+func (r *Reconciler) createJob(
+	ctx context.Context,
+	im *sdiv1alpha1.SDIImageMirror,
+	pairs []imagePair,
+	target *mirrorTarget,
+) (*batchv1.Job, error) {
+	image := images.Mirror.Resolve(im.Spec.Image)
+	lines := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		line := p.source + " " + target.push + "/" + p.path
+		if target.push != target.registry {
+			line += " " + target.registry + "/" + p.path
+		}
+		lines = append(lines, line)
+	}
+	var backoffLimit int32 = jobBackoffLimit
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: im.Namespace,
+			Name:      im.Status.JobName,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    containerName,
+						Image:   image,
+						Command: []string{"/bin/bash", "-c", mirrorScript},
+						Env: []corev1.EnvVar{
+							{Name: "IMAGES", Value: strings.Join(lines, "\n")},
+							{Name: "INSECURE_TARGET", Value: fmt.Sprintf("%t", im.Spec.InsecureTargetRegistry)},
+						},
+					}},
+				},
+			},
+		},
+	}
+	podSpec := &job.Spec.Template.Spec
+	if len(target.authSecretName) > 0 {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "auth",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: target.authSecretName},
+			},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "auth",
+			MountPath: authMountPath,
+			ReadOnly:  true,
+		})
+	}
+	if len(target.caConfigMapName) > 0 {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "certs",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: target.caConfigMapName},
+					Items:                []corev1.KeyToPath{{Key: target.caKey, Path: "ca.crt"}},
+				},
+			},
+		})
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "certs",
+			MountPath: certMountPath,
+			ReadOnly:  true,
+		})
+		podSpec.Containers[0].Env = append(podSpec.Containers[0].Env,
+			corev1.EnvVar{Name: "TARGET_CERT_DIR", Value: certMountPath})
+	}
+	primaryresource.SetManagedBy(job)
+	if err := controllerutil.SetControllerReference(im, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("creating mirroring job", "job", job.Name, "images", len(pairs))
+	return job, r.Create(ctx, job)
+}
+
+// updateProgress parses the logs of the most recent job pod.
+func (r *Reconciler) updateProgress(ctx context.Context, im *sdiv1alpha1.SDIImageMirror, job *batchv1.Job) error {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return err
+	}
+	var latest *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodPending {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			latest = pod
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	raw, err := r.kubeClient.CoreV1().Pods(latest.Namespace).GetLogs(latest.Name, &corev1.PodLogOptions{
+		Container: containerName,
+	}).DoRaw(ctx)
+	if err != nil {
+		return err
+	}
+	images := parseMirrorLog(raw)
+	if len(images) >= len(im.Status.Images) {
+		im.Status.Images = images
+		im.Status.Mirrored = len(images)
+	}
+	return nil
+}
+
+func parseMirrorLog(raw []byte) []sdiv1alpha1.SDIImageMirrorImageStatus {
+	var images []sdiv1alpha1.SDIImageMirrorImageStatus
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 || fields[0] != "mirrored" {
+			continue
+		}
+		image := sdiv1alpha1.SDIImageMirrorImageStatus{
+			Source: fields[1],
+			Target: fields[2],
+			Digest: fields[3],
+		}
+		if _, suffix := splitRepository(image.Target); strings.HasPrefix(suffix, ":") {
+			image.Tag = suffix[1:]
+		}
+		images = append(images, image)
+	}
+	return images
+}
+
+// skipPolicy returns true if no ImageContentSourcePolicy shall exist for the SDIImageMirror. The cluster-scoped
+// policy cannot be managed in the namespace-scoped RBAC mode.
+func skipPolicy(im *sdiv1alpha1.SDIImageMirror) bool {
+	return im.Spec.SkipImageContentSourcePolicy || rbacscope.Namespaced()
+}
+
+func imageContentSourcePolicyName(im *sdiv1alpha1.SDIImageMirror) string {
+	return fmt.Sprintf("%s-%s", im.Namespace, im.Name)
+}
+
+// manageImageContentSourcePolicy creates or updates the ImageContentSourcePolicy. Any change of the policy
+// makes the machine config operator reboot the nodes. Therefore it is deferred until a maintenance window is
+// open in which case a non-zero requeue delay is returned.
+func (r *Reconciler) manageImageContentSourcePolicy(
+	ctx context.Context,
+	im *sdiv1alpha1.SDIImageMirror,
+) (time.Duration, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if skipPolicy(im) {
+		if err := r.deleteImageContentSourcePolicy(ctx, im); err != nil {
+			return 0, err
+		}
+		im.Status.ImageContentSourcePolicyName = ""
+		maintenance.SetPendingCondition(&im.Status.Conditions, im.Generation, false, maintenance.Result{}, "")
+		return 0, nil
+	}
+	if c := meta.FindStatusCondition(im.Status.Conditions, conditionMirrored); c == nil ||
+		c.Status != metav1.ConditionTrue {
+		return 0, nil
+	}
+
+	mirrors := make(map[string]string)
+	for _, image := range im.Status.Images {
+		source, _ := splitRepository(image.Source)
+		target, _ := splitRepository(image.Target)
+		mirrors[source] = target
+	}
+	sources := make([]string, 0, len(mirrors))
+	for source := range mirrors {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	icsp := &operatorv1alpha1.ImageContentSourcePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: imageContentSourcePolicyName(im)},
+	}
+	op, window, err := maintenance.CreateOrUpdate(ctx, r.Client, im.Namespace, icsp, func() error {
+		primaryresource.Set(icsp, im, kind)
+		icsp.Spec.RepositoryDigestMirrors = make([]operatorv1alpha1.RepositoryDigestMirrors, 0, len(sources))
+		for _, source := range sources {
+			icsp.Spec.RepositoryDigestMirrors = append(icsp.Spec.RepositoryDigestMirrors,
+				operatorv1alpha1.RepositoryDigestMirrors{Source: source, Mirrors: []string{mirrors[source]}})
+		}
+		return nil
+	}, true)
+	if err != nil {
+		tracer.Error(err, "failed to manage ImageContentSourcePolicy")
+		return 0, err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed ImageContentSourcePolicy", "name", icsp.Name, "operation", op)
+	}
+	deferred := op == maintenance.OperationResultDeferred
+	maintenance.SetPendingCondition(&im.Status.Conditions, im.Generation, deferred, window,
+		"the change of the ImageContentSourcePolicy")
+	if deferred {
+		if len(icsp.ResourceVersion) > 0 {
+			im.Status.ImageContentSourcePolicyName = icsp.Name
+		}
+		return window.RequeueAfter(), nil
+	}
+	im.Status.ImageContentSourcePolicyName = icsp.Name
+	return 0, nil
+}
+
+func setReadyCondition(im *sdiv1alpha1.SDIImageMirror) {
+	c := meta.FindStatusCondition(im.Status.Conditions, conditionMirrored)
+	switch {
+	case c == nil:
+		setCondition(im, "Ready", metav1.ConditionUnknown, "Mirroring", "the mirroring has not started yet")
+	case c.Status != metav1.ConditionTrue:
+		setCondition(im, "Ready", metav1.ConditionFalse, c.Reason, c.Message)
+	case !skipPolicy(im) && len(im.Status.ImageContentSourcePolicyName) == 0 &&
+		meta.IsStatusConditionTrue(im.Status.Conditions, sdiv1alpha1.ConditionMaintenancePending):
+		setCondition(im, "Ready", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonOutsideMaintenanceWindow,
+			"the images are mirrored but the ImageContentSourcePolicy waits for the next maintenance window")
+	case !skipPolicy(im) && len(im.Status.ImageContentSourcePolicyName) == 0:
+		setCondition(im, "Ready", metav1.ConditionFalse, "FailedImageContentSourcePolicy",
+			"the images are mirrored but the ImageContentSourcePolicy could not be created")
+	default:
+		setCondition(im, "Ready", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected, c.Message)
+	}
+}
+
+func (r *Reconciler) deleteJob(ctx context.Context, namespace, name string) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	if err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+		!errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *Reconciler) deleteImageContentSourcePolicy(ctx context.Context, im *sdiv1alpha1.SDIImageMirror) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+	icsp := &operatorv1alpha1.ImageContentSourcePolicy{}
+	err := r.Get(ctx, types.NamespacedName{Name: imageContentSourcePolicyName(im)}, icsp)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if rbacscope.IsForbidden(err) {
+		// created before the switch to the namespace-scoped RBAC
+		tracer.Info("leaving the ImageContentSourcePolicy behind", "name", icsp.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !primaryresource.IsOwnedBy(icsp, im, kind) {
+		return nil
+	}
+	if err := r.Delete(ctx, icsp); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+	r.kubeClient = kubeClient
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&sdiv1alpha1.SDIImageMirror{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.Secret{}).
+		Watches(&source.Kind{Type: &sdiv1alpha1.SDIRegistry{}}, handler.EnqueueRequestsFromMapFunc(r.mapRegistry))
+	if rbacscope.Namespaced() {
+		mgr.GetLogger().Info("the ImageContentSourcePolicies are not managed in the namespace-scoped RBAC mode")
+	} else {
+		b = b.Watches(&source.Kind{Type: &operatorv1alpha1.ImageContentSourcePolicy{}},
+			primaryresource.EnqueueRequestsForOwner(kind))
+	}
+	return b.Complete(r)
+}
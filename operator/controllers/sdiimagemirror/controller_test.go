@@ -0,0 +1,250 @@
+package sdiimagemirror_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+const (
+	timeout  = time.Second * 5
+	interval = time.Millisecond * 100
+
+	sourceRegistry = "registry.example.ltd/sap"
+	targetRegistry = "mirror.example.ltd:5000"
+	mirrorImage    = "registry.example.ltd/skopeo:latest"
+)
+
+// the jobs are not garbage collected in the test environment, each spec gets a new SDIImageMirror
+var mirrorIndex int
+
+func waitForMirror(im *sdiv1alpha1.SDIImageMirror, assert func(g Gomega, im *sdiv1alpha1.SDIImageMirror)) {
+	EventuallyWithOffset(1, func(g Gomega) {
+		g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(im), im)).ToNot(HaveOccurred())
+		assert(g, im)
+	}, timeout, interval).Should(Succeed())
+}
+
+func haveCondition(g Gomega, im *sdiv1alpha1.SDIImageMirror, cType string, status metav1.ConditionStatus, reason string) {
+	c := meta.FindStatusCondition(im.Status.Conditions, cType)
+	g.Ω(c).WithOffset(1).NotTo(BeNil())
+	g.Ω(c.Status).WithOffset(1).To(Equal(status))
+	g.Ω(c.Reason).WithOffset(1).To(Equal(reason))
+}
+
+func waitForObject(obj client.Object) {
+	EventuallyWithOffset(1, func() error {
+		return k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+	}, timeout, interval).Should(Succeed())
+}
+
+func waitForDeletion(obj client.Object) {
+	EventuallyWithOffset(1, func() bool {
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+		return errors.IsNotFound(err)
+	}, timeout, interval).Should(BeTrue())
+}
+
+// finishJob plays the job controller
+func finishJob(job *batchv1.Job, cType batchv1.JobConditionType) {
+	waitForObject(job)
+	ExpectWithOffset(1, retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(job), job); err != nil {
+			return err
+		}
+		now := metav1.Now()
+		job.Status.StartTime = &now
+		if cType == batchv1.JobComplete {
+			job.Status.CompletionTime = &now
+			job.Status.Succeeded = 1
+		} else {
+			job.Status.Failed = 1
+		}
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type:               cType,
+			Status:             corev1.ConditionTrue,
+			LastProbeTime:      now,
+			LastTransitionTime: now,
+			Message:            "finished by the test",
+		}}
+		return k8sClient.Status().Update(context.TODO(), job)
+	})).NotTo(HaveOccurred())
+}
+
+var _ = Describe("SDIImageMirror controller", func() {
+	var im *sdiv1alpha1.SDIImageMirror
+
+	mirrorJob := func(generation int64) *batchv1.Job {
+		return &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Namespace: im.Namespace,
+			Name:      fmt.Sprintf("%s-%d", im.Name, generation),
+		}}
+	}
+	policy := func() *operatorv1alpha1.ImageContentSourcePolicy {
+		return &operatorv1alpha1.ImageContentSourcePolicy{ObjectMeta: metav1.ObjectMeta{
+			Name: im.Namespace + "-" + im.Name,
+		}}
+	}
+	updateMirror := func(update func(im *sdiv1alpha1.SDIImageMirror)) {
+		Ω(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(im), im); err != nil {
+				return err
+			}
+			update(im)
+			return k8sClient.Update(context.TODO(), im)
+		})).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		mirrorIndex++
+		im = &sdiv1alpha1.SDIImageMirror{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "mirror",
+				Name:      fmt.Sprintf("sdi-%d", mirrorIndex),
+			},
+			Spec: sdiv1alpha1.SDIImageMirrorSpec{
+				Version:        "3.2.1",
+				SourceRegistry: sourceRegistry,
+				TargetRegistry: targetRegistry,
+				Images:         []string{"com.sap.datahub.linuxx86_64/vsystem"},
+				Image:          mirrorImage,
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		Ω(k8sClient.Create(context.Background(), im)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(context.Background(), im)
+		if !errors.IsNotFound(err) {
+			Ω(err).NotTo(HaveOccurred())
+		}
+		waitForDeletion(im)
+	})
+
+	Context("When an SDIImageMirror is created", func() {
+		It("Should create the mirroring job", func() {
+			job := mirrorJob(1)
+			waitForObject(job)
+			Ω(metav1.IsControlledBy(job, im)).To(BeTrue())
+			Ω(*job.Spec.BackoffLimit).To(BeEquivalentTo(2))
+			Ω(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+			container := job.Spec.Template.Spec.Containers[0]
+			Ω(container.Image).To(Equal(mirrorImage))
+			Ω(container.Env).To(ContainElement(corev1.EnvVar{
+				Name: "IMAGES",
+				Value: sourceRegistry + "/com.sap.datahub.linuxx86_64/vsystem:3.2.1 " +
+					targetRegistry + "/com.sap.datahub.linuxx86_64/vsystem:3.2.1",
+			}))
+
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				g.Ω(im.Status.JobName).To(Equal(job.Name))
+				g.Ω(im.Status.Total).To(Equal(1))
+				haveCondition(g, im, "Progressing", metav1.ConditionTrue, "Mirroring")
+				haveCondition(g, im, "Ready", metav1.ConditionFalse, "Mirroring")
+			})
+			err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(policy()), policy())
+			Ω(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should report the failed job", func() {
+			finishJob(mirrorJob(1), batchv1.JobFailed)
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				haveCondition(g, im, "Mirrored", metav1.ConditionFalse, "JobFailed")
+				haveCondition(g, im, "Ready", metav1.ConditionFalse, "JobFailed")
+			})
+			err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(policy()), policy())
+			Ω(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should create the ImageContentSourcePolicy once the job completes", func() {
+			finishJob(mirrorJob(1), batchv1.JobComplete)
+			icsp := policy()
+			waitForObject(icsp)
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				g.Ω(im.Status.ImageContentSourcePolicyName).To(Equal(icsp.Name))
+				haveCondition(g, im, "Mirrored", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected)
+				haveCondition(g, im, "Ready", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected)
+			})
+
+			By("removing the ImageContentSourcePolicy once deleted")
+			Ω(k8sClient.Delete(context.Background(), im)).NotTo(HaveOccurred())
+			waitForDeletion(im)
+			waitForDeletion(icsp)
+		})
+
+		It("Should start a new job for a new generation", func() {
+			formerJob := mirrorJob(1)
+			finishJob(formerJob, batchv1.JobComplete)
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				haveCondition(g, im, "Mirrored", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected)
+			})
+
+			updateMirror(func(im *sdiv1alpha1.SDIImageMirror) {
+				im.Spec.Images = append(im.Spec.Images, "com.sap.datahub.linuxx86_64/vflow")
+			})
+			job := mirrorJob(im.Generation)
+			waitForObject(job)
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				g.Ω(im.Status.ObservedGeneration).To(Equal(im.Generation))
+				g.Ω(im.Status.JobName).To(Equal(job.Name))
+				g.Ω(im.Status.Total).To(Equal(2))
+				haveCondition(g, im, "Mirrored", metav1.ConditionUnknown, "Mirroring")
+			})
+			Eventually(func() bool {
+				err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(formerJob), formerJob)
+				return errors.IsNotFound(err) || formerJob.DeletionTimestamp != nil
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When no target is given", func() {
+		BeforeEach(func() {
+			im.Spec.TargetRegistry = ""
+		})
+
+		It("Should refuse to mirror", func() {
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				haveCondition(g, im, "Mirrored", metav1.ConditionFalse, "InvalidSpec")
+				haveCondition(g, im, "Ready", metav1.ConditionFalse, "InvalidSpec")
+			})
+			job := mirrorJob(1)
+			err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(job), job)
+			Ω(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When the ImageContentSourcePolicy is skipped", func() {
+		It("Should remove the existing ImageContentSourcePolicy", func() {
+			finishJob(mirrorJob(1), batchv1.JobComplete)
+			icsp := policy()
+			waitForObject(icsp)
+
+			updateMirror(func(im *sdiv1alpha1.SDIImageMirror) {
+				im.Spec.SkipImageContentSourcePolicy = true
+			})
+			waitForDeletion(icsp)
+			waitForMirror(im, func(g Gomega, im *sdiv1alpha1.SDIImageMirror) {
+				g.Ω(im.Status.ObservedGeneration).To(Equal(im.Generation))
+				g.Ω(im.Status.ImageContentSourcePolicyName).To(BeEmpty())
+			})
+		})
+	})
+})
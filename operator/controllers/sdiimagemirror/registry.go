@@ -0,0 +1,179 @@
+package sdiimagemirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/htpasswd"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// the objects of the SDIRegistry controller read by the mirroring job
+	registryRawCredentialKey = ".htpasswd.raw"
+	registryServiceCAName    = "container-image-registry-service-ca"
+	registryServiceCAKey     = "service-ca.crt"
+)
+
+// mirrorTarget describes where the mirroring job pushes the images to.
+type mirrorTarget struct {
+	// the registry the images are reported with and redirected to
+	registry string
+	// the registry the job pushes to; the same as registry unless mirroring to an SDIRegistry
+	push string
+	// the secret with the .dockerconfigjson for the source and the push registries
+	authSecretName string
+	// the config map with the CA of the push registry
+	caConfigMapName string
+	caKey           string
+}
+
+func authSecretName(im *sdiv1alpha1.SDIImageMirror) string {
+	return im.Name + "-auth"
+}
+
+// getMirrorTarget resolves the target registry of the spec. Unless the target is ready, the conditions are set
+// and nil is returned together with true if the target is worth polling for.
+func (r *Reconciler) getMirrorTarget(
+	ctx context.Context,
+	im *sdiv1alpha1.SDIImageMirror,
+) (*mirrorTarget, bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	invalid := func(msg string) (*mirrorTarget, bool, error) {
+		setCondition(im, "Progressing", metav1.ConditionFalse, "InvalidSpec", msg)
+		setCondition(im, conditionMirrored, metav1.ConditionFalse, "InvalidSpec", msg)
+		return nil, false, nil
+	}
+	if im.Spec.RegistryRef == nil {
+		if len(im.Spec.TargetRegistry) == 0 {
+			return invalid("either spec.targetRegistry or spec.registryRef must be set")
+		}
+		target := &mirrorTarget{registry: strings.TrimSuffix(im.Spec.TargetRegistry, "/")}
+		target.push = target.registry
+		if im.Spec.AuthSecretRef != nil {
+			target.authSecretName = im.Spec.AuthSecretRef.Name
+		}
+		return target, false, nil
+	}
+	if len(im.Spec.TargetRegistry) > 0 {
+		return invalid("spec.targetRegistry and spec.registryRef are mutually exclusive")
+	}
+
+	waiting := func(msg string) (*mirrorTarget, bool, error) {
+		setCondition(im, "Progressing", metav1.ConditionTrue, "RegistryPending", msg)
+		setCondition(im, conditionMirrored, metav1.ConditionUnknown, "RegistryPending", msg)
+		return nil, true, nil
+	}
+	reg := &sdiv1alpha1.SDIRegistry{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: im.Namespace, Name: im.Spec.RegistryRef.Name}, reg)
+	if errors.IsNotFound(err) {
+		return waiting(fmt.Sprintf("SDIRegistry %s does not exist", im.Spec.RegistryRef.Name))
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !meta.IsStatusConditionTrue(reg.Status.Conditions, "Ready") || len(reg.Status.Hostname) == 0 {
+		return waiting(fmt.Sprintf("SDIRegistry %s is not ready or its route is not admitted", reg.Name))
+	}
+
+	secret := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: reg.Status.HtpasswdSecretName}, secret)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get the htpasswd secret of SDIRegistry %s: %v", reg.Name, err)
+	}
+	c, err := htpasswd.ParseCredential(string(secret.Data[registryRawCredentialKey]))
+	if err != nil {
+		return invalid(fmt.Sprintf("secret %s of SDIRegistry %s has no valid %s key to push with: %v",
+			secret.Name, reg.Name, registryRawCredentialKey, err))
+	}
+	data, err := htpasswd.DockerConfigJSON(c, reg.Status.ServiceAddress)
+	if err != nil {
+		return nil, false, err
+	}
+	if im.Spec.AuthSecretRef != nil {
+		source := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: im.Namespace, Name: im.Spec.AuthSecretRef.Name},
+			source); err != nil {
+			return nil, false, fmt.Errorf("failed to get the auth secret: %v", err)
+		}
+		if data, err = mergeDockerConfigJSON(source.Data[corev1.DockerConfigJsonKey], data); err != nil {
+			return nil, false, fmt.Errorf("invalid %s of secret %s: %v", corev1.DockerConfigJsonKey, source.Name,
+				err)
+		}
+	}
+
+	auth := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: im.Namespace, Name: authSecretName(im)}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, auth, func() error {
+		auth.Type = corev1.SecretTypeDockerConfigJson
+		auth.Data = map[string][]byte{corev1.DockerConfigJsonKey: data}
+		primaryresource.SetManagedBy(auth)
+		return controllerutil.SetControllerReference(im, auth, r.Scheme)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed the auth secret of the mirroring job", "secret", auth.Name, "operation", op)
+	}
+	return &mirrorTarget{
+		registry:        reg.Status.Hostname,
+		push:            reg.Status.ServiceAddress,
+		authSecretName:  auth.Name,
+		caConfigMapName: registryServiceCAName,
+		caKey:           registryServiceCAKey,
+	}, false, nil
+}
+
+// mergeDockerConfigJSON adds the auths of extra to the ones of base.
+func mergeDockerConfigJSON(base, extra []byte) ([]byte, error) {
+	type config struct {
+		Auths map[string]json.RawMessage `json:"auths"`
+	}
+	var merged, add config
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(extra, &add); err != nil {
+		return nil, err
+	}
+	if merged.Auths == nil {
+		merged.Auths = make(map[string]json.RawMessage, len(add.Auths))
+	}
+	for registry, auth := range add.Auths {
+		merged.Auths[registry] = auth
+	}
+	return json.Marshal(merged)
+}
+
+// mapRegistry enqueues the SDIImageMirrors of the namespace waiting for the registry.
+func (r *Reconciler) mapRegistry(reg client.Object) []reconcile.Request {
+	mirrors := &sdiv1alpha1.SDIImageMirrorList{}
+	if err := r.List(context.Background(), mirrors, client.InNamespace(reg.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, im := range mirrors.Items {
+		if im.Spec.RegistryRef != nil && im.Spec.RegistryRef.Name == reg.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: im.Namespace, Name: im.Name},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdimaintenancewindow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+// Reconciler reconciles SDIMaintenanceWindow objects.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// the DataHubs are not cached
+	apiReader client.Reader
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme, apiReader client.Reader) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme, apiReader: apiReader}
+}
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows/finalizers,verbs=update
+
+// Reconcile reports whether the window is open and which resources wait for it. It also stops and starts SDI
+// according to the shutdown windows.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	mw := &sdiv1alpha1.SDIMaintenanceWindow{}
+	if err = r.Get(ctx, req.NamespacedName, mw); err != nil {
+		return rs, client.IgnoreNotFound(err)
+	}
+
+	now := time.Now()
+	res, evalErr := maintenance.Evaluate(&mw.Spec, now)
+	if evalErr != nil {
+		setCondition(mw, "Open", metav1.ConditionUnknown, "InvalidSpec", evalErr.Error())
+		setCondition(mw, "Degraded", metav1.ConditionTrue, "InvalidSpec", evalErr.Error())
+		mw.Status.NextStart, mw.Status.CurrentEnd = nil, nil
+	} else {
+		setCondition(mw, "Degraded", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected, "")
+		switch {
+		case mw.Spec.Suspend:
+			setCondition(mw, "Open", metav1.ConditionTrue, "Suspended",
+				"the maintenance window is suspended, disruptive changes are allowed at any time")
+		case res.Allowed:
+			setCondition(mw, "Open", metav1.ConditionTrue, "InWindow", "disruptive changes are allowed")
+		default:
+			setCondition(mw, "Open", metav1.ConditionFalse, "OutsideWindow", "disruptive changes are deferred")
+		}
+		mw.Status.NextStart = toTime(res.NextStart)
+		mw.Status.CurrentEnd = toTime(res.CurrentEnd)
+		// re-evaluate at the next boundary
+		for _, t := range []time.Time{res.CurrentEnd, res.NextStart} {
+			if d := t.Sub(now) + time.Second; !t.IsZero() && (rs.RequeueAfter == 0 || d < rs.RequeueAfter) {
+				rs.RequeueAfter = d
+			}
+		}
+	}
+
+	var shutdownErr error
+	if evalErr == nil {
+		var next time.Duration
+		if next, shutdownErr = r.manageShutdown(ctx, mw, now); shutdownErr != nil {
+			tracer.Error(shutdownErr, "failed to manage the scheduled shutdown")
+			setCondition(mw, "Degraded", metav1.ConditionTrue, "FailedShutdown", shutdownErr.Error())
+		}
+		if next > 0 && (rs.RequeueAfter == 0 || next < rs.RequeueAfter) {
+			rs.RequeueAfter = next
+		}
+	}
+
+	if mw.Status.PendingChanges, err = r.listPendingChanges(ctx, mw.Namespace); err != nil {
+		tracer.Error(err, "failed to list pending changes")
+	}
+	status := mw.Status.DeepCopy()
+	updErr := updates.Status(ctx, r.Client, mw, func() (bool, error) {
+		mw.Status = *status
+		return true, nil
+	})
+	if updates.IsPermanent(updErr) {
+		// retrying would not help
+		tracer.Error(updErr, "SDIMaintenanceWindow status update has been refused")
+	} else if updErr != nil {
+		tracer.Error(updErr, "failed to update SDIMaintenanceWindow status")
+		return rs, updErr
+	}
+	if err == nil {
+		err = shutdownErr
+	}
+	return rs, err
+}
+
+func toTime(t time.Time) *metav1.Time {
+	if t.IsZero() {
+		return nil
+	}
+	mt := metav1.NewTime(t)
+	return &mt
+}
+
+func setCondition(mw *sdiv1alpha1.SDIMaintenanceWindow, cType string, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&mw.Status.Conditions, metav1.Condition{
+		Type:               cType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: mw.Generation,
+	})
+}
+
+// gatedKinds lists the resources whose disruptive changes are subject to the maintenance windows.
+var gatedKinds = []struct {
+	kind    string
+	newList func() client.ObjectList
+}{
+	{
+		kind:    "SLCBridge",
+		newList: func() client.ObjectList { return &sdiv1alpha1.SLCBridgeList{} },
+	},
+	{
+		kind:    "SDIImageMirror",
+		newList: func() client.ObjectList { return &sdiv1alpha1.SDIImageMirrorList{} },
+	},
+	{
+		kind:    "SDIObserver",
+		newList: func() client.ObjectList { return &sdiv1alpha1.SDIObserverList{} },
+	},
+}
+
+func (r *Reconciler) listPendingChanges(ctx context.Context, namespace string) ([]string, error) {
+	var pending []string
+	for _, gk := range gatedKinds {
+		list := gk.newList()
+		if err := r.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		if err := meta.EachListItem(list, func(obj runtime.Object) error {
+			var conditions []metav1.Condition
+			switch o := obj.(type) {
+			case *sdiv1alpha1.SLCBridge:
+				conditions = o.Status.Conditions
+			case *sdiv1alpha1.SDIImageMirror:
+				conditions = o.Status.Conditions
+			case *sdiv1alpha1.SDIObserver:
+				conditions = o.Status.Conditions
+			}
+			if meta.IsStatusConditionTrue(conditions, sdiv1alpha1.ConditionMaintenancePending) {
+				pending = append(pending, fmt.Sprintf("%s/%s", gk.kind, obj.(client.Object).GetName()))
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// enqueueWindows triggers the reconciliation of all the windows in the namespace of the gated resource.
+func (r *Reconciler) enqueueWindows(obj client.Object) []reconcile.Request {
+	windows := &sdiv1alpha1.SDIMaintenanceWindowList{}
+	if err := r.List(context.Background(), windows, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(windows.Items))
+	for i := range windows.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&windows.Items[i])})
+	}
+	return requests
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&sdiv1alpha1.SDIMaintenanceWindow{}).
+		Watches(&source.Kind{Type: &sdiv1alpha1.SLCBridge{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueWindows)).
+		Watches(&source.Kind{Type: &sdiv1alpha1.SDIImageMirror{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueWindows)).
+		Watches(&source.Kind{Type: &sdiv1alpha1.SDIObserver{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueWindows)).
+		Complete(r)
+}
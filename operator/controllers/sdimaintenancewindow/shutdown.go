@@ -0,0 +1,151 @@
+package sdimaintenancewindow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+)
+
+//+kubebuilder:rbac:groups=installers.datahub.sap.com,resources=datahubs,verbs=get;list;watch;update;patch
+
+var dataHubListGVK = schema.GroupVersionKind{
+	Group:   "installers.datahub.sap.com",
+	Version: "v1alpha1",
+	Kind:    "DataHubList",
+}
+
+// the DataHub field driving the ordered scale-down and scale-up done by the SAP operators
+var dataHubRunLevelField = []string{"spec", "runLevel"}
+
+var runLevels = map[sdiv1alpha1.SDIShutdownAction]string{
+	sdiv1alpha1.SDIShutdownActionStop:  "Stopped",
+	sdiv1alpha1.SDIShutdownActionStart: "Started",
+}
+
+// getShutdownNamespace returns the SDI namespace stopped by the schedule.
+func (r *Reconciler) getShutdownNamespace(ctx context.Context, mw *sdiv1alpha1.SDIMaintenanceWindow) (string, error) {
+	if ns := mw.Spec.Shutdown.Namespace; len(ns) > 0 {
+		return ns, nil
+	}
+	observers := &sdiv1alpha1.SDIObserverList{}
+	if err := r.List(ctx, observers, client.InNamespace(mw.Namespace)); err != nil {
+		return "", err
+	}
+	for _, obs := range observers.Items {
+		if len(obs.Spec.SDINamespace) > 0 {
+			return obs.Spec.SDINamespace, nil
+		}
+	}
+	return "", fmt.Errorf("no SDIObserver in namespace %s determines the SDI namespace, set the shutdown namespace",
+		mw.Namespace)
+}
+
+// getDataHub returns the DataHub instance of the namespace. The instance named default is preferred.
+func (r *Reconciler) getDataHub(ctx context.Context, namespace string) (*unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(dataHubListGVK)
+	if err := r.apiReader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("no DataHub found in namespace %s", namespace)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		a, b := list.Items[i].GetName(), list.Items[j].GetName()
+		if a == "default" || b == "default" {
+			return a == "default"
+		}
+		return a < b
+	})
+	return &list.Items[0], nil
+}
+
+// getShutdownAction returns the run level change due now. A start is due only after a stop done by the
+// schedule, SDI stopped by hand stays stopped. Likewise, SDI started by hand during a shutdown window is left
+// running until the next window.
+func getShutdownAction(
+	sched maintenance.ShutdownSchedule,
+	last *sdiv1alpha1.SDIShutdownActionStatus,
+) (sdiv1alpha1.SDIShutdownAction, bool) {
+	action := sdiv1alpha1.SDIShutdownActionStart
+	if sched.Stopped {
+		action = sdiv1alpha1.SDIShutdownActionStop
+	}
+	if last == nil {
+		return action, action == sdiv1alpha1.SDIShutdownActionStop
+	}
+	return action, last.Action != action
+}
+
+// manageShutdown sets the run level of the DataHub according to the shutdown windows. It returns the delay
+// until the next run level change.
+func (r *Reconciler) manageShutdown(
+	ctx context.Context,
+	mw *sdiv1alpha1.SDIMaintenanceWindow,
+	now time.Time,
+) (time.Duration, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if mw.Spec.Shutdown == nil {
+		mw.Status.Shutdown = nil
+		return 0, nil
+	}
+	if mw.Status.Shutdown == nil {
+		mw.Status.Shutdown = &sdiv1alpha1.SDIMaintenanceWindowShutdownStatus{}
+	}
+	status := mw.Status.Shutdown
+	sched, err := maintenance.EvaluateShutdown(&mw.Spec, now)
+	if err != nil {
+		status.NextAction = nil
+		return 0, err
+	}
+	var requeue time.Duration
+	status.NextAction = nil
+	if len(sched.NextAction) > 0 {
+		status.NextAction = &sdiv1alpha1.SDIShutdownActionStatus{
+			Action: sched.NextAction,
+			Time:   metav1.NewTime(sched.NextTime),
+		}
+		requeue = sched.NextTime.Sub(now) + time.Second
+	}
+
+	action, due := getShutdownAction(sched, status.LastAction)
+	if !due {
+		return requeue, nil
+	}
+	namespace, err := r.getShutdownNamespace(ctx, mw)
+	if err != nil {
+		return requeue, err
+	}
+	dh, err := r.getDataHub(ctx, namespace)
+	if err != nil {
+		return requeue, fmt.Errorf("failed to get the DataHub: %v", err)
+	}
+	status.DataHub = dh.GetNamespace() + "/" + dh.GetName()
+	runLevel, _, _ := unstructured.NestedString(dh.Object, dataHubRunLevelField...)
+	if runLevel != runLevels[action] {
+		orig := dh.DeepCopy()
+		if err := unstructured.SetNestedField(dh.Object, runLevels[action], dataHubRunLevelField...); err != nil {
+			return requeue, err
+		}
+		tracer.Info("changing the run level of SDI", "datahub", status.DataHub, "runLevel", runLevels[action])
+		if err := r.Patch(ctx, dh, client.MergeFrom(orig)); err != nil {
+			return requeue, fmt.Errorf("failed to set the run level of DataHub %s to %s: %v",
+				status.DataHub, runLevels[action], err)
+		}
+	}
+	status.LastAction = &sdiv1alpha1.SDIShutdownActionStatus{Action: action, Time: metav1.NewTime(now)}
+	return requeue, nil
+}
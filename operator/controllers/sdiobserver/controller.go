@@ -19,21 +19,33 @@ package sdiobserver
 import (
 	"context"
 	"sort"
+	"sync"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
 	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiobserver/namespaced"
 	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/ratelimit"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
 	"github.com/redhat-sap/sap-data-intelligence/operator/util/sdiobservers"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/upgradeable"
 )
 
 // Reconciler reconciles all SDIObserver objects in all namespaces.
@@ -54,6 +66,27 @@ type Reconciler struct {
 	// and destroyed dynamicly as SDIObserver instances appear or disappear. No controllers are created for
 	// Backup observer instances.
 	NamespacedControllers map[types.NamespacedName]*namespaced.Controller
+	Recorder              record.EventRecorder
+	// Rate limiting of the retries of both the parent and the namespaced controllers. The controller-runtime
+	// defaults apply if unset.
+	RateLimiter *ratelimit.Options
+	// Resync periods of the informers of the namespaced controllers.
+	SyncTimes namespaced.SyncTimes
+	// The number of the concurrent reconciliations of the parent controller. Only the bookkeeping of the
+	// namespaced controllers is serialized.
+	MaxConcurrentReconciles int
+	// The default number of the concurrent reconciliations of each namespaced controller. SDIObserver's
+	// spec.maxConcurrentReconciles takes precedence.
+	NamespacedMaxConcurrentReconciles int
+	// Watch the DataHub resources across the cluster for the SDIObservers with the discovery Managed.
+	DiscoverDataHubs bool
+	discovery        *dataHubDiscovery
+	// Blocks the operator upgrade during the disruptive actions of the namespaced controllers. Optional.
+	UpgradeGate *upgradeable.Gate
+	// Guards the maps of the namespaced controllers and serializes their bookkeeping with the shutdown. No
+	// controllers are created once shuttingDown is set.
+	mu           sync.Mutex
+	shuttingDown bool
 }
 
 func NewReconciler(
@@ -68,12 +101,15 @@ func NewReconciler(
 		ManagedDHPerObserver:  make(map[types.NamespacedName]string),
 		ActiveObserverForDH:   make(map[string]types.NamespacedName),
 		NamespacedControllers: make(map[types.NamespacedName]*namespaced.Controller),
+		Recorder:              mgr.GetEventRecorderFor("sdi-observer"),
+		SyncTimes:             namespaced.DefaultSyncTimes(),
 	}
 }
 
 //+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiobservers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiobservers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiobservers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=operators.coreos.com,resources=operatorconditions,verbs=get;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -85,68 +121,98 @@ func NewReconciler(
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues(λ.ObjectKey, req.String()))
 	tracer := λ.Enter(log.FromContext(ctx))
 	defer λ.Leave(tracer)
 
-	knownManagedNamespace := r.ManagedDHPerObserver[req.NamespacedName]
-	_, isActive := r.NamespacedControllers[req.NamespacedName]
+	r.mu.Lock()
+	shuttingDown := r.shuttingDown
+	r.mu.Unlock()
+	if shuttingDown {
+		tracer.Info("shutting down, not reconciling")
+		return
+	}
 
 	obs := &sdiv1alpha1.SDIObserver{}
 	if err = r.Get(ctx, req.NamespacedName, obs); err != nil {
 		// TODO: do the same for terminating instances
-		if errors.IsNotFound(err) && isActive {
+		if errors.IsNotFound(err) {
 			// TODO: handle finalizers
-			_, err = r.orphanDH(ctx, knownManagedNamespace)
-			if err != nil {
-				return
-			}
-			var candidate *sdiv1alpha1.SDIObserver
-			candidate, err = r.findNewObsForDH(ctx, knownManagedNamespace, true)
-			if err != nil {
-				return
-			}
-			if candidate == nil {
-				tracer.Info("no known suitable substitute SDIObserver found")
-				return
-			}
-			return ctrl.Result{}, r.manageDataHubs(ctx, candidate, knownManagedNamespace)
+			err = r.releaseDeletedObs(ctx, req.NamespacedName)
 		}
 		return
 	}
 
-	sdiNamespace := obs.Spec.SDINamespace
-	if len(sdiNamespace) == 0 {
-		sdiNamespace = obs.Namespace
+	if isDiscoveryTemplate(obs) {
+		return r.reconcileDiscovery(ctx, obs)
+	}
+	if reDiscoveryRemoved.MatchString(obs.Spec.Discovery.ManagementState) {
+		if err = r.removeDiscovered(ctx, obs); err != nil {
+			return
+		}
 	}
 
-	if nm, ok := r.ManagedDHPerObserver[client.ObjectKeyFromObject(obs)]; !ok {
+	sdiNamespace := getSDINamespace(obs)
+
+	r.mu.Lock()
+	if nm, ok := r.ManagedDHPerObserver[req.NamespacedName]; !ok {
 		tracer.Info("recording new Observer instance")
-		r.ManagedDHPerObserver[client.ObjectKeyFromObject(obs)] = sdiNamespace
+		r.ManagedDHPerObserver[req.NamespacedName] = sdiNamespace
 	} else if nm != sdiNamespace {
 		tracer.Info("managed DH namespace change", "original", nm, "new", sdiNamespace)
+		r.Recorder.Eventf(obs, corev1.EventTypeNormal, "NamespaceChanged",
+			"the managed SDI namespace changed from %s to %s", nm, sdiNamespace)
 		_, err = r.orphanDH(ctx, nm)
-		r.ManagedDHPerObserver[client.ObjectKeyFromObject(obs)] = sdiNamespace
+		r.ManagedDHPerObserver[req.NamespacedName] = sdiNamespace
 	}
+	r.mu.Unlock()
 
-	managingObs, ok := r.ActiveObserverForDH[sdiNamespace]
-	if ok && managingObs.Namespace == obs.Namespace && managingObs.Name == obs.Name {
-		if dhCtrl, ok := r.NamespacedControllers[req.NamespacedName]; ok {
-			err = sdiobservers.SetBackupAndUpdate(ctx, r.Client, obs, false, managingObs)
-			dhCtrl.ReconcileObs(obs)
-			return
-		}
-		err = r.manageDataHubs(ctx, obs, sdiNamespace)
+	gone, err := r.isNamespaceGone(ctx, sdiNamespace)
+	if err != nil {
 		return
 	}
-	if ok {
-		return rs, sdiobservers.SetBackupAndUpdate(ctx, r.Client, obs, true, managingObs)
+	if gone {
+		err = r.releaseNamespace(ctx, obs, sdiNamespace)
+		return
 	}
 
 	return rs, r.manageDataHubs(ctx, obs, sdiNamespace)
 }
 
+// releaseDeletedObs stops the controller of the deleted SDIObserver and hands its SDI namespace over to the most
+// suitable tracked SDIObserver.
+func (r *Reconciler) releaseDeletedObs(ctx context.Context, obsNMName types.NamespacedName) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	r.mu.Lock()
+	sdiNamespace := r.ManagedDHPerObserver[obsNMName]
+	_, isActive := r.NamespacedControllers[obsNMName]
+	var err error
+	if isActive {
+		_, err = r.orphanDH(ctx, sdiNamespace)
+	} else {
+		delete(r.ManagedDHPerObserver, obsNMName)
+	}
+	r.mu.Unlock()
+	if err != nil || !isActive {
+		return err
+	}
+
+	candidate, err := r.findNewObsForDH(ctx, sdiNamespace, true)
+	if err != nil {
+		return err
+	}
+	if candidate == nil {
+		tracer.Info("no known suitable substitute SDIObserver found")
+		return nil
+	}
+	return r.manageDataHubs(ctx, candidate, sdiNamespace)
+}
+
+// orphanDH stops the controller managing the SDI namespace. It must be called with r.mu held.
 func (r *Reconciler) orphanDH(ctx context.Context, dhNamespace string) (changed bool, err error) {
-	defer λ.Leave(λ.Enter(log.FromContext(ctx), "dhNamespace", dhNamespace))
+	defer λ.Leave(λ.Enter(log.FromContext(ctx), λ.SDINamespaceKey, dhNamespace))
 	obsNMName, ok := r.ActiveObserverForDH[dhNamespace]
 	if !ok {
 		return false, nil
@@ -157,17 +223,21 @@ func (r *Reconciler) orphanDH(ctx context.Context, dhNamespace string) (changed
 		return true, nil
 	}
 	r.destroyController(ctx, obsNMName)
+	// nobody follows the rollout anymore
+	r.UpgradeGate.Release(ctx, namespaced.VRepRolloutAction(dhNamespace))
 	changed = true
 	return
 }
 
 func (r *Reconciler) unblockObs(ctx context.Context, obs *sdiv1alpha1.SDIObserver) (update bool) {
-	defer λ.Leave(λ.Enter(log.FromContext(ctx), "namespace", obs.Namespace, "name", obs.Name))
+	defer λ.Leave(λ.Enter(log.FromContext(ctx), λ.ObjectKey, client.ObjectKeyFromObject(obs).String()))
 	return sdiobservers.SetBackup(ctx, r.Client, obs, false, client.ObjectKeyFromObject(obs))
 }
 
+// destroyController stops the controller of the SDIObserver and forgets its SDI namespace. It must be called with
+// r.mu held.
 func (r *Reconciler) destroyController(ctx context.Context, obsNMName types.NamespacedName) {
-	defer λ.Leave(λ.Enter(log.FromContext(ctx), "observer namespace/name", obsNMName.String()))
+	defer λ.Leave(λ.Enter(log.FromContext(ctx), λ.ObjectKey, obsNMName.String()))
 	dhctrl, ok := r.NamespacedControllers[obsNMName]
 	if !ok {
 		return
@@ -266,9 +336,9 @@ func (r *Reconciler) findNewObsForDH(
 		items:       obss.Items,
 	})
 	for _, obs := range obss.Items {
-		if obs.DeletionTimestamp == nil && (obs.Spec.SDINamespace == dhNamespace ||
+		if obs.DeletionTimestamp == nil && !isDiscoveryTemplate(&obs) && (obs.Spec.SDINamespace == dhNamespace ||
 			(len(obs.Spec.SDINamespace) == 0 && obs.Namespace == dhNamespace)) {
-			if _, ok := r.ManagedDHPerObserver[client.ObjectKeyFromObject(&obs)]; !onlyTracked || ok {
+			if !onlyTracked || r.isTracked(client.ObjectKeyFromObject(&obs)) {
 				return &obs, nil
 			}
 		} else if !onlyTracked {
@@ -279,64 +349,211 @@ func (r *Reconciler) findNewObsForDH(
 	return nil, nil
 }
 
+// isTracked returns true if the SDIObserver has been reconciled by this reconciler.
+func (r *Reconciler) isTracked(obsNMName types.NamespacedName) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.ManagedDHPerObserver[obsNMName]
+	return ok
+}
+
+// manageDataHubs makes the SDIObserver manage the SDI namespace unless another SDIObserver already does. Only the
+// bookkeeping of the namespaced controllers is done with r.mu held; the status updates happen without it.
 func (r *Reconciler) manageDataHubs(
 	ctx context.Context,
 	obs *sdiv1alpha1.SDIObserver,
 	sdiNamespace string,
 ) error {
-	tracer := λ.Enter(log.FromContext(ctx), "SDI namespace", sdiNamespace)
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues(λ.SDINamespaceKey, sdiNamespace))
+	tracer := λ.Enter(log.FromContext(ctx))
 	defer λ.Leave(tracer)
 
 	obsNMName := client.ObjectKeyFromObject(obs)
-	if _, ok := r.NamespacedControllers[obsNMName]; ok {
-		// already managed
+	r.mu.Lock()
+	if r.shuttingDown {
+		r.mu.Unlock()
+		tracer.Info("shutting down, not managing the SDI instance")
 		return nil
 	}
+	managingObs, dhCtrl, started, err := r.startController(ctx, obs, sdiNamespace)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if managingObs != obsNMName {
+		if !sdiobservers.IsBackup(obs) {
+			r.Recorder.Eventf(obs, corev1.EventTypeNormal, "Backup",
+				"the SDI namespace %s is already managed by %s", sdiNamespace, managingObs.String())
+		}
+		return sdiobservers.SetBackupAndUpdate(ctx, r.Client, obs, true, managingObs)
+	}
+	if !started {
+		err = sdiobservers.SetBackupAndUpdate(ctx, r.Client, obs, false, managingObs)
+		dhCtrl.ReconcileObs(obs)
+		return err
+	}
+
+	tracer.Info("starting the management of SDI instance", "SDI namespace", sdiNamespace)
+	// the backup condition lives in the status
+	err = updates.Status(ctx, r.Client, obs, func() (bool, error) { return r.unblockObs(ctx, obs), nil })
+	if err != nil {
+		tracer.Error(err, "failed to update the SDIObserver", "SDIObserver instance", obsNMName.String())
+	}
+	dhCtrl.ReconcileObs(&sdiv1alpha1.SDIObserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: obsNMName.Namespace,
+			Name:      obsNMName.Name,
+		},
+	})
+	return err
+}
+
+// startController starts the namespaced controller of the SDIObserver unless another SDIObserver manages the SDI
+// namespace already. The running controller is restarted when its concurrency or its watched secret namespaces change. It returns the managing
+// SDIObserver, the controller of the SDIObserver if it is the managing one and whether the controller has just
+// been started. It must be called with r.mu held.
+func (r *Reconciler) startController(
+	ctx context.Context,
+	obs *sdiv1alpha1.SDIObserver,
+	sdiNamespace string,
+) (managingObs types.NamespacedName, dhCtrl *namespaced.Controller, started bool, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	obsNMName := client.ObjectKeyFromObject(obs)
+	if active, ok := r.ActiveObserverForDH[sdiNamespace]; ok && active != obsNMName {
+		return active, nil, false, nil
+	}
+	concurrency := r.namespacedConcurrency(obs)
+	secretNamespaces := namespaced.WatchedSecretNamespaces(obs, sdiNamespace)
+	if dhCtrl, ok := r.NamespacedControllers[obsNMName]; ok {
+		switch {
+		case dhCtrl.MaxConcurrentReconciles() != concurrency:
+			tracer.Info("restarting the controller with the new concurrency",
+				"original", dhCtrl.MaxConcurrentReconciles(), "new", concurrency)
+		case !dhCtrl.WatchesSecretNamespaces(secretNamespaces):
+			tracer.Info("restarting the controller with the new secret namespaces", "new", secretNamespaces)
+		default:
+			// already managed
+			return obsNMName, dhCtrl, false, nil
+		}
+		r.destroyController(ctx, obsNMName)
+	}
 	tracer.Info("creating the controller for SAP Data Intelligence instance", "SDI namespace", sdiNamespace)
 
-	ctrl, err := namespaced.NewController(
+	dhCtrl, err = namespaced.NewController(
 		r.Client,
 		r.Scheme,
 		obsNMName,
 		sdiNamespace,
+		secretNamespaces,
 		r.Mgr,
-		controller.Options{})
+		controller.Options{
+			RateLimiter:             r.newRateLimiter(),
+			MaxConcurrentReconciles: concurrency,
+		},
+		r.SyncTimes,
+		r.UpgradeGate)
 	if err != nil {
-		return err
+		r.Recorder.Eventf(obs, corev1.EventTypeWarning, "FailedManage",
+			"failed to create the controller for the SDI namespace %s: %v", sdiNamespace, err)
+		return
 	}
 
-	err = ctrl.Start(ctx)
+	err = dhCtrl.Start(ctx)
 	if err != nil {
 		tracer.Error(err, "controller of SDI instance", "SDI namespace", sdiNamespace)
-		return err
+		r.Recorder.Eventf(obs, corev1.EventTypeWarning, "FailedManage",
+			"failed to start the controller for the SDI namespace %s: %v", sdiNamespace, err)
+		return
 	}
 	tracer.Info("started the controller")
+	r.Recorder.Eventf(obs, corev1.EventTypeNormal, "Managing",
+		"managing SAP Data Intelligence in namespace %s", sdiNamespace)
 
 	r.ActiveObserverForDH[sdiNamespace] = obsNMName
 	r.ManagedDHPerObserver[obsNMName] = sdiNamespace
-	r.NamespacedControllers[obsNMName] = ctrl
-	tracer.Info("starting the management of SDI instance", "SDI namespace", sdiNamespace)
-	if err != nil {
-		tracer.Error(err, "controller of SDI instance", "SDI namespace", sdiNamespace)
-		return err
+	r.NamespacedControllers[obsNMName] = dhCtrl
+	return obsNMName, dhCtrl, true, nil
+}
+
+// namespacedConcurrency returns the number of the concurrent reconciliations of the namespaced controller
+// managed by the SDIObserver.
+func (r *Reconciler) namespacedConcurrency(obs *sdiv1alpha1.SDIObserver) int {
+	if obs.Spec.MaxConcurrentReconciles != nil && *obs.Spec.MaxConcurrentReconciles > 0 {
+		return int(*obs.Spec.MaxConcurrentReconciles)
 	}
+	if r.NamespacedMaxConcurrentReconciles > 0 {
+		return r.NamespacedMaxConcurrentReconciles
+	}
+	return 1
+}
 
-	if r.unblockObs(ctx, obs) {
-		err = r.Update(ctx, obs)
-		if err != nil {
-			tracer.Error(err, "failed to update the SDIObserver", "SDIObserver instance", obsNMName.String())
-		}
+// newRateLimiter returns a rate limiter for a new controller or nil for the default one.
+func (r *Reconciler) newRateLimiter() workqueue.RateLimiter {
+	if r.RateLimiter == nil {
+		return nil
 	}
-	ctrl.ReconcileObs(&sdiv1alpha1.SDIObserver{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: obsNMName.Namespace,
-			Name:      obsNMName.Name,
-		},
-	})
-	return err
+	return r.RateLimiter.New()
+}
+
+// stopControllers stops all the namespaced controllers in parallel once the context is done. No new
+// controllers are created afterwards.
+func (r *Reconciler) stopControllers(ctx context.Context) error {
+	<-ctx.Done()
+	tracer := λ.Enter(r.Mgr.GetLogger().WithValues(λ.ComponentKey, "sdiobserver"))
+	defer λ.Leave(tracer)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shuttingDown = true
+	var wg sync.WaitGroup
+	for nmName, dhctrl := range r.NamespacedControllers {
+		tracer.Info("stopping namespaced controller", λ.ObjectKey, nmName.String())
+		wg.Add(1)
+		go func(dhctrl *namespaced.Controller) {
+			defer wg.Done()
+			dhctrl.Stop()
+		}(dhctrl)
+	}
+	wg.Wait()
+	return nil
 }
 
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// drain the namespaced controllers on the shutdown of the manager
+	if err := mgr.Add(manager.RunnableFunc(r.stopControllers)); err != nil {
+		return err
+	}
 	var obs = &sdiv1alpha1.SDIObserver{}
-	return ctrl.NewControllerManagedBy(mgr).For(obs).Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(obs).
+		// the SDIObservers of the discovered namespaces
+		Owns(&sdiv1alpha1.SDIObserver{})
+	if rbacscope.Namespaced() {
+		mgr.GetLogger().Info("the deletion of the SDI namespaces is not watched in the namespace-scoped RBAC mode")
+	} else {
+		// stop the namespaced controllers of the deleted SDI namespaces
+		b = b.Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.mapNamespace),
+			builder.WithPredicates(namespaceLifecyclePredicate))
+	}
+	if r.DiscoverDataHubs {
+		discovery, err := newDataHubDiscovery(mgr, r.SyncTimes.DataHub)
+		if err != nil {
+			return err
+		}
+		if err := mgr.Add(discovery); err != nil {
+			return err
+		}
+		r.discovery = discovery
+		b = b.Watches(&source.Channel{Source: discovery.events}, &handler.EnqueueRequestForObject{})
+	}
+	return b.
+		WithOptions(controller.Options{
+			RateLimiter:             r.newRateLimiter(),
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+		}).
+		WithLogger(mgr.GetLogger().WithValues(λ.ComponentKey, "sdiobserver")).
+		Complete(r)
 }
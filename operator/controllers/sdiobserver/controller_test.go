@@ -54,6 +54,31 @@ var _ = Describe("SDIObserver controller", func() {
 		})
 	})
 
+	Context("When the vsystem route requests a hostname with the nodePort exposure", func() {
+		It("Should be rejected by the API server", func() {
+			invalid := obs.DeepCopy()
+			invalid.ResourceVersion = ""
+			invalid.Name = "sdi-invalid"
+			invalid.Spec.VSystemRoute.Hostname = "vsystem.apps.example.com"
+			invalid.Spec.VSystemRoute.Exposure = sdiv1alpha1.RouteExposureNodePort
+			err := k8sClient.Create(context.Background(), invalid)
+			Ω(errors.IsInvalid(err)).To(BeTrue())
+			Ω(err.Error()).To(ContainSubstring("hostname requires the route or serviceMesh exposure"))
+		})
+	})
+
+	Context("When the SDI namespace does not exist", func() {
+		It("Should report the missing namespace", func() {
+			ωbs.Update(k8sClient, obs, func(obs *sdiv1alpha1.SDIObserver) {
+				obs.Spec.SDINamespace = "sdi-missing"
+			})
+			ωbs.WaitForObserver(k8sClient, obs,
+				ωbs.HaveConditionReason("Ready", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonNamespaceNotFound),
+				ωbs.HaveConditionReason("Degraded", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonNamespaceNotFound),
+				ωbs.ReferenceDataHub(nil))
+		})
+	})
+
 	Context("When a DH instance is removed", func() {
 		BeforeEach(func() {
 			dh := dhv1alpha1.GetSampleDH("sdi")
@@ -0,0 +1,247 @@
+package sdiobserver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiobserver/namespaced"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+// discoveredByLabelKey marks the SDIObservers created for the discovered namespaces with the name of the
+// template.
+const discoveredByLabelKey = "di.sap-cop.redhat.com/discovered-by"
+
+// how long to wait for the initial listing of the DataHub resources
+const discoveryRetryDelay = time.Second * 5
+
+var (
+	reDiscoveryManaged = regexp.MustCompile(`^(?i)managed$`)
+	reDiscoveryRemoved = regexp.MustCompile(`^(?i)removed?$`)
+)
+
+// isDiscoveryTemplate returns true if the SDIObserver only stamps out copies for the discovered namespaces.
+func isDiscoveryTemplate(obs *sdiv1alpha1.SDIObserver) bool {
+	return reDiscoveryManaged.MatchString(obs.Spec.Discovery.ManagementState)
+}
+
+// dataHubDiscovery watches the metadata of the DataHub resources across the cluster and notifies the parent
+// controller about the discovery templates whenever a DataHub appears or disappears.
+type dataHubDiscovery struct {
+	client   client.Client
+	informer cache.SharedIndexInformer
+	events   chan event.GenericEvent
+	ctx      context.Context
+}
+
+func newDataHubDiscovery(mgr ctrl.Manager, resync time.Duration) (*dataHubDiscovery, error) {
+	metadataClient, err := metadata.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	factory := metadatainformer.NewSharedInformerFactory(metadataClient, resync)
+	d := &dataHubDiscovery{
+		client:   mgr.GetClient(),
+		informer: factory.ForResource(namespaced.MakeDataHubGVR()).Informer(),
+		events:   make(chan event.GenericEvent),
+	}
+	d.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { d.notifyTemplates() },
+		DeleteFunc: func(interface{}) { d.notifyTemplates() },
+	})
+	return d, nil
+}
+
+// Start runs the informer until the context is done.
+func (d *dataHubDiscovery) Start(ctx context.Context) error {
+	d.ctx = ctx
+	d.informer.Run(ctx.Done())
+	return nil
+}
+
+// notifyTemplates enqueues all the discovery templates.
+func (d *dataHubDiscovery) notifyTemplates() {
+	tracer := λ.Enter(log.FromContext(d.ctx).WithValues(λ.ComponentKey, "datahub-discovery"))
+	defer λ.Leave(tracer)
+
+	var obss sdiv1alpha1.SDIObserverList
+	if err := d.client.List(d.ctx, &obss); err != nil {
+		tracer.Error(err, "failed to list SDIObservers")
+		return
+	}
+	for i := range obss.Items {
+		if !isDiscoveryTemplate(&obss.Items[i]) {
+			continue
+		}
+		select {
+		case d.events <- event.GenericEvent{Object: &obss.Items[i]}:
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
+
+// namespaces returns the sorted namespaces with a DataHub resource. It returns false until the initial
+// listing completes.
+func (d *dataHubDiscovery) namespaces() ([]string, bool) {
+	if !d.informer.HasSynced() {
+		return nil, false
+	}
+	set := make(map[string]struct{})
+	for _, key := range d.informer.GetStore().ListKeys() {
+		namespace, _, err := cache.SplitMetaNamespaceKey(key)
+		if err == nil && len(namespace) > 0 {
+			set[namespace] = struct{}{}
+		}
+	}
+	namespaces := make([]string, 0, len(set))
+	for namespace := range set {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces, true
+}
+
+func discoveredObsName(template *sdiv1alpha1.SDIObserver, namespace string) string {
+	return template.Name + "-" + namespace
+}
+
+// listDiscovered returns the SDIObservers created for the template.
+func (r *Reconciler) listDiscovered(ctx context.Context, template *sdiv1alpha1.SDIObserver) (
+	[]sdiv1alpha1.SDIObserver,
+	error,
+) {
+	var obss sdiv1alpha1.SDIObserverList
+	if err := r.List(ctx, &obss, client.InNamespace(template.Namespace),
+		client.MatchingLabels{discoveredByLabelKey: template.Name}); err != nil {
+		return nil, err
+	}
+	var discovered []sdiv1alpha1.SDIObserver
+	for _, obs := range obss.Items {
+		if metav1.IsControlledBy(&obs, template) {
+			discovered = append(discovered, obs)
+		}
+	}
+	return discovered, nil
+}
+
+// removeDiscovered deletes the SDIObservers created for the template.
+func (r *Reconciler) removeDiscovered(ctx context.Context, template *sdiv1alpha1.SDIObserver) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	discovered, err := r.listDiscovered(ctx, template)
+	if err != nil {
+		return err
+	}
+	for i := range discovered {
+		tracer.Info("deleting discovered SDIObserver", "name", discovered[i].Name)
+		if err := r.Delete(ctx, &discovered[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return updates.Status(ctx, r.Client, template, func() (bool, error) {
+		changed := len(template.Status.DiscoveredNamespaces) > 0
+		template.Status.DiscoveredNamespaces = nil
+		return changed, nil
+	})
+}
+
+// reconcileDiscovery maintains a copy of the template for each namespace with a DataHub resource.
+func (r *Reconciler) reconcileDiscovery(ctx context.Context, template *sdiv1alpha1.SDIObserver) (
+	ctrl.Result,
+	error,
+) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	// a template does not manage any namespace on its own
+	key := client.ObjectKeyFromObject(template)
+	r.mu.Lock()
+	if _, ok := r.NamespacedControllers[key]; ok {
+		r.destroyController(ctx, key)
+	}
+	delete(r.ManagedDHPerObserver, key)
+	r.mu.Unlock()
+
+	if r.discovery == nil {
+		r.Recorder.Event(template, corev1.EventTypeWarning, "DiscoveryDisabled",
+			"the operator does not discover the DataHub resources, enable it with --discover-datahubs")
+		return ctrl.Result{}, nil
+	}
+	namespaces, synced := r.discovery.namespaces()
+	if !synced {
+		tracer.V(1).Info("waiting for the DataHub resources to be listed")
+		return ctrl.Result{RequeueAfter: discoveryRetryDelay}, nil
+	}
+
+	wanted := make(map[string]struct{}, len(namespaces))
+	for _, namespace := range namespaces {
+		wanted[namespace] = struct{}{}
+		obs := &sdiv1alpha1.SDIObserver{ObjectMeta: metav1.ObjectMeta{
+			Namespace: template.Namespace,
+			Name:      discoveredObsName(template, namespace),
+		}}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, obs, func() error {
+			if obs.Labels == nil {
+				obs.Labels = make(map[string]string)
+			}
+			obs.Labels[discoveredByLabelKey] = template.Name
+			obs.Spec = *template.Spec.DeepCopy()
+			obs.Spec.SDINamespace = namespace
+			obs.Spec.Discovery = sdiv1alpha1.SDIObserverSpecDiscovery{ManagementState: "Unmanaged"}
+			return controllerutil.SetControllerReference(template, obs, r.Scheme)
+		})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to manage the SDIObserver for namespace %s: %v", namespace, err)
+		}
+		if op == controllerutil.OperationResultCreated {
+			r.Recorder.Eventf(template, corev1.EventTypeNormal, "Discovered",
+				"discovered a DataHub in namespace %s, created SDIObserver %s", namespace, obs.Name)
+		}
+	}
+
+	discovered, err := r.listDiscovered(ctx, template)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range discovered {
+		obs := &discovered[i]
+		if _, ok := wanted[obs.Spec.SDINamespace]; ok && obs.Name == discoveredObsName(template, obs.Spec.SDINamespace) {
+			continue
+		}
+		tracer.Info("deleting SDIObserver of a namespace without DataHub", "name", obs.Name,
+			λ.SDINamespaceKey, obs.Spec.SDINamespace)
+		if err := r.Delete(ctx, obs); client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Eventf(template, corev1.EventTypeNormal, "Undiscovered",
+			"the DataHub in namespace %s disappeared, deleted SDIObserver %s", obs.Spec.SDINamespace, obs.Name)
+	}
+
+	return ctrl.Result{}, updates.Status(ctx, r.Client, template, func() (bool, error) {
+		if reflect.DeepEqual(template.Status.DiscoveredNamespaces, namespaces) ||
+			(len(namespaces) == 0 && len(template.Status.DiscoveredNamespaces) == 0) {
+			return false, nil
+		}
+		template.Status.DiscoveredNamespaces = namespaces
+		return true, nil
+	})
+}
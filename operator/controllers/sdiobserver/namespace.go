@@ -0,0 +1,155 @@
+package sdiobserver
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/sdiobservers"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// getSDINamespace returns the SDI namespace managed by the SDIObserver. It defaults to the namespace of the
+// SDIObserver.
+func getSDINamespace(obs *sdiv1alpha1.SDIObserver) string {
+	if len(obs.Spec.SDINamespace) > 0 {
+		return obs.Spec.SDINamespace
+	}
+	return obs.Namespace
+}
+
+// isNamespaceGone returns true if the namespace does not exist or is being deleted. The namespaces cannot be
+// read in the namespace-scoped RBAC mode and are assumed to exist.
+func (r *Reconciler) isNamespaceGone(ctx context.Context, name string) (bool, error) {
+	if rbacscope.Namespaced() {
+		return false, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return ns.DeletionTimestamp != nil, nil
+}
+
+// releaseNamespace stops the namespaced controller of the SDIObserver managing the deleted SDI namespace and
+// clears the status it maintained. The management resumes once the namespace is created again.
+func (r *Reconciler) releaseNamespace(ctx context.Context, obs *sdiv1alpha1.SDIObserver, sdiNamespace string) error {
+	tracer := λ.Enter(log.FromContext(ctx), λ.SDINamespaceKey, sdiNamespace)
+	defer λ.Leave(tracer)
+
+	obsNMName := client.ObjectKeyFromObject(obs)
+	r.mu.Lock()
+	managingObs, ok := r.ActiveObserverForDH[sdiNamespace]
+	stop := ok && managingObs == obsNMName
+	var err error
+	if stop {
+		tracer.Info("stopping the controller of the deleted SDI namespace")
+		_, err = r.orphanDH(ctx, sdiNamespace)
+	}
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if stop {
+		r.Recorder.Eventf(obs, corev1.EventTypeNormal, "NamespaceDeleted",
+			"stopped managing the deleted SDI namespace %s", sdiNamespace)
+	}
+
+	return updates.Status(ctx, r.Client, obs, func() (bool, error) {
+		orig := obs.Status.DeepCopy()
+		clearNamespacedStatus(obs, sdiNamespace)
+		return !equality.Semantic.DeepEqual(orig, &obs.Status), nil
+	})
+}
+
+// clearNamespacedStatus removes the status entries maintained by the namespaced controller and reports the
+// missing namespace in the conditions.
+func clearNamespacedStatus(obs *sdiv1alpha1.SDIObserver, sdiNamespace string) {
+	status := &obs.Status
+	status.ManagedDataHubRef = nil
+	status.DataHub = nil
+	status.VSystemHealth = nil
+	status.SCCReport = nil
+	status.Permissions = nil
+	status.ServiceAccountTokens = nil
+	status.VSystemRoute = sdiv1alpha1.SDIObserverRouteStatus{}
+	status.SLCBRoute = sdiv1alpha1.SDIObserverRouteStatus{}
+	status.DiagnosticsGrafanaRoute = nil
+	status.DiagnosticsKibanaRoute = nil
+	status.VoraToolsRoute = nil
+	status.VSystemURL = ""
+	status.SLCBURL = ""
+	status.RegistryURL = ""
+
+	for _, condType := range []string{
+		"Backup",
+		"MaintenancePending",
+		sdiv1alpha1.ConditionDataHubReady,
+		sdiv1alpha1.ConditionDuplicateDataHubs,
+		sdiv1alpha1.ConditionVersionSkewDetected,
+	} {
+		meta.RemoveStatusCondition(&status.Conditions, condType)
+	}
+	msg := fmt.Sprintf("the SDI namespace %s does not exist", sdiNamespace)
+	for _, c := range []struct {
+		condType string
+		status   metav1.ConditionStatus
+	}{
+		{"Ready", metav1.ConditionFalse},
+		{"Progressing", metav1.ConditionFalse},
+		{"Degraded", metav1.ConditionTrue},
+	} {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               c.condType,
+			Status:             c.status,
+			Reason:             sdiv1alpha1.ConditionReasonNamespaceNotFound,
+			Message:            msg,
+			ObservedGeneration: obs.Generation,
+		})
+	}
+	sdiobservers.SetHealth(obs)
+}
+
+// mapNamespace enqueues the SDIObservers managing the namespace.
+func (r *Reconciler) mapNamespace(ns client.Object) []reconcile.Request {
+	observers := &sdiv1alpha1.SDIObserverList{}
+	if err := r.List(context.Background(), observers); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range observers.Items {
+		obs := &observers.Items[i]
+		if isDiscoveryTemplate(obs) || getSDINamespace(obs) != ns.GetName() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obs)})
+	}
+	return requests
+}
+
+// namespaceLifecyclePredicate passes the creation, the deletion and the start of the termination of namespaces.
+var namespaceLifecyclePredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return (e.ObjectOld.GetDeletionTimestamp() == nil) != (e.ObjectNew.GetDeletionTimestamp() == nil)
+	},
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
@@ -0,0 +1,188 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	defaultCertificateExpiryWarning  = time.Hour * 24 * 30
+	defaultCertificateExpiryCritical = time.Hour * 24 * 7
+	defaultRouteUnreachableFor       = time.Minute * 10
+	defaultDataHubDegradedFor        = time.Minute * 15
+	defaultFluentdPatchDriftFor      = time.Minute * 30
+)
+
+var prometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PrometheusRule",
+}
+
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+
+func prometheusRuleName(obs *sdiv1alpha1.SDIObserver) string {
+	return obs.Name + "-alerts"
+}
+
+func durationOrDefault(d *metav1.Duration, defaultValue time.Duration) time.Duration {
+	if d == nil || d.Duration <= 0 {
+		return defaultValue
+	}
+	return d.Duration
+}
+
+// promDuration formats the duration in a way understood by Prometheus.
+func promDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d.Seconds()))
+}
+
+func makeAlertRules(obs *sdiv1alpha1.SDIObserver, namespace string) []interface{} {
+	spec := obs.Spec.Alerts
+	selector := fmt.Sprintf(`{%s=%q}`, sdiNamespaceLabel, namespace)
+	expiry := metricsNamespace + "_vsystem_route_certificate_expiry_timestamp_seconds" + selector + " - time()"
+	servingExpiry := metricsNamespace + "_route_serving_certificate_expiry_timestamp_seconds" + selector +
+		" - time()"
+	caSecretName, _ := getDestinationCASecretRef(obs)
+	caExpiry := metricsNamespace + "_destination_ca_certificate_expiry_timestamp_seconds" + selector + " - time()"
+	warning := durationOrDefault(spec.CertificateExpiryWarning, defaultCertificateExpiryWarning)
+	critical := durationOrDefault(spec.CertificateExpiryCritical, defaultCertificateExpiryCritical)
+
+	rule := func(name, severity, expr string, forDuration time.Duration, summary, description string) interface{} {
+		r := map[string]interface{}{
+			"alert": name,
+			"expr":  expr,
+			"labels": map[string]interface{}{
+				"severity":        severity,
+				sdiNamespaceLabel: namespace,
+			},
+			"annotations": map[string]interface{}{
+				"summary":     summary,
+				"description": description,
+			},
+		}
+		if forDuration > 0 {
+			r["for"] = promDuration(forDuration)
+		}
+		return r
+	}
+
+	return []interface{}{
+		rule("SDIVSystemRouteCertificateExpiring", "warning",
+			fmt.Sprintf("%s < %d", expiry, int64(warning.Seconds())), 0,
+			"The vsystem route certificate expires soon.",
+			fmt.Sprintf("The certificate of the vsystem route in namespace %s expires in less than %s.",
+				namespace, warning)),
+		rule("SDIVSystemRouteCertificateExpiring", "critical",
+			fmt.Sprintf("%s < %d", expiry, int64(critical.Seconds())), 0,
+			"The vsystem route certificate is about to expire.",
+			fmt.Sprintf("The certificate of the vsystem route in namespace %s expires in less than %s.",
+				namespace, critical)),
+		rule("SDIRouteServingCertificateExpiring", "warning",
+			fmt.Sprintf("%s < %d", servingExpiry, int64(warning.Seconds())), 0,
+			"A route serving certificate expires soon.",
+			fmt.Sprintf("The serving certificate presented by the {{ $labels.%s }} route of the SDI namespace %s"+
+				" expires in less than %s.", routeLabel, namespace, warning)),
+		rule("SDIRouteServingCertificateExpiring", "critical",
+			fmt.Sprintf("%s < %d", servingExpiry, int64(critical.Seconds())), 0,
+			"A route serving certificate is about to expire.",
+			fmt.Sprintf("The serving certificate presented by the {{ $labels.%s }} route of the SDI namespace %s"+
+				" expires in less than %s.", routeLabel, namespace, critical)),
+		rule("SDIDestinationCACertificateExpiring", "warning",
+			fmt.Sprintf("%s < %d", caExpiry, int64(warning.Seconds())), 0,
+			"The vsystem CA bundle expires soon.",
+			fmt.Sprintf("The CA bundle secret %s in namespace %s used as the destination CA of the vsystem route"+
+				" expires in less than %s.", caSecretName, namespace, warning)),
+		rule("SDIDestinationCACertificateExpiring", "critical",
+			fmt.Sprintf("%s < %d", caExpiry, int64(critical.Seconds())), 0,
+			"The vsystem CA bundle is about to expire.",
+			fmt.Sprintf("The CA bundle secret %s in namespace %s used as the destination CA of the vsystem route"+
+				" expires in less than %s.", caSecretName, namespace, critical)),
+		rule("SDIVSystemRouteUnreachable", "warning",
+			metricsNamespace+"_vsystem_route_exposed"+selector+" == 0",
+			durationOrDefault(spec.RouteUnreachableFor, defaultRouteUnreachableFor),
+			"The vsystem route is not exposed.",
+			fmt.Sprintf("The vsystem route in namespace %s has not been admitted by the ingress.", namespace)),
+		rule("SDIDataHubDegraded", "critical",
+			metricsNamespace+"_datahub_degraded"+selector+" == 1",
+			durationOrDefault(spec.DataHubDegradedFor, defaultDataHubDegradedFor),
+			"The DataHub resource is degraded.",
+			fmt.Sprintf("The DataHub resource in namespace %s reports a failure.", namespace)),
+		rule("SDIFluentdPatchDriftDetected", "warning",
+			metricsNamespace+"_fluentd_patch_drift"+selector+" == 1",
+			durationOrDefault(spec.FluentdPatchDriftFor, defaultFluentdPatchDriftFor),
+			"The diagnostics fluentd patch has drifted.",
+			fmt.Sprintf("The diagnostics fluentd daemonset in namespace %s differs from the patched state.",
+				namespace)),
+	}
+}
+
+// managePrometheusRule creates a PrometheusRule with alerts for the SDI namespace in the namespace of the
+// SDIObserver.
+func managePrometheusRule(
+	ctx context.Context,
+	scheme *runtime.Scheme,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	rule.SetNamespace(obs.Namespace)
+	rule.SetName(prometheusRuleName(obs))
+
+	state := obs.Spec.Alerts.ManagementState
+	if reExplicitlyUnmanaged.MatchString(state) {
+		tracer.V(2).Info("prometheus rule is not managed")
+		return nil
+	}
+	if reRemoved.MatchString(state) {
+		err := c.Delete(ctx, rule)
+		if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
+		}
+		return nil
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, c, rule, func() error {
+		rule.Object["spec"] = map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  "sdi-observer." + namespace,
+					"rules": makeAlertRules(obs, namespace),
+				},
+			},
+		}
+		primaryresource.SetManagedBy(rule)
+		return controllerutil.SetControllerReference(obs, rule, scheme)
+	})
+	if meta.IsNoMatchError(err) {
+		tracer.Info("PrometheusRule kind is not available, skipping alerts")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed prometheus rule", "name", rule.GetName(), "operation", op)
+	}
+	return nil
+}
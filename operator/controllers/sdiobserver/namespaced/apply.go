@@ -0,0 +1,95 @@
+package namespaced
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	// fieldManager owns the fields of the managed objects set with the server-side apply.
+	fieldManager = "sdi-observer"
+	// appliedHashAnnotationKey holds the hash of the desired content last applied by the operator. A managed
+	// object differing from the desired content of the same hash has been modified by someone else.
+	appliedHashAnnotationKey = "di.sap-cop.redhat.com/applied-hash"
+)
+
+// applyObject sets the fields of obj with the server-side apply. Only the fields set in obj are owned by the
+// operator; the conflicting ones are taken over from the other managers. The fields set by the other managers
+// (e.g. GitOps or cert-manager) are kept intact unless the operator owned them before.
+func applyObject(ctx context.Context, c client.Client, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	return c.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
+
+// isFieldApplied returns true if the field at the path (e.g. "spec", "host") is owned by the operator's apply.
+func isFieldApplied(obj metav1.Object, path ...string) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager != fieldManager || entry.Operation != metav1.ManagedFieldsOperationApply ||
+			entry.FieldsV1 == nil {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			continue
+		}
+		found := true
+		for _, name := range path {
+			nested, ok := fields["f:"+name].(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			fields = nested
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// hashContent returns the hash of the JSON representation of the desired content.
+func hashContent(content interface{}) (string, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// driftRecorder is implemented by the clients reporting the external modifications of the managed objects.
+type driftRecorder interface {
+	recordDrift(obj client.Object, fields []string)
+}
+
+// flagDrift reports the fields of the managed object modified by someone else since the last apply of the
+// unchanged desired content.
+func flagDrift(ctx context.Context, c client.Client, namespace, component string, obj client.Object, fields []string) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	tracer.Info("detected an external modification of a managed object, restoring",
+		"namespace", obj.GetNamespace(), "name", obj.GetName(), "fields", strings.Join(fields, ","))
+	managedObjectDrifts.With(prometheus.Labels{sdiNamespaceLabel: namespace, componentLabel: component}).Inc()
+	if recorder, ok := c.(driftRecorder); ok {
+		recorder.recordDrift(obj, fields)
+	}
+}
@@ -0,0 +1,117 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	auditConfigMapKey = "audit.json"
+	// the number of the most recent mutations kept in the config map
+	auditCapacity = 100
+	// the longest patch kept in an entry
+	auditMaxSummaryLength = 1024
+)
+
+// auditEntry describes a single mutation of a managed object.
+type auditEntry struct {
+	Time      metav1.Time `json:"time"`
+	Operation string      `json:"operation"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	// The merge patch applied to the object. Empty for creations and deletions.
+	Summary string `json:"summary,omitempty"`
+	// The step of the reconciliation that made the change.
+	Reason string `json:"reason"`
+}
+
+var (
+	auditPkgPath        = reflect.TypeOf(auditEntry{}).PkgPath()
+	reAuditCallerSuffix = regexp.MustCompile(`(\.func\d+)+(\.\d+)*$`)
+)
+
+func auditConfigMapName(obs *sdiv1alpha1.SDIObserver) string {
+	return obs.Name + "-audit"
+}
+
+// getAuditReason returns the name of the innermost function of this package outside of the eventing client
+// and the reporting helpers on the call stack, e.g. manageVSystemRoute.
+func getAuditReason() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		name := strings.TrimPrefix(frame.Function, auditPkgPath+".")
+		if name != frame.Function && !strings.Contains(name, "eventingClient") && name != "flagDrift" {
+			name = strings.TrimPrefix(name, "(*reconciler).")
+			return reAuditCallerSuffix.ReplaceAllString(name, "")
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// summarizeChange returns the truncated merge patch.
+func summarizeChange(data []byte) string {
+	summary := string(data)
+	if len(summary) > auditMaxSummaryLength {
+		summary = summary[:auditMaxSummaryLength] + "..."
+	}
+	return summary
+}
+
+// recordAudit appends the entries to the ring buffer kept in the audit config map in the namespace of the
+// SDIObserver.
+func recordAudit(
+	ctx context.Context,
+	scheme *k8sruntime.Scheme,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	entries []auditEntry,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if len(entries) == 0 {
+		return nil
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: obs.Namespace, Name: auditConfigMapName(obs)}}
+	_, err := controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		var trail []auditEntry
+		if data, ok := cm.Data[auditConfigMapKey]; ok {
+			if err := json.Unmarshal([]byte(data), &trail); err != nil {
+				tracer.Info("discarding the unparsable audit trail", "error", err)
+				trail = nil
+			}
+		}
+		trail = append(trail, entries...)
+		if len(trail) > auditCapacity {
+			trail = trail[len(trail)-auditCapacity:]
+		}
+		data, err := json.MarshalIndent(trail, "", "  ")
+		if err != nil {
+			return err
+		}
+		cm.Data = map[string]string{auditConfigMapKey: string(data)}
+		primaryresource.SetManagedBy(cm)
+		return controllerutil.SetControllerReference(obs, cm, scheme)
+	})
+	return err
+}
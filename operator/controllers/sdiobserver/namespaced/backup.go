@@ -0,0 +1,325 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+const (
+	// marks the workloads and claims annotated or labeled for Velero by the operator
+	backupManagedAnnotationKey = "di.sap-cop.redhat.com/backup-managed"
+	veleroExcludeLabelKey      = "velero.io/exclude-from-backup"
+	veleroHookAnnotationPrefix = ".hook.backup.velero.io/"
+
+	defaultBackupScheduleNamespace = "openshift-adp"
+	defaultBackupTTL               = time.Hour * 24 * 30
+)
+
+var veleroScheduleGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Schedule"}
+
+// the default hook quiesces hana with a savepoint
+var defaultBackupHooks = []sdiv1alpha1.SDIObserverSpecBackupHook{{
+	Workload:  "hana",
+	Container: "hana",
+	Pre:       []string{"/bin/sh", "-c", `hdbsql -U BACKUP "ALTER SYSTEM SAVEPOINT"`},
+	OnError:   "Continue",
+}}
+
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=velero.io,resources=schedules,verbs=get;list;watch;create;update;patch;delete
+
+// setBackupHookAnnotations replaces the Velero hook annotations of the pod template. A nil hook removes them.
+func setBackupHookAnnotations(template *corev1.PodTemplateSpec, hook *sdiv1alpha1.SDIObserverSpecBackupHook) {
+	annotations := template.GetAnnotations()
+	for k := range annotations {
+		if strings.HasPrefix(k, "pre"+veleroHookAnnotationPrefix) || strings.HasPrefix(k, "post"+veleroHookAnnotationPrefix) {
+			delete(annotations, k)
+		}
+	}
+	if hook == nil {
+		template.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	for _, phase := range []struct {
+		name    string
+		command []string
+	}{{"pre", hook.Pre}, {"post", hook.Post}} {
+		if len(phase.command) == 0 {
+			continue
+		}
+		prefix := phase.name + veleroHookAnnotationPrefix
+		command, _ := json.Marshal(phase.command)
+		annotations[prefix+"command"] = string(command)
+		if len(hook.Container) > 0 {
+			annotations[prefix+"container"] = hook.Container
+		}
+		if phase.name == "pre" {
+			if len(hook.OnError) > 0 {
+				annotations[prefix+"on-error"] = hook.OnError
+			}
+			if hook.Timeout != nil {
+				annotations[prefix+"timeout"] = hook.Timeout.Duration.String()
+			}
+		}
+	}
+	template.SetAnnotations(annotations)
+}
+
+func getPodTemplate(obj client.Object) *corev1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template
+	}
+	return nil
+}
+
+// manageBackupHooks annotates the pod templates of the workloads with the Velero backup hooks. The returned
+// changes are deferred until the maintenance window.
+func manageBackupHooks(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := &obs.Spec.Backup
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("backup hooks are not managed")
+		return nil, maintenance.Result{}, nil
+	}
+	hooks := make(map[string]*sdiv1alpha1.SDIObserverSpecBackupHook)
+	if !reRemoved.MatchString(spec.ManagementState) {
+		desired := spec.Hooks
+		if len(desired) == 0 {
+			desired = defaultBackupHooks
+		}
+		for i := range desired {
+			hooks[desired[i].Workload] = &desired[i]
+		}
+	}
+
+	workloads, err := listWorkloads(ctx, c, namespace)
+	if err != nil {
+		return nil, maintenance.Result{}, fmt.Errorf("failed to list workloads: %v", err)
+	}
+	var pending []string
+	var window maintenance.Result
+	for _, obj := range workloads {
+		obj := obj
+		template := getPodTemplate(obj)
+		if template == nil {
+			continue
+		}
+		hook := hooks[obj.GetName()]
+		if _, managed := obj.GetAnnotations()[backupManagedAnnotationKey]; hook == nil && !managed {
+			continue
+		}
+		kind, _, err := getPodSpec(obj)
+		if err != nil {
+			return nil, window, err
+		}
+		var op controllerutil.OperationResult
+		op, window, err = maintenance.CreateOrUpdate(ctx, c, obs.Namespace, obj, func() error {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			if hook != nil {
+				annotations[backupManagedAnnotationKey] = "true"
+			} else {
+				delete(annotations, backupManagedAnnotationKey)
+			}
+			obj.SetAnnotations(annotations)
+			setBackupHookAnnotations(template, hook)
+			return nil
+		}, false)
+		if err != nil {
+			return nil, window, fmt.Errorf("failed to set backup hooks of %s %s: %v", kind, obj.GetName(), err)
+		}
+		if op == maintenance.OperationResultDeferred {
+			pending = append(pending, fmt.Sprintf("the backup hooks of %s %s", kind, obj.GetName()))
+		} else if op != controllerutil.OperationResultNone {
+			tracer.Info("set backup hooks", "kind", kind, "name", obj.GetName(), "set", hook != nil,
+				"operation", op)
+		}
+	}
+	return pending, window, nil
+}
+
+// manageBackup excludes the cache volumes from the backup and manages the Velero Schedule of the SDI namespace.
+// The Schedule lives in the Velero namespace, which is not cached.
+func manageBackup(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := &obs.Spec.Backup
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("backup is not managed")
+		return nil
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+
+	var excluded []*regexp.Regexp
+	if !removed {
+		for _, expr := range spec.ExcludedClaims {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("invalid excluded claim expression %q: %v", expr, err)
+			}
+			excluded = append(excluded, re)
+		}
+	}
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := c.List(ctx, claims, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list persistent volume claims: %v", err)
+	}
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		exclude := false
+		for _, re := range excluded {
+			if re.MatchString(pvc.Name) {
+				exclude = true
+				break
+			}
+		}
+		_, managed := pvc.Annotations[backupManagedAnnotationKey]
+		if exclude == managed {
+			continue
+		}
+		patch := client.MergeFrom(pvc.DeepCopy())
+		if exclude {
+			metav1.SetMetaDataLabel(&pvc.ObjectMeta, veleroExcludeLabelKey, "true")
+			metav1.SetMetaDataAnnotation(&pvc.ObjectMeta, backupManagedAnnotationKey, "true")
+		} else {
+			delete(pvc.Labels, veleroExcludeLabelKey)
+			delete(pvc.Annotations, backupManagedAnnotationKey)
+		}
+		tracer.Info("updating backup exclusion of persistent volume claim", "name", pvc.Name, "excluded", exclude)
+		if err := c.Patch(ctx, pvc, patch); err != nil {
+			return fmt.Errorf("failed to label persistent volume claim %s: %v", pvc.Name, err)
+		}
+	}
+
+	if rbacscope.Namespaced() {
+		// the Schedule lives in the Velero namespace; reported in the ClusterScopeAvailable condition
+		return nil
+	}
+	return manageBackupSchedule(ctx, c, apiReader, obs, namespace, removed)
+}
+
+func backupScheduleName(namespace string) string {
+	return "sdi-" + namespace
+}
+
+func manageBackupSchedule(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+	removed bool,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.Backup.Schedule
+	scheduleNamespace := defaultBackupScheduleNamespace
+	if spec != nil && len(spec.Namespace) > 0 {
+		scheduleNamespace = spec.Namespace
+	}
+	schedule := &unstructured.Unstructured{}
+	schedule.SetGroupVersionKind(veleroScheduleGVK)
+	err := apiReader.Get(ctx, types.NamespacedName{Namespace: scheduleNamespace, Name: backupScheduleName(namespace)},
+		schedule)
+	if meta.IsNoMatchError(err) {
+		if spec == nil || removed {
+			return nil
+		}
+		return fmt.Errorf("velero is not installed: %v", err)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if spec == nil || removed {
+		if !exists || !primaryresource.IsOwnedBy(schedule, obs, "SDIObserver") {
+			return nil
+		}
+		tracer.Info("deleting velero schedule", "namespace", scheduleNamespace, "name", schedule.GetName())
+		return client.IgnoreNotFound(c.Delete(ctx, schedule))
+	}
+
+	ttl := defaultBackupTTL
+	if spec.TTL != nil && spec.TTL.Duration > 0 {
+		ttl = spec.TTL.Duration
+	}
+	desired := map[string]interface{}{
+		"schedule": spec.Cron,
+		"template": map[string]interface{}{
+			"includedNamespaces": []interface{}{namespace},
+			"snapshotVolumes":    true,
+			"ttl":                ttl.String(),
+		},
+	}
+	if !exists {
+		schedule.SetNamespace(scheduleNamespace)
+		schedule.SetName(backupScheduleName(namespace))
+		primaryresource.Set(schedule, obs, "SDIObserver")
+		schedule.Object["spec"] = desired
+		tracer.Info("creating velero schedule", "namespace", scheduleNamespace, "name", schedule.GetName())
+		return c.Create(ctx, schedule)
+	}
+	if !primaryresource.IsOwnedBy(schedule, obs, "SDIObserver") {
+		return fmt.Errorf("velero schedule %s/%s is not owned by this SDIObserver", scheduleNamespace,
+			schedule.GetName())
+	}
+	current, _, _ := unstructured.NestedMap(schedule.Object, "spec")
+	if current["schedule"] == desired["schedule"] {
+		template, _, _ := unstructured.NestedMap(current, "template")
+		want := desired["template"].(map[string]interface{})
+		if template["ttl"] == want["ttl"] && template["snapshotVolumes"] == true {
+			if ns, _, _ := unstructured.NestedStringSlice(template, "includedNamespaces"); len(ns) == 1 &&
+				ns[0] == namespace {
+				return nil
+			}
+		}
+	}
+	schedule.Object["spec"] = desired
+	tracer.Info("updating velero schedule", "namespace", scheduleNamespace, "name", schedule.GetName())
+	return c.Update(ctx, schedule)
+}
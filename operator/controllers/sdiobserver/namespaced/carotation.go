@@ -0,0 +1,184 @@
+package namespaced
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	conditionDestinationCAVerified = "DestinationCAVerified"
+
+	reasonDestinationCARotated            = "DestinationCARotated"
+	reasonDestinationCAPending            = "Pending"
+	reasonDestinationCAVerificationFailed = "VerificationFailed"
+
+	// the serving certificate of vsystem may be replaced a bit later than the CA bundle
+	destinationCAVerificationRetry = time.Second * 30
+)
+
+// eventRecorder is implemented by the clients emitting events on the SDIObserver.
+type eventRecorder interface {
+	recordEvent(eventtype, reason, message string)
+}
+
+func emitEvent(c client.Client, eventtype, reason, message string) {
+	if recorder, ok := c.(eventRecorder); ok {
+		recorder.recordEvent(eventtype, reason, message)
+	}
+}
+
+func fingerprintCABundle(caBundle string) string {
+	sum := sha256.Sum256([]byte(caBundle))
+	return hex.EncodeToString(sum[:])
+}
+
+// trackDestinationCARotation records the fingerprint of the destination CA bundle applied to the vsystem route.
+// A changed fingerprint means that SDI has rotated its certificates; the new chain is verified by
+// verifyDestinationCA.
+func trackDestinationCARotation(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	caBundle string,
+) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	status := &obs.Status.VSystemRoute
+	fingerprint := fingerprintCABundle(caBundle)
+	previous := status.DestinationCAFingerprint
+	if previous == fingerprint {
+		return
+	}
+	status.DestinationCAFingerprint = fingerprint
+	if len(previous) == 0 {
+		// applied for the first time, nothing to compare with
+		return
+	}
+	now := metav1.Now()
+	status.LastDestinationCARotationTime = &now
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionDestinationCAVerified,
+		Status:             metav1.ConditionUnknown,
+		Reason:             reasonDestinationCAPending,
+		Message:            "the rotated destination CA bundle is being verified",
+		ObservedGeneration: obs.Generation,
+	})
+	tracer.Info("applied the rotated destination CA bundle to the vsystem route", "fingerprint", fingerprint)
+	emitEvent(c, corev1.EventTypeNormal, reasonDestinationCARotated, fmt.Sprintf(
+		"applied the rotated destination CA bundle (sha256 %s) to the vsystem route", fingerprint))
+}
+
+// verifyDestinationCA probes the vsystem service with the rotated destination CA bundle until the serving
+// certificate verifies against it. It returns the delay of the next attempt or zero if none is needed.
+func verifyDestinationCA(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) time.Duration {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	status := &obs.Status.VSystemRoute
+	spec := obs.Spec.VSystemRoute
+	if reUnmanaged.MatchString(spec.ManagementState) || reRemoved.MatchString(spec.ManagementState) ||
+		isServiceMeshExposure(spec) {
+		meta.RemoveStatusCondition(&status.Conditions, conditionDestinationCAVerified)
+		return 0
+	}
+	cond := meta.FindStatusCondition(status.Conditions, conditionDestinationCAVerified)
+	if cond == nil || cond.Status == metav1.ConditionTrue {
+		return 0
+	}
+
+	err := probeDestinationCA(ctx, c, apiReader, obs, namespace)
+	if err != nil {
+		tracer.Info("the rotated destination CA bundle does not verify yet", "error", err)
+		if cond.Status != metav1.ConditionFalse {
+			emitEvent(c, corev1.EventTypeWarning, reasonDestinationCAVerificationFailed, fmt.Sprintf(
+				"the vsystem service cannot be verified with the rotated destination CA bundle: %v", err))
+		}
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionDestinationCAVerified,
+			Status:             metav1.ConditionFalse,
+			Reason:             reasonDestinationCAVerificationFailed,
+			Message:            fmt.Sprintf("the vsystem service cannot be verified with the rotated CA bundle: %v", err),
+			ObservedGeneration: obs.Generation,
+		})
+		return destinationCAVerificationRetry
+	}
+	tracer.Info("verified the rotated destination CA bundle")
+	emitEvent(c, corev1.EventTypeNormal, conditionDestinationCAVerified,
+		"the vsystem service verifies with the rotated destination CA bundle")
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionDestinationCAVerified,
+		Status:             metav1.ConditionTrue,
+		Reason:             sdiv1alpha1.ConditionReasonAsExpected,
+		Message:            "the vsystem service verifies with the rotated destination CA bundle",
+		ObservedGeneration: obs.Generation,
+	})
+	return 0
+}
+
+// probeDestinationCA completes a TLS handshake with the vsystem service trusting just the current destination CA
+// bundle, the same way as the router re-encrypting the connections does.
+func probeDestinationCA(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	caSecretName, caSecretKey := getDestinationCASecretRef(obs)
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: caSecretName}, secret); err != nil {
+		return fmt.Errorf("failed to get the destination CA secret %s: %v", caSecretName, err)
+	}
+	caBundle, err := getCertFromCaBundleSecret(secret, caSecretKey)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+		return fmt.Errorf("no certificate found in the %s secret", caSecretName)
+	}
+	// the service domain is normally excluded from the cluster proxy but a custom no-proxy list may omit it
+	proxy, err := clusterproxy.Get(ctx, apiReader)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{
+		Timeout:       routeProbeTimeout,
+		Transport:     proxy.Transport(pool),
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("https://vsystem.%s.svc:%d/", namespace, vsystemPortNumber), nil)
+	if err != nil {
+		return err
+	}
+	// any response proves the chain, the status is irrelevant
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
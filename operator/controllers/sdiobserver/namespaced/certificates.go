@@ -0,0 +1,97 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/certmanager"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	vsystemCertificateName       = "vsystem-route"
+	vsystemCertificateSecretName = "vsystem-route-tls"
+)
+
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
+
+// manageVSystemCertificate ensures a cert-manager Certificate for the vsystem route hostname exists if an
+// issuer is configured and returns the issued certificate. If no issuer is configured, the previously
+// created Certificate is removed and nil is returned.
+func manageVSystemCertificate(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	namespace string,
+) (*certmanager.Issued, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := owner.Spec.VSystemRoute
+	cert := certmanager.NewCertificate(namespace, vsystemCertificateName)
+
+	if spec.Certificate == nil || spec.Certificate.IssuerRef == nil {
+		return nil, deleteVSystemCertificate(ctx, c, owner, cert)
+	}
+	if len(spec.Hostname) == 0 {
+		return nil, fmt.Errorf("the certificate issuer requires a custom hostname of the vsystem route")
+	}
+
+	issuerRef := spec.Certificate.IssuerRef
+	op, err := controllerutil.CreateOrUpdate(ctx, c, cert, func() error {
+		primaryresource.Set(cert, owner, "SDIObserver")
+		certmanager.SetCertificateSpec(cert, vsystemCertificateSecretName, spec.Hostname, certmanager.IssuerRef{
+			Name:  issuerRef.Name,
+			Kind:  issuerRef.Kind,
+			Group: issuerRef.Group,
+		})
+		return nil
+	})
+	if meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("cert-manager is not installed: %v", err)
+	}
+	if err != nil {
+		tracer.Error(err, "failed to manage vsystem certificate")
+		return nil, err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed vsystem certificate", "name", cert.GetName(), "operation", op)
+	}
+
+	return certmanager.GetIssued(ctx, c, namespace, vsystemCertificateSecretName)
+}
+
+func deleteVSystemCertificate(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	cert *unstructured.Unstructured,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	err := c.Get(ctx, client.ObjectKeyFromObject(cert), cert)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !primaryresource.IsOwnedBy(cert, owner, "SDIObserver") {
+		return nil
+	}
+	tracer.Info("deleting vsystem certificate", "name", cert.GetName())
+	if err := c.Delete(ctx, cert); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
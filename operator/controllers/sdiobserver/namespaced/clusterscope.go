@@ -0,0 +1,43 @@
+package namespaced
+
+import (
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+// getDisabledFeatures returns the features requested by the spec that are disabled in the namespace-scoped RBAC
+// mode. They manage cluster-scoped objects, objects in the namespaces of other operators or read the cluster
+// configuration.
+func getDisabledFeatures(obs *sdiv1alpha1.SDIObserver) []string {
+	if !rbacscope.Namespaced() {
+		return nil
+	}
+	var disabled []string
+	if !reUnmanaged.MatchString(obs.Spec.NodeTuning.ManagementState) {
+		disabled = append(disabled, "node tuning")
+	}
+	if !reUnmanaged.MatchString(obs.Spec.Compliance.ManagementState) {
+		disabled = append(disabled, "compliance profile")
+	}
+	if !reUnmanaged.MatchString(obs.Spec.PriorityClass.ManagementState) {
+		disabled = append(disabled, "priority class")
+	}
+	if !reUnmanaged.MatchString(obs.Spec.ProxyInjection.ManagementState) {
+		disabled = append(disabled, "proxy injection")
+	}
+	if backup := obs.Spec.Backup; backup.Schedule != nil && !reUnmanaged.MatchString(backup.ManagementState) &&
+		!reRemoved.MatchString(backup.ManagementState) {
+		disabled = append(disabled, "backup schedule")
+	}
+	if state := obs.Spec.PodSecurity.ManagementState; !reUnmanaged.MatchString(state) && !reRemoved.MatchString(state) {
+		disabled = append(disabled, "pod security labels")
+	}
+	if state := obs.Spec.SCCReport.ManagementState; !reUnmanaged.MatchString(state) && !reRemoved.MatchString(state) {
+		disabled = append(disabled, "SCC report")
+	}
+	if registry := obs.Spec.Registry; len(registry.Hostname) > 0 &&
+		!reUnmanaged.MatchString(registry.ManagementState) {
+		disabled = append(disabled, "registry trust")
+	}
+	return disabled
+}
@@ -0,0 +1,225 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	defaultComplianceNamespace = "openshift-compliance"
+	defaultComplianceProfile   = "ocp4-cis"
+	complianceRuleActionManual = "Manual"
+	complianceAPIGroup         = "compliance.openshift.io"
+)
+
+//+kubebuilder:rbac:groups=compliance.openshift.io,resources=tailoredprofiles;scansettingbindings,verbs=get;list;watch;create;update;patch;delete
+
+var (
+	tailoredProfileGVK = schema.GroupVersionKind{
+		Group: complianceAPIGroup, Version: "v1alpha1", Kind: "TailoredProfile"}
+	scanSettingBindingGVK = schema.GroupVersionKind{
+		Group: complianceAPIGroup, Version: "v1alpha1", Kind: "ScanSettingBinding"}
+)
+
+func newComplianceObject(obs *sdiv1alpha1.SDIObserver, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	namespace := obs.Spec.Compliance.Namespace
+	if len(namespace) == 0 {
+		namespace = defaultComplianceNamespace
+	}
+	obj.SetNamespace(namespace)
+	obj.SetName("sdi-observer-" + obs.Name)
+	return obj
+}
+
+// getComplianceRules returns the rules violated by design of SDI followed by the additional ones.
+func getComplianceRules(obs *sdiv1alpha1.SDIObserver, namespace string) []sdiv1alpha1.SDIObserverComplianceRule {
+	rules := []sdiv1alpha1.SDIObserverComplianceRule{
+		{
+			Name: "ocp4-scc-limit-privileged-containers",
+			Rationale: fmt.Sprintf("The diagnostics fluentd daemonset of SAP Data Intelligence in namespace %s"+
+				" reads the container logs of the nodes and needs the privileged SCC; the namespace is labeled"+
+				" with the privileged Pod Security level.", namespace),
+		},
+		{
+			Name: "ocp4-scc-limit-root-containers",
+			Rationale: fmt.Sprintf("The service accounts of SAP Data Intelligence in namespace %s are granted the"+
+				" anyuid SCC because several SDI images run as root.", namespace),
+		},
+		{
+			Name: "ocp4-scc-limit-privilege-escalation",
+			Rationale: fmt.Sprintf("The privileged and anyuid SCCs granted to the service accounts of SAP Data"+
+				" Intelligence in namespace %s allow the privilege escalation.", namespace),
+		},
+	}
+	return append(rules, obs.Spec.Compliance.AdditionalRules...)
+}
+
+func makeTailoredProfileSpec(obs *sdiv1alpha1.SDIObserver, namespace string) map[string]interface{} {
+	spec := obs.Spec.Compliance
+	profile := spec.Profile
+	if len(profile) == 0 {
+		profile = defaultComplianceProfile
+	}
+	var rules []interface{}
+	for _, rule := range getComplianceRules(obs, namespace) {
+		rules = append(rules, map[string]interface{}{"name": rule.Name, "rationale": rule.Rationale})
+	}
+	field := "disableRules"
+	if len(spec.RuleAction) == 0 || spec.RuleAction == complianceRuleActionManual {
+		field = "manualRules"
+	}
+	return map[string]interface{}{
+		"extends": profile,
+		"title":   fmt.Sprintf("%s tailored for SAP Data Intelligence in namespace %s", profile, namespace),
+		"description": "Documents the deviations from the profile required by SAP Data Intelligence. Managed by" +
+			" the SDIObserver " + obs.Namespace + "/" + obs.Name + ".",
+		field: rules,
+	}
+}
+
+func makeScanSettingBinding(obs *sdiv1alpha1.SDIObserver, profile *unstructured.Unstructured) map[string]interface{} {
+	return map[string]interface{}{
+		"profiles": []interface{}{
+			map[string]interface{}{
+				"apiGroup": complianceAPIGroup + "/v1alpha1",
+				"kind":     tailoredProfileGVK.Kind,
+				"name":     profile.GetName(),
+			},
+		},
+		"settingsRef": map[string]interface{}{
+			"apiGroup": complianceAPIGroup + "/v1alpha1",
+			"kind":     "ScanSetting",
+			"name":     obs.Spec.Compliance.ScanSetting,
+		},
+	}
+}
+
+// applyComplianceObject creates or updates the top-level fields of the object. The objects live in the
+// namespace of the Compliance Operator which is not covered by the cache.
+func applyComplianceObject(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	obj *unstructured.Unstructured,
+	fields map[string]interface{},
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	err := apiReader.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if errors.IsNotFound(err) {
+		primaryresource.Set(obj, obs, "SDIObserver")
+		for key, value := range fields {
+			obj.Object[key] = value
+		}
+		tracer.Info("creating "+obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return c.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	if !primaryresource.IsOwnedBy(obj, obs, "SDIObserver") {
+		return fmt.Errorf("%s %s/%s exists and is not managed by the observer", obj.GetKind(),
+			obj.GetNamespace(), obj.GetName())
+	}
+	current := obj.DeepCopy()
+	for key, value := range fields {
+		obj.Object[key] = value
+	}
+	if equality.Semantic.DeepEqual(current.Object, obj.Object) {
+		return nil
+	}
+	tracer.Info("updating "+obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return c.Update(ctx, obj)
+}
+
+// deleteComplianceObject deletes the object unless it is missing or not managed by the SDIObserver.
+func deleteComplianceObject(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	obj *unstructured.Unstructured,
+) error {
+	err := apiReader.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !primaryresource.IsOwnedBy(obj, obs, "SDIObserver") {
+		return nil
+	}
+	log.FromContext(ctx).Info("deleting "+obj.GetKind(), "namespace", obj.GetNamespace(), "name", obj.GetName())
+	return client.IgnoreNotFound(c.Delete(ctx, obj))
+}
+
+// manageCompliance maintains the TailoredProfile of the Compliance Operator documenting the deviations required
+// by SDI and optionally binds it to a ScanSetting. Nothing is done unless the Compliance Operator is installed.
+func manageCompliance(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.Compliance
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("compliance profile is not managed")
+		return nil
+	}
+	profile := newComplianceObject(obs, tailoredProfileGVK)
+	binding := newComplianceObject(obs, scanSettingBindingGVK)
+	if reRemoved.MatchString(spec.ManagementState) {
+		if err := deleteComplianceObject(ctx, c, apiReader, obs, binding); err != nil {
+			return err
+		}
+		return deleteComplianceObject(ctx, c, apiReader, obs, profile)
+	}
+
+	err := applyComplianceObject(ctx, c, apiReader, obs, profile, map[string]interface{}{
+		"spec": makeTailoredProfileSpec(obs, namespace),
+	})
+	if meta.IsNoMatchError(err) {
+		tracer.Info("the Compliance Operator is not installed, skipping the tailored profile")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(spec.ScanSetting) == 0 {
+		if err := deleteComplianceObject(ctx, c, apiReader, obs, binding); err != nil {
+			return err
+		}
+	} else if err := applyComplianceObject(ctx, c, apiReader, obs, binding,
+		makeScanSettingBinding(obs, profile)); err != nil {
+		return err
+	}
+
+	// e.g. a rule unknown to the content of the Compliance Operator
+	if state, _, _ := unstructured.NestedString(profile.Object, "status", "state"); state == "ERROR" {
+		message, _, _ := unstructured.NestedString(profile.Object, "status", "errorMessage")
+		return fmt.Errorf("the tailored profile %s/%s is invalid: %s", profile.GetNamespace(), profile.GetName(),
+			message)
+	}
+	return nil
+}
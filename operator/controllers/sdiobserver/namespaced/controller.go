@@ -2,16 +2,24 @@ package namespaced
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -19,6 +27,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	csroute "github.com/openshift/client-go/route/clientset/versioned"
@@ -26,32 +35,94 @@ import (
 
 	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
 	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/upgradeable"
 )
 
+// The default resync periods of the informers.
 const (
-	//defaultSyncTime = time.Minute
-	dhSyncTime    = time.Minute * 3
-	routeSyncTime = time.Minute * 10
-	coreSyncTime  = time.Minute * 10
+	DefaultDHSyncTime    = time.Minute * 3
+	DefaultRouteSyncTime = time.Minute * 10
+	DefaultCoreSyncTime  = time.Minute * 10
+	// MinSyncTime protects the API server from too frequent relists.
+	MinSyncTime = time.Second * 30
+	// how long Stop waits for the workers and informers to exit
+	stopTimeout = time.Second * 30
+	// how often the DataHub CRD registration is checked without the permission to watch the CRDs
+	dataHubCRDPollInterval = time.Minute
 )
 
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
 // Controller manages a single DataHub instance. It is controlled by the SDIObserver resource. The
 // controller updates its status. It is created dynamically by the parent controller.
 type Controller struct {
 	controller.Controller
 
-	mgr                manager.Manager
-	unstartedFactories []informerFactory
-	cancels            []context.CancelFunc
+	mgr manager.Manager
+	// the informers backing the watches; they are run along with the controller
+	informers []cache.SharedIndexInformer
+	cancels   []context.CancelFunc
 	// get notified from the parent controller when SDIObserver changes
 	chanReconcileObs chan event.GenericEvent
-	isStarted        bool
+	// enqueues the SDIObserver for the events of all the watched objects so that the workqueue coalesces them
+	obsHandler  handler.EventHandler
+	isStarted   bool
+	dhNamespace string
+	// the number of the concurrent workers
+	maxConcurrentReconciles int
+	// the namespaces of the watched secrets besides the SDI namespace
+	secretNamespaces []string
+	// tracks the goroutines of the controller and its informers
+	wg sync.WaitGroup
+	// closed when the controller stops accepting the notifications
+	stopCh chan struct{}
+	// held by the pending notifications so that Stop can wait for them before closing chanReconcileObs
+	notifyLock sync.RWMutex
+	stopOnce   sync.Once
+	// the routes are not watched if the cluster does not serve the route API
+	noRouteAPI bool
 }
 
 var _ controller.Controller = &Controller{}
 
-type informerFactory interface {
-	Start(<-chan struct{})
+// SyncTimes are the resync periods of the informers of the namespaced controller.
+type SyncTimes struct {
+	// DataHub resources
+	DataHub time.Duration
+	// routes
+	Route time.Duration
+	// services, secrets, workloads and role bindings
+	Core time.Duration
+}
+
+// DefaultSyncTimes returns the default resync periods.
+func DefaultSyncTimes() SyncTimes {
+	return SyncTimes{
+		DataHub: DefaultDHSyncTime,
+		Route:   DefaultRouteSyncTime,
+		Core:    DefaultCoreSyncTime,
+	}
+}
+
+// Validate ensures the resync periods are not shorter than MinSyncTime.
+func (s SyncTimes) Validate() error {
+	for _, t := range []struct {
+		desc   string
+		period time.Duration
+	}{{"DataHub", s.DataHub}, {"route", s.Route}, {"core", s.Core}} {
+		if t.period < MinSyncTime {
+			return fmt.Errorf("the %s resync period %s is shorter than %s", t.desc, t.period, MinSyncTime)
+		}
+	}
+	return nil
 }
 
 // NewController in this context means that the SDIObserver CR is managed by the controller. The controller
@@ -62,33 +133,52 @@ func NewController(
 	scheme *runtime.Scheme,
 	nmName types.NamespacedName,
 	dhNamespace string,
+	// see WatchedSecretNamespaces; the SDI namespace is always watched
+	secretNamespaces []string,
 	mgr manager.Manager,
 	options controller.Options,
+	syncTimes SyncTimes,
+	upgradeGate *upgradeable.Gate,
 ) (*Controller, error) {
 	defer λ.Leave(λ.Enter(logf.Log))
 	r := &reconciler{
 		client:         client,
+		apiReader:      mgr.GetAPIReader(),
+		restMapper:     mgr.GetRESTMapper(),
 		scheme:         scheme,
 		namespacedName: nmName,
 		dhNamespace:    dhNamespace,
+		recorder:       mgr.GetEventRecorderFor("sdi-observer"),
+		upgradeGate:    upgradeGate,
+		noRouteAPI:     !routeapi.IsServed(mgr.GetRESTMapper()),
+		expiryNotices:  &expiryNotices{},
 	}
 	dhClient, err := NewDHClient(mgr.GetConfig())
 	if err != nil {
 		return nil, err
 	}
 	r.dhClient = dhClient
+	kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	r.tokens = kubeClient.CoreV1()
+	r.permissions = &permissionChecks{reviews: kubeClient.AuthorizationV1()}
 
 	ctrlName := strings.Join([]string{"namespaced", nmName.Namespace, nmName.Name}, "-")
 	logger := logf.Log.WithValues(
-		"controller name", ctrlName,
-		"managed DH namespace", dhNamespace)
+		λ.ComponentKey, "sdiobserver-namespaced",
+		"controller", ctrlName,
+		λ.SDINamespaceKey, dhNamespace)
 
 	unmanagedCtrl, err := controller.NewUnmanaged(
 		ctrlName,
 		mgr,
 		controller.Options{
-			Reconciler: r,
-			Log:        logger,
+			Reconciler:              r,
+			Log:                     logger,
+			RateLimiter:             options.RateLimiter,
+			MaxConcurrentReconciles: options.MaxConcurrentReconciles,
 		})
 	if err != nil {
 		return nil, err
@@ -98,6 +188,20 @@ func NewController(
 		Controller:       unmanagedCtrl,
 		mgr:              mgr,
 		chanReconcileObs: make(chan event.GenericEvent),
+		obsHandler:       handler.EnqueueRequestsFromMapFunc(r.mapToObserver),
+		dhNamespace:      dhNamespace,
+		stopCh:           make(chan struct{}),
+		noRouteAPI:       r.noRouteAPI,
+	}
+	ctrl.maxConcurrentReconciles = options.MaxConcurrentReconciles
+	for _, namespace := range secretNamespaces {
+		if namespace != dhNamespace {
+			ctrl.secretNamespaces = append(ctrl.secretNamespaces, namespace)
+		}
+	}
+	if ctrl.maxConcurrentReconciles <= 0 {
+		// the controller-runtime default
+		ctrl.maxConcurrentReconciles = 1
 	}
 
 	obsContext, obsWatchCancel := context.WithCancel(context.Background())
@@ -106,13 +210,18 @@ func NewController(
 		obsWatchCancel()
 		return nil, err
 	}
-	if err := ctrl.Watch(&sc, &handler.EnqueueRequestForObject{}); err != nil {
+	if err := ctrl.Watch(&sc, ctrl.obsHandler); err != nil {
 		obsWatchCancel()
 		return nil, err
 	}
 	ctrl.cancels = append(ctrl.cancels, obsWatchCancel)
 
-	err = ctrl.manageDHNamespace(obsContext, dhNamespace)
+	// keep the copies of the image pull secret in sync with the source and refresh the destination CA of the routes
+	// when a custom CA secret changes
+	err = ctrl.manageDHNamespace(obsContext, dhNamespace,
+		predicate.NewPredicateFuncs(r.isPullSecret),
+		predicate.NewPredicateFuncs(r.isDestinationCASecret),
+		syncTimes)
 	if err != nil {
 		obsWatchCancel()
 		return nil, err
@@ -121,25 +230,151 @@ func NewController(
 	return ctrl, nil
 }
 
-func (c *Controller) startFactories(chCancel <-chan struct{}) {
+// watchInformer enqueues the SDIObserver for the events of the informer passing the predicates. The informer
+// is run once the controller is started.
+func (c *Controller) watchInformer(informer cache.SharedIndexInformer, predicates ...predicate.Predicate) error {
+	c.informers = append(c.informers, informer)
+	return c.Watch(&source.Informer{Informer: informer}, c.obsHandler, predicates...)
+}
+
+// mapToObserver enqueues the managed SDIObserver for any object. The reconciler always reconciles the whole SDI
+// namespace so that the concurrent workers must never get distinct keys.
+func (r *reconciler) mapToObserver(client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: r.namespacedName}}
+}
+
+// runInformers runs the informers until the channel is closed. The informers are not run through their
+// factories so that Stop can wait for them to exit.
+func (c *Controller) runInformers(chCancel <-chan struct{}) {
 	defer λ.Leave(λ.Enter(c.GetLogger()))
 	if !c.isStarted {
 		// we don't want to miss the intial list of objects produced by each informer once started
-		// let's make sure to start the factories once the controller and its queue are prepared
+		// let's make sure to start the informers once the controller and its queue are prepared
 		return
 	}
-	for _, f := range c.unstartedFactories {
-		f.Start(chCancel)
+	for _, informer := range c.informers {
+		informer := informer
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			informer.Run(chCancel)
+		}()
 	}
-	c.unstartedFactories = nil
+	// drop the references so that the caches can be garbage collected once stopped
+	c.informers = nil
 }
 
+// MaxConcurrentReconciles returns the number of the concurrent workers of the controller.
+func (c *Controller) MaxConcurrentReconciles() int {
+	return c.maxConcurrentReconciles
+}
+
+// WatchesSecretNamespaces returns true if the controller watches the secrets of exactly the given namespaces. The
+// watches are fixed for the lifetime of the controller.
+func (c *Controller) WatchesSecretNamespaces(namespaces []string) bool {
+	watched := sets.NewString(c.secretNamespaces...).Insert(c.dhNamespace)
+	return watched.Equal(sets.NewString(namespaces...).Insert(c.dhNamespace))
+}
+
+// ReconcileObs enqueues the SDIObserver. The notification is dropped if the controller is stopping.
 func (c *Controller) ReconcileObs(obs *sdiv1alpha1.SDIObserver) {
 	defer λ.Leave(λ.Enter(c.GetLogger()))
-	c.chanReconcileObs <- event.GenericEvent{Object: obs}
+	c.notifyLock.RLock()
+	defer c.notifyLock.RUnlock()
+	select {
+	case <-c.stopCh:
+		return
+	default:
+	}
+	select {
+	case c.chanReconcileObs <- event.GenericEvent{Object: obs}:
+	case <-c.stopCh:
+	}
+}
+
+// watchDataHubs watches the DataHub resources in the namespace. If SAP Data Intelligence is not installed yet,
+// the CRD registration is watched instead and the DataHub watch is established once the CRD appears.
+func (c *Controller) watchDataHubs(
+	ctx context.Context,
+	metadataClient metadata.Interface,
+	dhNamespace string,
+	resync time.Duration,
+) error {
+	tracer := λ.Enter(c.GetLogger())
+	defer λ.Leave(tracer)
+
+	factory := metadatainformer.NewFilteredSharedInformerFactory(metadataClient, resync, dhNamespace, nil)
+	dhInformer := factory.ForResource(MakeDataHubGVR()).Informer()
+	if isDataHubCRDInstalled(c.mgr.GetRESTMapper()) {
+		return c.watchInformer(dhInformer)
+	}
+
+	tracer.Info("the DataHub CRD is not installed, deferring the DataHub watch", "crd", DataHubResourceFull)
+	if rbacscope.Namespaced() {
+		// the CRDs cannot be watched without the cluster-scoped permissions
+		go c.pollDataHubCRD(ctx, dhInformer)
+		return nil
+	}
+	crdFactory := metadatainformer.NewFilteredSharedInformerFactory(metadataClient, resync, "",
+		func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", DataHubResourceFull).String()
+		})
+	crdInformer := crdFactory.ForResource(crdGVR).Informer()
+	var once sync.Once
+	crdInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) {
+			once.Do(func() { go c.establishDataHubWatch(ctx, dhInformer) })
+		},
+	})
+	// the CRD events also trigger the reconciliation to refresh the status
+	return c.watchInformer(crdInformer)
+}
+
+// establishDataHubWatch starts the deferred DataHub watch unless the controller is stopping.
+func (c *Controller) establishDataHubWatch(ctx context.Context, dhInformer cache.SharedIndexInformer) {
+	tracer := λ.Enter(c.GetLogger())
+	defer λ.Leave(tracer)
+
+	// Stop waits for the lock before waiting for the goroutines
+	c.notifyLock.RLock()
+	defer c.notifyLock.RUnlock()
+	select {
+	case <-c.stopCh:
+		return
+	default:
+	}
+	tracer.Info("the DataHub CRD has been registered, watching the DataHub resources")
+	if err := c.Watch(&source.Informer{Informer: dhInformer}, c.obsHandler); err != nil {
+		tracer.Error(err, "failed to watch the DataHub resources")
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		dhInformer.Run(ctx.Done())
+	}()
 }
 
-func (c *Controller) manageDHNamespace(ctx context.Context, dhNamespace string) error {
+// pollDataHubCRD starts the deferred DataHub watch once the REST mapper knows the DataHub kind. It replaces the
+// CRD watch in the namespace-scoped RBAC mode.
+func (c *Controller) pollDataHubCRD(ctx context.Context, dhInformer cache.SharedIndexInformer) {
+	err := wait.PollImmediateUntil(dataHubCRDPollInterval, func() (bool, error) {
+		return isDataHubCRDInstalled(c.mgr.GetRESTMapper()), nil
+	}, ctx.Done())
+	if err == nil {
+		c.establishDataHubWatch(ctx, dhInformer)
+	}
+}
+
+func (c *Controller) manageDHNamespace(
+	ctx context.Context,
+	dhNamespace string,
+	// passes the secrets of the SDIObserver in any of the watched namespaces
+	secretPredicate predicate.Predicate,
+	// passes the additional secrets of the SDI namespace
+	dhSecretPredicate predicate.Predicate,
+	syncTimes SyncTimes,
+) error {
 	tracer := λ.Enter(c.GetLogger())
 	defer λ.Leave(tracer)
 	cfg := c.mgr.GetConfig()
@@ -147,34 +382,29 @@ func (c *Controller) manageDHNamespace(ctx context.Context, dhNamespace string)
 	if err != nil {
 		return err
 	}
-	routesClientSet := csroute.NewForConfigOrDie(cfg)
-	dhDynClient := dynamic.NewForConfigOrDie(cfg)
+	metadataClient, err := metadata.NewForConfig(cfg)
 	if err != nil {
 		return err
 	}
 
 	// Create a factory object that can generate informers for resource types
-	tracer.Info("setting up watches for DH instance", "DH namespace", dhNamespace)
+	tracer.Info("setting up watches for DH instance", λ.SDINamespaceKey, dhNamespace)
 
-	// TODO: Watch just metadata
-	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		dhDynClient,
-		dhSyncTime,
-		dhNamespace,
-		nil)
-	informer := factory.ForResource(MakeDataHubGVR())
-	c.unstartedFactories = append(c.unstartedFactories, factory)
-	if err := c.Watch(
-		&source.Informer{Informer: informer.Informer()},
-		&handler.EnqueueRequestForObject{}); err != nil {
+	// The DataHub and Secret watches only trigger the reconciliation which fetches the objects on its own.
+	// Caching just their metadata keeps the memory footprint low with large DataHub resources.
+	if err := c.watchDataHubs(ctx, metadataClient, dhNamespace, syncTimes.DataHub); err != nil {
 		return err
 	}
 
 	kubeInformerFactory := informers.NewSharedInformerFactoryWithOptions(
 		kubeClient,
-		coreSyncTime,
+		syncTimes.Core,
 		informers.WithNamespace(dhNamespace))
-	c.unstartedFactories = append(c.unstartedFactories, kubeInformerFactory)
+	coreMetadataFactory := metadatainformer.NewFilteredSharedInformerFactory(
+		metadataClient,
+		syncTimes.Core,
+		dhNamespace,
+		nil)
 	lsPred, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			"datahub.sap.com/app-component": "vsystem",
@@ -184,33 +414,75 @@ func (c *Controller) manageDHNamespace(ctx context.Context, dhNamespace string)
 	if err != nil {
 		return err
 	}
-	if err := c.Watch(
-		&source.Informer{Informer: kubeInformerFactory.Core().V1().Services().Informer()},
-		&handler.EnqueueRequestForObject{},
+	if err := c.watchInformer(
+		kubeInformerFactory.Core().V1().Services().Informer(),
 		lsPred); err != nil {
 		return err
 	}
-	if err := c.Watch(
-		&source.Informer{Informer: kubeInformerFactory.Core().V1().Secrets().Informer()},
-		&handler.EnqueueRequestForObject{},
+	secretsGVR := corev1.SchemeGroupVersion.WithResource("secrets")
+	if err := c.watchInformer(
+		coreMetadataFactory.ForResource(secretsGVR).Informer(),
+		predicate.Or(
+			predicate.NewPredicateFuncs(func(object client.Object) bool {
+				// the certificate secret is watched to update the route when cert-manager renews the certificate
+				return object.GetName() == vsystemCaBundleSecretName || object.GetName() == vsystemCertificateSecretName
+			}),
+			secretPredicate,
+			dhSecretPredicate)); err != nil {
+		return err
+	}
+	for _, namespace := range c.secretNamespaces {
+		// the metadata is enough to trigger the reconciliation, the secrets themselves are not cached
+		factory := metadatainformer.NewFilteredSharedInformerFactory(metadataClient, syncTimes.Core, namespace, nil)
+		if err := c.watchInformer(factory.ForResource(secretsGVR).Informer(), secretPredicate); err != nil {
+			return err
+		}
+	}
+
+	if err := c.watchInformer(
+		kubeInformerFactory.Apps().V1().StatefulSets().Informer(),
+		predicate.Or(
+			predicate.NewPredicateFuncs(func(object client.Object) bool {
+				// re-apply the exports mask on drift
+				return object.GetName() == vrepStatefulSetName
+			}),
+			// re-apply the injected scheduling on drift
+			predicate.GenerationChangedPredicate{})); err != nil {
+		return err
+	}
+	if err := c.watchInformer(
+		kubeInformerFactory.Apps().V1().Deployments().Informer(),
+		predicate.GenerationChangedPredicate{}); err != nil {
+		return err
+	}
+	if err := c.watchInformer(
+		kubeInformerFactory.Apps().V1().DaemonSets().Informer(),
+		predicate.GenerationChangedPredicate{}); err != nil {
+		return err
+	}
+
+	if err := c.watchInformer(
+		kubeInformerFactory.Rbac().V1().RoleBindings().Informer(),
 		predicate.NewPredicateFuncs(func(object client.Object) bool {
-			return object.GetName() == vsystemCaBundleSecretName
+			// restore the SCC bindings on drift
+			return strings.HasPrefix(object.GetName(), sccRoleBindingPrefix)
 		})); err != nil {
 		return err
 	}
 
-	routeInformerFactory := routeinformers.NewSharedInformerFactoryWithOptions(
-		routesClientSet,
-		routeSyncTime,
-		routeinformers.WithNamespace(dhNamespace))
-	c.unstartedFactories = append(c.unstartedFactories, routeInformerFactory)
-	if err := c.Watch(
-		&source.Informer{Informer: routeInformerFactory.Route().V1().Routes().Informer()},
-		&handler.EnqueueRequestForObject{}); err != nil {
-		return err
+	if c.noRouteAPI {
+		tracer.Info("the route API is not served, the routes are not watched")
+	} else {
+		routeInformerFactory := routeinformers.NewSharedInformerFactoryWithOptions(
+			csroute.NewForConfigOrDie(cfg),
+			syncTimes.Route,
+			routeinformers.WithNamespace(dhNamespace))
+		if err := c.watchInformer(routeInformerFactory.Route().V1().Routes().Informer()); err != nil {
+			return err
+		}
 	}
 
-	c.startFactories(ctx.Done())
+	c.runInformers(ctx.Done())
 	return nil
 }
 
@@ -219,22 +491,50 @@ func (c *Controller) Start(ctx context.Context) error {
 	defer λ.Leave(tracer)
 
 	childContext, cancel := context.WithCancel(context.Background())
+	c.wg.Add(1)
 	go func() {
+		defer c.wg.Done()
+		// returns once the queue is shut down and the workers have processed the remaining requests
 		if err := c.Controller.Start(childContext); err != nil {
 			tracer.Error(err, "controller terminated")
 		}
 	}()
 
 	c.isStarted = true
-	c.startFactories(childContext.Done())
+	c.runInformers(childContext.Done())
 	c.cancels = append(c.cancels, cancel)
 	return nil
 }
 
+// Stop shuts down the controller in order: it stops accepting the notifications, lets the in-flight
+// reconciliations finish and waits for the informers to exit before closing the notification channel. A
+// controller started again for the same namespace thus does not watch alongside the stopped one. It is safe to
+// call Stop multiple times.
 func (c *Controller) Stop() {
-	defer λ.Leave(λ.Enter(c.GetLogger()))
-	close(c.chanReconcileObs)
-	for _, c := range c.cancels {
-		c()
-	}
+	tracer := λ.Enter(c.GetLogger())
+	defer λ.Leave(tracer)
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+		// wait for the pending notifications to give up
+		c.notifyLock.Lock()
+		defer c.notifyLock.Unlock()
+
+		for _, c := range c.cancels {
+			c()
+		}
+		c.informers = nil
+
+		done := make(chan struct{})
+		go func() {
+			c.wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(stopTimeout):
+			tracer.Info("timed out waiting for the controller to stop", "timeout", stopTimeout)
+		}
+		close(c.chanReconcileObs)
+		forgetMetrics(c.dhNamespace)
+	})
 }
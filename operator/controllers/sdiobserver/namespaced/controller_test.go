@@ -42,6 +42,8 @@ var _ = Describe("Namespaced SDI Observer controller", func() {
 				"datahub.sap.com/app-component":   "vsystem",
 				"datahub.sap.com/app-version":     "3.2.21",
 				"datahub.sap.com/package-version": "3.2.34",
+				"di.sap-cop.redhat.com/managed":   "true",
+				"app.kubernetes.io/managed-by":    "sdi-observer",
 			},
 		))
 		Ω(route.Annotations).To(SatisfyAll(
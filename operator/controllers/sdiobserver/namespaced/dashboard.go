@@ -0,0 +1,205 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// the label watched by the dashboard sidecar of the Grafana helm chart
+	dashboardSidecarLabelKey = "grafana_dashboard"
+	dashboardConfigMapKey    = "sdi-observer.json"
+)
+
+var grafanaDashboardGVK = schema.GroupVersionKind{
+	Group:   "integreatly.org",
+	Version: "v1alpha1",
+	Kind:    "GrafanaDashboard",
+}
+
+//+kubebuilder:rbac:groups=integreatly.org,resources=grafanadashboards,verbs=get;list;watch;create;update;patch;delete
+
+func dashboardName(obs *sdiv1alpha1.SDIObserver) string {
+	return obs.Name + "-dashboard"
+}
+
+// makeDashboard renders the Grafana dashboard model for the SDI namespace.
+func makeDashboard(namespace string) (string, error) {
+	selector := fmt.Sprintf(`{%s=%q}`, sdiNamespaceLabel, namespace)
+	metric := func(name string) string { return metricsNamespace + "_" + name + selector }
+	panel := func(id int, title, unit string, x, y int, targets ...[2]string) map[string]interface{} {
+		var ts []interface{}
+		for i, t := range targets {
+			ts = append(ts, map[string]interface{}{
+				"expr":         t[0],
+				"legendFormat": t[1],
+				"refId":        string(rune('A' + i)),
+			})
+		}
+		return map[string]interface{}{
+			"id":         id,
+			"type":       "timeseries",
+			"title":      title,
+			"datasource": "${datasource}",
+			"gridPos":    map[string]interface{}{"h": 8, "w": 12, "x": x, "y": y},
+			"fieldConfig": map[string]interface{}{
+				"defaults": map[string]interface{}{"unit": unit},
+			},
+			"targets": ts,
+		}
+	}
+	daysLeft := func(name string) string { return fmt.Sprintf("(%s - time()) / 86400", metric(name)) }
+	componentDuration := fmt.Sprintf("histogram_quantile(0.95, sum by (component, le) (rate(%s[15m])))",
+		metric("component_reconcile_duration_seconds_bucket"))
+	componentErrors := fmt.Sprintf(`sum by (component) (increase(%s_component_reconcile_total{%s=%q,%s=%q}[15m]))`,
+		metricsNamespace, sdiNamespaceLabel, namespace, outcomeLabel, outcomeError)
+
+	dashboard := map[string]interface{}{
+		"uid":           "sdi-observer-" + namespace,
+		"title":         fmt.Sprintf("SAP Data Intelligence (%s)", namespace),
+		"tags":          []string{"sdi-observer"},
+		"schemaVersion": 30,
+		"refresh":       "1m",
+		"time":          map[string]interface{}{"from": "now-24h", "to": "now"},
+		"templating": map[string]interface{}{
+			"list": []interface{}{
+				map[string]interface{}{
+					"name":  "datasource",
+					"type":  "datasource",
+					"query": "prometheus",
+				},
+			},
+		},
+		"panels": []interface{}{
+			panel(1, "Route availability", "short", 0, 0,
+				[2]string{metric("vsystem_route_available"), "vsystem reachable"},
+				[2]string{metric("vsystem_route_exposed"), "vsystem exposed"}),
+			panel(2, "DataHub readiness", "short", 12, 0,
+				[2]string{metric("datahub_ready"), "ready"},
+				[2]string{metric("datahub_degraded"), "degraded"}),
+			panel(3, "Reconcile errors", "short", 0, 8,
+				[2]string{fmt.Sprintf("increase(%s[15m])", metric("reconcile_errors_total")), "errors"}),
+			panel(4, "Certificate expiry", "d", 12, 8,
+				[2]string{daysLeft("route_serving_certificate_expiry_timestamp_seconds"), "{{route}} serving"},
+				[2]string{daysLeft("vsystem_route_certificate_expiry_timestamp_seconds"), "vsystem route"},
+				[2]string{daysLeft("destination_ca_certificate_expiry_timestamp_seconds"), "vsystem CA bundle"}),
+			panel(5, "Component reconcile duration (p95)", "s", 0, 16,
+				[2]string{componentDuration, "{{component}}"}),
+			panel(6, "Component reconcile errors", "short", 12, 16,
+				[2]string{componentErrors, "{{component}}"}),
+		},
+	}
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// manageDashboard maintains the dashboard for the SDI namespace in the namespace of the SDIObserver as a
+// GrafanaDashboard if the Grafana operator is installed or as a ConfigMap otherwise.
+func manageDashboard(
+	ctx context.Context,
+	scheme *runtime.Scheme,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.Dashboard
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("dashboard is not managed")
+		return nil
+	}
+
+	gd := &unstructured.Unstructured{}
+	gd.SetGroupVersionKind(grafanaDashboardGVK)
+	gd.SetNamespace(obs.Namespace)
+	gd.SetName(dashboardName(obs))
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: obs.Namespace, Name: dashboardName(obs)}}
+
+	if reRemoved.MatchString(spec.ManagementState) {
+		for _, obj := range []client.Object{gd, cm} {
+			err := c.Delete(ctx, obj)
+			if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	data, err := makeDashboard(namespace)
+	if err != nil {
+		return err
+	}
+	setLabels := func(obj metav1.Object) {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, v := range spec.Labels {
+			labels[k] = v
+		}
+		obj.SetLabels(labels)
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, c, gd, func() error {
+		setLabels(gd)
+		if err := unstructured.SetNestedField(gd.Object, data, "spec", "json"); err != nil {
+			return err
+		}
+		primaryresource.SetManagedBy(gd)
+		return controllerutil.SetControllerReference(obs, gd, scheme)
+	})
+	if err == nil {
+		if op != controllerutil.OperationResultNone {
+			tracer.Info("managed grafana dashboard", "name", gd.GetName(), "operation", op)
+		}
+		// drop the fallback created before the Grafana operator got installed
+		if err := c.Get(ctx, client.ObjectKeyFromObject(cm), cm); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(cm, obs) {
+			return nil
+		}
+		tracer.Info("deleting dashboard config map", "name", cm.Name)
+		return client.IgnoreNotFound(c.Delete(ctx, cm))
+	}
+	if !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	tracer.V(1).Info("GrafanaDashboard kind is not available, falling back to a config map")
+	op, err = controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		setLabels(cm)
+		cm.Labels[dashboardSidecarLabelKey] = "1"
+		cm.Data = map[string]string{dashboardConfigMapKey: data}
+		primaryresource.SetManagedBy(cm)
+		return controllerutil.SetControllerReference(obs, cm, scheme)
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed dashboard config map", "name", cm.Name, "operation", op)
+	}
+	return nil
+}
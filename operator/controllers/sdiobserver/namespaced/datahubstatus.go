@@ -0,0 +1,145 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+// getComponentStates returns the states of the components reported by the DataHub resource keyed by the
+// component name. Both a list of {name, status} objects and a map keyed by the name are understood.
+func getComponentStates(dh *unstructured.Unstructured) map[string]string {
+	states := make(map[string]string)
+	stateOf := func(component interface{}) string {
+		switch c := component.(type) {
+		case string:
+			return c
+		case map[string]interface{}:
+			for _, key := range []string{"status", "state"} {
+				if state, ok := c[key].(string); ok {
+					return state
+				}
+			}
+		}
+		return ""
+	}
+	components, _, _ := unstructured.NestedFieldNoCopy(dh.Object, "status", "components")
+	switch components := components.(type) {
+	case []interface{}:
+		for _, component := range components {
+			if c, ok := component.(map[string]interface{}); ok {
+				if name, ok := c["name"].(string); ok {
+					states[name] = stateOf(c)
+				}
+			}
+		}
+	case map[string]interface{}:
+		for name, component := range components {
+			states[name] = stateOf(component)
+		}
+	}
+	return states
+}
+
+// GetDataHubVersion returns the installed SDI version or the desired one while the installation is in progress.
+func GetDataHubVersion(dh *unstructured.Unstructured) string {
+	version, _, _ := unstructured.NestedString(dh.Object, "status", "version")
+	if len(version) == 0 {
+		version, _, _ = unstructured.NestedString(dh.Object, "spec", "version")
+	}
+	return version
+}
+
+// summarizeDataHub parses the status of the DataHub resource.
+func summarizeDataHub(dh *unstructured.Unstructured) *sdiv1alpha1.SDIObserverDataHubStatus {
+	summary := &sdiv1alpha1.SDIObserverDataHubStatus{}
+	summary.State, _, _ = unstructured.NestedString(dh.Object, "status", "status")
+	summary.Message, _, _ = unstructured.NestedString(dh.Object, "status", "message")
+	summary.Version = GetDataHubVersion(dh)
+	for name, state := range getComponentStates(dh) {
+		summary.TotalComponents++
+		if reDataHubReady.MatchString(state) {
+			summary.ReadyComponents++
+		} else {
+			summary.NotReadyComponents = append(summary.NotReadyComponents, name)
+		}
+	}
+	sort.Strings(summary.NotReadyComponents)
+	return summary
+}
+
+// setDuplicateDataHubs reports the DataHub instances ignored besides the managed one in the DuplicateDataHubs
+// condition. A warning event is emitted once they appear.
+func (r *reconciler) setDuplicateDataHubs(
+	ctx context.Context,
+	obs *sdiv1alpha1.SDIObserver,
+	dh *unstructured.Unstructured,
+	ignored []string,
+) {
+	if len(ignored) == 0 {
+		meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionDuplicateDataHubs)
+		return
+	}
+	msg := fmt.Sprintf("namespace %s contains %d DataHub resources; managing %s, ignoring %s", r.dhNamespace,
+		len(ignored)+1, dh.GetName(), strings.Join(ignored, ", "))
+	if !meta.IsStatusConditionTrue(obs.Status.Conditions, sdiv1alpha1.ConditionDuplicateDataHubs) {
+		log.FromContext(ctx).Info("found unexpected DataHub resources", "managed", dh.GetName(),
+			"ignored", ignored)
+		r.recorder.Event(obs, corev1.EventTypeWarning, sdiv1alpha1.ConditionDuplicateDataHubs, msg)
+	}
+	meta.SetStatusCondition(&obs.Status.Conditions, metav1.Condition{
+		Type:               sdiv1alpha1.ConditionDuplicateDataHubs,
+		Status:             metav1.ConditionTrue,
+		Reason:             "MultipleDataHubs",
+		Message:            msg,
+		ObservedGeneration: obs.Generation,
+	})
+}
+
+// setDataHubStatus mirrors the summary of the DataHub status into the SDIObserver status. A nil dh resets it.
+func setDataHubStatus(obs *sdiv1alpha1.SDIObserver, dh *unstructured.Unstructured) {
+	if dh == nil {
+		obs.Status.DataHub = nil
+		meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionDataHubReady)
+		// the version skew cannot be evaluated without the SDI version
+		meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionVersionSkewDetected)
+		return
+	}
+	summary := summarizeDataHub(dh)
+	obs.Status.DataHub = summary
+
+	cond := metav1.Condition{
+		Type:               sdiv1alpha1.ConditionDataHubReady,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NotReady",
+		Message:            fmt.Sprintf("the DataHub resource reports the state %q", summary.State),
+		ObservedGeneration: obs.Generation,
+	}
+	switch {
+	case len(summary.State) == 0:
+		cond.Status = metav1.ConditionUnknown
+		cond.Reason = "Unknown"
+		cond.Message = "the DataHub resource reports no state"
+	case reDataHubReady.MatchString(summary.State):
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = sdiv1alpha1.ConditionReasonAsExpected
+	case reDataHubFailure.MatchString(summary.State):
+		cond.Reason = "Failed"
+	}
+	if len(summary.NotReadyComponents) > 0 {
+		cond.Message += fmt.Sprintf(", not ready components: %v", summary.NotReadyComponents)
+	}
+	if len(summary.Message) > 0 {
+		cond.Message += ": " + summary.Message
+	}
+	meta.SetStatusCondition(&obs.Status.Conditions, cond)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -18,8 +19,18 @@ const (
 	DataHubResourceName    = "DataHubs"
 	DataHubResourceFull    = "datahubs.installers.datahub.sap.com"
 	DataHubResourceVersion = "v1alpha1"
+	DataHubResourceKind    = "DataHub"
 )
 
+// isDataHubCRDInstalled returns false if the API server does not serve the DataHub kind (yet).
+func isDataHubCRDInstalled(mapper meta.RESTMapper) bool {
+	_, err := mapper.RESTMapping(schema.GroupKind{
+		Group: DataHubResourceGroup,
+		Kind:  DataHubResourceKind,
+	}, DataHubResourceVersion)
+	return !meta.IsNoMatchError(err)
+}
+
 func MakeDataHubGVR() schema.GroupVersionResource {
 	return schema.GroupVersionResource{
 		Group:    DataHubResourceGroup,
@@ -55,7 +66,7 @@ func (dhc *dhClient) List(ctx context.Context, namespace string) ([]unstructured
 }
 
 // byDefault moves the default DataHub instance to the front of the list.
-// It is a singleton, no other instance is actually expected.
+// It is a singleton, no other instance is actually expected. The rest is ordered by name.
 type byDefault []unstructured.Unstructured
 
 func (a byDefault) Len() int      { return len(a) }
@@ -71,13 +82,28 @@ func (a byDefault) Less(i, j int) bool {
 }
 
 func (dhc *dhClient) Get(ctx context.Context, namespace string) (*unstructured.Unstructured, error) {
+	dh, _, err := getPrimaryDataHub(ctx, dhc, namespace)
+	return dh, err
+}
+
+// getPrimaryDataHub returns the DataHub instance managed in the namespace and the names of the other instances
+// which are ignored. The instance named default is preferred, otherwise the first one by name.
+func getPrimaryDataHub(
+	ctx context.Context,
+	dhc DHClient,
+	namespace string,
+) (*unstructured.Unstructured, []string, error) {
 	list, err := dhc.List(ctx, namespace)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil, errors.NewNotFound(MakeDataHubGVR().GroupResource(), "default")
 	}
 	sort.Sort(byDefault(list))
-	if len(list) > 0 {
-		return &list[0], nil
+	var ignored []string
+	for _, dh := range list[1:] {
+		ignored = append(ignored, dh.GetName())
 	}
-	return nil, errors.NewNotFound(MakeDataHubGVR().GroupResource(), "default")
+	return &list[0], ignored, nil
 }
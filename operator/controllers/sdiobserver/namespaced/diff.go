@@ -0,0 +1,226 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+// The actions of the object differences.
+const (
+	DiffActionCreate = "create"
+	DiffActionUpdate = "update"
+	DiffActionDelete = "delete"
+)
+
+const redactedValue = "<redacted>"
+
+// FieldDiff is a field of a managed object differing from the desired value. A missing value is nil.
+type FieldDiff struct {
+	Path    string      `json:"path"`
+	Live    interface{} `json:"live,omitempty"`
+	Desired interface{} `json:"desired,omitempty"`
+}
+
+// ObjectDiff is a managed object the operator would change.
+type ObjectDiff struct {
+	Action    string      `json:"action"`
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	Fields    []FieldDiff `json:"fields,omitempty"`
+}
+
+// Diff compares the managed objects with the ones the operator would generate from the current spec of the
+// SDIObserver, even if paused. Only the fields set by the operator are compared, so the fields added by the
+// API server or the other controllers do not show up. The cluster is only read.
+func Diff(
+	ctx context.Context,
+	cfg *rest.Config,
+	scheme *runtime.Scheme,
+	nmName types.NamespacedName,
+) ([]ObjectDiff, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	c, err := newReadClient(cfg, scheme)
+	if err != nil {
+		return nil, err
+	}
+	obs := &sdiv1alpha1.SDIObserver{}
+	if err := c.Get(ctx, nmName, obs); err != nil {
+		return nil, err
+	}
+	rc, err := renderObjects(ctx, cfg, scheme, c, obs)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []ObjectDiff
+	for _, key := range rc.order {
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(key.gvk)
+		err := c.Get(ctx, key.NamespacedName, live)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get %s %s: %v", strings.ToLower(key.gvk.Kind), key.NamespacedName, err)
+		}
+		d := ObjectDiff{Kind: key.gvk.Kind, Namespace: key.Namespace, Name: key.Name}
+		switch {
+		case rc.deleted[key]:
+			if err != nil {
+				continue
+			}
+			d.Action = DiffActionDelete
+		case err != nil:
+			d.Action = DiffActionCreate
+		default:
+			d.Action = DiffActionUpdate
+			d.Fields = diffFields("", live.Object, rc.objects[key].Object)
+			if len(d.Fields) == 0 {
+				continue
+			}
+			if key.gvk.Kind == "Secret" {
+				redactSecretFields(d.Fields)
+			}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// isEmpty returns true for the values equivalent to an unset field.
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return false
+}
+
+func isScalar(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	kind := reflect.TypeOf(value).Kind()
+	return kind != reflect.Map && kind != reflect.Slice
+}
+
+// diffFields returns the fields set in desired whose values differ in live. The lists are compared item by item
+// if their lengths match.
+func diffFields(path string, live, desired interface{}) []FieldDiff {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, _ := live.(map[string]interface{})
+		if l == nil && !isEmpty(live) {
+			return []FieldDiff{{Path: path, Live: live, Desired: desired}}
+		}
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var diffs []FieldDiff
+		for _, k := range keys {
+			p := k
+			if len(path) > 0 {
+				p = path + "." + k
+			}
+			diffs = append(diffs, diffFields(p, l[k], d[k])...)
+		}
+		return diffs
+	case []interface{}:
+		if l, ok := live.([]interface{}); ok && len(l) == len(d) {
+			var diffs []FieldDiff
+			for i := range d {
+				diffs = append(diffs, diffFields(fmt.Sprintf("%s[%d]", path, i), l[i], d[i])...)
+			}
+			return diffs
+		}
+	}
+	if isEmpty(desired) && isEmpty(live) {
+		return nil
+	}
+	// the numbers decoded from JSON and converted from the typed objects differ in type
+	if isScalar(live) && isScalar(desired) && fmt.Sprint(live) == fmt.Sprint(desired) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, Live: live, Desired: desired}}
+}
+
+// redactSecretFields hides the secret values.
+func redactSecretFields(fields []FieldDiff) {
+	for i := range fields {
+		if !strings.HasPrefix(fields[i].Path, "data") && !strings.HasPrefix(fields[i].Path, "stringData") {
+			continue
+		}
+		if fields[i].Live != nil {
+			fields[i].Live = redactedValue
+		}
+		if fields[i].Desired != nil {
+			fields[i].Desired = redactedValue
+		}
+	}
+}
+
+func formatDiffValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// WriteDiffText writes the differences in a human readable form.
+func WriteDiffText(w io.Writer, diffs []ObjectDiff) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintln(w, "the managed objects are up to date")
+		return err
+	}
+	for _, d := range diffs {
+		name := d.Name
+		if len(d.Namespace) > 0 {
+			name = d.Namespace + "/" + d.Name
+		}
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", d.Action, strings.ToLower(d.Kind), name); err != nil {
+			return err
+		}
+		for _, f := range d.Fields {
+			if _, err := fmt.Fprintf(w, "  %s: %s -> %s\n", f.Path, formatDiffValue(f.Live),
+				formatDiffValue(f.Desired)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteDiffJSON writes the differences as an indented JSON document.
+func WriteDiffJSON(w io.Writer, diffs []ObjectDiff) error {
+	if diffs == nil {
+		diffs = []ObjectDiff{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(diffs)
+}
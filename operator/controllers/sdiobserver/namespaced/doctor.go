@@ -0,0 +1,308 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+// The statuses of the diagnostic steps.
+const (
+	DoctorStatusOK      = "OK"
+	DoctorStatusBroken  = "Broken"
+	DoctorStatusSkipped = "Skipped"
+)
+
+// DoctorStep is a link of the chain exposing vsystem.
+type DoctorStep struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Message     string `json:"message,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// DoctorReport lists the diagnostic steps in order. The steps following the first broken one are skipped.
+type DoctorReport struct {
+	Steps []DoctorStep `json:"steps"`
+	// the first broken step, if any
+	Broken *DoctorStep `json:"broken,omitempty"`
+}
+
+// doctorState is shared by the diagnostic steps.
+type doctorState struct {
+	c         client.Reader
+	namespace string
+	route     *routev1.Route
+	pod       *corev1.Pod
+	// the destination CA secret of the route, possibly overridden by the SDIObserver
+	caSecretName string
+	caSecretKey  string
+}
+
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context, s *doctorState) (msg, remediation string, ok bool)
+}
+
+// the links of the chain in the order of their dependencies
+var doctorChecks = []doctorCheck{
+	{"route-exists", doctorRouteExists},
+	{"route-admitted", doctorRouteAdmitted},
+	{"service-endpoints", doctorServiceEndpoints},
+	{"ca-bundle", doctorCABundle},
+	{"router-to-pods", doctorRouterToPods},
+	{"certificate", doctorCertificate},
+}
+
+// Doctor follows the chain exposing the vsystem URL of the SDI namespace and reports the first broken link with
+// the remediation. The cluster is only read.
+func Doctor(ctx context.Context, c client.Reader, namespace string) *DoctorReport {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	s := &doctorState{c: c, namespace: namespace}
+	s.caSecretName, s.caSecretKey = getDestinationCASecretRef(&sdiv1alpha1.SDIObserver{})
+	observers := &sdiv1alpha1.SDIObserverList{}
+	if err := c.List(ctx, observers); err != nil {
+		tracer.V(1).Info("failed to list the SDIObservers, assuming the default destination CA secret", "error", err)
+	}
+	for i := range observers.Items {
+		if observers.Items[i].Spec.SDINamespace == namespace {
+			s.caSecretName, s.caSecretKey = getDestinationCASecretRef(&observers.Items[i])
+			break
+		}
+	}
+	report := &DoctorReport{Steps: make([]DoctorStep, len(doctorChecks))}
+	for i, check := range doctorChecks {
+		step := &report.Steps[i]
+		step.Name = check.name
+		if report.Broken != nil {
+			step.Status = DoctorStatusSkipped
+			continue
+		}
+		var ok bool
+		step.Message, step.Remediation, ok = check.run(ctx, s)
+		step.Status = DoctorStatusOK
+		if !ok {
+			step.Status = DoctorStatusBroken
+			report.Broken = step
+			tracer.Info("found a broken link", "step", step.Name, "message", step.Message)
+		}
+	}
+	return report
+}
+
+func doctorRouteExists(ctx context.Context, s *doctorState) (string, string, bool) {
+	route := &routev1.Route{}
+	err := s.c.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: "vsystem"}, route)
+	if errors.IsNotFound(err) {
+		return "the vsystem route does not exist",
+			"set spec.vsystemRoute.managementState of the SDIObserver to Managed", false
+	}
+	if err != nil {
+		return fmt.Sprintf("failed to get the vsystem route: %v", err), "verify the permissions to read routes", false
+	}
+	if len(route.Spec.Host) == 0 {
+		return "the vsystem route has no host", "set spec.vsystemRoute.hostname of the SDIObserver", false
+	}
+	s.route = route
+	return "the vsystem route exposes " + route.Spec.Host, "", true
+}
+
+func doctorRouteAdmitted(_ context.Context, s *doctorState) (string, string, bool) {
+	if isAnyRouteIngressAdmitted(s.route) {
+		return "the route is admitted by the router", "", true
+	}
+	msg := "the route has not been admitted by any router"
+	for _, ingress := range s.route.Status.Ingress {
+		if c := findRouteIngressCondition(ingress.Conditions, routev1.RouteAdmitted); c != nil &&
+			c.Status != corev1.ConditionTrue {
+			msg = fmt.Sprintf("router %s refused the route (%s): %s", ingress.RouterName, c.Reason, c.Message)
+			break
+		}
+	}
+	return msg,
+		"make sure the host is unique and matches the domain of an ingress controller selecting the namespace", false
+}
+
+func doctorServiceEndpoints(ctx context.Context, s *doctorState) (string, string, bool) {
+	name := s.route.Spec.To.Name
+	endpoints := &corev1.Endpoints{}
+	if err := s.c.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: name}, endpoints); err != nil {
+		return fmt.Sprintf("failed to get the endpoints of service %s: %v", name, err),
+			"make sure the route targets the vsystem service", false
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+				continue
+			}
+			pod := &corev1.Pod{}
+			if err := s.c.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: addr.TargetRef.Name},
+				pod); err == nil {
+				s.pod = pod
+				return fmt.Sprintf("service %s is backed by ready pod %s", name, pod.Name), "", true
+			}
+		}
+	}
+	return fmt.Sprintf("service %s has no ready endpoints", name),
+		fmt.Sprintf("check the readiness of the pods selected by service %s", name), false
+}
+
+func doctorCABundle(ctx context.Context, s *doctorState) (string, string, bool) {
+	tls := s.route.Spec.TLS
+	if tls == nil || tls.Termination != routev1.TLSTerminationReencrypt {
+		return "the route does not re-encrypt, no destination CA is needed", "", true
+	}
+	secret := &corev1.Secret{}
+	err := s.c.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: s.caSecretName}, secret)
+	remediation := "the secret is created by the SDI installation; verify the installation"
+	if s.caSecretName != vsystemCaBundleSecretName {
+		remediation = "verify spec.vsystemRoute.destinationCASecretRef of the SDIObserver"
+	}
+	if err != nil {
+		return fmt.Sprintf("failed to get secret %s: %v", s.caSecretName, err), remediation, false
+	}
+	caBundle, err := getCertFromCaBundleSecret(secret, s.caSecretKey)
+	if err != nil {
+		return err.Error(), remediation, false
+	}
+	if tls.DestinationCACertificate != caBundle {
+		return "the destination CA certificate of the route differs from secret " + s.caSecretName,
+			"let the SDIObserver manage the route or copy the secret content to spec.tls.destinationCACertificate",
+			false
+	}
+	return "the destination CA certificate matches secret " + s.caSecretName, "", true
+}
+
+// doctorRouterToPods verifies that the network policies isolating the vsystem pod admit the ingress routers.
+func doctorRouterToPods(ctx context.Context, s *doctorState) (string, string, bool) {
+	policies := &networkingv1.NetworkPolicyList{}
+	if err := s.c.List(ctx, policies, client.InNamespace(s.namespace)); err != nil {
+		return fmt.Sprintf("failed to list network policies: %v", err), "verify the permissions to read network policies",
+			false
+	}
+	isolated := false
+	for i := range policies.Items {
+		np := &policies.Items[i]
+		if !isIngressPolicy(np) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&np.Spec.PodSelector)
+		if err != nil || !selector.Matches(labels.Set(s.pod.Labels)) {
+			continue
+		}
+		isolated = true
+		if admitsIngressRouters(np) {
+			return fmt.Sprintf("network policy %s admits the ingress routers", np.Name), "", true
+		}
+	}
+	if !isolated {
+		return "no network policy isolates pod " + s.pod.Name, "", true
+	}
+	return fmt.Sprintf("the network policies isolating pod %s do not admit the ingress routers", s.pod.Name),
+		"set spec.networkPolicies.managementState of the SDIObserver to Managed or allow the namespaces " +
+			"labeled network.openshift.io/policy-group=ingress", false
+}
+
+func isIngressPolicy(np *networkingv1.NetworkPolicy) bool {
+	if len(np.Spec.PolicyTypes) == 0 {
+		return true
+	}
+	for _, t := range np.Spec.PolicyTypes {
+		if t == networkingv1.PolicyTypeIngress {
+			return true
+		}
+	}
+	return false
+}
+
+// admitsIngressRouters returns true if any ingress rule allows all the sources or the namespaces of the routers.
+func admitsIngressRouters(np *networkingv1.NetworkPolicy) bool {
+	for _, rule := range np.Spec.Ingress {
+		if len(rule.From) == 0 {
+			return true
+		}
+		for _, peer := range rule.From {
+			if peer.NamespaceSelector == nil || peer.IPBlock != nil {
+				continue
+			}
+			if peer.PodSelector != nil && len(peer.PodSelector.MatchLabels)+len(peer.PodSelector.MatchExpressions) > 0 {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(peer.NamespaceSelector)
+			if err != nil {
+				continue
+			}
+			for _, nsLabels := range ingressNamespaceSelectors {
+				if selector.Matches(labels.Set(nsLabels)) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// doctorCertificate probes the URL the same way as the periodic health probes.
+func doctorCertificate(ctx context.Context, s *doctorState) (string, string, bool) {
+	if expiry, ok := getRouteCertificateExpiry(s.route); ok && time.Now().After(expiry) {
+		return fmt.Sprintf("the certificate of the route expired at %s", expiry.UTC().Format(time.RFC3339)),
+			"renew the certificate or let cert-manager issue it via spec.vsystemRoute.certificate", false
+	}
+	proxy, err := clusterproxy.Get(ctx, s.c)
+	if err != nil {
+		log.FromContext(ctx).Info("cannot read the cluster proxy, relying on the environment", "error", err)
+	}
+	expiry, err := probeRoute(ctx, s.route, getIngressCA(ctx, s.c), proxy)
+	if err != nil {
+		return fmt.Sprintf("the vsystem URL is not reachable: %v", err),
+			"verify that the serving certificate is trusted and valid for " + s.route.Spec.Host +
+				" and that the host resolves to the ingress controller", false
+	}
+	msg := "the vsystem URL is reachable"
+	if !expiry.IsZero() {
+		msg += ", the certificate expires at " + expiry.UTC().Format(time.RFC3339)
+	}
+	return msg, "", true
+}
+
+// WriteText writes the steps as a table followed by the remediation of the broken one.
+func (r *DoctorReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tSTATUS\tMESSAGE")
+	for _, step := range r.Steps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", step.Name, step.Status, step.Message)
+	}
+	if r.Broken != nil {
+		fmt.Fprintf(tw, "\nthe first broken link is %s: %s\nremediation: %s\n", r.Broken.Name, r.Broken.Message,
+			r.Broken.Remediation)
+	} else {
+		fmt.Fprintln(tw, "\nthe vsystem URL is exposed correctly")
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *DoctorReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,163 @@
+package namespaced
+
+import (
+	"context"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+)
+
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// eventingClient emits an event on the SDIObserver for every write to a managed object so that the recent
+// actions show up in "oc describe sdiobserver". The successful writes are collected for the audit trail.
+type eventingClient struct {
+	client.Client
+	apiReader client.Reader
+	recorder  record.EventRecorder
+	obs       *sdiv1alpha1.SDIObserver
+	audit     []auditEntry
+}
+
+func newEventingClient(
+	c client.Client,
+	apiReader client.Reader,
+	recorder record.EventRecorder,
+	obs *sdiv1alpha1.SDIObserver,
+) *eventingClient {
+	return &eventingClient{Client: c, apiReader: apiReader, recorder: recorder, obs: obs}
+}
+
+// describe returns the lowercase kind and the namespaced name of the object.
+func (c *eventingClient) describe(obj client.Object) (string, string) {
+	kind := "object"
+	if gvk, gvkErr := apiutil.GVKForObject(obj, c.Scheme()); gvkErr == nil {
+		kind = strings.ToLower(gvk.Kind)
+	}
+	name := obj.GetName()
+	if len(obj.GetNamespace()) > 0 {
+		name = obj.GetNamespace() + "/" + name
+	}
+	return kind, name
+}
+
+func (c *eventingClient) record(obj client.Object, reason, verb, summary string, err error) {
+	switch obj.(type) {
+	case *sdiv1alpha1.SDIObserver, *authorizationv1.SubjectAccessReview:
+		// not a managed object
+		return
+	}
+	kind, name := c.describe(obj)
+	if err != nil {
+		c.recorder.Eventf(c.obs, corev1.EventTypeWarning, "Failed"+reason, "failed to %s %s %s: %v", verb, kind,
+			name, err)
+		return
+	}
+	if dryrun.Enabled() {
+		// nothing has changed, the event describes the change instead
+		c.recorder.Eventf(c.obs, corev1.EventTypeNormal, dryrun.ReasonDryRun, "would %s %s %s", verb, kind, name)
+		return
+	}
+	c.recorder.Eventf(c.obs, corev1.EventTypeNormal, reason, "%s %s %s", reason, kind, name)
+	c.audit = append(c.audit, auditEntry{
+		Time:      metav1.Now(),
+		Operation: verb,
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Summary:   summary,
+		Reason:    getAuditReason(),
+	})
+}
+
+// recordEvent emits an event on the SDIObserver.
+func (c *eventingClient) recordEvent(eventtype, reason, message string) {
+	c.recorder.Event(c.obs, eventtype, reason, message)
+}
+
+// recordDrift emits a warning event for the external modification of the managed object and adds it to the
+// audit trail.
+func (c *eventingClient) recordDrift(obj client.Object, fields []string) {
+	kind, name := c.describe(obj)
+	c.recorder.Eventf(c.obs, corev1.EventTypeWarning, "Drifted", "%s %s has been modified externally (%s), restoring",
+		kind, name, strings.Join(fields, ","))
+	c.audit = append(c.audit, auditEntry{
+		Time:      metav1.Now(),
+		Operation: "drift",
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Summary:   strings.Join(fields, ","),
+		Reason:    getAuditReason(),
+	})
+}
+
+func (c *eventingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	err := c.Client.Create(ctx, obj, opts...)
+	c.record(obj, "Created", "create", "", err)
+	return err
+}
+
+func (c *eventingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	// the change is summarized against the stored object; cluster-scoped kinds are not in the cache
+	summary := ""
+	if current, ok := obj.DeepCopyObject().(client.Object); ok {
+		if err := c.apiReader.Get(ctx, client.ObjectKeyFromObject(obj), current); err == nil {
+			if data, err := client.MergeFrom(current).Data(obj); err == nil {
+				summary = summarizeChange(data)
+			}
+		}
+	}
+	err := c.Client.Update(ctx, obj, opts...)
+	c.record(obj, "Updated", "update", summary, err)
+	return err
+}
+
+func (c *eventingClient) Patch(
+	ctx context.Context,
+	obj client.Object,
+	patch client.Patch,
+	opts ...client.PatchOption,
+) error {
+	summary := ""
+	if data, err := patch.Data(obj); err == nil {
+		summary = summarizeChange(data)
+	}
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	if patch.Type() == types.ApplyPatchType {
+		c.record(obj, "Applied", "apply", summary, err)
+		return err
+	}
+	c.record(obj, "Patched", "patch", summary, err)
+	return err
+}
+
+func (c *eventingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := c.Client.Delete(ctx, obj, opts...)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		// nothing to delete
+		return err
+	}
+	c.record(obj, "Deleted", "delete", "", err)
+	return err
+}
+
+// recordFailures emits a warning event for each failed step of the reconciliation.
+func recordFailures(recorder record.EventRecorder, obs *sdiv1alpha1.SDIObserver, degraded []metav1.Condition) {
+	for _, cond := range degraded {
+		if strings.HasPrefix(cond.Reason, "Failed") {
+			recorder.Event(obs, corev1.EventTypeWarning, cond.Reason, cond.Message)
+		}
+	}
+}
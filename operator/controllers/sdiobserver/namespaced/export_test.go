@@ -0,0 +1,4 @@
+package namespaced
+
+// GetPodSpec exposes getPodSpec to the tests.
+var GetPodSpec = getPodSpec
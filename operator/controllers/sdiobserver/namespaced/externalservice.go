@@ -0,0 +1,202 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	vsystemExternalServiceName = "vsystem-external"
+	defaultExternalServicePort = 443
+)
+
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+
+func isServiceExposure(spec sdiv1alpha1.SDIObserverSpecRoute) bool {
+	return spec.Exposure == sdiv1alpha1.RouteExposureLoadBalancer || spec.Exposure == sdiv1alpha1.RouteExposureNodePort
+}
+
+// getVSystemServicePort returns the port of the vsystem service the route would target.
+func getVSystemServicePort(svc *corev1.Service) *corev1.ServicePort {
+	for i, sp := range svc.Spec.Ports {
+		if sp.Name == "vsystem" || (sp.Port == vsystemPortNumber && sp.Protocol == "TCP") {
+			return &svc.Spec.Ports[i]
+		}
+	}
+	return nil
+}
+
+// mutateExternalService sets the desired spec of the Service publishing vsystem. The node port allocated by the
+// cluster is kept unless configured.
+func mutateExternalService(
+	ext *corev1.Service,
+	spec sdiv1alpha1.SDIObserverSpecRoute,
+	svc *corev1.Service,
+	vsystemPort *corev1.ServicePort,
+) {
+	var svcSpec sdiv1alpha1.SDIObserverSpecRouteService
+	if spec.Service != nil {
+		svcSpec = *spec.Service
+	}
+	port := svcSpec.Port
+	if port == 0 {
+		port = defaultExternalServicePort
+	}
+	targetPort := vsystemPort.TargetPort
+	if targetPort == (intstr.IntOrString{}) {
+		targetPort = intstr.FromInt(int(vsystemPort.Port))
+	}
+
+	svcType := corev1.ServiceTypeLoadBalancer
+	if spec.Exposure == sdiv1alpha1.RouteExposureNodePort {
+		svcType = corev1.ServiceTypeNodePort
+	}
+	nodePort := svcSpec.NodePort
+	if nodePort == 0 && len(ext.Spec.Ports) == 1 && ext.Spec.Ports[0].Port == port {
+		nodePort = ext.Spec.Ports[0].NodePort
+	}
+
+	if ext.Annotations == nil {
+		ext.Annotations = make(map[string]string)
+	}
+	for key, value := range svcSpec.Annotations {
+		ext.Annotations[key] = value
+	}
+	// labeled like the vsystem service to be covered by its watch and cache selectors
+	ext.Labels = getRouteLabelsForVsystemService(svc)
+	ext.Spec.Type = svcType
+	ext.Spec.Selector = svc.Spec.Selector
+	ext.Spec.Ports = []corev1.ServicePort{{
+		Name:       "vsystem",
+		Protocol:   corev1.ProtocolTCP,
+		Port:       port,
+		TargetPort: targetPort,
+		NodePort:   nodePort,
+	}}
+	if svcType == corev1.ServiceTypeLoadBalancer {
+		ext.Spec.LoadBalancerSourceRanges = svcSpec.LoadBalancerSourceRanges
+	} else {
+		ext.Spec.LoadBalancerSourceRanges = nil
+	}
+}
+
+// manageVSystemExternalService publishes the vsystem service with a Service of the LoadBalancer or NodePort type
+// and removes the vsystem route. The address of the Service is recorded in the route status.
+func manageVSystemExternalService(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	svc *corev1.Service,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := owner.Spec.VSystemRoute
+	vsystemPort := getVSystemServicePort(svc)
+	if vsystemPort == nil {
+		err := fmt.Errorf("the vsystem service does not expose the vsystem port")
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+			"InvalidService", err.Error())
+		return err
+	}
+
+	route := &routev1.Route{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: svc.Name}, route)
+	if err == nil {
+		tracer.Info("deleting vsystem route replaced by the " + spec.Exposure + " service")
+		err = c.Delete(ctx, route)
+	}
+	// the exposure does not need the route API
+	if err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+			"FailedDelete", fmt.Sprintf("failed to delete vsystem route: %v", err))
+		return err
+	}
+
+	ext := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: vsystemExternalServiceName}}
+	op, err := controllerutil.CreateOrUpdate(ctx, c, ext, func() error {
+		mutateExternalService(ext, spec, svc, vsystemPort)
+		primaryresource.Set(ext, owner, "SDIObserver")
+		return nil
+	})
+	if err != nil {
+		tracer.Error(err, "failed to manage vsystem external service")
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+			"FailedService", fmt.Sprintf("failed to manage vsystem %s service: %v", spec.Exposure, err))
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed vsystem external service", "name", ext.Name, "type", ext.Spec.Type, "operation", op)
+	}
+
+	status := &sdiv1alpha1.SDIObserverRouteServiceStatus{Name: ext.Name, Type: ext.Spec.Type}
+	if len(ext.Spec.Ports) > 0 {
+		status.Port = ext.Spec.Ports[0].Port
+		status.NodePort = ext.Spec.Ports[0].NodePort
+	}
+	if ext.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range ext.Status.LoadBalancer.Ingress {
+			if len(ingress.Hostname) > 0 {
+				status.Hosts = append(status.Hosts, ingress.Hostname)
+			} else if len(ingress.IP) > 0 {
+				status.Hosts = append(status.Hosts, ingress.IP)
+			}
+		}
+	}
+	owner.Status.VSystemRoute.Service = status
+
+	if ext.Spec.Type == corev1.ServiceTypeLoadBalancer && len(status.Hosts) == 0 {
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionFalse,
+			"WaitingForLoadBalancer", "the load balancer of the vsystem service is not provisioned yet")
+		return nil
+	}
+	setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionTrue, metav1.ConditionFalse,
+		"ServiceExposed", fmt.Sprintf("vsystem is published with the %s service %s", ext.Spec.Type, ext.Name))
+	return nil
+}
+
+// deleteVSystemExternalService removes the Service created for the loadBalancer and nodePort exposures.
+func deleteVSystemExternalService(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	owner.Status.VSystemRoute.Service = nil
+	ext := &corev1.Service{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: vsystemExternalServiceName}, ext)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !primaryresource.IsOwnedBy(ext, owner, "SDIObserver") {
+		return nil
+	}
+	tracer.Info("deleting vsystem external service", "name", ext.Name)
+	if err := c.Delete(ctx, ext); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
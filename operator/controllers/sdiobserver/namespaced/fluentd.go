@@ -0,0 +1,126 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+)
+
+const (
+	fluentdDaemonSetName  = "diagnostics-fluentd"
+	fluentdContainerName  = "diagnostics-fluentd"
+	fluentdDockerVolume   = "varlibdockercontainers"
+	fluentdDockerHostPath = "/var/lib/docker"
+)
+
+// isFluentdDockerVolume returns true for the volumes of the Docker log directory absent on the CRI-O nodes.
+func isFluentdDockerVolume(v *corev1.Volume) bool {
+	if v.Name == fluentdDockerVolume {
+		return true
+	}
+	return v.HostPath != nil && strings.HasPrefix(v.HostPath.Path, fluentdDockerHostPath)
+}
+
+// patchFluentd removes the Docker log volumes and makes the fluentd container privileged to read the CRI-O logs.
+func patchFluentd(podSpec *corev1.PodSpec) {
+	removed := map[string]struct{}{}
+	volumes := podSpec.Volumes[:0]
+	for i := range podSpec.Volumes {
+		if isFluentdDockerVolume(&podSpec.Volumes[i]) {
+			removed[podSpec.Volumes[i].Name] = struct{}{}
+			continue
+		}
+		volumes = append(volumes, podSpec.Volumes[i])
+	}
+	podSpec.Volumes = volumes
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		mounts := container.VolumeMounts[:0]
+		for _, m := range container.VolumeMounts {
+			if _, ok := removed[m.Name]; !ok {
+				mounts = append(mounts, m)
+			}
+		}
+		container.VolumeMounts = mounts
+		if container.Name == fluentdContainerName {
+			if container.SecurityContext == nil {
+				container.SecurityContext = &corev1.SecurityContext{}
+			}
+			privileged := true
+			container.SecurityContext.Privileged = &privileged
+		}
+	}
+}
+
+// isFluentdPatched returns true if the pod template needs no patching.
+func isFluentdPatched(podSpec *corev1.PodSpec) bool {
+	for i := range podSpec.Volumes {
+		if isFluentdDockerVolume(&podSpec.Volumes[i]) {
+			return false
+		}
+	}
+	for _, c := range podSpec.Containers {
+		if c.Name == fluentdContainerName {
+			return c.SecurityContext != nil && c.SecurityContext.Privileged != nil && *c.SecurityContext.Privileged
+		}
+	}
+	return true
+}
+
+// isFluentdManaged returns true if the operator patches the fluentd daemonset of the SDI release.
+func isFluentdManaged(obs *sdiv1alpha1.SDIObserver) bool {
+	return reManaged.MatchString(obs.Spec.Diagnostics.Fluentd) && getProfile(obs).patchFluentd
+}
+
+// manageFluentd patches the diagnostics-fluentd DaemonSet to collect the logs on the CRI-O nodes. The returned
+// changes are deferred until the maintenance window.
+func manageFluentd(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if !isFluentdManaged(obs) {
+		tracer.V(2).Info("diagnostics-fluentd is not managed", "profile", getProfile(obs).name)
+		return nil, maintenance.Result{}, nil
+	}
+
+	ds := &appsv1.DaemonSet{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: fluentdDaemonSetName}, ds); err != nil {
+		if errors.IsNotFound(err) {
+			tracer.V(2).Info("diagnostics-fluentd daemonset not found")
+			return nil, maintenance.Result{}, nil
+		}
+		return nil, maintenance.Result{}, err
+	}
+	op, window, err := maintenance.CreateOrUpdate(ctx, c, obs.Namespace, ds, func() error {
+		patchFluentd(&ds.Spec.Template.Spec)
+		return nil
+	}, false)
+	if err != nil {
+		return nil, window, fmt.Errorf("failed to patch daemonset %s: %v", fluentdDaemonSetName, err)
+	}
+	if op == maintenance.OperationResultDeferred {
+		return []string{"the log volumes of daemonset " + fluentdDaemonSetName}, window, nil
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("patched diagnostics-fluentd", "operation", op)
+	}
+	return nil, window, nil
+}
@@ -0,0 +1,60 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/insights"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+// manageInsightsReport keeps the summary of the consolidated status in the annotation of the managed DataHub.
+// The annotation is merge-patched so that the fields applied by the operator (e.g. the kaniko flag) are not
+// released.
+func manageInsightsReport(ctx context.Context, c client.Client, obs *sdiv1alpha1.SDIObserver) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	state := obs.Spec.Insights.ManagementState
+	ref := obs.Status.ManagedDataHubRef
+	if ref == nil || reExplicitlyUnmanaged.MatchString(state) {
+		return nil
+	}
+
+	dh := &unstructured.Unstructured{}
+	dh.SetAPIVersion(DataHubResourceGroup + "/" + DataHubResourceVersion)
+	dh.SetKind(DataHubResourceKind)
+	if err := c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, dh); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	current, annotated := dh.GetAnnotations()[insights.AnnotationKey]
+
+	var desired *string
+	if !reRemoved.MatchString(state) {
+		value, err := insights.NewReport(obs).Encode()
+		if err != nil {
+			return err
+		}
+		desired = &value
+	}
+	if (desired == nil && !annotated) || (desired != nil && annotated && current == *desired) {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{insights.AnnotationKey: desired},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	tracer.V(1).Info("updating the insights report", "datahub", dh.GetName(), "removed", desired == nil)
+	return c.Patch(ctx, dh, client.RawPatch(types.MergePatchType, patch))
+}
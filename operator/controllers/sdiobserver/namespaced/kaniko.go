@@ -0,0 +1,63 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+// the DataHub field set by the installer's "Enable Kaniko Usage" option
+var dataHubKanikoField = []string{"spec", "vflow", "enableKaniko"}
+
+//+kubebuilder:rbac:groups=installers.datahub.sap.com,resources=datahubs,verbs=get;list;watch;update;patch
+
+// isKanikoEnabled returns true if the Pipeline Modeler of the DataHub builds the images with kaniko. The
+// profile decides whether an unset field enables it.
+func isKanikoEnabled(dh *unstructured.Unstructured, profile *patchProfile) bool {
+	enabled, found, err := unstructured.NestedBool(dh.Object, dataHubKanikoField...)
+	if err != nil {
+		return false
+	}
+	return enabled || (!found && profile.kanikoByDefault)
+}
+
+// manageKaniko verifies that the Pipeline Modeler builds the images with kaniko and enables it if Managed. It
+// returns false if kaniko is (still) disabled.
+func manageKaniko(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	dh *unstructured.Unstructured,
+) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	state := obs.Spec.PipelineModeler.Kaniko
+	if reExplicitlyUnmanaged.MatchString(state) || isKanikoEnabled(dh, getProfile(obs)) {
+		return true, nil
+	}
+	if !reManaged.MatchString(state) {
+		tracer.Info("kaniko is not enabled for the Pipeline Modeler", "datahub", dh.GetName())
+		return false, nil
+	}
+
+	// apply just the kaniko flag so that the rest of the DataHub stays owned by the SAP installer
+	patched := &unstructured.Unstructured{}
+	patched.SetGroupVersionKind(dh.GroupVersionKind())
+	patched.SetNamespace(dh.GetNamespace())
+	patched.SetName(dh.GetName())
+	if err := unstructured.SetNestedField(patched.Object, true, dataHubKanikoField...); err != nil {
+		return false, err
+	}
+	tracer.Info("enabling kaniko for the Pipeline Modeler", "datahub", dh.GetName())
+	if err := applyObject(ctx, c, patched); err != nil {
+		return false, fmt.Errorf("failed to enable kaniko in datahub %s: %v", dh.GetName(), err)
+	}
+	return true, nil
+}
@@ -0,0 +1,389 @@
+package namespaced
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/sdiobservers"
+)
+
+const (
+	metricsNamespace  = "sdi_observer"
+	sdiNamespaceLabel = "sdi_namespace"
+	routeLabel        = "route"
+	tenantLabel       = "tenant"
+	applicationLabel  = "application"
+	componentLabel    = "component"
+	outcomeLabel      = "outcome"
+
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+var (
+	vsystemRouteCertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "vsystem_route_certificate_expiry_timestamp_seconds",
+		Help: "Expiration time of the vsystem route certificate. The destination CA certificate is used for " +
+			"routes without a custom certificate.",
+	}, []string{sdiNamespaceLabel})
+	vsystemRouteExposed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "vsystem_route_exposed",
+		Help:      "Whether the managed vsystem route is admitted (1) or not (0).",
+	}, []string{sdiNamespaceLabel})
+	dataHubDegraded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "datahub_degraded",
+		Help:      "Whether the DataHub resource reports a failure (1) or not (0).",
+	}, []string{sdiNamespaceLabel})
+	fluentdPatchDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "fluentd_patch_drift",
+		Help:      "Whether the diagnostics fluentd daemonset differs from the desired patched state (1) or not (0).",
+	}, []string{sdiNamespaceLabel})
+	vsystemRouteAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "vsystem_route_available",
+		Help:      "Whether the last probe of the vsystem route endpoint succeeded (1) or not (0).",
+	}, []string{sdiNamespaceLabel})
+	dataHubReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "datahub_ready",
+		Help:      "Whether the DataHub resource reports the Ready status (1) or not (0).",
+	}, []string{sdiNamespaceLabel})
+	caBundleSecretAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "cabundle_secret_age_seconds",
+		Help:      "Age of the secret holding the CA bundle of vsystem.",
+	}, []string{sdiNamespaceLabel})
+	routeServingCertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "route_serving_certificate_expiry_timestamp_seconds",
+		Help:      "Expiration time of the serving certificate presented by the route endpoint during the last probe.",
+	}, []string{sdiNamespaceLabel, routeLabel})
+	destinationCACertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "destination_ca_certificate_expiry_timestamp_seconds",
+		Help:      "Expiration time of the vsystem CA bundle used as the destination CA of the vsystem route.",
+	}, []string{sdiNamespaceLabel})
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of failed steps of the reconciliation of the SDI namespace.",
+	}, []string{sdiNamespaceLabel})
+	vsystemTenantAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "vsystem_tenant_available",
+		Help:      "Whether the tenant user could log in to vsystem during the last check (1) or not (0).",
+	}, []string{sdiNamespaceLabel, tenantLabel})
+	vsystemApplicationHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "vsystem_application_healthy",
+		Help:      "Whether the vsystem application responded without a server error (1) or not (0).",
+	}, []string{sdiNamespaceLabel, applicationLabel})
+	componentReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "component_reconcile_duration_seconds",
+		Help:      "Duration of the reconciliation of a single managed component.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+	}, []string{sdiNamespaceLabel, componentLabel})
+	componentReconciles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "component_reconcile_total",
+		Help:      "Number of the reconciliations of a single managed component by their outcome.",
+	}, []string{sdiNamespaceLabel, componentLabel, outcomeLabel})
+	managedObjectDrifts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "managed_object_drift_total",
+		Help:      "Number of the external modifications of the objects of a managed component reverted by the operator.",
+	}, []string{sdiNamespaceLabel, componentLabel})
+)
+
+// The managed components whose reconciliation is measured.
+const (
+	componentVSystemRoute            = "vsystemRoute"
+	componentVSystemRouteProbe       = "vsystemRouteProbe"
+	componentSLCBRouteProbe          = "slcbRouteProbe"
+	componentDiagnosticsGrafanaRoute = "diagnosticsGrafanaRoute"
+	componentDiagnosticsKibanaRoute  = "diagnosticsKibanaRoute"
+	componentVoraToolsRoute          = "voraToolsRoute"
+	componentAlerts                  = "alerts"
+	componentDashboard               = "dashboard"
+	componentNetworkPolicies         = "networkPolicies"
+	componentProxyInjection          = "proxyInjection"
+	componentVRep                    = "vRep"
+	componentScheduling              = "scheduling"
+	componentPriorityClass           = "priorityClass"
+	componentResourceOverrides       = "resourceOverrides"
+	componentNodeTuning              = "nodeTuning"
+	componentNodeConfig              = "nodeConfig"
+	componentCompliance              = "compliance"
+	componentSCC                     = "scc"
+	componentPodSecurity             = "podSecurity"
+	componentSCCReport               = "sccReport"
+	componentPullSecret              = "pullSecret"
+	componentServiceAccountTokens    = "serviceAccountTokens"
+	componentKaniko                  = "kaniko"
+	componentFluentd                 = "fluentd"
+	componentBackupHooks             = "backupHooks"
+	componentBackup                  = "backup"
+	componentPermissions             = "permissions"
+)
+
+var components = []string{
+	componentVSystemRoute,
+	componentVSystemRouteProbe,
+	componentSLCBRouteProbe,
+	componentDiagnosticsGrafanaRoute,
+	componentDiagnosticsKibanaRoute,
+	componentVoraToolsRoute,
+	componentAlerts,
+	componentDashboard,
+	componentNetworkPolicies,
+	componentProxyInjection,
+	componentVRep,
+	componentScheduling,
+	componentPriorityClass,
+	componentResourceOverrides,
+	componentNodeTuning,
+	componentNodeConfig,
+	componentSCC,
+	componentPullSecret,
+	componentKaniko,
+}
+
+func init() {
+	metrics.Registry.MustRegister(
+		vsystemRouteCertificateExpiry,
+		vsystemRouteExposed,
+		dataHubDegraded,
+		fluentdPatchDrift,
+		vsystemRouteAvailable,
+		dataHubReady,
+		caBundleSecretAge,
+		reconcileErrors,
+		routeServingCertificateExpiry,
+		destinationCACertificateExpiry,
+		vsystemTenantAvailable,
+		vsystemApplicationHealthy,
+		componentReconcileDuration,
+		componentReconciles,
+		managedObjectDrifts,
+	)
+}
+
+var reDataHubFailure = regexp.MustCompile(`(?i)fail|error|degraded`)
+var reDataHubReady = regexp.MustCompile(`^(?i)ready$`)
+
+// recordMetrics updates the gauges backing the generated alerts for the given SDI namespace.
+func recordMetrics(
+	ctx context.Context,
+	client client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	dh *unstructured.Unstructured,
+	namespace string,
+) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	labels := prometheus.Labels{sdiNamespaceLabel: namespace}
+
+	if sdiobservers.IsRouteInCondition(obs.Status.VSystemRoute, "Exposed") {
+		vsystemRouteExposed.With(labels).Set(1)
+	} else {
+		vsystemRouteExposed.With(labels).Set(0)
+	}
+
+	route := &routev1.Route{}
+	err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "vsystem"}, route)
+	if expiry, ok := getRouteCertificateExpiry(route); err == nil && ok {
+		vsystemRouteCertificateExpiry.With(labels).Set(float64(expiry.Unix()))
+	} else {
+		vsystemRouteCertificateExpiry.Delete(labels)
+	}
+
+	status, _, _ := unstructured.NestedString(dh.Object, "status", "status")
+	if reDataHubFailure.MatchString(status) {
+		dataHubDegraded.With(labels).Set(1)
+	} else {
+		dataHubDegraded.With(labels).Set(0)
+	}
+	if reDataHubReady.MatchString(status) {
+		dataHubReady.With(labels).Set(1)
+	} else {
+		dataHubReady.With(labels).Set(0)
+	}
+
+	caSecretName, caSecretKey := getDestinationCASecretRef(obs)
+	secret := &corev1.Secret{}
+	err = client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: caSecretName}, secret)
+	if err == nil {
+		caBundleSecretAge.With(labels).Set(time.Since(secret.CreationTimestamp.Time).Seconds())
+	} else {
+		caBundleSecretAge.Delete(labels)
+	}
+	if expiry, ok := getCertificateExpiry(secret.Data[caSecretKey]); err == nil && ok {
+		destinationCACertificateExpiry.With(labels).Set(float64(expiry.Unix()))
+	} else {
+		destinationCACertificateExpiry.Delete(labels)
+	}
+
+	ds := &appsv1.DaemonSet{}
+	if !isFluentdManaged(obs) {
+		fluentdPatchDrift.With(labels).Set(0)
+	} else if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: fluentdDaemonSetName}, ds); err != nil {
+		fluentdPatchDrift.Delete(labels)
+	} else if isFluentdPatched(&ds.Spec.Template.Spec) {
+		fluentdPatchDrift.With(labels).Set(0)
+	} else {
+		fluentdPatchDrift.With(labels).Set(1)
+	}
+}
+
+// recordRouteAvailability updates the gauge of the vsystem route from the result of the last probe.
+func recordRouteAvailability(obs *sdiv1alpha1.SDIObserver, namespace string) {
+	labels := prometheus.Labels{sdiNamespaceLabel: namespace}
+	if sdiobservers.IsRouteInCondition(obs.Status.VSystemRoute, "Reachable") {
+		vsystemRouteAvailable.With(labels).Set(1)
+	} else {
+		vsystemRouteAvailable.With(labels).Set(0)
+	}
+}
+
+// recordServingCertificateExpiry updates the gauge of the serving certificate of the route. A zero expiry
+// removes the gauge.
+func recordServingCertificateExpiry(namespace, route string, expiry time.Time) {
+	labels := prometheus.Labels{sdiNamespaceLabel: namespace, routeLabel: route}
+	if expiry.IsZero() {
+		routeServingCertificateExpiry.Delete(labels)
+		return
+	}
+	routeServingCertificateExpiry.With(labels).Set(float64(expiry.Unix()))
+}
+
+// countReconcileErrors increments the error counter of the SDI namespace by the number of the failed steps.
+func countReconcileErrors(namespace string, degraded []metav1.Condition, err error) {
+	failed := 0
+	for _, cond := range degraded {
+		if strings.HasPrefix(cond.Reason, "Failed") {
+			failed++
+		}
+	}
+	if err != nil {
+		failed++
+	}
+	if failed > 0 {
+		reconcileErrors.With(prometheus.Labels{sdiNamespaceLabel: namespace}).Add(float64(failed))
+	}
+}
+
+// forgetMetrics removes the gauges of an SDI namespace that is no longer managed.
+func forgetMetrics(namespace string) {
+	labels := prometheus.Labels{sdiNamespaceLabel: namespace}
+	for _, g := range []*prometheus.GaugeVec{
+		vsystemRouteCertificateExpiry,
+		vsystemRouteExposed,
+		dataHubDegraded,
+		fluentdPatchDrift,
+		vsystemRouteAvailable,
+		dataHubReady,
+		caBundleSecretAge,
+		destinationCACertificateExpiry,
+	} {
+		g.Delete(labels)
+	}
+	for _, route := range []string{"vsystem", "slcb"} {
+		routeServingCertificateExpiry.Delete(prometheus.Labels{sdiNamespaceLabel: namespace, routeLabel: route})
+	}
+	reconcileErrors.Delete(labels)
+	for _, component := range components {
+		componentReconcileDuration.Delete(prometheus.Labels{sdiNamespaceLabel: namespace, componentLabel: component})
+		managedObjectDrifts.Delete(prometheus.Labels{sdiNamespaceLabel: namespace, componentLabel: component})
+		for _, outcome := range []string{outcomeSuccess, outcomeError} {
+			componentReconciles.Delete(prometheus.Labels{
+				sdiNamespaceLabel: namespace,
+				componentLabel:    component,
+				outcomeLabel:      outcome,
+			})
+		}
+	}
+}
+
+// observeComponent records the duration and the outcome of the reconciliation of the component.
+func observeComponent(namespace, component string, start time.Time, err error) {
+	componentReconcileDuration.With(prometheus.Labels{sdiNamespaceLabel: namespace, componentLabel: component}).
+		Observe(time.Since(start).Seconds())
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeError
+	}
+	componentReconciles.With(prometheus.Labels{
+		sdiNamespaceLabel: namespace,
+		componentLabel:    component,
+		outcomeLabel:      outcome,
+	}).Inc()
+}
+
+// measureComponent runs the reconciliation step of the component and records its duration and outcome.
+func measureComponent(namespace, component string, step func() error) error {
+	start := time.Now()
+	err := step()
+	observeComponent(namespace, component, start, err)
+	return err
+}
+
+// getRouteCertificateExpiry returns the earliest expiration time of the route's certificate or of its
+// destination CA certificates if no custom certificate is set.
+func getRouteCertificateExpiry(route *routev1.Route) (time.Time, bool) {
+	if route.Spec.TLS == nil {
+		return time.Time{}, false
+	}
+	data := route.Spec.TLS.Certificate
+	if len(data) == 0 {
+		data = route.Spec.TLS.DestinationCACertificate
+	}
+	return getCertificateExpiry([]byte(data))
+}
+
+// getCertificateExpiry returns the earliest expiration time of the PEM encoded certificates.
+func getCertificateExpiry(data []byte) (time.Time, bool) {
+	var earliest time.Time
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	return earliest, !earliest.IsZero()
+}
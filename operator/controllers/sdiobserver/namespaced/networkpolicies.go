@@ -0,0 +1,196 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const networkPolicyPrefix = "sdi-observer-"
+
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+
+var (
+	// namespaces of the ingress routers are labeled differently with OpenShift SDN and OVN-Kubernetes
+	ingressNamespaceSelectors = []map[string]string{
+		{"network.openshift.io/policy-group": "ingress"},
+		{"policy-group.network.openshift.io/ingress": ""},
+	}
+	dnsNamespaceSelector = map[string]string{"kubernetes.io/metadata.name": "openshift-dns"}
+)
+
+func makeIngressPeers() []networkingv1.NetworkPolicyPeer {
+	peers := make([]networkingv1.NetworkPolicyPeer, 0, len(ingressNamespaceSelectors))
+	for _, labels := range ingressNamespaceSelectors {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: labels},
+		})
+	}
+	return peers
+}
+
+// makeNetworkPolicies returns the desired policies for the given namespace. The routedPods select the pods
+// exposed via routes. Peer namespaces are allowed to access all the pods.
+func makeNetworkPolicies(
+	spec sdiv1alpha1.SDIObserverSpecNetworkPolicies,
+	namespace string,
+	routedPods map[string]string,
+	peerNamespaces []string,
+) []*networkingv1.NetworkPolicy {
+	sameNamespace := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	fromPeers := append([]networkingv1.NetworkPolicyPeer{}, sameNamespace...)
+	for _, ns := range peerNamespaces {
+		fromPeers = append(fromPeers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"kubernetes.io/metadata.name": ns},
+			},
+		})
+	}
+
+	newPolicy := func(
+		name string,
+		podSelector map[string]string,
+		spec networkingv1.NetworkPolicySpec,
+	) *networkingv1.NetworkPolicy {
+		spec.PodSelector = metav1.LabelSelector{MatchLabels: podSelector}
+		return &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: networkPolicyPrefix + name},
+			Spec:       spec,
+		}
+	}
+
+	policies := []*networkingv1.NetworkPolicy{
+		newPolicy("default-deny", nil, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		}),
+		newPolicy("allow-same-namespace", nil, networkingv1.NetworkPolicySpec{
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: fromPeers}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		}),
+		newPolicy("allow-from-ingress", routedPods, networkingv1.NetworkPolicySpec{
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: makeIngressPeers()}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		}),
+	}
+	if !spec.RestrictEgress {
+		return policies
+	}
+
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	var dnsPorts []networkingv1.NetworkPolicyPort
+	for _, port := range []int{53, 5353} {
+		p := intstr.FromInt(port)
+		dnsPorts = append(dnsPorts,
+			networkingv1.NetworkPolicyPort{Protocol: &udp, Port: &p},
+			networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &p})
+	}
+	return append(policies,
+		newPolicy("default-deny-egress", nil, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		}),
+		newPolicy("allow-egress-same-namespace", nil, networkingv1.NetworkPolicySpec{
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{To: sameNamespace}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		}),
+		newPolicy("allow-egress-dns", nil, networkingv1.NetworkPolicySpec{
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: dnsNamespaceSelector},
+				}},
+				Ports: dnsPorts,
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		}),
+	)
+}
+
+// manageNetworkPolicies maintains the NetworkPolicies in the SDI and SLCB namespaces.
+func manageNetworkPolicies(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.NetworkPolicies
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("network policies are not managed")
+		return nil
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+
+	type target struct {
+		namespace  string
+		routedPods map[string]string
+		peers      []string
+	}
+	targets := []target{{
+		namespace: namespace,
+		routedPods: map[string]string{
+			"datahub.sap.com/app-component": "vsystem",
+			"datahub.sap.com/app":           "vsystem",
+		},
+	}}
+	if slcbNamespace := obs.Spec.SLCBNamespace; len(slcbNamespace) > 0 && slcbNamespace != namespace {
+		// the bridge manages the SDI installation
+		targets[0].peers = []string{slcbNamespace}
+		targets = append(targets, target{
+			namespace:  slcbNamespace,
+			routedPods: map[string]string{"app": "slcbridge"},
+		})
+	}
+
+	for _, t := range targets {
+		var desired []*networkingv1.NetworkPolicy
+		if !removed {
+			desired = makeNetworkPolicies(spec, t.namespace, t.routedPods, t.peers)
+		}
+		keep := make(map[string]struct{}, len(desired))
+		for _, np := range desired {
+			np := np
+			keep[np.Name] = struct{}{}
+			policySpec := np.Spec
+			op, err := controllerutil.CreateOrUpdate(ctx, c, np, func() error {
+				primaryresource.Set(np, obs, "SDIObserver")
+				np.Spec = policySpec
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to manage network policy %s/%s: %v", np.Namespace, np.Name, err)
+			}
+			if op != controllerutil.OperationResultNone {
+				tracer.Info("managed network policy", "namespace", np.Namespace, "name", np.Name, "operation", op)
+			}
+		}
+
+		existing := &networkingv1.NetworkPolicyList{}
+		if err := c.List(ctx, existing, client.InNamespace(t.namespace)); err != nil {
+			return fmt.Errorf("failed to list network policies in namespace %s: %v", t.namespace, err)
+		}
+		for i := range existing.Items {
+			np := &existing.Items[i]
+			if _, ok := keep[np.Name]; ok || !primaryresource.IsOwnedBy(np, obs, "SDIObserver") {
+				continue
+			}
+			tracer.Info("deleting network policy", "namespace", np.Namespace, "name", np.Name)
+			if err := c.Delete(ctx, np); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete network policy %s/%s: %v", np.Namespace, np.Name, err)
+			}
+		}
+	}
+	return nil
+}
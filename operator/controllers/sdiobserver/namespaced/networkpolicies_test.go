@@ -0,0 +1,269 @@
+package namespaced_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	testapi "github.com/redhat-sap/sap-data-intelligence/operator/test/api"
+	ωbs "github.com/redhat-sap/sap-data-intelligence/operator/test/sdiobservers"
+)
+
+var _ = Describe("Network policies of the SDI namespace", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+
+		// the namespaces cannot be deleted in the test environment
+		slcbNamespace = "sap-slcbridge-np"
+	)
+
+	ingressPeers := []networkingv1.NetworkPolicyPeer{
+		{NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"network.openshift.io/policy-group": "ingress"}}},
+		{NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"policy-group.network.openshift.io/ingress": ""}}},
+	}
+	sameNamespace := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{}}
+
+	createObs := func(spec sdiv1alpha1.SDIObserverSpecNetworkPolicies) *sdiv1alpha1.SDIObserver {
+		obs := &sdiv1alpha1.SDIObserver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sdi",
+				Namespace: "sdi-observer",
+			},
+			Spec: sdiv1alpha1.SDIObserverSpec{
+				SDINamespace: "sdi",
+				VSystemRoute: sdiv1alpha1.SDIObserverSpecRoute{
+					ManagementState: sdiv1alpha1.RouteManagementStateUnmanaged,
+				},
+				NetworkPolicies: spec,
+			},
+		}
+		Ω(k8sClient.Create(context.Background(), obs)).NotTo(HaveOccurred())
+		// the controller does not watch the SDIObserver resource on its own
+		nmCtrl.ReconcileObs(obs)
+		return obs
+	}
+
+	// waitForPolicies waits until exactly the given policies are present in the namespace and returns them
+	waitForPolicies := func(namespace string, names ...string) map[string]*networkingv1.NetworkPolicy {
+		policies := make(map[string]*networkingv1.NetworkPolicy)
+		EventuallyWithOffset(1, func(g Gomega) {
+			list := &networkingv1.NetworkPolicyList{}
+			g.Ω(k8sClient.List(context.TODO(), list, client.InNamespace(namespace))).NotTo(HaveOccurred())
+			present := make([]string, 0, len(list.Items))
+			for i := range list.Items {
+				present = append(present, list.Items[i].Name)
+				policies[list.Items[i].Name] = &list.Items[i]
+			}
+			g.Ω(present).To(ConsistOf(names))
+		}, timeout, interval).Should(Succeed())
+		return policies
+	}
+
+	AfterEach(func() {
+		err := k8sClient.Delete(context.Background(), &sdiv1alpha1.SDIObserver{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "sdi-observer", Name: "sdi"},
+		})
+		Ω(err).Should(Or(BeNil(), testapi.FailWithStatus(metav1.StatusReasonNotFound)))
+		for _, namespace := range []string{"sdi", slcbNamespace} {
+			Ω(client.IgnoreNotFound(k8sClient.DeleteAllOf(context.Background(), &networkingv1.NetworkPolicy{},
+				client.InNamespace(namespace)))).NotTo(HaveOccurred())
+		}
+	})
+
+	Context("When the network policies are Managed", func() {
+		It("Should isolate the namespace", func() {
+			obs := createObs(sdiv1alpha1.SDIObserverSpecNetworkPolicies{
+				ManagementState: sdiv1alpha1.RouteManagementStateManaged,
+			})
+			policies := waitForPolicies("sdi",
+				"sdi-observer-default-deny",
+				"sdi-observer-allow-same-namespace",
+				"sdi-observer-allow-from-ingress")
+			for _, np := range policies {
+				Ω(np.Annotations).To(SatisfyAll(
+					HaveKeyWithValue("operator-sdk/primary-resource-type", "SDIObserver.di.sap-cop.redhat.com"),
+					HaveKeyWithValue("operator-sdk/primary-resource", "sdi-observer/sdi")))
+				Ω(np.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeIngress}))
+			}
+
+			np := policies["sdi-observer-default-deny"]
+			Ω(np.Spec.PodSelector).To(Equal(metav1.LabelSelector{}))
+			Ω(np.Spec.Ingress).To(BeEmpty())
+
+			np = policies["sdi-observer-allow-same-namespace"]
+			Ω(np.Spec.PodSelector).To(Equal(metav1.LabelSelector{}))
+			Ω(np.Spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{
+				{From: []networkingv1.NetworkPolicyPeer{sameNamespace}},
+			}))
+
+			np = policies["sdi-observer-allow-from-ingress"]
+			Ω(np.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{
+				"datahub.sap.com/app-component": "vsystem",
+				"datahub.sap.com/app":           "vsystem",
+			}))
+			Ω(np.Spec.Ingress).To(Equal([]networkingv1.NetworkPolicyIngressRule{{From: ingressPeers}}))
+
+			By("restricting the egress")
+			ωbs.Update(k8sClient, obs, func(obs *sdiv1alpha1.SDIObserver) {
+				obs.Spec.NetworkPolicies.RestrictEgress = true
+			})
+			nmCtrl.ReconcileObs(obs)
+			policies = waitForPolicies("sdi",
+				"sdi-observer-default-deny",
+				"sdi-observer-allow-same-namespace",
+				"sdi-observer-allow-from-ingress",
+				"sdi-observer-default-deny-egress",
+				"sdi-observer-allow-egress-same-namespace",
+				"sdi-observer-allow-egress-dns")
+
+			np = policies["sdi-observer-default-deny-egress"]
+			Ω(np.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeEgress}))
+			Ω(np.Spec.Egress).To(BeEmpty())
+
+			np = policies["sdi-observer-allow-egress-same-namespace"]
+			Ω(np.Spec.Egress).To(Equal([]networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{sameNamespace}},
+			}))
+
+			np = policies["sdi-observer-allow-egress-dns"]
+			Ω(np.Spec.Egress).To(HaveLen(1))
+			Ω(np.Spec.Egress[0].To).To(Equal([]networkingv1.NetworkPolicyPeer{{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"kubernetes.io/metadata.name": "openshift-dns"}},
+			}}))
+			var ports []string
+			for _, p := range np.Spec.Egress[0].Ports {
+				ports = append(ports, string(*p.Protocol)+"/"+p.Port.String())
+			}
+			Ω(ports).To(ConsistOf("UDP/53", "TCP/53", "UDP/5353", "TCP/5353"))
+
+			By("pruning the egress policies once the egress is not restricted anymore")
+			ωbs.Update(k8sClient, obs, func(obs *sdiv1alpha1.SDIObserver) {
+				obs.Spec.NetworkPolicies.RestrictEgress = false
+			})
+			nmCtrl.ReconcileObs(obs)
+			waitForPolicies("sdi",
+				"sdi-observer-default-deny",
+				"sdi-observer-allow-same-namespace",
+				"sdi-observer-allow-from-ingress")
+		})
+
+		It("Should allow the traffic from the SLCB namespace", func() {
+			Ω(k8sClient.Create(context.Background(), &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: slcbNamespace},
+			})).NotTo(HaveOccurred())
+			obs := &sdiv1alpha1.SDIObserver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "sdi",
+					Namespace: "sdi-observer",
+				},
+				Spec: sdiv1alpha1.SDIObserverSpec{
+					SDINamespace:  "sdi",
+					SLCBNamespace: slcbNamespace,
+					VSystemRoute: sdiv1alpha1.SDIObserverSpecRoute{
+						ManagementState: sdiv1alpha1.RouteManagementStateUnmanaged,
+					},
+					SLCBRoute: sdiv1alpha1.SDIObserverSpecRoute{
+						ManagementState: sdiv1alpha1.RouteManagementStateUnmanaged,
+					},
+					NetworkPolicies: sdiv1alpha1.SDIObserverSpecNetworkPolicies{
+						ManagementState: sdiv1alpha1.RouteManagementStateManaged,
+					},
+				},
+			}
+			Ω(k8sClient.Create(context.Background(), obs)).NotTo(HaveOccurred())
+			nmCtrl.ReconcileObs(obs)
+
+			policies := waitForPolicies("sdi",
+				"sdi-observer-default-deny",
+				"sdi-observer-allow-same-namespace",
+				"sdi-observer-allow-from-ingress")
+			Ω(policies["sdi-observer-allow-same-namespace"].Spec.Ingress).To(Equal(
+				[]networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{
+					sameNamespace,
+					{NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"kubernetes.io/metadata.name": slcbNamespace}}},
+				}}}))
+
+			policies = waitForPolicies(slcbNamespace,
+				"sdi-observer-default-deny",
+				"sdi-observer-allow-same-namespace",
+				"sdi-observer-allow-from-ingress")
+			Ω(policies["sdi-observer-allow-same-namespace"].Spec.Ingress).To(Equal(
+				[]networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{sameNamespace}}}))
+			Ω(policies["sdi-observer-allow-from-ingress"].Spec.PodSelector.MatchLabels).To(Equal(
+				map[string]string{"app": "slcbridge"}))
+		})
+	})
+
+	Context("When stale policies exist", func() {
+		var stale, foreign *networkingv1.NetworkPolicy
+
+		BeforeEach(func() {
+			stale = &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "sdi",
+					Name:      "sdi-observer-allow-legacy",
+					Annotations: map[string]string{
+						"operator-sdk/primary-resource-type": "SDIObserver.di.sap-cop.redhat.com",
+						"operator-sdk/primary-resource":      "sdi-observer/sdi",
+					},
+				},
+			}
+			// owned by another observer, the prefix alone does not make it ours
+			foreign = &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "sdi",
+					Name:      "sdi-observer-custom",
+					Annotations: map[string]string{
+						"operator-sdk/primary-resource-type": "SDIObserver.di.sap-cop.redhat.com",
+						"operator-sdk/primary-resource":      "sdi-observer/other",
+					},
+				},
+			}
+			for _, np := range []*networkingv1.NetworkPolicy{stale, foreign} {
+				np.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+				Ω(k8sClient.Create(context.Background(), np)).NotTo(HaveOccurred())
+			}
+		})
+
+		It("Should prune only the owned ones", func() {
+			obs := createObs(sdiv1alpha1.SDIObserverSpecNetworkPolicies{
+				ManagementState: sdiv1alpha1.RouteManagementStateManaged,
+			})
+			waitForPolicies("sdi",
+				"sdi-observer-default-deny",
+				"sdi-observer-allow-same-namespace",
+				"sdi-observer-allow-from-ingress",
+				foreign.Name)
+
+			By("deleting all the owned policies once Removed")
+			ωbs.Update(k8sClient, obs, func(obs *sdiv1alpha1.SDIObserver) {
+				obs.Spec.NetworkPolicies.ManagementState = sdiv1alpha1.RouteManagementStateRemoved
+			})
+			nmCtrl.ReconcileObs(obs)
+			waitForPolicies("sdi", foreign.Name)
+		})
+
+		It("Should leave them alone when Unmanaged", func() {
+			createObs(sdiv1alpha1.SDIObserverSpecNetworkPolicies{
+				ManagementState: sdiv1alpha1.RouteManagementStateUnmanaged,
+			})
+			Consistently(func(g Gomega) {
+				list := &networkingv1.NetworkPolicyList{}
+				g.Ω(k8sClient.List(context.TODO(), list, client.InNamespace("sdi"))).NotTo(HaveOccurred())
+				g.Ω(list.Items).To(HaveLen(2))
+			}, time.Second, interval).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,186 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/machineconfig"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/images"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	nodeConfiguratorName = "sdi-node-configurator"
+	// the cluster role granting the use of the privileged SCC
+	privilegedSCCClusterRole = "system:openshift:scc:privileged"
+	hostRootVolumeName       = "host-root"
+)
+
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=privileged,verbs=use
+
+// makeNodeConfiguratorScript returns the script loading the kernel modules and applying the sysctls on the
+// host.
+func makeNodeConfiguratorScript(sysctls map[string]string) string {
+	lines := []string{
+		"set -euo pipefail",
+		fmt.Sprintf("for module in %s; do", strings.Join(machineconfig.KernelModules, " ")),
+		`  modprobe --verbose "$module"`,
+		"done",
+	}
+	keys := make([]string, 0, len(sysctls))
+	for key := range sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("sysctl -w '%s=%s'", key, sysctls[key]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func mutateNodeConfigurator(ds *appsv1.DaemonSet, spec sdiv1alpha1.SDIObserverSpecNodeConfig) {
+	labels := map[string]string{"app": nodeConfiguratorName}
+	image := images.NodeConfigurator.Resolve(spec.Image)
+	privileged := true
+	var root int64
+	maxUnavailable := intstr.FromString("50%")
+
+	ds.Labels = labels
+	ds.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+	ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+	}
+	ds.Spec.Template.Labels = labels
+	podSpec := &ds.Spec.Template.Spec
+	podSpec.ServiceAccountName = nodeConfiguratorName
+	podSpec.NodeSelector = spec.NodeSelector
+	podSpec.HostPID = true
+	podSpec.HostNetwork = true
+	podSpec.Tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	podSpec.Volumes = []corev1.Volume{{
+		Name: hostRootVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+		},
+	}}
+	// the configuration is applied once per pod start, the keep-alive container prevents restarts
+	podSpec.InitContainers = []corev1.Container{{
+		Name:    "configure",
+		Image:   image,
+		Command: []string{"chroot", "/host", "/bin/bash", "-c", makeNodeConfiguratorScript(spec.Sysctls)},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &privileged,
+			RunAsUser:  &root,
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: hostRootVolumeName, MountPath: "/host"}},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+	}}
+	podSpec.Containers = []corev1.Container{{
+		Name:    "keep-alive",
+		Image:   image,
+		Command: []string{"/bin/sleep", "infinity"},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("10m"),
+				corev1.ResourceMemory: resource.MustParse("10Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("50m"),
+				corev1.ResourceMemory: resource.MustParse("50Mi"),
+			},
+		},
+	}}
+}
+
+// manageNodeConfigurator deploys the privileged node configurator DaemonSet into the namespace of the
+// SDIObserver if the daemonset strategy is selected. Otherwise, the DaemonSet is removed.
+func manageNodeConfigurator(ctx context.Context, c client.Client, obs *sdiv1alpha1.SDIObserver) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.NodeConfig
+	objMeta := metav1.ObjectMeta{Namespace: obs.Namespace, Name: nodeConfiguratorName}
+	components := []struct {
+		desc   string
+		obj    client.Object
+		mutate func(client.Object)
+	}{
+		{"service account", &corev1.ServiceAccount{ObjectMeta: objMeta}, func(client.Object) {}},
+		{"role binding", &rbacv1.RoleBinding{ObjectMeta: objMeta}, func(obj client.Object) {
+			rb := obj.(*rbacv1.RoleBinding)
+			rb.RoleRef = rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     privilegedSCCClusterRole,
+			}
+			rb.Subjects = []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: obs.Namespace,
+				Name:      nodeConfiguratorName,
+			}}
+		}},
+		{"daemonset", &appsv1.DaemonSet{ObjectMeta: objMeta}, func(obj client.Object) {
+			mutateNodeConfigurator(obj.(*appsv1.DaemonSet), spec)
+		}},
+	}
+
+	if spec.Strategy != sdiv1alpha1.NodeConfigStrategyDaemonSet {
+		for i := len(components) - 1; i >= 0; i-- {
+			obj := components[i].obj
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if !primaryresource.IsOwnedBy(obj, obs, "SDIObserver") {
+				continue
+			}
+			tracer.Info("deleting node configurator "+components[i].desc, "name", obj.GetName())
+			if err := c.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete node configurator %s: %v", components[i].desc, err)
+			}
+		}
+		return nil
+	}
+
+	for _, comp := range components {
+		comp := comp
+		op, err := controllerutil.CreateOrUpdate(ctx, c, comp.obj, func() error {
+			comp.mutate(comp.obj)
+			primaryresource.Set(comp.obj, obs, "SDIObserver")
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to manage node configurator %s: %v", comp.desc, err)
+		}
+		if op != controllerutil.OperationResultNone {
+			tracer.Info("managed node configurator "+comp.desc, "name", comp.obj.GetName(), "operation", op)
+		}
+	}
+	return nil
+}
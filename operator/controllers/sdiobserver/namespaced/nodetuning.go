@@ -0,0 +1,167 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	tunedNamespace       = "openshift-cluster-node-tuning-operator"
+	tunedProfilePrefix   = "sdi-"
+	tunedProfilePriority = 20
+	mcpRoleLabelKey      = "machineconfiguration.openshift.io/role"
+	defaultTunedPool     = "sdi"
+	defaultMaxMapCount   = 2147483647
+)
+
+//+kubebuilder:rbac:groups=tuned.openshift.io,resources=tuneds,verbs=get;list;watch;create;update;patch;delete
+
+func newTuned(obs *sdiv1alpha1.SDIObserver) *unstructured.Unstructured {
+	tuned := &unstructured.Unstructured{}
+	tuned.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "tuned.openshift.io",
+		Version: "v1",
+		Kind:    "Tuned",
+	})
+	tuned.SetNamespace(tunedNamespace)
+	tuned.SetName("sdi-observer-" + obs.Name)
+	return tuned
+}
+
+// makeTunedProfileData renders the tuned profile of the pool.
+func makeTunedProfileData(pool sdiv1alpha1.SDIObserverSpecNodeTuningPool) string {
+	maxMapCount := pool.MaxMapCount
+	if maxMapCount == 0 {
+		maxMapCount = defaultMaxMapCount
+	}
+	lines := []string{
+		"[main]",
+		fmt.Sprintf("summary=SAP Data Intelligence tuning of the %s nodes", pool.Role),
+		"include=openshift-node",
+		"",
+		"[sysctl]",
+		fmt.Sprintf("vm.max_map_count=%d", maxMapCount),
+	}
+	keys := make([]string, 0, len(pool.Sysctls))
+	for key := range pool.Sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s=%s", key, pool.Sysctls[key]))
+	}
+	if pool.HugePages2Mi > 0 {
+		lines = append(lines, "",
+			"[bootloader]",
+			fmt.Sprintf("cmdline_sdi_hugepages=hugepagesz=2M hugepages=%d", pool.HugePages2Mi))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func makeTunedSpec(spec sdiv1alpha1.SDIObserverSpecNodeTuning) map[string]interface{} {
+	pools := spec.Pools
+	if len(pools) == 0 {
+		pools = []sdiv1alpha1.SDIObserverSpecNodeTuningPool{{Role: defaultTunedPool}}
+	}
+	var profiles, recommend []interface{}
+	for _, pool := range pools {
+		name := tunedProfilePrefix + pool.Role
+		profiles = append(profiles, map[string]interface{}{
+			"name": name,
+			"data": makeTunedProfileData(pool),
+		})
+		recommend = append(recommend, map[string]interface{}{
+			"profile":  name,
+			"priority": int64(tunedProfilePriority),
+			// the Node Tuning Operator renders a MachineConfig for the pool (kernel arguments)
+			"machineConfigLabels": map[string]interface{}{mcpRoleLabelKey: pool.Role},
+		})
+	}
+	return map[string]interface{}{"profile": profiles, "recommend": recommend}
+}
+
+// manageNodeTuning maintains the Tuned object of the SDIObserver. The object lives in the namespace of the
+// Node Tuning Operator which is not covered by the cache.
+func manageNodeTuning(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.NodeTuning
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("node tuning is not managed")
+		return nil
+	}
+	if reRemoved.MatchString(spec.ManagementState) {
+		return deleteNodeTuning(ctx, c, apiReader, obs)
+	}
+
+	tuned := newTuned(obs)
+	desired := makeTunedSpec(spec)
+	err := apiReader.Get(ctx, client.ObjectKeyFromObject(tuned), tuned)
+	if meta.IsNoMatchError(err) {
+		return fmt.Errorf("the Node Tuning Operator is not installed: %v", err)
+	}
+	if errors.IsNotFound(err) {
+		primaryresource.Set(tuned, obs, "SDIObserver")
+		tuned.Object["spec"] = desired
+		tracer.Info("creating Tuned", "name", tuned.GetName())
+		return c.Create(ctx, tuned)
+	}
+	if err != nil {
+		return err
+	}
+	current := tuned.DeepCopy()
+	primaryresource.Set(tuned, obs, "SDIObserver")
+	if err := unstructured.SetNestedField(tuned.Object, desired, "spec"); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(current.Object, tuned.Object) {
+		return nil
+	}
+	tracer.Info("updating Tuned", "name", tuned.GetName())
+	return c.Update(ctx, tuned)
+}
+
+// deleteNodeTuning removes the Tuned object of the SDIObserver.
+func deleteNodeTuning(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	tuned := newTuned(obs)
+	err := apiReader.Get(ctx, client.ObjectKeyFromObject(tuned), tuned)
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !primaryresource.IsOwnedBy(tuned, obs, "SDIObserver") {
+		return nil
+	}
+	tracer.Info("deleting Tuned", "name", tuned.GetName())
+	return client.IgnoreNotFound(c.Delete(ctx, tuned))
+}
@@ -0,0 +1,213 @@
+package namespaced
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	webhookFormatSlack     = "Slack"
+	webhookURLDefaultKey   = "url"
+	webhookTimeout         = time.Second * 10
+	defaultExpiryThreshold = time.Hour * 24 * 14
+
+	notificationConditionChanged    = "ConditionChanged"
+	notificationCertificateExpiring = "CertificateExpiring"
+)
+
+// the conditions whose transitions are notified
+var notifiedConditions = []string{"Ready", "Degraded", sdiv1alpha1.ConditionDataHubReady}
+
+// notification is posted as is in the Generic format.
+type notification struct {
+	Kind         string      `json:"kind"`
+	Observer     string      `json:"observer"`
+	SDINamespace string      `json:"sdiNamespace"`
+	Type         string      `json:"type,omitempty"`
+	Status       string      `json:"status,omitempty"`
+	Reason       string      `json:"reason,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	Time         metav1.Time `json:"time"`
+}
+
+func (n *notification) text() string {
+	if n.Kind == notificationCertificateExpiring {
+		return fmt.Sprintf("SDIObserver %s (SDI namespace %s): %s", n.Observer, n.SDINamespace, n.Message)
+	}
+	text := fmt.Sprintf("SDIObserver %s (SDI namespace %s): %s is %s", n.Observer, n.SDINamespace, n.Type,
+		n.Status)
+	if len(n.Reason) > 0 {
+		text += " (" + n.Reason + ")"
+	}
+	if len(n.Message) > 0 {
+		text += ": " + n.Message
+	}
+	return text
+}
+
+// expiryNotices remembers the certificate expirations already notified. It is shared by the copies of the
+// reconciler and reset on the operator restart, which notifies the pending expiry once more.
+type expiryNotices struct {
+	mu       sync.Mutex
+	notified map[string]time.Time
+}
+
+// markNotified returns false if the expiry of the certificate of the route has been notified already.
+func (e *expiryNotices) markNotified(route string, expiry time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.notified == nil {
+		e.notified = make(map[string]time.Time)
+	}
+	if last, ok := e.notified[route]; ok && last.Equal(expiry) {
+		return false
+	}
+	e.notified[route] = expiry
+	return true
+}
+
+// conditionTransitions returns the notifications of the notified conditions whose status differs from the
+// previous one.
+func conditionTransitions(obs *sdiv1alpha1.SDIObserver, previous []metav1.Condition) []notification {
+	var notes []notification
+	for _, cType := range notifiedConditions {
+		current := meta.FindStatusCondition(obs.Status.Conditions, cType)
+		if current == nil || current.Status == metav1.ConditionUnknown {
+			continue
+		}
+		if prev := meta.FindStatusCondition(previous, cType); prev != nil && prev.Status == current.Status {
+			continue
+		}
+		notes = append(notes, notification{
+			Kind:    notificationConditionChanged,
+			Type:    current.Type,
+			Status:  string(current.Status),
+			Reason:  current.Reason,
+			Message: current.Message,
+		})
+	}
+	return notes
+}
+
+// certificateExpiry returns the notification of the certificate of the vsystem route expiring within the
+// threshold unless notified already.
+func (r *reconciler) certificateExpiry(ctx context.Context, obs *sdiv1alpha1.SDIObserver) []notification {
+	if r.noRouteAPI || r.expiryNotices == nil {
+		return nil
+	}
+	threshold := defaultExpiryThreshold
+	if t := obs.Spec.Notifications.CertificateExpiryThreshold; t != nil && t.Duration > 0 {
+		threshold = t.Duration
+	}
+	route := &routev1.Route{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: r.dhNamespace, Name: "vsystem"}, route); err != nil {
+		return nil
+	}
+	expiry, ok := getRouteCertificateExpiry(route)
+	if !ok || time.Until(expiry) > threshold || !r.expiryNotices.markNotified(route.Name, expiry) {
+		return nil
+	}
+	return []notification{{
+		Kind: notificationCertificateExpiring,
+		Message: fmt.Sprintf("the certificate of route %s/%s expires at %s", route.Namespace, route.Name,
+			expiry.UTC().Format(time.RFC3339)),
+	}}
+}
+
+// notify posts the condition transitions and the expiring certificates to the webhooks of the SDIObserver.
+func (r *reconciler) notify(ctx context.Context, obs *sdiv1alpha1.SDIObserver, previous []metav1.Condition) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	webhooks := obs.Spec.Notifications.Webhooks
+	if len(webhooks) == 0 {
+		return
+	}
+	notes := append(conditionTransitions(obs, previous), r.certificateExpiry(ctx, obs)...)
+	if len(notes) == 0 {
+		return
+	}
+	proxy, err := clusterproxy.Get(ctx, r.apiReader)
+	if err != nil {
+		tracer.Info("cannot read the cluster proxy, relying on the environment", "error", err)
+	}
+	httpClient := &http.Client{Timeout: webhookTimeout, Transport: proxy.Transport(proxy.CertPool())}
+	now := metav1.Now()
+	for i := range notes {
+		notes[i].Observer = obs.Namespace + "/" + obs.Name
+		notes[i].SDINamespace = r.dhNamespace
+		notes[i].Time = now
+	}
+	for _, wh := range webhooks {
+		if err := postNotifications(ctx, r.client, httpClient, obs.Namespace, wh, notes); err != nil {
+			tracer.Error(err, "failed to notify the webhook", "webhook", wh.Name)
+			r.recorder.Eventf(obs, corev1.EventTypeWarning, "FailedNotification", "failed to notify webhook %s: %v",
+				wh.Name, err)
+		}
+	}
+}
+
+func postNotifications(
+	ctx context.Context,
+	c client.Client,
+	httpClient *http.Client,
+	namespace string,
+	wh sdiv1alpha1.SDIObserverSpecWebhook,
+	notes []notification,
+) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: wh.URLSecretRef.Name}, secret); err != nil {
+		return err
+	}
+	key := wh.URLSecretRef.Key
+	if len(key) == 0 {
+		key = webhookURLDefaultKey
+	}
+	url, ok := secret.Data[key]
+	if !ok {
+		return fmt.Errorf("failed to find key %q in secret %q", key, wh.URLSecretRef.Name)
+	}
+	for i := range notes {
+		var body interface{} = &notes[i]
+		if wh.Format == webhookFormatSlack {
+			body = map[string]string{"text": notes[i].text()}
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(bytes.TrimSpace(url)),
+			bytes.NewReader(data))
+		if err != nil {
+			// the error would disclose the URL
+			return fmt.Errorf("invalid webhook URL")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to post the notification to %s", req.URL.Host)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("the webhook responded with %d", resp.StatusCode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+)
+
+// ReconcileOnce runs a single reconciliation of the SDIObserver managing the SDI namespace without starting
+// the informers of a Controller. The client is used for both the reads and the writes. An error is returned
+// if the reconciliation fails or leaves the SDIObserver degraded.
+func ReconcileOnce(
+	ctx context.Context,
+	cfg *rest.Config,
+	c client.Client,
+	mapper meta.RESTMapper,
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder,
+	nmName types.NamespacedName,
+	dhNamespace string,
+) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues(λ.SDINamespaceKey, dhNamespace))
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	dhClient, err := NewDHClient(cfg)
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	r := &reconciler{
+		client:         c,
+		apiReader:      c,
+		dhClient:       dhClient,
+		restMapper:     mapper,
+		scheme:         scheme,
+		namespacedName: nmName,
+		dhNamespace:    dhNamespace,
+		recorder:       recorder,
+		noRouteAPI:     !routeapi.IsServed(mapper),
+		// a periodic invocation notifies the expiring certificates once per run
+		expiryNotices: &expiryNotices{},
+		tokens:        kubeClient.CoreV1(),
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nmName}); err != nil {
+		return err
+	}
+	obs := &sdiv1alpha1.SDIObserver{}
+	if err := c.Get(ctx, nmName, obs); err != nil {
+		return err
+	}
+	if cond := meta.FindStatusCondition(obs.Status.Conditions, "Degraded"); cond != nil && cond.Status == metav1.ConditionTrue {
+		return fmt.Errorf("degraded (%s): %s", cond.Reason, cond.Message)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const permissionsCheckInterval = time.Minute * 10
+
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
+
+// requiredRule lists the verbs the operator needs for the resources of a target namespace.
+type requiredRule struct {
+	group     string
+	resources []string
+	verbs     []string
+}
+
+var (
+	readVerbs   = []string{"get", "list", "watch"}
+	patchVerbs  = []string{"get", "list", "watch", "update", "patch"}
+	manageVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+)
+
+// the rules needed in the SDI namespace by the default features
+var sdiNamespaceRules = []requiredRule{
+	{group: "installers.datahub.sap.com", resources: []string{"datahubs"}, verbs: patchVerbs},
+	{group: "", resources: []string{"services", "secrets", "configmaps", "serviceaccounts"}, verbs: manageVerbs},
+	{group: "", resources: []string{"pods"}, verbs: readVerbs},
+	{group: "apps", resources: []string{"deployments", "statefulsets", "daemonsets"}, verbs: patchVerbs},
+}
+
+// the rules needed in the SLCB namespace besides the route ones
+var slcbNamespaceRules = []requiredRule{
+	{group: "", resources: []string{"services"}, verbs: readVerbs},
+}
+
+var routeRule = requiredRule{group: "route.openshift.io", resources: []string{"routes"}, verbs: manageVerbs}
+
+// permissionChecks remembers whether the controller has reviewed the permissions since its start. The status of
+// the SDIObserver may come from the previous run of the operator with a different role.
+type permissionChecks struct {
+	// the reviews are sent with the plain client; they are neither recorded as events nor subject to the dry-run
+	reviews authorizationv1client.SelfSubjectAccessReviewsGetter
+	mu      sync.Mutex
+	checked bool
+}
+
+// due returns true if the permissions shall be reviewed now.
+func (p *permissionChecks) due(obs *sdiv1alpha1.SDIObserver) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.checked {
+		return true
+	}
+	status := obs.Status.Permissions
+	return status == nil || status.LastCheckTime == nil ||
+		time.Since(status.LastCheckTime.Time) >= permissionsCheckInterval
+}
+
+func (p *permissionChecks) markChecked() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checked = true
+}
+
+// nextPermissionsCheckIn returns the delay until the next review of the permissions is due.
+func nextPermissionsCheckIn(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	status := obs.Status.Permissions
+	if status == nil || status.LastCheckTime == nil {
+		return time.Second
+	}
+	next := time.Until(status.LastCheckTime.Add(permissionsCheckInterval))
+	if next < time.Second {
+		next = time.Second
+	}
+	return next
+}
+
+// getRequiredRules returns the rules needed per target namespace.
+func getRequiredRules(obs *sdiv1alpha1.SDIObserver, namespace string, noRouteAPI bool) map[string][]requiredRule {
+	rules := map[string][]requiredRule{
+		namespace: append([]requiredRule(nil), sdiNamespaceRules...),
+	}
+	if !noRouteAPI {
+		rules[namespace] = append(rules[namespace], routeRule)
+	}
+	if slcb := obs.Spec.SLCBNamespace; len(slcb) > 0 && slcb != namespace {
+		rules[slcb] = append([]requiredRule(nil), slcbNamespaceRules...)
+		if !noRouteAPI {
+			rules[slcb] = append(rules[slcb], routeRule)
+		}
+	}
+	return rules
+}
+
+// formatRule describes the verbs of the resource in the namespace like "create,delete routes.route.openshift.io
+// in sdi".
+func formatRule(verbs []string, group, resource, namespace string) string {
+	if len(group) > 0 {
+		resource += "." + group
+	}
+	return fmt.Sprintf("%s %s in %s", strings.Join(verbs, ","), resource, namespace)
+}
+
+// reviewPermissions asks the API server with SelfSubjectAccessReviews whether the operator is granted the rules
+// and returns the missing ones.
+func reviewPermissions(
+	ctx context.Context,
+	reviews authorizationv1client.SelfSubjectAccessReviewsGetter,
+	rules map[string][]requiredRule,
+) ([]string, error) {
+	namespaces := make([]string, 0, len(rules))
+	for namespace := range rules {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var missing []string
+	for _, namespace := range namespaces {
+		for _, rule := range rules[namespace] {
+			for _, resource := range rule.resources {
+				var denied []string
+				for _, verb := range rule.verbs {
+					ssar := &authorizationv1.SelfSubjectAccessReview{
+						Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+							ResourceAttributes: &authorizationv1.ResourceAttributes{
+								Namespace: namespace,
+								Verb:      verb,
+								Group:     rule.group,
+								Resource:  resource,
+							},
+						},
+					}
+					ssar, err := reviews.SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
+					if err != nil {
+						return nil, err
+					}
+					if !ssar.Status.Allowed {
+						denied = append(denied, verb)
+					}
+				}
+				if len(denied) > 0 {
+					missing = append(missing, formatRule(denied, rule.group, resource, namespace))
+				}
+			}
+		}
+	}
+	return missing, nil
+}
+
+// checkPermissions reviews the permissions when due and returns the Degraded condition for the missing ones.
+func (r *reconciler) checkPermissions(ctx context.Context, obs *sdiv1alpha1.SDIObserver) *metav1.Condition {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if r.permissions.due(obs) {
+		var missing []string
+		err := measureComponent(r.dhNamespace, componentPermissions, func() (err error) {
+			missing, err = reviewPermissions(ctx, r.permissions.reviews, getRequiredRules(obs, r.dhNamespace, r.noRouteAPI))
+			return
+		})
+		if err != nil {
+			tracer.Error(err, "failed to review the permissions")
+			return &metav1.Condition{
+				Status:  metav1.ConditionUnknown,
+				Reason:  "FailedPermissionsCheck",
+				Message: fmt.Sprintf("failed to review the permissions of the operator: %v", err),
+			}
+		}
+		r.permissions.markChecked()
+		now := metav1.Now()
+		obs.Status.Permissions = &sdiv1alpha1.SDIObserverPermissionsStatus{LastCheckTime: &now, MissingRules: missing}
+		if len(missing) > 0 {
+			tracer.Info("the operator lacks permissions", "missing", missing)
+		}
+	}
+
+	if obs.Status.Permissions == nil || len(obs.Status.Permissions.MissingRules) == 0 {
+		return nil
+	}
+	return &metav1.Condition{
+		Status: metav1.ConditionTrue,
+		Reason: "MissingPermissions",
+		Message: "the operator is not granted the rules: " +
+			strings.Join(obs.Status.Permissions.MissingRules, "; "),
+	}
+}
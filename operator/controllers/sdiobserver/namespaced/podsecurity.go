@@ -0,0 +1,163 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+const (
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	// disables the synchronization of the audit and warn labels with the SCCs on OpenShift 4.12+
+	podSecuritySyncLabelKey = "security.openshift.io/scc.podSecurityLabelSync"
+	defaultPodSecurityLevel = "privileged"
+
+	reasonPodSecurityForbidden  = "Forbidden"
+	reasonPodSecurityOverridden = "Overridden"
+)
+
+var podSecurityModes = []string{"enforce", "audit", "warn"}
+
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;patch
+
+// getPodSecurityNamespaces returns the namespaces to label, the SDI and SLCB namespaces by default.
+func getPodSecurityNamespaces(obs *sdiv1alpha1.SDIObserver, namespace string) []string {
+	if len(obs.Spec.PodSecurity.Namespaces) > 0 {
+		return obs.Spec.PodSecurity.Namespaces
+	}
+	namespaces := []string{namespace}
+	if slcb := obs.Spec.SLCBNamespace; len(slcb) > 0 && slcb != namespace {
+		namespaces = append(namespaces, slcb)
+	}
+	return namespaces
+}
+
+func makePodSecurityLabels(level string) map[string]string {
+	labels := map[string]string{podSecuritySyncLabelKey: "false"}
+	for _, mode := range podSecurityModes {
+		labels[podSecurityLabelPrefix+mode] = level
+	}
+	return labels
+}
+
+// managePodSecurity sets the Pod Security Admission labels of the namespaces. The namespaces refusing the labels,
+// e.g. due to an admission policy of the cluster, are reported in the PodSecurityLabeled condition instead of
+// degrading the SDIObserver. Missing namespaces are skipped.
+func managePodSecurity(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.PodSecurity
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("pod security labels are not managed")
+		meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionPodSecurityLabeled)
+		return nil
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+	if rbacscope.Namespaced() {
+		tracer.V(2).Info("pod security labels are disabled in the namespace-scoped RBAC mode")
+		if removed {
+			meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionPodSecurityLabeled)
+			return nil
+		}
+		meta.SetStatusCondition(&obs.Status.Conditions, metav1.Condition{
+			Type:               sdiv1alpha1.ConditionPodSecurityLabeled,
+			Status:             metav1.ConditionFalse,
+			Reason:             rbacscope.ReasonNamespacedRBAC,
+			Message:            "the namespaces cannot be labeled with the namespace-scoped RBAC",
+			ObservedGeneration: obs.Generation,
+		})
+		return nil
+	}
+
+	level := spec.Level
+	if len(level) == 0 {
+		level = defaultPodSecurityLevel
+	}
+	desired := makePodSecurityLabels(level)
+	var refused []string
+	var reason string
+	for _, name := range getPodSecurityNamespaces(obs, namespace) {
+		// the cluster-scoped object is not covered by the namespaced cache
+		ns := &corev1.Namespace{}
+		if err := apiReader.Get(ctx, types.NamespacedName{Name: name}, ns); err != nil {
+			if errors.IsNotFound(err) {
+				tracer.V(1).Info("skipping missing namespace", "namespace", name)
+				continue
+			}
+			return err
+		}
+		orig := ns.DeepCopy()
+		if ns.Labels == nil {
+			ns.Labels = map[string]string{}
+		}
+		for key, value := range desired {
+			if !removed {
+				ns.Labels[key] = value
+			} else if ns.Labels[key] == value {
+				delete(ns.Labels, key)
+			}
+		}
+		if equality.Semantic.DeepEqual(orig.Labels, ns.Labels) {
+			continue
+		}
+		if err := c.Patch(ctx, ns, client.MergeFrom(orig)); err != nil {
+			if errors.IsForbidden(err) || errors.IsInvalid(err) {
+				reason = reasonPodSecurityForbidden
+				refused = append(refused, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			return fmt.Errorf("failed to patch the labels of namespace %s: %v", name, err)
+		}
+		tracer.Info("patched the pod security labels", "namespace", name, "removed", removed)
+		for key, value := range desired {
+			if !removed && ns.Labels[key] != value {
+				// e.g. rewritten by a mutating admission webhook
+				if len(reason) == 0 {
+					reason = reasonPodSecurityOverridden
+				}
+				refused = append(refused, fmt.Sprintf("%s: label %s is %q instead of %q", name, key,
+					ns.Labels[key], value))
+				break
+			}
+		}
+	}
+
+	if removed && len(refused) == 0 {
+		meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionPodSecurityLabeled)
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:               sdiv1alpha1.ConditionPodSecurityLabeled,
+		Status:             metav1.ConditionTrue,
+		Reason:             sdiv1alpha1.ConditionReasonAsExpected,
+		Message:            fmt.Sprintf("the namespaces are labeled for the %s pod security level", level),
+		ObservedGeneration: obs.Generation,
+	}
+	if len(refused) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reason
+		condition.Message = "the cluster policy prevents the pod security labels: " + strings.Join(refused, "; ")
+	}
+	meta.SetStatusCondition(&obs.Status.Conditions, condition)
+	return nil
+}
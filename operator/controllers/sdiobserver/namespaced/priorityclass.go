@@ -0,0 +1,176 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+// holds the priority class name of the workload before the assignment
+const originalPriorityClassAnnotationKey = "di.sap-cop.redhat.com/original-priority-class"
+
+//+kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch;create;delete
+
+// ensurePriorityClass creates the PriorityClass if a value is given or verifies that it exists. The
+// cluster-scoped object is not covered by the namespaced cache.
+func ensurePriorityClass(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.PriorityClass
+	pc := &schedulingv1.PriorityClass{}
+	err := apiReader.Get(ctx, types.NamespacedName{Name: spec.Name}, pc)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if spec.Value == nil {
+		if err != nil {
+			return fmt.Errorf("priority class %s does not exist", spec.Name)
+		}
+		return nil
+	}
+	if err == nil {
+		if pc.Value != *spec.Value {
+			// the value is immutable
+			return fmt.Errorf("priority class %s exists with value %d instead of %d", spec.Name, pc.Value,
+				*spec.Value)
+		}
+		return nil
+	}
+	pc = &schedulingv1.PriorityClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: spec.Name},
+		Value:       *spec.Value,
+		Description: "Critical SAP Data Intelligence components.",
+	}
+	primaryresource.Set(pc, obs, "SDIObserver")
+	tracer.Info("creating priority class", "name", pc.Name, "value", pc.Value)
+	return c.Create(ctx, pc)
+}
+
+// deletePriorityClass removes the PriorityClass if created by the operator.
+func deletePriorityClass(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	pc := &schedulingv1.PriorityClass{}
+	if err := apiReader.Get(ctx, types.NamespacedName{Name: obs.Spec.PriorityClass.Name}, pc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !primaryresource.IsOwnedBy(pc, obs, "SDIObserver") {
+		return nil
+	}
+	tracer.Info("deleting priority class", "name", pc.Name)
+	return client.IgnoreNotFound(c.Delete(ctx, pc))
+}
+
+// managePriorityClass assigns the PriorityClass to the critical SDI workloads and restores the original
+// priority class of the others. The returned changes are deferred until the maintenance window.
+func managePriorityClass(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.PriorityClass
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("priority class is not managed")
+		return nil, maintenance.Result{}, nil
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+	if !removed {
+		if err := ensurePriorityClass(ctx, c, apiReader, obs); err != nil {
+			return nil, maintenance.Result{}, fmt.Errorf("failed to ensure priority class: %v", err)
+		}
+	}
+
+	critical := make(map[string]struct{}, len(spec.Workloads))
+	if !removed {
+		for _, name := range spec.Workloads {
+			critical[name] = struct{}{}
+		}
+	}
+	workloads, err := listWorkloads(ctx, c, namespace)
+	if err != nil {
+		return nil, maintenance.Result{}, fmt.Errorf("failed to list workloads: %v", err)
+	}
+	var pending []string
+	var window maintenance.Result
+	for _, obj := range workloads {
+		obj := obj
+		_, assign := critical[obj.GetName()]
+		if _, ok := obj.(*appsv1.DaemonSet); ok {
+			assign = false
+		}
+		if _, assigned := obj.GetAnnotations()[originalPriorityClassAnnotationKey]; !assign && !assigned {
+			continue
+		}
+		kind, podSpec, err := getPodSpec(obj)
+		if err != nil {
+			return nil, window, err
+		}
+		var op controllerutil.OperationResult
+		op, window, err = maintenance.CreateOrUpdate(ctx, c, obs.Namespace, obj, func() error {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			original, assigned := annotations[originalPriorityClassAnnotationKey]
+			if assign {
+				if !assigned {
+					annotations[originalPriorityClassAnnotationKey] = podSpec.PriorityClassName
+				}
+				podSpec.PriorityClassName = spec.Name
+			} else {
+				delete(annotations, originalPriorityClassAnnotationKey)
+				podSpec.PriorityClassName = original
+			}
+			// the priority is resolved from the class name by the admission
+			podSpec.Priority = nil
+			obj.SetAnnotations(annotations)
+			return nil
+		}, false)
+		if err != nil {
+			return nil, window, fmt.Errorf("failed to set priority class of %s %s: %v", kind, obj.GetName(), err)
+		}
+		if op == maintenance.OperationResultDeferred {
+			pending = append(pending, fmt.Sprintf("the priority class of %s %s", kind, obj.GetName()))
+		} else if op != controllerutil.OperationResultNone {
+			tracer.Info("set priority class", "kind", kind, "name", obj.GetName(), "assigned", assign,
+				"operation", op)
+		}
+	}
+
+	if removed {
+		if err := deletePriorityClass(ctx, c, apiReader, obs); err != nil {
+			return pending, window, fmt.Errorf("failed to delete priority class: %v", err)
+		}
+	}
+	return pending, window, nil
+}
@@ -0,0 +1,293 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	defaultRouteProbeInterval = time.Minute * 5
+	routeProbeTimeout         = time.Second * 10
+
+	slcbRouteName = "sap-slcbridge"
+
+	// the CA of the default ingress certificate
+	ingressCANamespace = "openshift-config-managed"
+	ingressCAName      = "default-ingress-cert"
+	ingressCAKey       = "ca-bundle.crt"
+)
+
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+
+func getRouteProbeInterval(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	if obs.Spec.RouteProbeInterval == nil {
+		return defaultRouteProbeInterval
+	}
+	return obs.Spec.RouteProbeInterval.Duration
+}
+
+// nextRouteProbeIn returns the delay until the next probe of the routes is due or zero if the probing is
+// disabled.
+func nextRouteProbeIn(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	interval := getRouteProbeInterval(obs)
+	if interval <= 0 {
+		return 0
+	}
+	next := interval
+	for _, status := range []*sdiv1alpha1.SDIObserverRouteStatus{&obs.Status.VSystemRoute, &obs.Status.SLCBRoute} {
+		if status.LastProbeTime == nil {
+			continue
+		}
+		if d := time.Until(status.LastProbeTime.Add(interval)); d < next {
+			next = d
+		}
+	}
+	if next < time.Second {
+		next = time.Second
+	}
+	return next
+}
+
+// probeRoutes probes the vsystem and slcb route endpoints if due and records the results in the status.
+func probeRoutes(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	interval := getRouteProbeInterval(obs)
+	if interval <= 0 {
+		tracer.V(2).Info("route probing is disabled")
+		return
+	}
+
+	var ingressCA []byte
+	var proxy *clusterproxy.Settings
+	for _, r := range []struct {
+		desc      string
+		component string
+		key       types.NamespacedName
+		status    *sdiv1alpha1.SDIObserverRouteStatus
+	}{
+		{"vsystem", componentVSystemRouteProbe, types.NamespacedName{Namespace: namespace, Name: "vsystem"},
+			&obs.Status.VSystemRoute},
+		{"slcb", componentSLCBRouteProbe, types.NamespacedName{Namespace: obs.Spec.SLCBNamespace, Name: slcbRouteName},
+			&obs.Status.SLCBRoute},
+	} {
+		if r.status.LastProbeTime != nil && time.Since(r.status.LastProbeTime.Time) < interval {
+			continue
+		}
+		if len(r.key.Namespace) == 0 {
+			continue
+		}
+
+		route := &routev1.Route{}
+		if err := c.Get(ctx, r.key, route); err != nil {
+			if errors.IsNotFound(err) {
+				setProbeResult(obs, r.status, metav1.ConditionUnknown, "NotFound",
+					fmt.Sprintf("the %s route does not exist", r.desc), "")
+				recordServingCertificateExpiry(namespace, r.desc, time.Time{})
+			} else {
+				tracer.Error(err, "failed to get route", "route", r.key)
+			}
+			continue
+		}
+		if len(route.Spec.Host) == 0 {
+			setProbeResult(obs, r.status, metav1.ConditionUnknown, "NoHost",
+				fmt.Sprintf("the %s route has no host", r.desc), "")
+			continue
+		}
+
+		if ingressCA == nil {
+			ingressCA = getIngressCA(ctx, apiReader)
+			var err error
+			if proxy, err = clusterproxy.Get(ctx, apiReader); err != nil {
+				tracer.Info("cannot read the cluster proxy, relying on the environment", "error", err)
+			}
+		}
+		start := time.Now()
+		expiry, err := probeRoute(ctx, route, ingressCA, proxy)
+		observeComponent(namespace, r.component, start, err)
+		if !expiry.IsZero() {
+			// an expired certificate fails the handshake, keep the last known expiry for the alerts
+			recordServingCertificateExpiry(namespace, r.desc, expiry)
+		}
+		if err != nil {
+			tracer.Info("route probe failed", "route", r.key, "error", err)
+			setProbeResult(obs, r.status, metav1.ConditionFalse, "ProbeFailed",
+				fmt.Sprintf("the %s route is not reachable", r.desc), err.Error())
+			continue
+		}
+		setProbeResult(obs, r.status, metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+			fmt.Sprintf("the %s route is reachable", r.desc), "")
+	}
+}
+
+func setProbeResult(
+	obs *sdiv1alpha1.SDIObserver,
+	status *sdiv1alpha1.SDIObserverRouteStatus,
+	reachable metav1.ConditionStatus,
+	reason, msg, probeError string,
+) {
+	now := metav1.Now()
+	status.LastProbeTime = &now
+	status.LastProbeError = probeError
+	if len(probeError) > 0 {
+		msg += ": " + probeError
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               "Reachable",
+		Status:             reachable,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: obs.Generation,
+	})
+}
+
+// getIngressCA returns the PEM encoded CA of the default ingress certificate. An empty slice is returned if
+// it cannot be read.
+func getIngressCA(ctx context.Context, apiReader client.Reader) []byte {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	cm := &corev1.ConfigMap{}
+	err := apiReader.Get(ctx, types.NamespacedName{Namespace: ingressCANamespace, Name: ingressCAName}, cm)
+	if err != nil {
+		tracer.Info("cannot read the ingress CA, relying on the system certificates", "error", err)
+		return []byte{}
+	}
+	return []byte(cm.Data[ingressCAKey])
+}
+
+// probeRoute verifies that the route endpoint completes the TLS handshake with a certificate trusted by the
+// system, the cluster proxy, the ingress CA or the route's CA certificate and that it does not respond with a
+// server error. On dual-stack clusters, the endpoint is probed over each IP family the route host resolves to
+// unless the cluster proxy is in the way. The earliest expiration time of the presented serving certificates
+// is returned as well.
+func probeRoute(
+	ctx context.Context,
+	route *routev1.Route,
+	ingressCA []byte,
+	proxy *clusterproxy.Settings,
+) (time.Time, error) {
+	pool := proxy.CertPool()
+	pool.AppendCertsFromPEM(ingressCA)
+	if route.Spec.TLS != nil && len(route.Spec.TLS.CACertificate) > 0 {
+		pool.AppendCertsFromPEM([]byte(route.Spec.TLS.CACertificate))
+	}
+	scheme := "https"
+	if route.Spec.TLS == nil {
+		scheme = "http"
+	}
+
+	url := fmt.Sprintf("%s://%s%s", scheme, route.Spec.Host, route.Spec.Path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	networks, err := getProbeNetworks(ctx, req, proxy)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var errs []string
+	var earliest time.Time
+	for _, network := range networks {
+		expiry, err := probeURL(req, proxy.Transport(pool), network)
+		if !expiry.IsZero() && (earliest.IsZero() || expiry.Before(earliest)) {
+			earliest = expiry
+		}
+		if err != nil {
+			if len(networks) > 1 {
+				err = fmt.Errorf("%s: %v", network, err)
+			}
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return earliest, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return earliest, nil
+}
+
+// getProbeNetworks returns the networks (tcp4, tcp6) the request shall be sent over. A single generic network
+// is returned if the request goes through a proxy.
+func getProbeNetworks(ctx context.Context, req *http.Request, proxy *clusterproxy.Settings) ([]string, error) {
+	if proxyURL, err := proxy.ProxyFunc()(req); err != nil || proxyURL != nil {
+		return []string{"tcp"}, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	var networks []string
+	var has4, has6 bool
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			has4 = true
+		} else {
+			has6 = true
+		}
+	}
+	if has4 {
+		networks = append(networks, "tcp4")
+	}
+	if has6 {
+		networks = append(networks, "tcp6")
+	}
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("no address found for %s", req.URL.Hostname())
+	}
+	return networks, nil
+}
+
+// probeURL sends the request with the transport over the given network. It returns the expiration time of the
+// presented serving certificate, if any.
+func probeURL(req *http.Request, transport *http.Transport, network string) (time.Time, error) {
+	dialer := &net.Dialer{Timeout: routeProbeTimeout}
+	transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	httpClient := &http.Client{
+		Timeout:   routeProbeTimeout,
+		Transport: transport,
+		// a redirect (e.g. to a login page) is a sign of a working endpoint
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	defer httpClient.CloseIdleConnections()
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	var expiry time.Time
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		expiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return expiry, fmt.Errorf("GET %s returned %s", req.URL, resp.Status)
+	}
+	return expiry, nil
+}
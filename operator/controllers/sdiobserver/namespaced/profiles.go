@@ -0,0 +1,65 @@
+package namespaced
+
+import (
+	"regexp"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/compat"
+)
+
+// patchProfile is the set of the patches an SDI release needs on OpenShift.
+type patchProfile struct {
+	name string
+	// the latest SDI release the profile covers; empty for the newest profile
+	lastRelease string
+	// vsystem-vrep cannot export /exports over NFS from the overlay filesystem of RHCOS
+	maskVRepExports bool
+	// diagnostics-fluentd mounts the Docker log directory and cannot read the CRI-O logs unprivileged
+	patchFluentd bool
+	// whether the Pipeline Modeler uses kaniko unless the kaniko field is set to false
+	kanikoByDefault bool
+}
+
+// profiles ordered from the oldest SDI release
+var profiles = []patchProfile{
+	{
+		name:            "SDI-3.2",
+		lastRelease:     "3.2",
+		maskVRepExports: true,
+		patchFluentd:    true,
+	},
+	{
+		name:            "SDI-3.3",
+		kanikoByDefault: true,
+	},
+}
+
+var reAutoProfile = regexp.MustCompile(`^(\s*|(?i)auto)$`)
+
+// getProfile returns the profile set in the spec or the one covering the SDI version of the DataHub. Without
+// a known version, the oldest profile is returned since the older DataHub resources lack the version.
+func getProfile(obs *sdiv1alpha1.SDIObserver) *patchProfile {
+	if !reAutoProfile.MatchString(obs.Spec.Profile) {
+		for i := range profiles {
+			if profiles[i].name == obs.Spec.Profile {
+				return &profiles[i]
+			}
+		}
+	}
+	if obs.Status.DataHub == nil || len(obs.Status.DataHub.Version) == 0 {
+		return &profiles[0]
+	}
+	for i := range profiles {
+		if len(profiles[i].lastRelease) == 0 {
+			return &profiles[i]
+		}
+		cmp, err := compat.CompareReleases(obs.Status.DataHub.Version, profiles[i].lastRelease)
+		if err != nil {
+			return &profiles[0]
+		}
+		if cmp <= 0 {
+			return &profiles[i]
+		}
+	}
+	return &profiles[len(profiles)-1]
+}
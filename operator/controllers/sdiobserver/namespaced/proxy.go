@@ -0,0 +1,187 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	trustedCAConfigMapName = "sdi-observer-trusted-ca"
+	trustedCAVolumeName    = "sdi-observer-trusted-ca"
+	trustedCAMountPath     = "/etc/pki/ca-trust/extracted/pem"
+	// the cluster network operator fills the labeled config map with the trusted CA bundle
+	trustedCAInjectLabelKey = "config.openshift.io/inject-trusted-cabundle"
+	trustedCAKey            = "ca-bundle.crt"
+	trustedCAPath           = "tls-ca-bundle.pem"
+)
+
+var proxyEnvVarNames = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows,verbs=get;list;watch
+
+// setEnv sets the environment variable of the container or removes it if the value is empty.
+func setEnv(container *corev1.Container, name, value string) {
+	for i, env := range container.Env {
+		if env.Name != name {
+			continue
+		}
+		if len(value) == 0 {
+			container.Env = append(container.Env[:i], container.Env[i+1:]...)
+		} else {
+			container.Env[i] = corev1.EnvVar{Name: name, Value: value}
+		}
+		return
+	}
+	if len(value) > 0 {
+		container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: value})
+	}
+}
+
+// injectProxy sets or removes (if proxy is nil) the proxy settings and the trusted CA bundle.
+func injectProxy(podSpec *corev1.PodSpec, proxy *configv1.ProxyStatus) {
+	values := map[string]string{}
+	if proxy != nil {
+		values = map[string]string{
+			"HTTP_PROXY":  proxy.HTTPProxy,
+			"HTTPS_PROXY": proxy.HTTPSProxy,
+			"NO_PROXY":    proxy.NoProxy,
+		}
+	}
+
+	volumes := podSpec.Volumes[:0]
+	for _, v := range podSpec.Volumes {
+		if v.Name != trustedCAVolumeName {
+			volumes = append(volumes, v)
+		}
+	}
+	podSpec.Volumes = volumes
+	if proxy != nil {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: trustedCAVolumeName,
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: trustedCAConfigMapName},
+				Items:                []corev1.KeyToPath{{Key: trustedCAKey, Path: trustedCAPath}},
+			}},
+		})
+	}
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		for _, name := range proxyEnvVarNames {
+			setEnv(container, name, values[name])
+			setEnv(container, strings.ToLower(name), values[name])
+		}
+		mounts := container.VolumeMounts[:0]
+		for _, m := range container.VolumeMounts {
+			if m.Name != trustedCAVolumeName {
+				mounts = append(mounts, m)
+			}
+		}
+		container.VolumeMounts = mounts
+		if proxy != nil {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      trustedCAVolumeName,
+				MountPath: trustedCAMountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+}
+
+// manageProxyInjection injects the cluster proxy settings into the configured SDI deployments. The returned
+// changes are deferred until the maintenance window.
+func manageProxyInjection(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.ProxyInjection
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("proxy injection is not managed")
+		return nil, maintenance.Result{}, nil
+	}
+
+	var proxyStatus *configv1.ProxyStatus
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: trustedCAConfigMapName}}
+	if reRemoved.MatchString(spec.ManagementState) {
+		if err := c.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			return nil, maintenance.Result{}, err
+		}
+	} else {
+		proxy := &configv1.Proxy{}
+		// the cluster scoped object is not covered by the namespaced cache
+		if err := apiReader.Get(ctx, types.NamespacedName{Name: "cluster"}, proxy); err != nil &&
+			!errors.IsNotFound(err) {
+			return nil, maintenance.Result{}, fmt.Errorf("failed to get the cluster proxy: %v", err)
+		}
+		proxyStatus = &proxy.Status
+
+		op, err := controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+			primaryresource.Set(cm, obs, "SDIObserver")
+			if cm.Labels == nil {
+				cm.Labels = make(map[string]string)
+			}
+			cm.Labels[trustedCAInjectLabelKey] = "true"
+			return nil
+		})
+		if err != nil {
+			return nil, maintenance.Result{}, fmt.Errorf("failed to manage the trusted CA config map: %v", err)
+		}
+		if op != controllerutil.OperationResultNone {
+			tracer.Info("managed trusted CA config map", "name", cm.Name, "operation", op)
+		}
+	}
+
+	var pending []string
+	var window maintenance.Result
+	for _, name := range spec.Deployments {
+		deploy := &appsv1.Deployment{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deploy); err != nil {
+			if errors.IsNotFound(err) {
+				tracer.V(2).Info("deployment not found, skipping proxy injection", "name", name)
+				continue
+			}
+			return nil, maintenance.Result{}, err
+		}
+		var op controllerutil.OperationResult
+		var err error
+		op, window, err = maintenance.CreateOrUpdate(ctx, c, obs.Namespace, deploy, func() error {
+			injectProxy(&deploy.Spec.Template.Spec, proxyStatus)
+			return nil
+		}, false)
+		if err != nil {
+			return nil, window, fmt.Errorf("failed to inject proxy settings into deployment %s: %v", name, err)
+		}
+		if op == maintenance.OperationResultDeferred {
+			pending = append(pending, "the proxy injection into deployment "+name)
+		} else if op != controllerutil.OperationResultNone {
+			tracer.Info("injected proxy settings", "deployment", name, "operation", op)
+		}
+	}
+	return pending, window, nil
+}
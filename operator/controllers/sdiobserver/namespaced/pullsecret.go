@@ -0,0 +1,211 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// getPullSecretSource returns the namespaced name of the source pull secret.
+func getPullSecretSource(obs *sdiv1alpha1.SDIObserver) types.NamespacedName {
+	spec := obs.Spec.ImagePullSecret
+	namespace := spec.Namespace
+	if len(namespace) == 0 {
+		namespace = obs.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: spec.Name}
+}
+
+// getPullSecretTargets returns the SDI and SLCB namespaces.
+func getPullSecretTargets(obs *sdiv1alpha1.SDIObserver, namespace string) []string {
+	targets := []string{namespace}
+	if slcbNamespace := obs.Spec.SLCBNamespace; len(slcbNamespace) > 0 && slcbNamespace != namespace {
+		targets = append(targets, slcbNamespace)
+	}
+	return targets
+}
+
+// isPullSecretRelated returns true if the secret is the source pull secret or one of its copies.
+func isPullSecretRelated(obs *sdiv1alpha1.SDIObserver, namespace string, secret client.Object) bool {
+	src := getPullSecretSource(obs)
+	if len(src.Name) == 0 || secret.GetName() != src.Name {
+		return false
+	}
+	if secret.GetNamespace() == src.Namespace {
+		return true
+	}
+	for _, target := range getPullSecretTargets(obs, namespace) {
+		if secret.GetNamespace() == target {
+			return true
+		}
+	}
+	return false
+}
+
+// isPullSecret returns true if the secret is the image pull secret of the SDIObserver or a copy of it.
+func (r *reconciler) isPullSecret(secret client.Object) bool {
+	obs := &sdiv1alpha1.SDIObserver{}
+	if err := r.client.Get(context.Background(), r.namespacedName, obs); err != nil {
+		return false
+	}
+	return isPullSecretRelated(obs, r.dhNamespace, secret)
+}
+
+// WatchedSecretNamespaces returns the sorted namespaces whose secrets are watched by the namespaced controller of
+// the SDIObserver: the SDI namespace, the targets of the pull secret and the namespace of its source.
+func WatchedSecretNamespaces(obs *sdiv1alpha1.SDIObserver, namespace string) []string {
+	namespaces := sets.NewString(getPullSecretTargets(obs, namespace)...)
+	if src := getPullSecretSource(obs); len(src.Name) > 0 {
+		namespaces.Insert(src.Namespace)
+	}
+	return namespaces.List()
+}
+
+// linkPullSecret adds or removes the secret to/from the image pull secrets of the service account. It
+// returns true if the service account has been modified.
+func linkPullSecret(sa *corev1.ServiceAccount, name string, link bool) bool {
+	refs := sa.ImagePullSecrets[:0]
+	linked := false
+	for _, ref := range sa.ImagePullSecrets {
+		if ref.Name == name {
+			linked = true
+			if !link {
+				continue
+			}
+		}
+		refs = append(refs, ref)
+	}
+	sa.ImagePullSecrets = refs
+	if link && !linked {
+		sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	return link != linked
+}
+
+// managePullSecretLinks links the secret to the existing service accounts of the namespace or unlinks it.
+func managePullSecretLinks(
+	ctx context.Context,
+	c client.Client,
+	spec sdiv1alpha1.SDIObserverSpecImagePullSecret,
+	namespace string,
+	link bool,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	for _, name := range spec.ServiceAccounts {
+		name = strings.ReplaceAll(name, "$(NAMESPACE)", namespace)
+		sa := &corev1.ServiceAccount{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, sa); err != nil {
+			if errors.IsNotFound(err) {
+				tracer.V(2).Info("service account not found", "namespace", namespace, "name", name)
+				continue
+			}
+			return err
+		}
+		if !linkPullSecret(sa, spec.Name, link) {
+			continue
+		}
+		tracer.Info("updating image pull secrets of service account",
+			"namespace", namespace, "name", name, "secret", spec.Name, "link", link)
+		if err := c.Update(ctx, sa); err != nil {
+			return fmt.Errorf("failed to update service account %s/%s: %v", namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// managePullSecret copies the referenced pull secret into the SDI and SLCB namespaces and links it to the
+// service accounts there.
+func managePullSecret(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.ImagePullSecret
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("image pull secret is not managed")
+		return nil
+	}
+	if len(spec.Name) == 0 {
+		return fmt.Errorf("missing the name of the image pull secret")
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+	srcName := getPullSecretSource(obs)
+
+	src := &corev1.Secret{}
+	if !removed {
+		if err := c.Get(ctx, srcName, src); err != nil {
+			return fmt.Errorf("failed to get image pull secret %s: %v", srcName.String(), err)
+		}
+	}
+
+	for _, target := range getPullSecretTargets(obs, namespace) {
+		if removed {
+			if err := managePullSecretLinks(ctx, c, spec, target, false); err != nil {
+				return err
+			}
+			if target == srcName.Namespace {
+				continue
+			}
+			secret := &corev1.Secret{}
+			if err := c.Get(ctx, types.NamespacedName{Namespace: target, Name: spec.Name}, secret); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if !primaryresource.IsOwnedBy(secret, obs, "SDIObserver") {
+				continue
+			}
+			tracer.Info("deleting image pull secret", "namespace", target, "name", spec.Name)
+			if err := c.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete secret %s/%s: %v", target, spec.Name, err)
+			}
+			continue
+		}
+
+		if target != srcName.Namespace {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: target, Name: spec.Name}}
+			op, err := controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+				if !secret.CreationTimestamp.IsZero() && !primaryresource.IsOwnedBy(secret, obs, "SDIObserver") {
+					return fmt.Errorf("secret %s/%s exists and is not managed by the observer",
+						target, spec.Name)
+				}
+				primaryresource.Set(secret, obs, "SDIObserver")
+				secret.Type = src.Type
+				secret.Data = src.Data
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to copy image pull secret: %v", err)
+			}
+			if op != controllerutil.OperationResultNone {
+				tracer.Info("copied image pull secret", "namespace", target, "name", spec.Name, "operation", op)
+			}
+		}
+		if err := managePullSecretLinks(ctx, c, spec, target, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,15 +3,19 @@ package namespaced
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/tools/reference"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -19,17 +23,45 @@ import (
 
 	"github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
 	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
 	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
 	"github.com/redhat-sap/sap-data-intelligence/operator/util/sdiobservers"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/upgradeable"
+)
+
+// the management states shared by the features of the SDIObserver
+var (
+	reUnmanaged           = regexp.MustCompile(`^(\s*|(?i)Unmanaged)$`)
+	reExplicitlyUnmanaged = regexp.MustCompile(`^(?i)Unmanaged$`)
+	reManaged             = regexp.MustCompile(`^(?i)managed$`)
+	reRemoved             = regexp.MustCompile(`^(?i)removed?$`)
 )
 
 type reconciler struct {
 	client         client.Client
+	apiReader      client.Reader
 	dhClient       DHClient
+	restMapper     meta.RESTMapper
 	scheme         *runtime.Scheme
 	namespacedName types.NamespacedName
 	// Namespace where the managed DataHub resource lives.
 	dhNamespace string
+	recorder    record.EventRecorder
+	upgradeGate *upgradeable.Gate
+	// skips the probes of the endpoints when only the managed objects are rendered
+	renderOnly bool
+	// the cluster does not serve the route API; the routes are neither managed nor probed
+	noRouteAPI bool
+	// the route certificate expirations notified to the webhooks; nil disables the expiry notifications
+	expiryNotices *expiryNotices
+	// requests the service account tokens not served by the client; nil disables the managed tokens
+	tokens corev1client.ServiceAccountsGetter
+	// tracks the reviews of the operator's permissions; nil disables the reviews
+	permissions *permissionChecks
 }
 
 var _ reconcile.Reconciler = &reconciler{}
@@ -47,7 +79,8 @@ var _ reconcile.Reconciler = &reconciler{}
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.9.2/pkg/reconcile
 func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (rs reconcile.Result, err error) {
-	tracer := λ.Enter(log.FromContext(ctx), "request", req)
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues(λ.ObjectKey, req.String()))
+	tracer := λ.Enter(log.FromContext(ctx))
 	defer λ.Leave(tracer)
 
 	select {
@@ -64,48 +97,241 @@ func (r *reconciler) Reconcile(ctx context.Context, req reconcile.Request) (rs r
 		return
 	}
 
-	ready, degraded, progressing, err := r.doReconcileObs(ctx, obs)
+	if obs.Spec.Paused {
+		return rs, r.pause(ctx, obs)
+	}
+	if meta.IsStatusConditionTrue(obs.Status.Conditions, sdiv1alpha1.ConditionPaused) {
+		r.recorder.Event(obs, corev1.EventTypeNormal, "Resumed", "the management of the SDI namespace is resumed")
+	}
+	meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionPaused)
+
+	// the conditions before the reconciliation to notify the webhooks about the transitions
+	previous := append([]metav1.Condition(nil), obs.Status.Conditions...)
+
+	// the writes to the managed objects are recorded as events on the SDIObserver and in the audit trail
+	er := *r
+	ec := newEventingClient(r.client, r.apiReader, r.recorder, obs)
+	er.client = ec
+	ready, degraded, progressing, requeueAfter, err := er.doReconcileObs(ctx, obs)
 	if err != nil {
 		tracer.Error(err, "failed to reconcile SDI Observer")
+		r.recorder.Event(obs, corev1.EventTypeWarning, "FailedReconcile", err.Error())
 	}
+	if auditErr := recordAudit(ctx, r.scheme, r.client, obs, ec.audit); auditErr != nil {
+		tracer.Error(auditErr, "failed to record the audit trail")
+	}
+	recordFailures(r.recorder, obs, degraded)
+	countReconcileErrors(r.dhNamespace, degraded, err)
 	err = r.updateStatus(ctx, obs, ready, degraded, progressing)
-	if err != nil {
+	if updates.IsPermanent(err) {
+		// a retry would fail the same way
+		tracer.Error(err, "SDI Observer status update has been refused")
+		r.recorder.Event(obs, corev1.EventTypeWarning, "FailedStatusUpdate", err.Error())
+		err = nil
+	} else if err != nil {
 		tracer.Error(err, "failed to update SDI Observer status")
 	}
+	if !sdiobservers.IsBackup(obs) {
+		if insightsErr := manageInsightsReport(ctx, r.client, obs); insightsErr != nil {
+			tracer.Error(insightsErr, "failed to update the insights report")
+		}
+		if err == nil {
+			// the transitions are notified once persisted
+			r.notify(ctx, obs, previous)
+		}
+	}
 	// TODO: handle FailedGet on DH - require after some time
 	if sdiobservers.IsStatusInCondition(obs, "FailedGet") {
 		rs.RequeueAfter = time.Second * 30
 		rs.Requeue = true
+	} else if d := nextRouteProbeIn(obs); d > 0 && !sdiobservers.IsBackup(obs) {
+		rs.RequeueAfter = d
+	}
+	if d := nextVSystemHealthCheckIn(obs); d > 0 && !sdiobservers.IsBackup(obs) &&
+		(rs.RequeueAfter == 0 || d < rs.RequeueAfter) {
+		rs.RequeueAfter = d
+	}
+	if d := nextSCCReportIn(obs); d > 0 && !sdiobservers.IsBackup(obs) &&
+		(rs.RequeueAfter == 0 || d < rs.RequeueAfter) {
+		rs.RequeueAfter = d
+	}
+	if d := nextTokenRenewalIn(obs); d > 0 && !sdiobservers.IsBackup(obs) &&
+		(rs.RequeueAfter == 0 || d < rs.RequeueAfter) {
+		rs.RequeueAfter = d
+	}
+	if d := nextPermissionsCheckIn(obs); r.permissions != nil && !sdiobservers.IsBackup(obs) &&
+		(rs.RequeueAfter == 0 || d < rs.RequeueAfter) {
+		rs.RequeueAfter = d
+	}
+	if requeueAfter > 0 && (rs.RequeueAfter == 0 || requeueAfter < rs.RequeueAfter) {
+		rs.RequeueAfter = requeueAfter
+	}
+	// follow the rollout blocking the operator upgrade
+	if r.upgradeGate.Holds(VRepRolloutAction(r.dhNamespace)) &&
+		(rs.RequeueAfter == 0 || upgradeable.PollInterval < rs.RequeueAfter) {
+		rs.RequeueAfter = upgradeable.PollInterval
 	}
 	return rs, err
 }
 
+// manageGatedChanges applies the changes subject to the maintenance windows and reports the deferred ones in
+// the MaintenancePending condition. A non-zero delay is returned if a change has been deferred.
+func (r *reconciler) manageGatedChanges(
+	ctx context.Context,
+	obs *sdiv1alpha1.SDIObserver,
+	degraded *[]metav1.Condition,
+) time.Duration {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	var pending []string
+	var window maintenance.Result
+	managed := false
+	for _, change := range []struct {
+		desc            string
+		component       string
+		reason          string
+		managementState string
+		apply           func() ([]string, maintenance.Result, error)
+	}{
+		{"inject proxy settings", componentProxyInjection, "FailedProxyInjection", obs.Spec.ProxyInjection.ManagementState,
+			func() ([]string, maintenance.Result, error) {
+				return manageProxyInjection(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+			}},
+		{"patch vsystem-vrep", componentVRep, "FailedVRepPatch", obs.Spec.VRep.ExportsMask,
+			func() ([]string, maintenance.Result, error) {
+				return manageVRepExports(ctx, r.client, r.upgradeGate, obs, r.dhNamespace)
+			}},
+		{"inject scheduling", componentScheduling, "FailedSchedulingInjection", obs.Spec.Scheduling.ManagementState,
+			func() ([]string, maintenance.Result, error) {
+				return manageScheduling(ctx, r.client, obs, r.dhNamespace)
+			}},
+		{"assign priority class", componentPriorityClass, "FailedPriorityClass", obs.Spec.PriorityClass.ManagementState,
+			func() ([]string, maintenance.Result, error) {
+				return managePriorityClass(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+			}},
+		{"override resources", componentResourceOverrides, "FailedResourceOverrides", obs.Spec.ResourceOverrides.ManagementState,
+			func() ([]string, maintenance.Result, error) {
+				return manageResourceOverrides(ctx, r.client, obs, r.dhNamespace)
+			}},
+		{"patch diagnostics-fluentd", componentFluentd, "FailedFluentdPatch", obs.Spec.Diagnostics.Fluentd,
+			func() ([]string, maintenance.Result, error) {
+				return manageFluentd(ctx, r.client, obs, r.dhNamespace)
+			}},
+		{"annotate backup hooks", componentBackupHooks, "FailedBackupHooks", obs.Spec.Backup.ManagementState,
+			func() ([]string, maintenance.Result, error) {
+				return manageBackupHooks(ctx, r.client, obs, r.dhNamespace)
+			}},
+	} {
+		if rbacscope.Namespaced() &&
+			(change.component == componentProxyInjection || change.component == componentPriorityClass) {
+			// reported in the ClusterScopeAvailable condition
+			continue
+		}
+		if !reUnmanaged.MatchString(change.managementState) {
+			managed = true
+		}
+		start := time.Now()
+		deferred, res, err := change.apply()
+		observeComponent(r.dhNamespace, change.component, start, err)
+		if err != nil {
+			tracer.Error(err, "failed to "+change.desc)
+			*degraded = append(*degraded, metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  change.reason,
+				Message: fmt.Sprintf("failed to %s: %v", change.desc, err),
+			})
+			continue
+		}
+		if len(deferred) > 0 {
+			pending = append(pending, deferred...)
+			window = res
+		}
+	}
+
+	if !managed {
+		meta.RemoveStatusCondition(&obs.Status.Conditions, sdiv1alpha1.ConditionMaintenancePending)
+		return 0
+	}
+	maintenance.SetPendingCondition(&obs.Status.Conditions, obs.Generation, len(pending) > 0, window,
+		strings.Join(pending, ", "))
+	if len(pending) > 0 {
+		return window.RequeueAfter()
+	}
+	return 0
+}
+
+// doReconcileObs returns the conditions to consolidate and a non-zero delay if a change has been deferred.
 func (r *reconciler) doReconcileObs(
 	ctx context.Context,
 	obs *sdiv1alpha1.SDIObserver,
-) (ready, degraded, progressing []metav1.Condition, err error) {
+) (ready, degraded, progressing []metav1.Condition, requeueAfter time.Duration, err error) {
 	tracer := λ.Enter(log.FromContext(ctx))
 	defer λ.Leave(tracer)
 
+	// the missing permissions are reported before they fail the reconciliation
+	if r.permissions != nil && !r.renderOnly && !sdiobservers.IsBackup(obs) {
+		if c := r.checkPermissions(ctx, obs); c != nil {
+			degraded = append(degraded, *c)
+		}
+	}
+
 	var dh *unstructured.Unstructured
-	dh, err = r.dhClient.Get(ctx, r.dhNamespace)
+	var ignoredDataHubs []string
+	dh, ignoredDataHubs, err = getPrimaryDataHub(ctx, r.dhClient, r.dhNamespace)
+	if err == nil || errors.IsNotFound(err) {
+		r.setDuplicateDataHubs(ctx, obs, dh, ignoredDataHubs)
+	}
 	removeManagedObjects := false
 	if err != nil {
 		if errors.IsNotFound(err) {
-			// TODO: delete the owned objects
+			var tuningErr error
+			switch {
+			case rbacscope.Namespaced():
+				// the Tuned object lives in the namespace of the Node Tuning Operator
+			case obs.Status.ManagedDataHubRef != nil:
+				// SDI has been uninstalled
+				tuningErr = deleteNodeTuning(ctx, r.client, r.apiReader, obs)
+			default:
+				// the nodes shall be tuned before SDI is installed
+				tuningErr = manageNodeTuning(ctx, r.client, r.apiReader, obs)
+			}
+			if tuningErr != nil {
+				tracer.Error(tuningErr, "failed to manage node tuning")
+			}
+			reason, msg := "NotFound", fmt.Sprintf("waiting for the managed DH to appear: %v", err)
+			if !isDataHubCRDInstalled(r.restMapper) {
+				// the DataHub watch is established once the CRD gets registered
+				reason, msg = "WaitingForDataHubCRD", fmt.Sprintf(
+					"waiting for the %s CRD to be installed by SAP Data Intelligence", DataHubResourceFull)
+			}
 			ready = append(ready, metav1.Condition{
 				Status:  metav1.ConditionFalse,
-				Reason:  "NotFound",
-				Message: fmt.Sprintf("waiting for the managed DH to appear: %v", err),
+				Reason:  reason,
+				Message: msg,
 			})
 			progressing = append(progressing, metav1.Condition{
 				Status:  metav1.ConditionFalse,
-				Reason:  "NotFound",
-				Message: fmt.Sprintf("waiting for the managed DH to appear: %v", err),
+				Reason:  reason,
+				Message: msg,
 			})
-			tracer.Info("DH not found")
+			tracer.Info("DH not found", "reason", reason)
 			err = nil
+			if obs.Status.ManagedDataHubRef != nil {
+				// SDI has been uninstalled
+				if gcErr := deleteVSystemExposure(ctx, r.client, obs, r.dhNamespace, r.noRouteAPI); gcErr != nil {
+					tracer.Error(gcErr, "failed to delete the vsystem exposure objects")
+					degraded = append(degraded, metav1.Condition{
+						Status:  metav1.ConditionTrue,
+						Reason:  "FailedGarbageCollection",
+						Message: fmt.Sprintf("failed to delete the vsystem exposure objects: %v", gcErr),
+					})
+					requeueAfter = time.Second * 30
+					return
+				}
+			}
 			obs.Status.ManagedDataHubRef = nil
+			setDataHubStatus(obs, nil)
 			return
 		}
 
@@ -136,6 +362,7 @@ func (r *reconciler) doReconcileObs(
 		progressing = append(progressing, metav1.Condition{Status: metav1.ConditionUnknown, Reason: reason, Message: msg})
 		degraded = append(degraded, metav1.Condition{Status: metav1.ConditionFalse, Reason: reason, Message: msg})
 		obs.Status.ManagedDataHubRef = nil
+		setDataHubStatus(obs, nil)
 		return
 	}
 
@@ -149,6 +376,9 @@ func (r *reconciler) doReconcileObs(
 	} else {
 		obs.Status.ManagedDataHubRef = ref
 	}
+	setDataHubStatus(obs, dh)
+	obs.Status.Profile = getProfile(obs).name
+	pauseDisruptive := r.checkVersionSkew(ctx, obs)
 
 	owner := obs
 	if removeManagedObjects {
@@ -156,7 +386,30 @@ func (r *reconciler) doReconcileObs(
 			ManagementState: sdiv1alpha1.RouteManagementStateRemoved,
 		}
 	}
-	err = manageVSystemRoute(ctx, r.scheme, r.client, owner, r.dhNamespace)
+	routeapi.SetCondition(&obs.Status.Conditions, obs.Generation, !r.noRouteAPI)
+	rbacscope.SetCondition(&obs.Status.Conditions, obs.Generation, getDisabledFeatures(obs))
+	if r.noRouteAPI {
+		for _, status := range []*sdiv1alpha1.SDIObserverRouteStatus{&obs.Status.VSystemRoute, &obs.Status.SLCBRoute} {
+			setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionFalse, routeapi.ReasonNotServed,
+				"the cluster does not serve the route API")
+		}
+		// the external service does not need the route API
+		err = measureComponent(r.dhNamespace, componentVSystemRoute, func() error {
+			state := owner.Spec.VSystemRoute.ManagementState
+			switch {
+			case reUnmanaged.MatchString(state):
+				return nil
+			case isServiceExposure(owner.Spec.VSystemRoute) && !reRemoved.MatchString(state):
+				return manageVSystemRoute(ctx, r.scheme, r.client, owner, r.dhNamespace)
+			default:
+				return deleteVSystemExternalService(ctx, r.client, owner, r.dhNamespace)
+			}
+		})
+	} else {
+		err = measureComponent(r.dhNamespace, componentVSystemRoute, func() error {
+			return manageVSystemRoute(ctx, r.scheme, r.client, owner, r.dhNamespace)
+		})
+	}
 	if err != nil {
 		tracer.Error(err, "failed to reconcile vsystem route")
 		ready = append(ready, metav1.Condition{
@@ -166,6 +419,213 @@ func (r *reconciler) doReconcileObs(
 		})
 		return
 	}
+	if !r.noRouteAPI {
+		for _, sr := range serviceRoutes {
+			sr := sr
+			if err = measureComponent(r.dhNamespace, sr.component, func() error {
+				return manageServiceRoute(ctx, r.client, owner, r.dhNamespace, sr)
+			}); err != nil {
+				tracer.Error(err, "failed to reconcile "+sr.desc+" route")
+				degraded = append(degraded, metav1.Condition{
+					Status:  metav1.ConditionTrue,
+					Reason:  "FailedServiceRoute",
+					Message: fmt.Sprintf("failed to reconcile %s route: %v", sr.desc, err),
+				})
+				err = nil
+			}
+		}
+	}
+
+	recordMetrics(ctx, r.client, obs, dh, r.dhNamespace)
+	if err = measureComponent(r.dhNamespace, componentAlerts, func() error {
+		return managePrometheusRule(ctx, r.scheme, r.client, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to reconcile prometheus rule")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedAlerts",
+			Message: fmt.Sprintf("failed to reconcile prometheus rule: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentDashboard, func() error {
+		return manageDashboard(ctx, r.scheme, r.client, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to reconcile grafana dashboard")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedDashboard",
+			Message: fmt.Sprintf("failed to reconcile grafana dashboard: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentNetworkPolicies, func() error {
+		return manageNetworkPolicies(ctx, r.client, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to reconcile network policies")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedNetworkPolicies",
+			Message: fmt.Sprintf("failed to reconcile network policies: %v", err),
+		})
+		err = nil
+	}
+	if pauseDisruptive {
+		msg := "the disruptive changes are paused: " +
+			meta.FindStatusCondition(obs.Status.Conditions, sdiv1alpha1.ConditionVersionSkewDetected).Message
+		tracer.Info(msg)
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  sdiv1alpha1.ConditionVersionSkewDetected,
+			Message: msg,
+		})
+	} else {
+		requeueAfter = r.manageGatedChanges(ctx, obs, &degraded)
+	}
+	if rbacscope.Namespaced() {
+		tracer.V(2).Info("node tuning is disabled in the namespace-scoped RBAC mode")
+	} else if err = measureComponent(r.dhNamespace, componentNodeTuning, func() error {
+		return manageNodeTuning(ctx, r.client, r.apiReader, obs)
+	}); err != nil {
+		tracer.Error(err, "failed to manage node tuning")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedNodeTuning",
+			Message: fmt.Sprintf("failed to manage node tuning: %v", err),
+		})
+		err = nil
+	}
+	if rbacscope.Namespaced() {
+		tracer.V(2).Info("compliance profile is disabled in the namespace-scoped RBAC mode")
+	} else if err = measureComponent(r.dhNamespace, componentCompliance, func() error {
+		return manageCompliance(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to manage compliance profile")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedCompliance",
+			Message: fmt.Sprintf("failed to manage compliance profile: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentNodeConfig, func() error {
+		return manageNodeConfigurator(ctx, r.client, obs)
+	}); err != nil {
+		tracer.Error(err, "failed to manage node configurator")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedNodeConfig",
+			Message: fmt.Sprintf("failed to manage node configurator: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentSCC, func() error {
+		return manageSCCRoleBindings(ctx, r.client, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to manage SCC role bindings")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedSCC",
+			Message: fmt.Sprintf("failed to manage SCC role bindings: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentPodSecurity, func() error {
+		return managePodSecurity(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to manage pod security labels")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedPodSecurity",
+			Message: fmt.Sprintf("failed to manage pod security labels: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentPullSecret, func() error {
+		return managePullSecret(ctx, r.client, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to manage image pull secret")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedPullSecret",
+			Message: fmt.Sprintf("failed to manage image pull secret: %v", err),
+		})
+		err = nil
+	}
+	if err = measureComponent(r.dhNamespace, componentBackup, func() error {
+		return manageBackup(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+	}); err != nil {
+		tracer.Error(err, "failed to manage backup")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedBackup",
+			Message: fmt.Sprintf("failed to manage backup: %v", err),
+		})
+		err = nil
+	}
+	var kaniko bool
+	if kanikoErr := measureComponent(r.dhNamespace, componentKaniko, func() (err error) {
+		kaniko, err = manageKaniko(ctx, r.client, obs, dh)
+		return
+	}); kanikoErr != nil {
+		tracer.Error(kanikoErr, "failed to enable kaniko")
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "FailedKaniko",
+			Message: fmt.Sprintf("failed to enable kaniko for the Pipeline Modeler: %v", kanikoErr),
+		})
+	} else if !kaniko {
+		degraded = append(degraded, metav1.Condition{
+			Status:  metav1.ConditionTrue,
+			Reason:  "KanikoDisabled",
+			Message: "the Pipeline Modeler does not build the images with kaniko as required on OpenShift",
+		})
+	}
+	if len(obs.Spec.Registry.Hostname) > 0 {
+		if err := fips.CheckCertificates("registry CA certificate", obs.Spec.Registry.CACertificate); err != nil {
+			degraded = append(degraded, metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  fips.ReasonNonCompliant,
+				Message: fmt.Sprintf("the registry CA is not trusted by the cluster: %v", err),
+			})
+		}
+	}
+	if !r.renderOnly {
+		if !r.noRouteAPI {
+			probeRoutes(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+			if d := verifyDestinationCA(ctx, r.client, r.apiReader, obs, r.dhNamespace); d > 0 &&
+				(requeueAfter == 0 || d < requeueAfter) {
+				requeueAfter = d
+			}
+		}
+		recordRouteAvailability(obs, r.dhNamespace)
+		checkVSystemHealth(ctx, r.client, r.apiReader, obs, r.dhNamespace)
+		publishURLs(ctx, r.client, obs, r.dhNamespace)
+		if err = measureComponent(r.dhNamespace, componentSCCReport, func() error {
+			return manageSCCReport(ctx, r.scheme, r.client, r.apiReader, obs, r.dhNamespace)
+		}); err != nil {
+			tracer.Error(err, "failed to refresh the SCC report")
+			degraded = append(degraded, metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  "FailedSCCReport",
+				Message: fmt.Sprintf("failed to refresh the SCC report: %v", err),
+			})
+			err = nil
+		}
+		if r.tokens != nil {
+			if err = measureComponent(r.dhNamespace, componentServiceAccountTokens, func() error {
+				return manageServiceAccountTokens(ctx, r.client, r.tokens, obs, r.dhNamespace)
+			}); err != nil {
+				tracer.Error(err, "failed to manage service account tokens")
+				degraded = append(degraded, metav1.Condition{
+					Status:  metav1.ConditionTrue,
+					Reason:  "FailedServiceAccountTokens",
+					Message: fmt.Sprintf("failed to manage service account tokens: %v", err),
+				})
+				err = nil
+			}
+		}
+	}
 
 	ready = append(ready, metav1.Condition{
 		Type:   "Ready",
@@ -190,6 +650,9 @@ func (r *reconciler) doReconcileObs(
 		if owner.Spec.VSystemRoute.ManagementState == sdiv1alpha1.RouteManagementStateRemoved {
 			message = "route(s) removed"
 		}
+		if r.noRouteAPI {
+			message = "route(s) not managed without the route API"
+		}
 		if sdiobservers.IsRouteInCondition(obs.Status.VSystemRoute, "Degraded") {
 			reason = v1alpha1.ConditionReasonIngressBlocked
 			message = "ingress cannot expose managed routes"
@@ -217,6 +680,30 @@ func (r *reconciler) doReconcileObs(
 	return
 }
 
+// pause reports the suspended management without touching the managed objects.
+func (r *reconciler) pause(ctx context.Context, obs *sdiv1alpha1.SDIObserver) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if !meta.IsStatusConditionTrue(obs.Status.Conditions, sdiv1alpha1.ConditionPaused) {
+		tracer.Info("the management of the SDI namespace is paused")
+		r.recorder.Event(obs, corev1.EventTypeNormal, "Paused", "the management of the SDI namespace is paused")
+	}
+	meta.SetStatusCondition(&obs.Status.Conditions, metav1.Condition{
+		Type:               sdiv1alpha1.ConditionPaused,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Paused",
+		Message:            "spec.paused is set, the managed objects are left as they are",
+		ObservedGeneration: obs.Generation,
+	})
+	sdiobservers.SetHealth(obs)
+	status := obs.Status.DeepCopy()
+	return updates.Status(ctx, r.client, obs, func() (bool, error) {
+		obs.Status = *status
+		return true, nil
+	})
+}
+
 // Consolidate lists of conditions into a single one for each type
 func (r *reconciler) updateStatus(
 	ctx context.Context,
@@ -333,7 +820,12 @@ func (r *reconciler) updateStatus(
 		tracer.Info("setting condition", "type", product.Type, "status", product.Status, "reason", product.Reason)
 		meta.SetStatusCondition(&obs.Status.Conditions, product)
 	}
-	// if this ends up in a conflict, let's just do a new reconciliation round
+	sdiobservers.SetHealth(obs)
 	tracer.Info("updating obs", "obs", fmt.Sprintf("%#v", obs))
-	return r.client.Status().Update(ctx, obs)
+	// the status is owned by this controller, so it just replaces the one of a conflicting newer version
+	status := obs.Status.DeepCopy()
+	return updates.Status(ctx, r.client, obs, func() (bool, error) {
+		obs.Status = *status
+		return true, nil
+	})
 }
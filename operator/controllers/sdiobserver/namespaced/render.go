@@ -0,0 +1,271 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+type renderedKey struct {
+	gvk schema.GroupVersionKind
+	types.NamespacedName
+}
+
+// renderingClient reads from the cluster and records the writes instead of sending them. The rendered
+// SDIObserver is served from memory and the recorded objects shadow their live versions.
+type renderingClient struct {
+	client.Client
+	obs     *sdiv1alpha1.SDIObserver
+	order   []renderedKey
+	objects map[renderedKey]*unstructured.Unstructured
+	deleted map[renderedKey]bool
+}
+
+func newRenderingClient(c client.Client, obs *sdiv1alpha1.SDIObserver) *renderingClient {
+	return &renderingClient{
+		Client:  c,
+		obs:     obs,
+		objects: make(map[renderedKey]*unstructured.Unstructured),
+		deleted: make(map[renderedKey]bool),
+	}
+}
+
+func (c *renderingClient) keyOf(obj client.Object) (renderedKey, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return renderedKey{}, err
+	}
+	return renderedKey{gvk: gvk, NamespacedName: client.ObjectKeyFromObject(obj)}, nil
+}
+
+// toManifest converts the object to the form suitable for a commit to Git.
+func toManifest(obj client.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	for _, field := range []string{"resourceVersion", "uid", "generation", "creationTimestamp", "managedFields",
+		"selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	return u, nil
+}
+
+func (c *renderingClient) store(obj client.Object) error {
+	key, err := c.keyOf(obj)
+	if err != nil {
+		return err
+	}
+	manifest, err := toManifest(obj, key.gvk)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; !ok && !c.deleted[key] {
+		c.order = append(c.order, key)
+	}
+	c.objects[key] = manifest
+	delete(c.deleted, key)
+	return nil
+}
+
+func (c *renderingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if obs, ok := obj.(*sdiv1alpha1.SDIObserver); ok && key == client.ObjectKeyFromObject(c.obs) {
+		c.obs.DeepCopyInto(obs)
+		return nil
+	}
+	obj.SetNamespace(key.Namespace)
+	obj.SetName(key.Name)
+	if rk, err := c.keyOf(obj); err == nil {
+		if c.deleted[rk] {
+			return errors.NewNotFound(schema.GroupResource{Group: rk.gvk.Group, Resource: strings.ToLower(rk.gvk.Kind)},
+				key.Name)
+		}
+		if rendered, ok := c.objects[rk]; ok {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				u.Object = rendered.DeepCopy().Object
+				return nil
+			}
+			return runtime.DefaultUnstructuredConverter.FromUnstructured(rendered.Object, obj)
+		}
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *renderingClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	return c.store(obj)
+}
+
+func (c *renderingClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	return c.store(obj)
+}
+
+// Patch records the patched object. The operator either applies or patches the objects mutated in place, so
+// the object holds the desired content in both cases.
+func (c *renderingClient) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	return c.store(obj)
+}
+
+func (c *renderingClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	key, err := c.keyOf(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.objects[key]; !ok && !c.deleted[key] {
+		c.order = append(c.order, key)
+	}
+	delete(c.objects, key)
+	c.deleted[key] = true
+	return nil
+}
+
+func (c *renderingClient) Status() client.StatusWriter {
+	return renderingStatusWriter{}
+}
+
+// renderingStatusWriter drops the status updates.
+type renderingStatusWriter struct{}
+
+func (renderingStatusWriter) Update(context.Context, client.Object, ...client.UpdateOption) error {
+	return nil
+}
+
+func (renderingStatusWriter) Patch(context.Context, client.Object, client.Patch, ...client.PatchOption) error {
+	return nil
+}
+
+// write outputs the recorded objects as a YAML stream in the order of the first write. The deletions are
+// listed as comments.
+func (c *renderingClient) write(out io.Writer) error {
+	for _, key := range c.order {
+		if c.deleted[key] {
+			name := key.Name
+			if len(key.Namespace) > 0 {
+				name = key.Namespace + "/" + name
+			}
+			if _, err := fmt.Fprintf(out, "# delete %s %s\n", strings.ToLower(key.gvk.Kind), name); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := yaml.Marshal(c.objects[key].Object)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(out, "---\n%s", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Render writes the objects the operator would create or patch for the SDIObserver as YAML to out without
+// modifying the cluster. The cluster is only read. If the SDIObserver exists, its metadata and status are used
+// along with the given spec.
+func Render(
+	ctx context.Context,
+	cfg *rest.Config,
+	scheme *runtime.Scheme,
+	obs *sdiv1alpha1.SDIObserver,
+	out io.Writer,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	c, err := newReadClient(cfg, scheme)
+	if err != nil {
+		return err
+	}
+	live := &sdiv1alpha1.SDIObserver{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obs), live); err == nil {
+		spec := obs.Spec
+		obs = live
+		obs.Spec = spec
+	} else if client.IgnoreNotFound(err) != nil && !meta.IsNoMatchError(err) {
+		return err
+	}
+	rc, err := renderObjects(ctx, cfg, scheme, c, obs)
+	if err != nil {
+		return err
+	}
+	return rc.write(out)
+}
+
+func newReadClient(cfg *rest.Config, scheme *runtime.Scheme) (client.Client, error) {
+	mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme, Mapper: mapper})
+}
+
+// renderObjects runs the reconciliation of the SDIObserver against a rendering client reading from c. The
+// pause of the SDIObserver is ignored.
+func renderObjects(
+	ctx context.Context,
+	cfg *rest.Config,
+	scheme *runtime.Scheme,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+) (*renderingClient, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	dhClient, err := NewDHClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dhNamespace := obs.Spec.SDINamespace
+	if len(dhNamespace) == 0 {
+		dhNamespace = obs.Namespace
+	}
+	rc := newRenderingClient(c, obs)
+	r := &reconciler{
+		client:         rc,
+		apiReader:      rc,
+		dhClient:       dhClient,
+		restMapper:     c.RESTMapper(),
+		scheme:         scheme,
+		namespacedName: client.ObjectKeyFromObject(obs),
+		dhNamespace:    dhNamespace,
+		// the events are discarded
+		recorder:   &record.FakeRecorder{},
+		renderOnly: true,
+	}
+	ready, degraded, _, _, err := r.doReconcileObs(ctx, obs)
+	if err != nil {
+		return nil, err
+	}
+	// the objects of the failed components are missing in the output
+	for _, c := range ready {
+		if c.Status == metav1.ConditionFalse {
+			tracer.Info("incomplete rendering", "reason", c.Reason, "message", c.Message)
+		}
+	}
+	for _, c := range degraded {
+		if c.Status == metav1.ConditionTrue {
+			tracer.Info("incomplete rendering", "reason", c.Reason, "message", c.Message)
+		}
+	}
+	return rc, nil
+}
@@ -0,0 +1,154 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+)
+
+// holds the resources of the containers before the override keyed by the container name
+const originalResourcesAnnotationKey = "di.sap-cop.redhat.com/original-resources"
+
+// getResourceOverride returns the override of the container. The container specific one takes precedence.
+func getResourceOverride(
+	components map[string]corev1.ResourceRequirements,
+	workload, container string,
+) (corev1.ResourceRequirements, bool) {
+	if override, ok := components[workload+"/"+container]; ok {
+		return override, true
+	}
+	override, ok := components[workload]
+	return override, ok
+}
+
+// overrideResources restores the original resources of the containers and applies the overrides. If
+// components is nil, the original resources are just restored.
+func overrideResources(
+	annotations map[string]string,
+	workload string,
+	podSpec *corev1.PodSpec,
+	components map[string]corev1.ResourceRequirements,
+) error {
+	original := make(map[string]corev1.ResourceRequirements)
+	if value, ok := annotations[originalResourcesAnnotationKey]; ok {
+		if err := json.Unmarshal([]byte(value), &original); err != nil {
+			return fmt.Errorf("failed to parse annotation %s: %v", originalResourcesAnnotationKey, err)
+		}
+		delete(annotations, originalResourcesAnnotationKey)
+	}
+
+	overridden := make(map[string]corev1.ResourceRequirements)
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if resources, ok := original[container.Name]; ok {
+			container.Resources = resources
+		}
+		override, ok := getResourceOverride(components, workload, container.Name)
+		if !ok {
+			continue
+		}
+		overridden[container.Name] = *container.Resources.DeepCopy()
+		for _, list := range []struct {
+			current  *corev1.ResourceList
+			override corev1.ResourceList
+		}{
+			{&container.Resources.Requests, override.Requests},
+			{&container.Resources.Limits, override.Limits},
+		} {
+			if len(list.override) == 0 {
+				continue
+			}
+			if *list.current == nil {
+				*list.current = make(corev1.ResourceList, len(list.override))
+			}
+			for name, quantity := range list.override {
+				(*list.current)[name] = quantity
+			}
+		}
+	}
+	if len(overridden) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(overridden)
+	if err != nil {
+		return err
+	}
+	annotations[originalResourcesAnnotationKey] = string(value)
+	return nil
+}
+
+// manageResourceOverrides patches the resources of the SDI workloads. The returned changes are deferred
+// until the maintenance window.
+func manageResourceOverrides(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.ResourceOverrides
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("resource overrides are not managed")
+		return nil, maintenance.Result{}, nil
+	}
+	components := spec.Components
+	if reRemoved.MatchString(spec.ManagementState) {
+		components = nil
+	}
+
+	workloads, err := listWorkloads(ctx, c, namespace)
+	if err != nil {
+		return nil, maintenance.Result{}, fmt.Errorf("failed to list workloads: %v", err)
+	}
+	var pending []string
+	var window maintenance.Result
+	for _, obj := range workloads {
+		obj := obj
+		kind, podSpec, err := getPodSpec(obj)
+		if err != nil {
+			return nil, window, err
+		}
+		overridden := false
+		for _, container := range podSpec.Containers {
+			if _, ok := getResourceOverride(components, obj.GetName(), container.Name); ok {
+				overridden = true
+				break
+			}
+		}
+		if _, ok := obj.GetAnnotations()[originalResourcesAnnotationKey]; !ok && !overridden {
+			continue
+		}
+		var op controllerutil.OperationResult
+		op, window, err = maintenance.CreateOrUpdate(ctx, c, obs.Namespace, obj, func() error {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			if err := overrideResources(annotations, obj.GetName(), podSpec, components); err != nil {
+				return err
+			}
+			obj.SetAnnotations(annotations)
+			return nil
+		}, false)
+		if err != nil {
+			return nil, window, fmt.Errorf("failed to override resources of %s %s: %v", kind, obj.GetName(), err)
+		}
+		if op == maintenance.OperationResultDeferred {
+			pending = append(pending, fmt.Sprintf("the resources of %s %s", kind, obj.GetName()))
+		} else if op != controllerutil.OperationResultNone {
+			tracer.Info("overrode resources", "kind", kind, "name", obj.GetName(), "operation", op)
+		}
+	}
+	return pending, window, nil
+}
@@ -2,6 +2,8 @@ package namespaced
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -24,7 +26,12 @@ import (
 	routev1 "github.com/openshift/api/route/v1"
 
 	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/argocd"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/certmanager"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/legacy"
 	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
 )
 
 const (
@@ -75,7 +82,7 @@ func manageVSystemRoute(
 	defer λ.Leave(tracer)
 
 	spec := owner.Spec.VSystemRoute
-	if regexp.MustCompile(`^(\s*|(?i)Unmanaged)$`).MatchString(spec.ManagementState) {
+	if reUnmanaged.MatchString(spec.ManagementState) {
 		tracer.V(2).Info("vsystem route is not managed")
 		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionFalse,
 			"Unmanaged", "the vsystem route is not managed by this SDIObserver instance")
@@ -99,6 +106,63 @@ func manageVSystemRoute(
 		return svcGetErr
 	}
 
+	removed := reRemoved.MatchString(spec.ManagementState)
+	if errors.IsNotFound(svcGetErr) && !removed && spec.OrphanOnDelete {
+		tracer.Info("vsystem service is missing, keeping the exposure objects as instructed")
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionFalse,
+			"Orphaned", "the vsystem service is missing, the exposure objects are kept due to orphanOnDelete")
+		return nil
+	}
+	if !isServiceExposure(spec) || removed || svcGetErr != nil {
+		if err := deleteVSystemExternalService(ctx, client, owner, namespace); err != nil {
+			tracer.Error(err, "failed to delete vsystem external service")
+			setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+				"FailedDelete", fmt.Sprintf("failed to delete vsystem external service: %v", err))
+			return err
+		}
+	}
+	if isServiceMeshExposure(spec) && !removed && svcGetErr == nil {
+		return manageVSystemServiceMesh(ctx, client, owner, svc, namespace)
+	}
+	if err := deleteVSystemServiceMesh(ctx, client, owner, namespace); err != nil {
+		tracer.Error(err, "failed to delete vsystem service mesh objects")
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+			"FailedDelete", fmt.Sprintf("failed to delete vsystem service mesh objects: %v", err))
+		return err
+	}
+	if isServiceExposure(spec) && !removed && svcGetErr == nil {
+		return manageVSystemExternalService(ctx, client, owner, svc, namespace)
+	}
+
+	var routeCert *certmanager.Issued
+	if !removed && svcGetErr == nil {
+		var err error
+		routeCert, err = manageVSystemCertificate(ctx, client, owner, namespace)
+		if notIssued, ok := err.(*certmanager.ErrNotIssued); ok {
+			tracer.Info("vsystem route certificate is not issued yet", "reason", notIssued.Reason)
+			setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionFalse,
+				"WaitingForCertificate", notIssued.Reason)
+			return nil
+		}
+		if err != nil {
+			tracer.Error(err, "failed to manage vsystem route certificate")
+			setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+				"FailedCertificate", fmt.Sprintf("failed to manage vsystem route certificate: %v", err))
+			return err
+		}
+		if routeCert != nil {
+			if err := routeCert.Check(vsystemCertificateSecretName); err != nil {
+				// the route keeps serving the previous certificate
+				tracer.Info("rejecting the vsystem route certificate", "error", err)
+				setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+					fips.ReasonNonCompliant, err.Error())
+				return nil
+			}
+		}
+	}
+
+	// the destination CA bundle of the route once applied
+	var appliedCA string
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		route := &routev1.Route{}
 		routeGetErr := client.Get(ctx, svcKey, route)
@@ -108,8 +172,18 @@ func manageVSystemRoute(
 				"FailedGet", fmt.Sprintf("failed to get vsystem route: %v", svcGetErr))
 			return routeGetErr
 		}
+		if routeGetErr == nil && legacy.Adopt(route) {
+			// reconciled in place below instead of being replaced
+			tracer.Info("adopting the vsystem route created by the legacy sdi-observer")
+			primaryresource.Set(route, owner, "SDIObserver")
+			if err := client.Update(ctx, route); err != nil {
+				setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+					"FailedAdopt", fmt.Sprintf("failed to adopt the legacy vsystem route: %v", err))
+				return err
+			}
+		}
 
-		if regexp.MustCompile("^(?i)removed?$").MatchString(spec.ManagementState) || errors.IsNotFound(svcGetErr) {
+		if reRemoved.MatchString(spec.ManagementState) || errors.IsNotFound(svcGetErr) {
 			if errors.IsNotFound(routeGetErr) {
 				msg := "vsystem route is removed"
 				if errors.IsNotFound(svcGetErr) {
@@ -133,18 +207,19 @@ func manageVSystemRoute(
 			return nil
 		}
 
+		caSecretName, caSecretKey := getDestinationCASecretRef(owner)
 		caBundleSecret := &corev1.Secret{}
 		err := client.Get(ctx, types.NamespacedName{
 			Namespace: namespace,
-			Name:      vsystemCaBundleSecretName,
+			Name:      caSecretName,
 		}, caBundleSecret)
 		if err != nil {
-			tracer.Error(err, "failed to get vsystem/vora ca-bundle.pem secret")
+			tracer.Error(err, "failed to get the destination CA secret", "secret", caSecretName)
 			setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
-				"FailedGet", fmt.Sprintf("failed to get vsystem/vora ca-bundle.pem secret: %v", err))
+				"FailedGet", fmt.Sprintf("failed to get the destination CA secret %s: %v", caSecretName, err))
 			return err
 		}
-		caBundle, err := getCertFromCaBundleSecret(caBundleSecret)
+		caBundle, err := getCertFromCaBundleSecret(caBundleSecret, caSecretKey)
 		if err != nil {
 			setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
 				"InvalidSecret", fmt.Sprintf("%v", err))
@@ -179,48 +254,114 @@ func manageVSystemRoute(
 				},
 			},
 		}
+		newRoute.Labels[primaryresource.ManagedLabelKey] = primaryresource.ManagedLabelValue
+		// selected by the protection webhook
+		newRoute.Labels[primaryresource.ManagedByLabelKey] = primaryresource.ManagedByLabelValue
 		if len(spec.Hostname) > 0 {
 			newRoute.Spec.Host = spec.Hostname
 		}
+		if routeCert != nil {
+			routeCert.ApplyToRoute(newRoute.Spec.TLS)
+		}
+		// the route is generated, so it must not appear out of sync in the Argo CD application of the owner
+		argocd.Annotate(&newRoute)
+		argocd.NormalizeRoute(&newRoute.Spec)
+		hash, err := hashContent([]interface{}{newRoute.Annotations, newRoute.Labels, newRoute.Spec})
+		if err != nil {
+			return err
+		}
+		newRoute.Annotations[appliedHashAnnotationKey] = hash
 
+		appliedCA = caBundle
 		if routeGetErr == nil && len(route.UID) > 0 {
-			changed, updatedFields := updateRoute(route, &newRoute)
-			if !changed {
+			outdated := getOutdatedRouteFields(route, &newRoute)
+			if len(outdated) == 0 {
 				tracer.Info("route is up to date")
 				setStatusForUptodateRoute(ctx, owner, route)
 				return nil
 			}
+			if route.Annotations[appliedHashAnnotationKey] == hash {
+				// the desired content has not changed since the last apply
+				flagDrift(ctx, client, namespace, componentVSystemRoute, route, outdated)
+			}
 
-			tracer.Info("updating route", "fields", strings.Join(updatedFields, ","))
+			tracer.Info("applying route", "fields", strings.Join(outdated, ","))
 			diff := cmp.Diff(route, &newRoute)
-			tracer.V(3).Info("route diff on Update", "diff", diff)
-			err = client.Update(ctx, route)
+			tracer.V(3).Info("route diff on Apply", "diff", diff)
+			err = applyObject(ctx, client, &newRoute)
 			// an immutable field (like TLS certificate) has changed
 			if errors.IsInvalid(err) {
-				tracer.Info("route update has been refused, replacing instead...",
+				tracer.Info("route apply has been refused, replacing instead...",
 					"error type", fmt.Sprintf("%T", err), "error", err)
 				err := client.Delete(ctx, route)
 				if err != nil && !errors.IsNotFound(err) {
 					// TODO set status
 					return err
 				}
-				err = client.Create(ctx, &newRoute)
-				return err
+				return applyObject(ctx, client, &newRoute)
 			}
 			if err != nil {
-				tracer.Info("route update has been refused ...",
+				tracer.Info("route apply has been refused ...",
 					"error type", fmt.Sprintf("%T", err), "error", err)
 			}
 		} else {
 			tracer.Info("creating a new route")
-			err = client.Create(ctx, &newRoute)
+			err = applyObject(ctx, client, &newRoute)
 		}
 		return err
 	})
+	if err == nil && len(appliedCA) > 0 {
+		trackDestinationCARotation(ctx, client, owner, appliedCA)
+	}
 	// TODO set status
 	return err
 }
 
+// deleteVSystemExposure deletes the route, the service mesh objects, the external service and the certificate
+// exposing vsystem once SDI is uninstalled unless they shall be orphaned.
+func deleteVSystemExposure(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	namespace string,
+	noRouteAPI bool,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := owner.Spec.VSystemRoute
+	if reUnmanaged.MatchString(spec.ManagementState) || spec.OrphanOnDelete {
+		tracer.V(2).Info("keeping the vsystem exposure objects")
+		return nil
+	}
+	if !noRouteAPI {
+		route := &routev1.Route{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "vsystem"}, route)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil && primaryresource.IsOwnedBy(route, owner, "SDIObserver") {
+			tracer.Info("deleting vsystem route of the uninstalled SDI")
+			if err := c.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete vsystem route: %v", err)
+			}
+		}
+	}
+	if err := deleteVSystemServiceMesh(ctx, c, owner, namespace); err != nil {
+		return fmt.Errorf("failed to delete vsystem service mesh objects: %v", err)
+	}
+	if err := deleteVSystemExternalService(ctx, c, owner, namespace); err != nil {
+		return fmt.Errorf("failed to delete vsystem external service: %v", err)
+	}
+	if err := deleteVSystemCertificate(ctx, c, owner,
+		certmanager.NewCertificate(namespace, vsystemCertificateName)); err != nil {
+		return fmt.Errorf("failed to delete vsystem certificate: %v", err)
+	}
+	setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionFalse, metav1.ConditionFalse, "Removed",
+		"vsystem route is removed along with SAP Data Intelligence")
+	return nil
+}
+
 // TODO(miminar) move to route utility module
 func findRouteIngressCondition(
 	conditions []routev1.RouteIngressCondition,
@@ -277,61 +418,63 @@ func setStatusForUptodateRoute(ctx context.Context, obs *sdiv1alpha1.SDIObserver
 		sdiv1alpha1.ConditionRouteNotAdmitted, msg)
 }
 
-func updateRoute(current, newRoute *routev1.Route) (bool, []string) {
-	var changed bool
-	var updatedFields = []string{}
-	if current.Annotations == nil {
-		current.Annotations = make(map[string]string)
+// getOutdatedRouteFields returns the fields of the current route differing from the desired ones. Only the
+// fields set by the operator are compared so that the fields added by the other controllers (e.g. a certificate
+// injected by cert-manager) do not trigger an apply.
+func getOutdatedRouteFields(current, desired *routev1.Route) []string {
+	var outdated []string
+	isSubset := func(subset, set map[string]string) bool {
+		for k, v := range subset {
+			if value, ok := set[k]; !ok || value != v {
+				return false
+			}
+		}
+		return true
 	}
-	for _, annKey := range []string{
-		routeAnnotationTimeoutKey,
-		opSdkPrimaryResourceTypeAnnotationKey,
-		opSdkPrimaryResourceAnnotationKey,
+	if !isSubset(desired.Annotations, current.Annotations) {
+		outdated = append(outdated, "annotations")
+	}
+	if !isSubset(desired.Labels, current.Labels) {
+		outdated = append(outdated, "labels")
+	}
+	if !reflect.DeepEqual(current.Spec.Port, desired.Spec.Port) {
+		outdated = append(outdated, "port")
+	}
+	if !isTLSApplied(current.Spec.TLS, desired.Spec.TLS) {
+		outdated = append(outdated, "tls")
+	}
+	// an unset host is either generated or owned by another manager unless previously applied by the operator
+	if (len(desired.Spec.Host) > 0 && current.Spec.Host != desired.Spec.Host) ||
+		(len(desired.Spec.Host) == 0 && isFieldApplied(current, "spec", "host")) {
+		outdated = append(outdated, "host")
+	}
+	if current.Spec.To.Kind != desired.Spec.To.Kind || current.Spec.To.Name != desired.Spec.To.Name {
+		outdated = append(outdated, "to")
+	}
+	return outdated
+}
+
+// isTLSApplied returns true if the non-empty fields of the desired TLS config are set in the current one.
+func isTLSApplied(current, desired *routev1.TLSConfig) bool {
+	if desired == nil {
+		return true
+	}
+	if current == nil {
+		return false
+	}
+	for _, field := range [][2]string{
+		{string(current.Termination), string(desired.Termination)},
+		{current.Certificate, desired.Certificate},
+		{current.Key, desired.Key},
+		{current.CACertificate, desired.CACertificate},
+		{current.DestinationCACertificate, desired.DestinationCACertificate},
+		{string(current.InsecureEdgeTerminationPolicy), string(desired.InsecureEdgeTerminationPolicy)},
 	} {
-		if current.Annotations[annKey] != newRoute.Annotations[annKey] {
-			current.Annotations[annKey] = newRoute.Annotations[annKey]
-			updatedFields = append(updatedFields, "annotations")
-			changed = true
+		if len(field[1]) > 0 && field[0] != field[1] {
+			return false
 		}
 	}
-	if current.Labels == nil {
-		current.Labels = make(map[string]string)
-		changed = true
-	}
-	if !reflect.DeepEqual(current.Labels, newRoute.Labels) {
-		current.Labels = newRoute.Labels
-		updatedFields = append(updatedFields, "labels")
-		changed = true
-	}
-	if !reflect.DeepEqual(current.ObjectMeta.OwnerReferences, newRoute.ObjectMeta.OwnerReferences) {
-		current.ObjectMeta.OwnerReferences = newRoute.ObjectMeta.OwnerReferences
-		updatedFields = append(updatedFields, "ownerReferences")
-		changed = true
-	}
-	if !reflect.DeepEqual(current.Spec.Port, newRoute.Spec.Port) {
-		current.Spec.Port = newRoute.Spec.Port
-		updatedFields = append(updatedFields, "port")
-		changed = true
-	}
-	if !reflect.DeepEqual(current.Spec.TLS, newRoute.Spec.TLS) {
-		current.Spec.TLS = newRoute.Spec.TLS
-		updatedFields = append(updatedFields, "tls")
-		changed = true
-	}
-	if (current.Spec.Host != newRoute.Spec.Host && (len(newRoute.Spec.Host) > 0 || current.Annotations["openshift.io/host.generated"] != "true")) ||
-		(len(newRoute.Spec.Host) > 0 && current.Annotations["openshift.io/host.generated"] == "true") {
-		delete(current.Annotations, "openshift.io/host.generated")
-		current.Spec.Host = newRoute.Spec.Host
-		updatedFields = append(updatedFields, "host")
-		changed = true
-	}
-	if current.Spec.To.Kind != newRoute.Spec.To.Kind || current.Spec.To.Name != newRoute.Spec.To.Name {
-		current.Spec.To.Kind = newRoute.Spec.To.Kind
-		current.Spec.To.Name = newRoute.Spec.To.Name
-		updatedFields = append(updatedFields, "to")
-		changed = true
-	}
-	return changed, updatedFields
+	return true
 }
 
 func getRoutePortForVsystemService(svc *corev1.Service) *routev1.RoutePort {
@@ -356,10 +499,75 @@ func getRouteLabelsForVsystemService(svc *corev1.Service) map[string]string {
 	return labels
 }
 
-func getCertFromCaBundleSecret(secret *corev1.Secret) (string, error) {
-	value, ok := secret.Data[vsystemCaBundleSecretKey]
+// getDestinationCASecretRef returns the name and key of the secret holding the destination CA bundle of the
+// vsystem route.
+func getDestinationCASecretRef(obs *sdiv1alpha1.SDIObserver) (string, string) {
+	name, key := vsystemCaBundleSecretName, vsystemCaBundleSecretKey
+	if ref := obs.Spec.VSystemRoute.DestinationCASecretRef; ref != nil {
+		if len(ref.Name) > 0 {
+			name = ref.Name
+		}
+		if len(ref.Key) > 0 {
+			key = ref.Key
+		}
+	}
+	return name, key
+}
+
+// isDestinationCASecret returns true if the secret is a custom destination CA secret of the SDIObserver. The default
+// secret is watched along with the other objects of the SDI namespace.
+func (r *reconciler) isDestinationCASecret(secret client.Object) bool {
+	if secret.GetNamespace() != r.dhNamespace {
+		return false
+	}
+	obs := &sdiv1alpha1.SDIObserver{}
+	if err := r.client.Get(context.Background(), r.namespacedName, obs); err != nil {
+		return false
+	}
+	refs := []*sdiv1alpha1.SecretKeySelector{obs.Spec.VSystemRoute.DestinationCASecretRef}
+	for _, sr := range serviceRoutes {
+		refs = append(refs, sr.spec(obs).TLS.DestinationCASecretRef)
+	}
+	for _, ref := range refs {
+		if ref != nil && ref.Name == secret.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// getCertFromCaBundleSecret returns the PEM encoded CA bundle stored under the key of the secret. Every
+// certificate of the bundle must be parsable.
+func getCertFromCaBundleSecret(secret *corev1.Secret, key string) (string, error) {
+	value, ok := secret.Data[key]
 	if !ok {
-		return "", fmt.Errorf("failed to find key \"%s\" in \"%s\" secret", vsystemCaBundleSecretKey, secret.ObjectMeta.Name)
+		return "", fmt.Errorf("failed to find key \"%s\" in \"%s\" secret", key, secret.ObjectMeta.Name)
+	}
+	if err := validateCABundle(value); err != nil {
+		return "", fmt.Errorf("invalid CA bundle in key \"%s\" of \"%s\" secret: %v", key,
+			secret.ObjectMeta.Name, err)
 	}
 	return strings.TrimSpace(string(value[:])), nil
 }
+
+func validateCABundle(data []byte) error {
+	var count int
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("failed to parse certificate #%d: %v", count+1, err)
+		}
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("no PEM encoded certificate found")
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// the name of the service account the token of the secret is issued for
+	boundTokenLabelKey              = "di.sap-cop.redhat.com/bound-token-for"
+	boundTokenExpirationAnnotation  = "di.sap-cop.redhat.com/token-expiration"
+	boundTokenRenewTimeAnnotation   = "di.sap-cop.redhat.com/token-renew-time"
+	boundTokenRequestHashAnnotation = "di.sap-cop.redhat.com/token-request-hash"
+	boundTokenSecretKey             = "token"
+
+	defaultBoundTokenExpiration = time.Hour * 24
+	// the part of the token lifetime after which the token is renewed
+	boundTokenRenewFraction = 0.8
+)
+
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts/token,verbs=create
+
+func getBoundTokenSecretName(token sdiv1alpha1.SDIObserverServiceAccountToken) string {
+	if len(token.SecretName) > 0 {
+		return token.SecretName
+	}
+	return token.ServiceAccount + "-bound-token"
+}
+
+func getBoundTokenExpiration(token sdiv1alpha1.SDIObserverServiceAccountToken) time.Duration {
+	if token.Expiration == nil {
+		return defaultBoundTokenExpiration
+	}
+	return token.Expiration.Duration
+}
+
+// nextTokenRenewalIn returns the delay until the earliest renewal of the bound tokens or zero if the tokens are
+// not managed.
+func nextTokenRenewalIn(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	if !reManaged.MatchString(obs.Spec.ServiceAccountTokens.ManagementState) {
+		return 0
+	}
+	var next time.Duration
+	for _, status := range obs.Status.ServiceAccountTokens {
+		if status.RenewTime == nil {
+			continue
+		}
+		d := time.Until(status.RenewTime.Time)
+		if d < time.Second {
+			d = time.Second
+		}
+		if next == 0 || d < next {
+			next = d
+		}
+	}
+	return next
+}
+
+// getLegacyTokenSecrets returns the names of the long-lived token secrets per service account.
+func getLegacyTokenSecrets(secrets []corev1.Secret) map[string][]string {
+	legacy := map[string][]string{}
+	for _, secret := range secrets {
+		if secret.Type != corev1.SecretTypeServiceAccountToken {
+			continue
+		}
+		sa := secret.Annotations[corev1.ServiceAccountNameKey]
+		legacy[sa] = append(legacy[sa], secret.Name)
+	}
+	for _, names := range legacy {
+		sort.Strings(names)
+	}
+	return legacy
+}
+
+// manageBoundToken requests a new token for the service account if the one stored in the secret is due for
+// renewal or has been requested with different parameters.
+func manageBoundToken(
+	ctx context.Context,
+	c client.Client,
+	tokens corev1client.ServiceAccountsGetter,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+	token sdiv1alpha1.SDIObserverServiceAccountToken,
+) (*sdiv1alpha1.SDIObserverServiceAccountTokenStatus, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	status := &sdiv1alpha1.SDIObserverServiceAccountTokenStatus{
+		ServiceAccount: token.ServiceAccount,
+		SecretName:     getBoundTokenSecretName(token),
+	}
+	expiration := getBoundTokenExpiration(token)
+	if expiration < time.Minute*10 {
+		return nil, fmt.Errorf("the expiration of the token of service account %s must be at least 10m",
+			token.ServiceAccount)
+	}
+	hash, err := hashContent([]interface{}{token.Audiences, expiration.String()})
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: status.SecretName}}
+	err = c.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+	if exists && !primaryresource.IsOwnedBy(secret, obs, "SDIObserver") {
+		return nil, fmt.Errorf("secret %s/%s exists and is not managed by the observer", namespace, secret.Name)
+	}
+	if exists && secret.Annotations[boundTokenRequestHashAnnotation] == hash &&
+		len(secret.Data[boundTokenSecretKey]) > 0 {
+		renewTime, renewErr := time.Parse(time.RFC3339, secret.Annotations[boundTokenRenewTimeAnnotation])
+		expirationTime, expErr := time.Parse(time.RFC3339, secret.Annotations[boundTokenExpirationAnnotation])
+		if renewErr == nil && expErr == nil && time.Now().Before(renewTime) {
+			status.RenewTime = &metav1.Time{Time: renewTime}
+			status.ExpirationTime = &metav1.Time{Time: expirationTime}
+			return status, nil
+		}
+	}
+
+	sa := &corev1.ServiceAccount{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: token.ServiceAccount}, sa); err != nil {
+		return nil, fmt.Errorf("failed to get service account %s: %v", token.ServiceAccount, err)
+	}
+	seconds := int64(expiration.Seconds())
+	opts := metav1.CreateOptions{}
+	if dryrun.Enabled() {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	issued := time.Now()
+	tr, err := tokens.ServiceAccounts(namespace).CreateToken(ctx, sa.Name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         token.Audiences,
+			ExpirationSeconds: &seconds,
+		},
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request a token for service account %s: %v", sa.Name, err)
+	}
+	expirationTime := tr.Status.ExpirationTimestamp.Time
+	// the API server may shorten the lifetime
+	renewTime := issued.Add(time.Duration(float64(expirationTime.Sub(issued)) * boundTokenRenewFraction)).
+		Truncate(time.Second)
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+		primaryresource.Set(secret, obs, "SDIObserver")
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[boundTokenLabelKey] = sa.Name
+		secret.Annotations[boundTokenExpirationAnnotation] = expirationTime.UTC().Format(time.RFC3339)
+		secret.Annotations[boundTokenRenewTimeAnnotation] = renewTime.UTC().Format(time.RFC3339)
+		secret.Annotations[boundTokenRequestHashAnnotation] = hash
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{boundTokenSecretKey: []byte(tr.Status.Token)}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store the token of service account %s: %v", sa.Name, err)
+	}
+	tracer.Info("issued a bound service account token", "serviceAccount", sa.Name, "secret", secret.Name,
+		"expiration", expirationTime, "renewal", renewTime)
+	status.RenewTime = &metav1.Time{Time: renewTime}
+	status.ExpirationTime = &tr.Status.ExpirationTimestamp
+	return status, nil
+}
+
+// manageServiceAccountTokens keeps the bound tokens of the service accounts of the SDI namespace valid and
+// deletes the secrets of the tokens no longer requested.
+func manageServiceAccountTokens(
+	ctx context.Context,
+	c client.Client,
+	tokens corev1client.ServiceAccountsGetter,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.ServiceAccountTokens
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("service account tokens are not managed")
+		return nil
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+
+	secrets := &corev1.SecretList{}
+	if err := c.List(ctx, secrets, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	legacy := getLegacyTokenSecrets(secrets.Items)
+
+	var statuses []sdiv1alpha1.SDIObserverServiceAccountTokenStatus
+	keep := map[string]struct{}{}
+	if !removed {
+		for _, token := range spec.Tokens {
+			name := getBoundTokenSecretName(token)
+			if _, ok := keep[name]; ok {
+				return fmt.Errorf("secret %s is referenced by more than one token", name)
+			}
+			keep[name] = struct{}{}
+			status, err := manageBoundToken(ctx, c, tokens, obs, namespace, token)
+			if err != nil {
+				return err
+			}
+			status.LegacySecrets = legacy[token.ServiceAccount]
+			statuses = append(statuses, *status)
+		}
+	}
+
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if _, ok := secret.Labels[boundTokenLabelKey]; !ok {
+			continue
+		}
+		if _, ok := keep[secret.Name]; ok || !primaryresource.IsOwnedBy(secret, obs, "SDIObserver") {
+			continue
+		}
+		tracer.Info("deleting bound service account token", "namespace", namespace, "name", secret.Name)
+		if err := c.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete secret %s/%s: %v", namespace, secret.Name, err)
+		}
+	}
+	obs.Status.ServiceAccountTokens = statuses
+	return nil
+}
@@ -0,0 +1,129 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	sccRoleBindingPrefix = "sdi-observer-scc-"
+	anyuidSCCClusterRole = "system:openshift:scc:anyuid"
+)
+
+// the service accounts granted the privileged SCC by the SDI installation guide
+var defaultPrivilegedServiceAccounts = []string{
+	"$(NAMESPACE)-elasticsearch",
+	"$(NAMESPACE)-fluentd",
+	"default",
+	"mlf-deployment-api",
+	"vora-vflow-server",
+	"vora-vsystem-$(NAMESPACE)",
+	"vora-vsystem-$(NAMESPACE)-vrep",
+}
+
+//+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=anyuid,verbs=use
+
+func makeSCCRoleBindings(spec sdiv1alpha1.SDIObserverSpecSCC, namespace string) []*rbacv1.RoleBinding {
+	saNames := spec.PrivilegedServiceAccounts
+	if len(saNames) == 0 {
+		saNames = defaultPrivilegedServiceAccounts
+	}
+	privileged := make([]rbacv1.Subject, 0, len(saNames))
+	for _, name := range saNames {
+		privileged = append(privileged, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: namespace,
+			Name:      strings.ReplaceAll(name, "$(NAMESPACE)", namespace),
+		})
+	}
+
+	newBinding := func(scc, clusterRole string, subjects []rbacv1.Subject) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: sccRoleBindingPrefix + scc},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     clusterRole,
+			},
+			Subjects: subjects,
+		}
+	}
+	return []*rbacv1.RoleBinding{
+		newBinding("anyuid", anyuidSCCClusterRole, []rbacv1.Subject{{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.GroupKind,
+			Name:     "system:serviceaccounts:" + namespace,
+		}}),
+		newBinding("privileged", privilegedSCCClusterRole, privileged),
+	}
+}
+
+// manageSCCRoleBindings binds the SCCs to the SDI service accounts and corrects any drift.
+func manageSCCRoleBindings(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.SecurityContextConstraints
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("SCC bindings are not managed")
+		return nil
+	}
+	removed := reRemoved.MatchString(spec.ManagementState)
+
+	for _, rb := range makeSCCRoleBindings(spec, namespace) {
+		rb := rb
+		if removed {
+			existing := &rbacv1.RoleBinding{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(rb), existing); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if !primaryresource.IsOwnedBy(existing, obs, "SDIObserver") {
+				continue
+			}
+			tracer.Info("deleting SCC role binding", "name", rb.Name)
+			if err := c.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete role binding %s: %v", rb.Name, err)
+			}
+			continue
+		}
+
+		roleRef, subjects := rb.RoleRef, rb.Subjects
+		op, err := controllerutil.CreateOrUpdate(ctx, c, rb, func() error {
+			primaryresource.Set(rb, obs, "SDIObserver")
+			if !rb.CreationTimestamp.IsZero() && rb.RoleRef != roleRef {
+				return fmt.Errorf("role binding %s refers to %s, refusing to change the immutable roleRef",
+					rb.Name, rb.RoleRef.Name)
+			}
+			rb.RoleRef = roleRef
+			rb.Subjects = subjects
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to manage role binding %s: %v", rb.Name, err)
+		}
+		if op != controllerutil.OperationResultNone {
+			tracer.Info("managed SCC role binding", "name", rb.Name, "operation", op)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+const (
+	sccReportConfigMapKey    = "report.json"
+	defaultSCCReportInterval = time.Hour * 6
+	// the annotation set by the SCC admission on the admitted pods
+	sccAnnotationKey = "openshift.io/scc"
+	// a service account that does not exist; the SCCs it may use are usable by every service account
+	sccBaselineServiceAccount = "sdi-observer-scc-baseline"
+)
+
+//+kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=get;list
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+//+kubebuilder:rbac:groups=core,resources=pods;serviceaccounts,verbs=get;list;watch
+
+// sccReport compares the granted and used SCCs of the service accounts of the SDI namespace.
+type sccReport struct {
+	Namespace       string                    `json:"namespace"`
+	Time            metav1.Time               `json:"time"`
+	ServiceAccounts []sccReportServiceAccount `json:"serviceAccounts"`
+	SCCs            []sccReportSCC            `json:"sccs"`
+	Recommendations []string                  `json:"recommendations,omitempty"`
+}
+
+type sccReportServiceAccount struct {
+	Name string `json:"name"`
+	// The SCCs the service account may use apart from those usable by every service account.
+	Granted []string `json:"granted,omitempty"`
+	// The SCCs admitting the running pods of the service account.
+	Used []string `json:"used,omitempty"`
+}
+
+type sccReportSCC struct {
+	Name      string   `json:"name"`
+	GrantedTo []string `json:"grantedTo,omitempty"`
+	UsedBy    []string `json:"usedBy,omitempty"`
+}
+
+func sccReportConfigMapName(obs *sdiv1alpha1.SDIObserver) string {
+	return obs.Name + "-scc-report"
+}
+
+func getSCCReportInterval(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	if obs.Spec.SCCReport.Interval == nil {
+		return defaultSCCReportInterval
+	}
+	return obs.Spec.SCCReport.Interval.Duration
+}
+
+// nextSCCReportIn returns the delay until the next refresh of the report is due or zero if the report is not
+// managed.
+func nextSCCReportIn(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	interval := getSCCReportInterval(obs)
+	if !reManaged.MatchString(obs.Spec.SCCReport.ManagementState) || interval <= 0 ||
+		rbacscope.Namespaced() {
+		return 0
+	}
+	if obs.Status.SCCReport == nil || obs.Status.SCCReport.LastUpdateTime == nil {
+		return time.Second
+	}
+	next := time.Until(obs.Status.SCCReport.LastUpdateTime.Add(interval))
+	if next < time.Second {
+		next = time.Second
+	}
+	return next
+}
+
+// canUseSCC asks the API server whether the service account may use the SCC.
+func canUseSCC(ctx context.Context, c client.Client, namespace, serviceAccount, scc string) (bool, error) {
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount),
+			Groups: []string{
+				"system:authenticated",
+				"system:serviceaccounts",
+				"system:serviceaccounts:" + namespace,
+			},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "use",
+				Group:     "security.openshift.io",
+				Resource:  "securitycontextconstraints",
+				Name:      scc,
+			},
+		},
+	}
+	if err := c.Create(ctx, sar); err != nil {
+		return false, err
+	}
+	return sar.Status.Allowed, nil
+}
+
+// getSCCUsers returns the names of the SCCs. The users and groups listed in the SCCs are honoured by the
+// admission besides the RBAC; the returned function tells whether the service account is one of them.
+func getSCCUsers(
+	ctx context.Context,
+	apiReader client.Reader,
+	namespace string,
+) ([]string, map[string]func(string) bool, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion("security.openshift.io/v1")
+	list.SetKind("SecurityContextConstraintsList")
+	if err := apiReader.List(ctx, list); err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	listed := make(map[string]func(string) bool, len(list.Items))
+	for i := range list.Items {
+		scc := &list.Items[i]
+		users, _, _ := unstructured.NestedStringSlice(scc.Object, "users")
+		groups, _, _ := unstructured.NestedStringSlice(scc.Object, "groups")
+		userSet, groupSet := sets.NewString(users...), sets.NewString(groups...)
+		names = append(names, scc.GetName())
+		listed[scc.GetName()] = func(sa string) bool {
+			return userSet.Has(fmt.Sprintf("system:serviceaccount:%s:%s", namespace, sa)) ||
+				groupSet.HasAny("system:authenticated", "system:serviceaccounts", "system:serviceaccounts:"+namespace)
+		}
+	}
+	sort.Strings(names)
+	return names, listed, nil
+}
+
+// makeSCCReport evaluates the grants of the SCCs to the service accounts of the namespace and their use by the
+// running pods.
+func makeSCCReport(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	namespace string,
+) (*sccReport, error) {
+	sccNames, listed, err := getSCCUsers(ctx, apiReader, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the SCCs: %v", err)
+	}
+	sas := &corev1.ServiceAccountList{}
+	if err := c.List(ctx, sas, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	used := map[string]sets.String{}
+	for _, pod := range pods.Items {
+		scc := pod.Annotations[sccAnnotationKey]
+		if len(scc) == 0 || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		sa := pod.Spec.ServiceAccountName
+		if len(sa) == 0 {
+			sa = "default"
+		}
+		if used[sa] == nil {
+			used[sa] = sets.NewString()
+		}
+		used[sa].Insert(scc)
+	}
+
+	isGranted := func(sa, scc string) (bool, error) {
+		if listed[scc](sa) {
+			return true, nil
+		}
+		return canUseSCC(ctx, c, namespace, sa, scc)
+	}
+	baseline := sets.NewString()
+	for _, scc := range sccNames {
+		granted, err := isGranted(sccBaselineServiceAccount, scc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to review the use of SCC %s: %v", scc, err)
+		}
+		if granted {
+			baseline.Insert(scc)
+		}
+	}
+
+	report := &sccReport{Namespace: namespace, Time: metav1.Now()}
+	grantedTo, usedBy := map[string][]string{}, map[string][]string{}
+	sort.Slice(sas.Items, func(i, j int) bool { return sas.Items[i].Name < sas.Items[j].Name })
+	for _, sa := range sas.Items {
+		entry := sccReportServiceAccount{Name: sa.Name, Used: used[sa.Name].List()}
+		for _, scc := range sccNames {
+			if baseline.Has(scc) {
+				continue
+			}
+			granted, err := isGranted(sa.Name, scc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to review the use of SCC %s by %s: %v", scc, sa.Name, err)
+			}
+			if granted {
+				entry.Granted = append(entry.Granted, scc)
+				grantedTo[scc] = append(grantedTo[scc], sa.Name)
+			}
+		}
+		for _, scc := range entry.Used {
+			usedBy[scc] = append(usedBy[scc], sa.Name)
+		}
+		report.ServiceAccounts = append(report.ServiceAccounts, entry)
+	}
+
+	for _, scc := range sccNames {
+		if len(grantedTo[scc]) == 0 && len(usedBy[scc]) == 0 {
+			continue
+		}
+		report.SCCs = append(report.SCCs, sccReportSCC{Name: scc, GrantedTo: grantedTo[scc], UsedBy: usedBy[scc]})
+		if len(grantedTo[scc]) == 0 {
+			continue
+		}
+		unused := sets.NewString(grantedTo[scc]...).Difference(sets.NewString(usedBy[scc]...)).List()
+		switch {
+		case len(unused) == 0:
+		case len(unused) == len(grantedTo[scc]):
+			report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+				"revoke the %s SCC, no pod uses it (granted to %s)", scc, strings.Join(unused, ", ")))
+		default:
+			report.Recommendations = append(report.Recommendations, fmt.Sprintf(
+				"bind the %s SCC only to %s, not used by %s", scc,
+				strings.Join(sets.NewString(usedBy[scc]...).Intersection(sets.NewString(grantedTo[scc]...)).List(),
+					", "),
+				strings.Join(unused, ", ")))
+		}
+	}
+	return report, nil
+}
+
+// manageSCCReport refreshes the SCC usage report in the config map in the namespace of the SDIObserver once the
+// interval elapses.
+func manageSCCReport(
+	ctx context.Context,
+	scheme *k8sruntime.Scheme,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.SCCReport
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("SCC report is not managed")
+		return nil
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: obs.Namespace, Name: sccReportConfigMapName(obs)}}
+	if reRemoved.MatchString(spec.ManagementState) || rbacscope.Namespaced() {
+		obs.Status.SCCReport = nil
+		if err := c.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	interval := getSCCReportInterval(obs)
+	status := obs.Status.SCCReport
+	if interval <= 0 || (status != nil && status.LastUpdateTime != nil &&
+		time.Since(status.LastUpdateTime.Time) < interval) {
+		return nil
+	}
+
+	report, err := makeSCCReport(ctx, c, apiReader, namespace)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		cm.Data = map[string]string{sccReportConfigMapKey: string(data)}
+		primaryresource.SetManagedBy(cm)
+		return controllerutil.SetControllerReference(obs, cm, scheme)
+	}); err != nil {
+		return err
+	}
+	tracer.Info("refreshed the SCC report", "recommendations", len(report.Recommendations))
+	obs.Status.SCCReport = &sdiv1alpha1.SDIObserverSCCReportStatus{
+		LastUpdateTime:  &report.Time,
+		ConfigMap:       cm.Name,
+		Recommendations: report.Recommendations,
+	}
+	return nil
+}
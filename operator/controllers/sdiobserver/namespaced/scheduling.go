@@ -0,0 +1,220 @@
+package namespaced
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+)
+
+// records what has been injected into the workload so that it can be reverted or replaced
+const schedulingAnnotationKey = "di.sap-cop.redhat.com/injected-scheduling"
+
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;watch;update;patch
+
+type injectedScheduling struct {
+	Tolerations  []corev1.Toleration  `json:"tolerations,omitempty"`
+	NodeAffinity *corev1.NodeAffinity `json:"nodeAffinity,omitempty"`
+	// the node affinity of the workload before the injection
+	OriginalNodeAffinity *corev1.NodeAffinity `json:"originalNodeAffinity,omitempty"`
+}
+
+// revertScheduling removes the previously injected tolerations and restores the original node affinity.
+func revertScheduling(podSpec *corev1.PodSpec, injected *injectedScheduling) {
+	tolerations := podSpec.Tolerations[:0]
+	for _, t := range podSpec.Tolerations {
+		found := false
+		for _, it := range injected.Tolerations {
+			if equality.Semantic.DeepEqual(t, it) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tolerations = append(tolerations, t)
+		}
+	}
+	podSpec.Tolerations = tolerations
+	if len(podSpec.Tolerations) == 0 {
+		podSpec.Tolerations = nil
+	}
+
+	if injected.NodeAffinity == nil {
+		return
+	}
+	if injected.OriginalNodeAffinity != nil {
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		podSpec.Affinity.NodeAffinity = injected.OriginalNodeAffinity
+	} else if podSpec.Affinity != nil {
+		podSpec.Affinity.NodeAffinity = nil
+		if equality.Semantic.DeepEqual(*podSpec.Affinity, corev1.Affinity{}) {
+			podSpec.Affinity = nil
+		}
+	}
+}
+
+// injectScheduling replaces the previously injected tolerations and node affinity with the desired ones. If
+// spec is nil, the injected settings are reverted.
+func injectScheduling(
+	annotations map[string]string,
+	podSpec *corev1.PodSpec,
+	spec *sdiv1alpha1.SDIObserverSpecScheduling,
+) error {
+	if value, ok := annotations[schedulingAnnotationKey]; ok {
+		injected := &injectedScheduling{}
+		if err := json.Unmarshal([]byte(value), injected); err != nil {
+			return fmt.Errorf("failed to parse annotation %s: %v", schedulingAnnotationKey, err)
+		}
+		revertScheduling(podSpec, injected)
+		delete(annotations, schedulingAnnotationKey)
+	}
+	if spec == nil {
+		return nil
+	}
+
+	injected := &injectedScheduling{}
+	for _, t := range spec.Tolerations {
+		present := false
+		for _, pt := range podSpec.Tolerations {
+			if equality.Semantic.DeepEqual(t, pt) {
+				present = true
+				break
+			}
+		}
+		// tolerations set by somebody else are left alone when reverting
+		if !present {
+			podSpec.Tolerations = append(podSpec.Tolerations, t)
+			injected.Tolerations = append(injected.Tolerations, t)
+		}
+	}
+	if spec.NodeAffinity != nil {
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+		injected.OriginalNodeAffinity = podSpec.Affinity.NodeAffinity
+		injected.NodeAffinity = spec.NodeAffinity.DeepCopy()
+		podSpec.Affinity.NodeAffinity = spec.NodeAffinity.DeepCopy()
+	}
+	if len(injected.Tolerations) == 0 && injected.NodeAffinity == nil {
+		return nil
+	}
+	value, err := json.Marshal(injected)
+	if err != nil {
+		return err
+	}
+	annotations[schedulingAnnotationKey] = string(value)
+	return nil
+}
+
+// listWorkloads returns the Deployments, StatefulSets and DaemonSets of the namespace.
+func listWorkloads(ctx context.Context, c client.Client, namespace string) ([]client.Object, error) {
+	var workloads []client.Object
+	deployments := &appsv1.DeploymentList{}
+	if err := c.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, &deployments.Items[i])
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := c.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, &statefulSets.Items[i])
+	}
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := c.List(ctx, daemonSets, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		workloads = append(workloads, &daemonSets.Items[i])
+	}
+	return workloads, nil
+}
+
+// getPodSpec returns the kind and the pod template spec of the workload.
+func getPodSpec(obj client.Object) (string, *corev1.PodSpec, error) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return "deployment", &o.Spec.Template.Spec, nil
+	case *appsv1.StatefulSet:
+		return "statefulset", &o.Spec.Template.Spec, nil
+	case *appsv1.DaemonSet:
+		return "daemonset", &o.Spec.Template.Spec, nil
+	}
+	return "", nil, fmt.Errorf("unexpected workload type %T", obj)
+}
+
+// manageScheduling injects the tolerations and node affinity into the SDI workloads. The returned changes are
+// deferred until the maintenance window.
+func manageScheduling(
+	ctx context.Context,
+	c client.Client,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := &obs.Spec.Scheduling
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("scheduling of the SDI workloads is not managed")
+		return nil, maintenance.Result{}, nil
+	}
+	if reRemoved.MatchString(spec.ManagementState) {
+		spec = nil
+	}
+
+	workloads, err := listWorkloads(ctx, c, namespace)
+	if err != nil {
+		return nil, maintenance.Result{}, fmt.Errorf("failed to list workloads: %v", err)
+	}
+	var pending []string
+	var window maintenance.Result
+	for _, obj := range workloads {
+		obj := obj
+		if spec == nil {
+			if _, ok := obj.GetAnnotations()[schedulingAnnotationKey]; !ok {
+				continue
+			}
+		}
+		kind, podSpec, err := getPodSpec(obj)
+		if err != nil {
+			return nil, window, err
+		}
+		var op controllerutil.OperationResult
+		op, window, err = maintenance.CreateOrUpdate(ctx, c, obs.Namespace, obj, func() error {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			if err := injectScheduling(annotations, podSpec, spec); err != nil {
+				return err
+			}
+			obj.SetAnnotations(annotations)
+			return nil
+		}, false)
+		if err != nil {
+			return nil, window, fmt.Errorf("failed to inject scheduling into %s %s: %v", kind, obj.GetName(), err)
+		}
+		if op == maintenance.OperationResultDeferred {
+			pending = append(pending, fmt.Sprintf("the scheduling of %s %s", kind, obj.GetName()))
+		} else if op != controllerutil.OperationResultNone {
+			tracer.Info("injected scheduling", "kind", kind, "name", obj.GetName(), "operation", op)
+		}
+	}
+	return pending, window, nil
+}
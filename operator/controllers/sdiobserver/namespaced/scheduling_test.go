@@ -0,0 +1,176 @@
+package namespaced_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	. "github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiobserver/namespaced"
+	testapi "github.com/redhat-sap/sap-data-intelligence/operator/test/api"
+	ωbs "github.com/redhat-sap/sap-data-intelligence/operator/test/sdiobservers"
+)
+
+var _ = Describe("Scheduling of the SDI workloads", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+
+		schedulingAnnotation = "di.sap-cop.redhat.com/injected-scheduling"
+	)
+
+	// set by somebody else, it must survive the revert
+	foreignToleration := corev1.Toleration{Key: "example.ltd/foreign", Operator: corev1.TolerationOpExists}
+	injectedToleration := corev1.Toleration{
+		Key:      "node-role.kubernetes.io/sdi",
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	nodeAffinity := &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      "node-role.kubernetes.io/sdi",
+					Operator: corev1.NodeSelectorOpExists,
+				}},
+			}},
+		},
+	}
+
+	podTemplate := func() corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "scheduled"}},
+			Spec: corev1.PodSpec{
+				Containers:  []corev1.Container{{Name: "main", Image: "registry.example.ltd/main:latest"}},
+				Tolerations: []corev1.Toleration{foreignToleration},
+			},
+		}
+	}
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "scheduled"},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "scheduled"}},
+				Template: podTemplate(),
+			},
+		}
+	}
+	newStatefulSet := func() *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "scheduled"},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "scheduled"}},
+				Template: podTemplate(),
+			},
+		}
+	}
+
+	createObs := func(managementState string) *sdiv1alpha1.SDIObserver {
+		obs := &sdiv1alpha1.SDIObserver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sdi",
+				Namespace: "sdi-observer",
+			},
+			Spec: sdiv1alpha1.SDIObserverSpec{
+				SDINamespace: "sdi",
+				VSystemRoute: sdiv1alpha1.SDIObserverSpecRoute{
+					ManagementState: sdiv1alpha1.RouteManagementStateUnmanaged,
+				},
+				Scheduling: sdiv1alpha1.SDIObserverSpecScheduling{
+					ManagementState: managementState,
+					Tolerations:     []corev1.Toleration{injectedToleration},
+					NodeAffinity:    nodeAffinity,
+				},
+			},
+		}
+		Ω(k8sClient.Create(context.Background(), obs)).NotTo(HaveOccurred())
+		// the controller does not watch the SDIObserver resource on its own
+		nmCtrl.ReconcileObs(obs)
+		return obs
+	}
+
+	getPodSpec := func(g Gomega, obj client.Object) *corev1.PodSpec {
+		g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)).NotTo(HaveOccurred())
+		_, podSpec, err := GetPodSpec(obj)
+		g.Ω(err).NotTo(HaveOccurred())
+		return podSpec
+	}
+
+	var workloads []client.Object
+
+	BeforeEach(func() {
+		workloads = []client.Object{newDeployment(), newStatefulSet()}
+		for _, obj := range workloads {
+			Ω(k8sClient.Create(context.Background(), obj)).NotTo(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		for _, obj := range append(workloads, &sdiv1alpha1.SDIObserver{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "sdi-observer", Name: "sdi"},
+		}) {
+			err := k8sClient.Delete(context.Background(), obj)
+			Ω(err).Should(Or(BeNil(), testapi.FailWithStatus(metav1.StatusReasonNotFound)))
+		}
+	})
+
+	Context("When the scheduling is Managed", func() {
+		It("Should inject the scheduling into the workloads and revert it once Removed", func() {
+			obs := createObs(sdiv1alpha1.RouteManagementStateManaged)
+			for _, obj := range workloads {
+				Eventually(func(g Gomega) {
+					podSpec := getPodSpec(g, obj)
+					g.Ω(obj.GetAnnotations()).To(HaveKey(schedulingAnnotation))
+					g.Ω(podSpec.Tolerations).To(Equal([]corev1.Toleration{foreignToleration, injectedToleration}))
+					g.Ω(podSpec.Affinity).NotTo(BeNil())
+					g.Ω(podSpec.Affinity.NodeAffinity).To(Equal(nodeAffinity))
+				}, timeout, interval).Should(Succeed())
+			}
+
+			By("reverting the injected settings")
+			ωbs.Update(k8sClient, obs, func(obs *sdiv1alpha1.SDIObserver) {
+				obs.Spec.Scheduling.ManagementState = sdiv1alpha1.RouteManagementStateRemoved
+			})
+			nmCtrl.ReconcileObs(obs)
+			for _, obj := range workloads {
+				Eventually(func(g Gomega) {
+					podSpec := getPodSpec(g, obj)
+					g.Ω(obj.GetAnnotations()).NotTo(HaveKey(schedulingAnnotation))
+					g.Ω(podSpec.Tolerations).To(Equal([]corev1.Toleration{foreignToleration}))
+					g.Ω(podSpec.Affinity).To(BeNil())
+				}, timeout, interval).Should(Succeed())
+			}
+		})
+	})
+
+	Context("When the scheduling is Unmanaged", func() {
+		It("Should leave the workloads alone", func() {
+			obs := createObs(sdiv1alpha1.RouteManagementStateUnmanaged)
+			ωbs.WaitForObserverState(k8sClient, 0, obs, func(g Gomega, obs *sdiv1alpha1.SDIObserver) {
+				g.Ω(obs.Status.ManagedDataHubRef).NotTo(BeNil())
+			})
+			for _, obj := range workloads {
+				Consistently(func(g Gomega) {
+					podSpec := getPodSpec(g, obj)
+					g.Ω(obj.GetAnnotations()).NotTo(HaveKey(schedulingAnnotation))
+					g.Ω(podSpec.Tolerations).To(Equal([]corev1.Toleration{foreignToleration}))
+					g.Ω(podSpec.Affinity).To(BeNil())
+				}, time.Second, interval).Should(Succeed())
+			}
+		})
+	})
+
+	Context("When the workload is of an unknown kind", func() {
+		It("Should refuse to patch it", func() {
+			_, podSpec, err := GetPodSpec(&corev1.Pod{})
+			Ω(err).To(MatchError(ContainSubstring("unexpected workload type *v1.Pod")))
+			Ω(podSpec).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,202 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	vsystemMeshObjectName = "vsystem"
+	meshHTTPSPortNumber   = 443
+)
+
+var meshKinds = []string{"Gateway", "VirtualService", "DestinationRule"}
+
+//+kubebuilder:rbac:groups=networking.istio.io,resources=gateways;virtualservices;destinationrules,verbs=get;list;watch;create;update;patch;delete
+
+func newMeshObject(kind, namespace string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    kind,
+	})
+	obj.SetNamespace(namespace)
+	obj.SetName(vsystemMeshObjectName)
+	return obj
+}
+
+func isServiceMeshExposure(spec sdiv1alpha1.SDIObserverSpecRoute) bool {
+	return spec.Exposure == sdiv1alpha1.RouteExposureServiceMesh
+}
+
+// makeMeshObjectSpec returns the spec of the given Istio kind publishing the vsystem service.
+func makeMeshObjectSpec(
+	kind string,
+	spec sdiv1alpha1.SDIObserverSpecRoute,
+	svc *corev1.Service,
+	namespace string,
+) map[string]interface{} {
+	svcHost := fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, namespace)
+	selector := map[string]interface{}{"istio": "ingressgateway"}
+	if len(spec.ServiceMesh.GatewaySelector) > 0 {
+		selector = make(map[string]interface{}, len(spec.ServiceMesh.GatewaySelector))
+		for k, v := range spec.ServiceMesh.GatewaySelector {
+			selector[k] = v
+		}
+	}
+
+	switch kind {
+	case "Gateway":
+		return map[string]interface{}{
+			"selector": selector,
+			"servers": []interface{}{
+				map[string]interface{}{
+					"hosts": []interface{}{spec.Hostname},
+					"port": map[string]interface{}{
+						"number":   int64(meshHTTPSPortNumber),
+						"name":     "https-vsystem",
+						"protocol": "HTTPS",
+					},
+					"tls": map[string]interface{}{
+						"mode":           "SIMPLE",
+						"credentialName": spec.ServiceMesh.CredentialName,
+					},
+				},
+			},
+		}
+	case "VirtualService":
+		return map[string]interface{}{
+			"hosts":    []interface{}{spec.Hostname},
+			"gateways": []interface{}{vsystemMeshObjectName},
+			"http": []interface{}{
+				map[string]interface{}{
+					"timeout": routeAnnotationTimeoutValue,
+					"route": []interface{}{
+						map[string]interface{}{
+							"destination": map[string]interface{}{
+								"host": svcHost,
+								"port": map[string]interface{}{"number": int64(vsystemPortNumber)},
+							},
+						},
+					},
+				},
+			},
+		}
+	default:
+		// vsystem serves https only
+		return map[string]interface{}{
+			"host": svcHost,
+			"trafficPolicy": map[string]interface{}{
+				"tls": map[string]interface{}{
+					"mode": "SIMPLE",
+					"sni":  svcHost,
+				},
+			},
+		}
+	}
+}
+
+// manageVSystemServiceMesh publishes the vsystem service through the Service Mesh ingress gateway and
+// removes the vsystem route.
+func manageVSystemServiceMesh(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	svc *corev1.Service,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := owner.Spec.VSystemRoute
+	if len(spec.Hostname) == 0 || spec.ServiceMesh == nil || len(spec.ServiceMesh.CredentialName) == 0 {
+		err := fmt.Errorf("the serviceMesh exposure requires the hostname and serviceMesh.credentialName")
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+			"InvalidSpec", err.Error())
+		return err
+	}
+
+	route := &routev1.Route{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: svc.Name}, route)
+	if err == nil {
+		tracer.Info("deleting vsystem route replaced by the service mesh")
+		err = c.Delete(ctx, route)
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+			"FailedDelete", fmt.Sprintf("failed to delete vsystem route: %v", err))
+		return err
+	}
+
+	for _, kind := range meshKinds {
+		obj := newMeshObject(kind, namespace)
+		op, err := controllerutil.CreateOrUpdate(ctx, c, obj, func() error {
+			primaryresource.Set(obj, owner, "SDIObserver")
+			obj.Object["spec"] = makeMeshObjectSpec(kind, spec, svc, namespace)
+			return nil
+		})
+		if meta.IsNoMatchError(err) {
+			err = fmt.Errorf("the Service Mesh is not installed: %v", err)
+		}
+		if err != nil {
+			tracer.Error(err, "failed to manage "+kind)
+			setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionUnknown, metav1.ConditionTrue,
+				"FailedServiceMesh", fmt.Sprintf("failed to manage vsystem %s: %v", kind, err))
+			return err
+		}
+		if op != controllerutil.OperationResultNone {
+			tracer.Info("managed "+kind, "name", obj.GetName(), "operation", op)
+		}
+	}
+
+	setConditions(owner, &owner.Status.VSystemRoute, metav1.ConditionTrue, metav1.ConditionFalse,
+		"ServiceMesh", "vsystem is published through the service mesh ingress gateway")
+	return nil
+}
+
+// deleteVSystemServiceMesh removes the Istio objects created for the serviceMesh exposure.
+func deleteVSystemServiceMesh(ctx context.Context, c client.Client, owner *sdiv1alpha1.SDIObserver, namespace string) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	for _, kind := range meshKinds {
+		obj := newMeshObject(kind, namespace)
+		err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if meta.IsNoMatchError(err) {
+			// the service mesh is not installed
+			return nil
+		}
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !primaryresource.IsOwnedBy(obj, owner, "SDIObserver") {
+			continue
+		}
+		tracer.Info("deleting vsystem "+kind, "name", obj.GetName())
+		if err := c.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/argocd"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+// serviceRoute describes an optional re-encrypting route of a service of the SDI namespace.
+type serviceRoute struct {
+	desc      string
+	component string
+	// the route is named after the service
+	defaultService string
+	spec           func(obs *sdiv1alpha1.SDIObserver) sdiv1alpha1.SDIObserverSpecServiceRoute
+	status         func(obs *sdiv1alpha1.SDIObserver) **sdiv1alpha1.SDIObserverRouteStatus
+}
+
+var serviceRoutes = []serviceRoute{
+	{
+		desc:           "diagnostics grafana",
+		component:      componentDiagnosticsGrafanaRoute,
+		defaultService: "diagnostics-grafana",
+		spec: func(obs *sdiv1alpha1.SDIObserver) sdiv1alpha1.SDIObserverSpecServiceRoute {
+			return obs.Spec.DiagnosticsGrafanaRoute
+		},
+		status: func(obs *sdiv1alpha1.SDIObserver) **sdiv1alpha1.SDIObserverRouteStatus {
+			return &obs.Status.DiagnosticsGrafanaRoute
+		},
+	},
+	{
+		desc:           "diagnostics kibana",
+		component:      componentDiagnosticsKibanaRoute,
+		defaultService: "diagnostics-kibana",
+		spec: func(obs *sdiv1alpha1.SDIObserver) sdiv1alpha1.SDIObserverSpecServiceRoute {
+			return obs.Spec.DiagnosticsKibanaRoute
+		},
+		status: func(obs *sdiv1alpha1.SDIObserver) **sdiv1alpha1.SDIObserverRouteStatus {
+			return &obs.Status.DiagnosticsKibanaRoute
+		},
+	},
+	{
+		desc:           "vora tools",
+		component:      componentVoraToolsRoute,
+		defaultService: "vora-tools",
+		spec: func(obs *sdiv1alpha1.SDIObserver) sdiv1alpha1.SDIObserverSpecServiceRoute {
+			return obs.Spec.VoraToolsRoute
+		},
+		status: func(obs *sdiv1alpha1.SDIObserver) **sdiv1alpha1.SDIObserverRouteStatus {
+			return &obs.Status.VoraToolsRoute
+		},
+	},
+}
+
+func (sr serviceRoute) getServiceName(obs *sdiv1alpha1.SDIObserver) string {
+	if name := sr.spec(obs).ServiceName; len(name) > 0 {
+		return name
+	}
+	return sr.defaultService
+}
+
+// getServiceRouteDestinationCA returns the validated destination CA bundle of the route.
+func getServiceRouteDestinationCA(
+	ctx context.Context,
+	c client.Client,
+	spec sdiv1alpha1.SDIObserverSpecServiceRoute,
+	namespace string,
+) (string, error) {
+	name, key := vsystemCaBundleSecretName, vsystemCaBundleSecretKey
+	if ref := spec.TLS.DestinationCASecretRef; ref != nil {
+		name = ref.Name
+		if len(ref.Key) > 0 {
+			key = ref.Key
+		}
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+		return "", fmt.Errorf("failed to get the destination CA secret %s: %v", name, err)
+	}
+	return getCertFromCaBundleSecret(secret, key)
+}
+
+// getServiceRouteTargetPort returns the configured target port or the first port of the service.
+func getServiceRouteTargetPort(
+	spec sdiv1alpha1.SDIObserverSpecServiceRoute,
+	svc *corev1.Service,
+) (intstr.IntOrString, error) {
+	if spec.TargetPort != nil {
+		return *spec.TargetPort, nil
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return intstr.IntOrString{}, fmt.Errorf("service %s has no port", svc.Name)
+	}
+	if port := svc.Spec.Ports[0]; len(port.Name) > 0 {
+		return intstr.FromString(port.Name), nil
+	}
+	return intstr.FromInt(int(svc.Spec.Ports[0].Port)), nil
+}
+
+// deleteServiceRoute deletes the route unless it is not owned by the SDIObserver.
+func deleteServiceRoute(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	key types.NamespacedName,
+) error {
+	route := &routev1.Route{}
+	if err := c.Get(ctx, key, route); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !primaryresource.IsOwnedBy(route, owner, "SDIObserver") {
+		return nil
+	}
+	log.FromContext(ctx).Info("deleting route", "name", route.Name)
+	if err := c.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// manageServiceRoute exposes the service of the SDI namespace with a re-encrypting route. The route is deleted
+// when removed or when the service does not exist.
+func manageServiceRoute(
+	ctx context.Context,
+	c client.Client,
+	owner *sdiv1alpha1.SDIObserver,
+	namespace string,
+	sr serviceRoute,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := sr.spec(owner)
+	statusRef := sr.status(owner)
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info(sr.desc + " route is not managed")
+		*statusRef = nil
+		return nil
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: sr.getServiceName(owner)}
+	if reRemoved.MatchString(spec.ManagementState) {
+		*statusRef = nil
+		return deleteServiceRoute(ctx, c, owner, key)
+	}
+
+	if *statusRef == nil {
+		*statusRef = &sdiv1alpha1.SDIObserverRouteStatus{}
+	}
+	status := *statusRef
+	svc := &corev1.Service{}
+	if err := c.Get(ctx, key, svc); err != nil {
+		if !errors.IsNotFound(err) {
+			setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionTrue, "FailedGet",
+				fmt.Sprintf("failed to get %s service: %v", sr.desc, err))
+			return err
+		}
+		if err := deleteServiceRoute(ctx, c, owner, key); err != nil {
+			setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionTrue, "FailedDelete",
+				fmt.Sprintf("failed to delete %s route: %v", sr.desc, err))
+			return err
+		}
+		setConditions(owner, status, metav1.ConditionFalse, metav1.ConditionFalse, "ServiceNotFound",
+			fmt.Sprintf("%s route is removed due to missing service %s", sr.desc, key.Name))
+		return nil
+	}
+
+	caBundle, err := getServiceRouteDestinationCA(ctx, c, spec, namespace)
+	if err != nil {
+		setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionTrue, "InvalidSecret", err.Error())
+		return err
+	}
+	targetPort, err := getServiceRouteTargetPort(spec, svc)
+	if err != nil {
+		setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionTrue, "InvalidService", err.Error())
+		return err
+	}
+	insecurePolicy := routev1.InsecureEdgeTerminationPolicyRedirect
+	if len(spec.TLS.InsecureEdgeTerminationPolicy) > 0 {
+		insecurePolicy = routev1.InsecureEdgeTerminationPolicyType(spec.TLS.InsecureEdgeTerminationPolicy)
+	}
+
+	route := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: key.Name}}
+	op, err := controllerutil.CreateOrUpdate(ctx, c, route, func() error {
+		if !route.CreationTimestamp.IsZero() && !primaryresource.IsOwnedBy(route, owner, "SDIObserver") {
+			return fmt.Errorf("route %s exists and is not managed by this SDIObserver", route.Name)
+		}
+		route.Labels = getRouteLabelsForVsystemService(svc)
+		primaryresource.Set(route, owner, "SDIObserver")
+		route.Annotations[routeAnnotationTimeoutKey] = routeAnnotationTimeoutValue
+		argocd.Annotate(route)
+		// the host generated by the ingress controller is kept
+		if len(spec.Hostname) > 0 {
+			route.Spec.Host = spec.Hostname
+		}
+		route.Spec.To = routev1.RouteTargetReference{Kind: "Service", Name: svc.Name}
+		route.Spec.Port = &routev1.RoutePort{TargetPort: targetPort}
+		route.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationReencrypt,
+			DestinationCACertificate:      caBundle,
+			InsecureEdgeTerminationPolicy: insecurePolicy,
+		}
+		argocd.NormalizeRoute(&route.Spec)
+		return nil
+	})
+	if err != nil {
+		tracer.Error(err, "failed to manage "+sr.desc+" route")
+		setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionTrue, "FailedApply",
+			fmt.Sprintf("failed to manage %s route: %v", sr.desc, err))
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed "+sr.desc+" route", "name", route.Name, "operation", op)
+	}
+
+	if !isAnyRouteIngressAdmitted(route) {
+		setConditions(owner, status, metav1.ConditionUnknown, metav1.ConditionFalse,
+			sdiv1alpha1.ConditionRouteNotAdmitted, fmt.Sprintf("%s route has not been admitted", sr.desc))
+		return nil
+	}
+	setConditions(owner, status, metav1.ConditionTrue, metav1.ConditionFalse, "Admitted",
+		fmt.Sprintf("%s route is admitted as %s", sr.desc, route.Spec.Host))
+	return nil
+}
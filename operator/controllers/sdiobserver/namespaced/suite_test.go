@@ -110,8 +110,11 @@ var _ = BeforeSuite(func() {
 			Name:      "sdi",
 		},
 		"sdi",
+		nil,
 		k8sManager,
 		controller.Options{},
+		DefaultSyncTimes(),
+		nil,
 	)
 	Expect(err).NotTo(HaveOccurred())
 
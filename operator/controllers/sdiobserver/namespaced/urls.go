@@ -0,0 +1,113 @@
+package namespaced
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiregistries,verbs=get;list;watch
+
+// makeURL returns the URL of the host. The port is left out if it is the default one of the scheme.
+func makeURL(scheme, host string, port int32, path string) string {
+	if port != 0 && !(scheme == "https" && port == 443) && !(scheme == "http" && port == 80) {
+		host = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	u := url.URL{Scheme: scheme, Host: host, Path: path}
+	return u.String()
+}
+
+// getRouteURL returns the URL of the first admitted ingress of the route or an empty string. The router
+// listens on the default ports.
+func getRouteURL(route *routev1.Route) string {
+	for _, ingress := range route.Status.Ingress {
+		c := findRouteIngressCondition(ingress.Conditions, "Admitted")
+		if c == nil || c.Status != corev1.ConditionTrue || len(ingress.Host) == 0 {
+			continue
+		}
+		scheme := "http"
+		if route.Spec.TLS != nil {
+			scheme = "https"
+		}
+		return makeURL(scheme, ingress.Host, 0, route.Spec.Path)
+	}
+	return ""
+}
+
+// getVSystemURL returns the URL of vsystem for the exposure in use.
+func getVSystemURL(ctx context.Context, c client.Client, obs *sdiv1alpha1.SDIObserver, namespace string) string {
+	spec := obs.Spec.VSystemRoute
+	switch spec.Exposure {
+	case sdiv1alpha1.RouteExposureServiceMesh:
+		if len(spec.Hostname) == 0 ||
+			!meta.IsStatusConditionTrue(obs.Status.VSystemRoute.Conditions, "Exposed") {
+			return ""
+		}
+		return makeURL("https", spec.Hostname, 0, "")
+	case sdiv1alpha1.RouteExposureLoadBalancer, sdiv1alpha1.RouteExposureNodePort:
+		// the nodes publishing the node port are not known
+		svc := obs.Status.VSystemRoute.Service
+		if svc == nil || len(svc.Hosts) == 0 {
+			return ""
+		}
+		return makeURL("https", svc.Hosts[0], svc.Port, "")
+	}
+	return getRouteURLByKey(ctx, c, types.NamespacedName{Namespace: namespace, Name: "vsystem"})
+}
+
+// getRouteURLByKey returns the URL of the route or an empty string if the route does not exist or is not
+// admitted.
+func getRouteURLByKey(ctx context.Context, c client.Client, key types.NamespacedName) string {
+	if len(key.Namespace) == 0 {
+		return ""
+	}
+	route := &routev1.Route{}
+	if err := c.Get(ctx, key, route); err != nil {
+		if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			log.FromContext(ctx).Info("failed to get route", "route", key, "error", err)
+		}
+		return ""
+	}
+	return getRouteURL(route)
+}
+
+// getRegistryURL returns the URL of the SDI Registry in the namespace. With multiple registries, the first one
+// by name with an admitted route wins.
+func getRegistryURL(ctx context.Context, c client.Client, namespace string) string {
+	registries := &sdiv1alpha1.SDIRegistryList{}
+	if err := c.List(ctx, registries, client.InNamespace(namespace)); err != nil {
+		log.FromContext(ctx).Info("failed to list the SDI registries", "namespace", namespace, "error", err)
+		return ""
+	}
+	sort.Slice(registries.Items, func(i, j int) bool { return registries.Items[i].Name < registries.Items[j].Name })
+	for _, registry := range registries.Items {
+		if len(registry.Status.Hostname) > 0 {
+			return makeURL("https", registry.Status.Hostname, 0, "")
+		}
+	}
+	return ""
+}
+
+// publishURLs records the URLs of the exposed SDI endpoints in the status.
+func publishURLs(ctx context.Context, c client.Client, obs *sdiv1alpha1.SDIObserver, namespace string) {
+	defer λ.Leave(λ.Enter(log.FromContext(ctx)))
+
+	obs.Status.VSystemURL = getVSystemURL(ctx, c, obs, namespace)
+	obs.Status.SLCBURL = getRouteURLByKey(ctx, c,
+		types.NamespacedName{Namespace: obs.Spec.SLCBNamespace, Name: slcbRouteName})
+	obs.Status.RegistryURL = getRegistryURL(ctx, c, obs.Namespace)
+}
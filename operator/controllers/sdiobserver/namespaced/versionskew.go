@@ -0,0 +1,47 @@
+package namespaced
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/compat"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch
+
+// checkVersionSkew evaluates the OpenShift release against the SDI release mirrored from the DataHub and sets
+// the VersionSkewDetected condition. A warning event is emitted when the skew is detected. It returns true if
+// the disruptive changes shall be paused.
+func (r *reconciler) checkVersionSkew(ctx context.Context, obs *sdiv1alpha1.SDIObserver) bool {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	var sdiVersion string
+	if obs.Status.DataHub != nil {
+		sdiVersion = obs.Status.DataHub.Version
+	}
+	ocpVersion, err := compat.GetOpenShiftVersion(ctx, r.apiReader)
+	if err != nil {
+		tracer.Error(err, "failed to get the OpenShift version")
+	}
+	cond := compat.Evaluate(ocpVersion, sdiVersion)
+	cond.Type = sdiv1alpha1.ConditionVersionSkewDetected
+	cond.ObservedGeneration = obs.Generation
+	if err != nil {
+		cond.Message = "failed to get the OpenShift version: " + err.Error()
+	}
+
+	skewed := cond.Status == metav1.ConditionTrue
+	if skewed && !meta.IsStatusConditionTrue(obs.Status.Conditions, sdiv1alpha1.ConditionVersionSkewDetected) {
+		tracer.Info("detected an unsupported combination of releases", "openshift", ocpVersion, "sdi", sdiVersion)
+		r.recorder.Event(obs, corev1.EventTypeWarning, sdiv1alpha1.ConditionVersionSkewDetected, cond.Message)
+	}
+	meta.SetStatusCondition(&obs.Status.Conditions, cond)
+	return skewed && obs.Spec.VersionSkew.PauseDisruptiveChanges
+}
@@ -0,0 +1,150 @@
+package namespaced
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/upgradeable"
+)
+
+const (
+	vrepStatefulSetName = "vsystem-vrep"
+	vrepExportsPath     = "/exports"
+	// the same volume name as used by the shell observer
+	vrepExportsVolumeName = "exports-mask"
+)
+
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;update;patch
+
+// maskVRepExports mounts an emptyDir volume at /exports of the vsystem-vrep container and of all the other
+// containers already mounting /exports.
+func maskVRepExports(podSpec *corev1.PodSpec) {
+	volumes := podSpec.Volumes[:0]
+	for _, v := range podSpec.Volumes {
+		if v.Name != vrepExportsVolumeName {
+			volumes = append(volumes, v)
+		}
+	}
+	podSpec.Volumes = append(volumes, corev1.Volume{
+		Name:         vrepExportsVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+
+	mask := func(containers []corev1.Container) {
+		for i := range containers {
+			container := &containers[i]
+			mounted := container.Name == vrepStatefulSetName
+			mounts := container.VolumeMounts[:0]
+			for _, m := range container.VolumeMounts {
+				if m.MountPath == vrepExportsPath {
+					mounted = true
+					continue
+				}
+				mounts = append(mounts, m)
+			}
+			container.VolumeMounts = mounts
+			if mounted {
+				container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+					Name:      vrepExportsVolumeName,
+					MountPath: vrepExportsPath,
+				})
+			}
+		}
+	}
+	mask(podSpec.InitContainers)
+	mask(podSpec.Containers)
+}
+
+// VRepRolloutAction identifies the rollout of the vsystem-vrep StatefulSet in the SDI namespace blocking the
+// operator upgrade.
+func VRepRolloutAction(namespace string) string {
+	return "vrep/" + namespace
+}
+
+// isVRepMasked returns true if the exports mask is in the pod template.
+func isVRepMasked(podSpec *corev1.PodSpec) bool {
+	for _, v := range podSpec.Volumes {
+		if v.Name == vrepExportsVolumeName {
+			return true
+		}
+	}
+	return false
+}
+
+// isStatefulSetRolledOut returns true if all the replicas run the latest revision of the pod template.
+func isStatefulSetRolledOut(sts *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	return sts.Status.ObservedGeneration >= sts.Generation && sts.Status.UpdatedReplicas == replicas &&
+		sts.Status.ReadyReplicas == replicas && sts.Status.CurrentRevision == sts.Status.UpdateRevision
+}
+
+// manageVRepExports patches the vsystem-vrep StatefulSet to mask /exports. The returned changes are deferred
+// until the maintenance window.
+func manageVRepExports(
+	ctx context.Context,
+	c client.Client,
+	gate *upgradeable.Gate,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) ([]string, maintenance.Result, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	// the upgrade is blocked while the patched vsystem-vrep rolls out
+	rollingOut := false
+	defer func() {
+		if rollingOut {
+			gate.Hold(ctx, VRepRolloutAction(namespace),
+				fmt.Sprintf("rollout of statefulset %s/%s", namespace, vrepStatefulSetName))
+		} else {
+			gate.Release(ctx, VRepRolloutAction(namespace))
+		}
+	}()
+
+	if reUnmanaged.MatchString(obs.Spec.VRep.ExportsMask) {
+		tracer.V(2).Info("vsystem-vrep exports mask is not managed")
+		return nil, maintenance.Result{}, nil
+	}
+	if profile := getProfile(obs); !profile.maskVRepExports {
+		tracer.V(1).Info("the profile does not need the vsystem-vrep exports mask", "profile", profile.name)
+		return nil, maintenance.Result{}, nil
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: vrepStatefulSetName}, sts); err != nil {
+		if errors.IsNotFound(err) {
+			tracer.V(2).Info("vsystem-vrep statefulset not found")
+			return nil, maintenance.Result{}, nil
+		}
+		return nil, maintenance.Result{}, err
+	}
+	op, window, err := maintenance.CreateOrUpdate(ctx, c, obs.Namespace, sts, func() error {
+		maskVRepExports(&sts.Spec.Template.Spec)
+		return nil
+	}, false)
+	if err != nil {
+		return nil, window, fmt.Errorf("failed to patch statefulset %s: %v", vrepStatefulSetName, err)
+	}
+	if op == maintenance.OperationResultDeferred {
+		return []string{"the exports mask of statefulset " + vrepStatefulSetName}, window, nil
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("masked vsystem-vrep exports", "operation", op)
+	}
+	rollingOut = isVRepMasked(&sts.Spec.Template.Spec) && !isStatefulSetRolledOut(sts)
+	return nil, window, nil
+}
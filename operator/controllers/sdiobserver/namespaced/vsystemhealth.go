@@ -0,0 +1,264 @@
+package namespaced
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	defaultVSystemHealthInterval = time.Minute * 5
+	vsystemHealthTimeout         = time.Second * 30
+
+	defaultVSystemTenant = "default"
+	// the application used to verify the login of the tenant user
+	vsystemLoginApplication = "datahub-app-launchpad"
+
+	conditionTenantAvailable     = "TenantAvailable"
+	conditionApplicationsHealthy = "ApplicationsHealthy"
+)
+
+func getVSystemHealthInterval(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	if obs.Spec.VSystemHealth.Interval == nil {
+		return defaultVSystemHealthInterval
+	}
+	return obs.Spec.VSystemHealth.Interval.Duration
+}
+
+// nextVSystemHealthCheckIn returns the delay until the next poll of the vsystem API is due or zero if the
+// polling is disabled.
+func nextVSystemHealthCheckIn(obs *sdiv1alpha1.SDIObserver) time.Duration {
+	interval := getVSystemHealthInterval(obs)
+	if !reManaged.MatchString(obs.Spec.VSystemHealth.ManagementState) || interval <= 0 {
+		return 0
+	}
+	if obs.Status.VSystemHealth == nil || obs.Status.VSystemHealth.LastCheckTime == nil {
+		return time.Second
+	}
+	next := time.Until(obs.Status.VSystemHealth.LastCheckTime.Add(interval))
+	if next < time.Second {
+		next = time.Second
+	}
+	return next
+}
+
+// vsystemAPI sends authenticated requests to the vsystem service.
+type vsystemAPI struct {
+	httpClient *http.Client
+	baseURL    string
+	tenant     string
+	username   string
+	password   string
+}
+
+// get returns the HTTP status code of the GET request for the path.
+func (api *vsystemAPI) get(ctx context.Context, path string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.baseURL+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	// vsystem expects the tenant to prefix the user name
+	req.SetBasicAuth(api.tenant+`\`+api.username, api.password)
+	resp, err := api.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// newVSystemAPI returns a client of the vsystem service trusting the vsystem CA bundle and authenticating with
+// the credentials of the given secret.
+func newVSystemAPI(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) (*vsystemAPI, error) {
+	spec := obs.Spec.VSystemHealth
+	if len(spec.CredentialsSecret) == 0 {
+		return nil, fmt.Errorf("no credentials secret configured")
+	}
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: obs.Namespace, Name: spec.CredentialsSecret}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the credentials secret: %v", err)
+	}
+	api := &vsystemAPI{
+		baseURL:  fmt.Sprintf("https://vsystem.%s.svc:%d", namespace, vsystemPortNumber),
+		tenant:   string(secret.Data["tenant"]),
+		username: string(secret.Data["username"]),
+		password: string(secret.Data["password"]),
+	}
+	if len(api.tenant) == 0 {
+		api.tenant = defaultVSystemTenant
+	}
+	if len(api.username) == 0 || len(api.password) == 0 {
+		return nil, fmt.Errorf("the credentials secret %s lacks the username or password", spec.CredentialsSecret)
+	}
+
+	caSecretName, caSecretKey := getDestinationCASecretRef(obs)
+	caBundleSecret := &corev1.Secret{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: caSecretName}, caBundleSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the vsystem CA bundle: %v", err)
+	}
+	caBundle, err := getCertFromCaBundleSecret(caBundleSecret, caSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caBundle)) {
+		return nil, fmt.Errorf("no certificate found in the %s secret", caSecretName)
+	}
+	// the service domain is normally excluded from the cluster proxy but a custom no-proxy list may omit it
+	proxy, err := clusterproxy.Get(ctx, apiReader)
+	if err != nil {
+		return nil, err
+	}
+	api.httpClient = &http.Client{
+		Timeout:   vsystemHealthTimeout,
+		Transport: proxy.Transport(pool),
+		// an unauthenticated request is redirected to the login page
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return api, nil
+}
+
+// checkVSystemHealth polls the vsystem API if due and records the health of the tenant and the applications
+// in the status and the metrics.
+func checkVSystemHealth(
+	ctx context.Context,
+	c client.Client,
+	apiReader client.Reader,
+	obs *sdiv1alpha1.SDIObserver,
+	namespace string,
+) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := obs.Spec.VSystemHealth
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("vsystem health is not managed")
+		return
+	}
+	if reRemoved.MatchString(spec.ManagementState) {
+		if obs.Status.VSystemHealth != nil {
+			deleteVSystemHealthMetrics(namespace, obs.Status.VSystemHealth, spec.Applications)
+			obs.Status.VSystemHealth = nil
+		}
+		return
+	}
+	interval := getVSystemHealthInterval(obs)
+	status := obs.Status.VSystemHealth
+	if status == nil {
+		status = &sdiv1alpha1.SDIObserverVSystemHealthStatus{}
+		obs.Status.VSystemHealth = status
+	}
+	if interval <= 0 || (status.LastCheckTime != nil && time.Since(status.LastCheckTime.Time) < interval) {
+		return
+	}
+
+	now := metav1.Now()
+	status.LastCheckTime = &now
+	setCondition := func(condType string, condStatus metav1.ConditionStatus, reason, msg string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               condType,
+			Status:             condStatus,
+			Reason:             reason,
+			Message:            msg,
+			ObservedGeneration: obs.Generation,
+		})
+	}
+
+	api, err := newVSystemAPI(ctx, c, apiReader, obs, namespace)
+	if err != nil {
+		tracer.Info("cannot poll the vsystem API", "error", err)
+		setCondition(conditionTenantAvailable, metav1.ConditionUnknown, "InvalidConfiguration", err.Error())
+		setCondition(conditionApplicationsHealthy, metav1.ConditionUnknown, "InvalidConfiguration", err.Error())
+		return
+	}
+	if len(status.Tenant) > 0 && status.Tenant != api.tenant {
+		vsystemTenantAvailable.Delete(prometheus.Labels{sdiNamespaceLabel: namespace, tenantLabel: status.Tenant})
+	}
+	status.Tenant = api.tenant
+	tenantLabels := prometheus.Labels{sdiNamespaceLabel: namespace, tenantLabel: api.tenant}
+
+	code, err := api.get(ctx, "/app/"+vsystemLoginApplication+"/")
+	switch {
+	case err != nil:
+		tracer.Info("vsystem is not reachable", "error", err)
+		vsystemTenantAvailable.With(tenantLabels).Set(0)
+		setCondition(conditionTenantAvailable, metav1.ConditionFalse, "Unreachable",
+			fmt.Sprintf("vsystem is not reachable: %v", err))
+		setCondition(conditionApplicationsHealthy, metav1.ConditionUnknown, "Unreachable",
+			"vsystem is not reachable")
+		return
+	case code == http.StatusUnauthorized || code == http.StatusForbidden ||
+		(code >= http.StatusMultipleChoices && code < http.StatusBadRequest):
+		vsystemTenantAvailable.With(tenantLabels).Set(0)
+		setCondition(conditionTenantAvailable, metav1.ConditionFalse, "LoginFailed",
+			fmt.Sprintf("the user %s cannot log in to the tenant %s (HTTP %d)", api.username, api.tenant, code))
+		setCondition(conditionApplicationsHealthy, metav1.ConditionUnknown, "LoginFailed",
+			"the applications cannot be checked without a login")
+		return
+	}
+	vsystemTenantAvailable.With(tenantLabels).Set(1)
+	setCondition(conditionTenantAvailable, metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+		fmt.Sprintf("the user %s logged in to the tenant %s", api.username, api.tenant))
+
+	var unhealthy []string
+	for _, app := range spec.Applications {
+		labels := prometheus.Labels{sdiNamespaceLabel: namespace, applicationLabel: app}
+		code, err := api.get(ctx, "/app/"+app+"/")
+		if err == nil && code < http.StatusInternalServerError {
+			vsystemApplicationHealthy.With(labels).Set(1)
+			continue
+		}
+		if err == nil {
+			err = fmt.Errorf("HTTP %d", code)
+		}
+		tracer.Info("vsystem application is not healthy", "application", app, "error", err)
+		vsystemApplicationHealthy.With(labels).Set(0)
+		unhealthy = append(unhealthy, app)
+	}
+	sort.Strings(unhealthy)
+	status.UnhealthyApplications = unhealthy
+	if len(unhealthy) > 0 {
+		setCondition(conditionApplicationsHealthy, metav1.ConditionFalse, "Unhealthy",
+			fmt.Sprintf("the applications %v respond with a server error", unhealthy))
+		return
+	}
+	setCondition(conditionApplicationsHealthy, metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+		"all the checked applications are healthy")
+}
+
+// deleteVSystemHealthMetrics removes the gauges recorded for the tenant of the status and the applications.
+func deleteVSystemHealthMetrics(
+	namespace string,
+	status *sdiv1alpha1.SDIObserverVSystemHealthStatus,
+	applications []string,
+) {
+	if len(status.Tenant) > 0 {
+		vsystemTenantAvailable.Delete(prometheus.Labels{sdiNamespaceLabel: namespace, tenantLabel: status.Tenant})
+	}
+	for _, app := range applications {
+		vsystemApplicationHealthy.Delete(prometheus.Labels{sdiNamespaceLabel: namespace, applicationLabel: app})
+	}
+}
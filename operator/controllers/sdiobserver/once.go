@@ -0,0 +1,84 @@
+package sdiobserver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiobserver/namespaced"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+// ReconcileOnce reconciles once each SDIObserver that would manage an SDI namespace in the long-running
+// operator. The discovery templates are not expanded and the backup instances are left alone. All the
+// SDIObservers are reconciled even if some fail; the failures are returned together.
+func ReconcileOnce(ctx context.Context, cfg *rest.Config, scheme *runtime.Scheme, recorder record.EventRecorder) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return err
+	}
+	r := &Reconciler{
+		Client:               dryrun.Wrap(c),
+		Scheme:               scheme,
+		ManagedDHPerObserver: make(map[types.NamespacedName]string),
+		Recorder:             recorder,
+	}
+
+	var obss sdiv1alpha1.SDIObserverList
+	if err := r.List(ctx, &obss); err != nil {
+		return err
+	}
+	namespaces := make(map[string]struct{})
+	for i := range obss.Items {
+		obs := &obss.Items[i]
+		if obs.DeletionTimestamp != nil || isDiscoveryTemplate(obs) {
+			continue
+		}
+		namespaces[getSDINamespace(obs)] = struct{}{}
+	}
+	sorted := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		sorted = append(sorted, ns)
+	}
+	sort.Strings(sorted)
+
+	var failures []string
+	for _, sdiNamespace := range sorted {
+		obs, err := r.findNewObsForDH(ctx, sdiNamespace, false)
+		if err != nil {
+			return err
+		}
+		if obs == nil {
+			continue
+		}
+		nmName := client.ObjectKeyFromObject(obs)
+		tracer.Info("reconciling", "sdiobserver", nmName.String(), "sdiNamespace", sdiNamespace)
+		if err := namespaced.ReconcileOnce(ctx, cfg, r.Client, mapper, scheme, recorder, nmName,
+			sdiNamespace); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", nmName, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to reconcile %d of %d SDIObservers: %s", len(failures), len(sorted),
+			strings.Join(failures, "; "))
+	}
+	return nil
+}
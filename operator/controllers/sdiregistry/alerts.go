@@ -0,0 +1,131 @@
+package sdiregistry
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	alertsName                         = componentName + "-alerts"
+	defaultStorageUsageWarningPercent  = 80
+	defaultStorageUsageCriticalPercent = 90
+	// the registry stays read-only for longer than the deadline of the garbage collection job
+	readOnlyAlertFor      = "4h"
+	storageUsageAlertFor  = "5m"
+	usedRatioRecordedName = metricsNamespace + ":registry_storage_used_ratio"
+)
+
+var prometheusRuleGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PrometheusRule",
+}
+
+func percentOrDefault(value int32, defaultValue int32) int32 {
+	if value <= 0 || value > 100 {
+		return defaultValue
+	}
+	return value
+}
+
+// makeAlertRules returns the rules of the registry. The storage usage of the PVC is recorded from the kubelet
+// volume statistics under the labels of the operator metrics.
+func makeAlertRules(reg *sdiv1alpha1.SDIRegistry, backend string) []interface{} {
+	selector := fmt.Sprintf(`{%s=%q,%s=%q}`, registryNamespaceLabel, reg.Namespace, registryLabel, reg.Name)
+	labels := map[string]interface{}{registryNamespaceLabel: reg.Namespace, registryLabel: reg.Name}
+	alert := func(name, severity, expr, forDuration, summary, description string) interface{} {
+		ruleLabels := map[string]interface{}{"severity": severity}
+		for k, v := range labels {
+			ruleLabels[k] = v
+		}
+		return map[string]interface{}{
+			"alert":  name,
+			"expr":   expr,
+			"for":    forDuration,
+			"labels": ruleLabels,
+			"annotations": map[string]interface{}{
+				"summary":     summary,
+				"description": description,
+			},
+		}
+	}
+
+	var rules []interface{}
+	if backend == sdiv1alpha1.SDIRegistryStorageBackendPVC {
+		volume := fmt.Sprintf(`{namespace=%q,persistentvolumeclaim=%q}`, reg.Namespace, componentName)
+		warning := percentOrDefault(reg.Spec.Alerts.StorageUsageWarningPercent, defaultStorageUsageWarningPercent)
+		critical := percentOrDefault(reg.Spec.Alerts.StorageUsageCriticalPercent,
+			defaultStorageUsageCriticalPercent)
+		used := usedRatioRecordedName + selector + " * 100"
+		rules = append(rules,
+			map[string]interface{}{
+				"record": usedRatioRecordedName,
+				"expr":   "kubelet_volume_stats_used_bytes" + volume + " / kubelet_volume_stats_capacity_bytes" + volume,
+				"labels": labels,
+			},
+			alert("SDIRegistryStorageNearFull", "warning", fmt.Sprintf("%s > %d", used, warning),
+				storageUsageAlertFor, "The registry volume is filling up.",
+				fmt.Sprintf("The volume of the registry %s in namespace %s is more than %d%% full; pushes fail once"+
+					" it is full. Schedule the garbage collection or expand the volume.", reg.Name, reg.Namespace,
+					warning)),
+			alert("SDIRegistryStorageNearFull", "critical", fmt.Sprintf("%s > %d", used, critical),
+				storageUsageAlertFor, "The registry volume is almost full.",
+				fmt.Sprintf("The volume of the registry %s in namespace %s is more than %d%% full; pushes fail once"+
+					" it is full. Schedule the garbage collection or expand the volume.", reg.Name, reg.Namespace,
+					critical)))
+	}
+	rules = append(rules,
+		alert("SDIRegistryGarbageCollectionFailed", "warning",
+			metricsNamespace+"_registry_gc_last_failed"+selector+" == 1", "0s",
+			"The registry garbage collection failed.",
+			fmt.Sprintf("The last garbage collection job of the registry %s in namespace %s failed; see the logs of"+
+				" the %s job pods.", reg.Name, reg.Namespace, gcComponentName)),
+		alert("SDIRegistryReadOnly", "warning",
+			metricsNamespace+"_registry_read_only"+selector+" == 1", readOnlyAlertFor,
+			"The registry rejects pushes.",
+			fmt.Sprintf("The registry %s in namespace %s has been read-only for the garbage collection for more"+
+				" than %s.", reg.Name, reg.Namespace, readOnlyAlertFor)))
+	return rules
+}
+
+// manageAlerts creates the PrometheusRule with the alerts of the registry in its namespace.
+func (r *Reconciler) manageAlerts(ctx context.Context, reg *sdiv1alpha1.SDIRegistry, backend string) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	state := reg.Spec.Alerts.ManagementState
+	if r.noMonitoringAPI || reExplicitlyUnmanaged.MatchString(state) {
+		tracer.V(2).Info("registry alerts are not managed")
+		return nil
+	}
+	rule := &unstructured.Unstructured{}
+	rule.SetGroupVersionKind(prometheusRuleGVK)
+	rule.SetNamespace(reg.Namespace)
+	rule.SetName(alertsName)
+	if reRemoved.MatchString(state) {
+		if err := r.Delete(ctx, rule); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	_, _, err := r.apply(ctx, reg, "prometheus rule", rule, func() error {
+		rule.Object["spec"] = map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  fmt.Sprintf("sdi-registry.%s.%s", reg.Namespace, reg.Name),
+					"rules": makeAlertRules(reg, backend),
+				},
+			},
+		}
+		return nil
+	}, false)
+	return err
+}
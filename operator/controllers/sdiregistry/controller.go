@@ -0,0 +1,832 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdiregistry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/images"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+// the management states of the route and the alerts of the registry
+var (
+	reExplicitlyUnmanaged = regexp.MustCompile(`^(?i)Unmanaged$`)
+	reRemoved             = regexp.MustCompile(`^(?i)removed?$`)
+)
+
+const (
+	// the name of all the registry components, the same as of deploy-registry.sh
+	componentName     = "container-image-registry"
+	serviceCAName     = componentName + "-service-ca"
+	containerName     = "registry"
+	registryPortName  = "registry"
+	registryPort      = 5000
+	storageVolumeName = "storage"
+	storageMountPath  = "/var/lib/registry"
+	serviceCAVolume   = "service-ca"
+	serviceCAMount    = "/etc/registry/service-ca"
+	appLabelKey       = "app"
+
+	injectCABundleAnnotationKey = "service.beta.openshift.io/inject-cabundle"
+	serviceCAKey                = "service-ca.crt"
+	servingCertAnnotationKey    = "service.beta.openshift.io/serving-cert-secret-name"
+	servingSecretName           = componentName + "-tls"
+	tlsVolume                   = "tls"
+	tlsMountDir                 = "/etc/registry/tls"
+	// the checksum of the serving certificate the registry pods run with
+	servingCertChecksumAnnotationKey = "di.sap-cop.redhat.com/serving-cert-checksum"
+	// the region is required by the S3 driver though ignored by the bucket provisioners
+	defaultS3Region = "us-east-1"
+
+	// how often the binding of the object bucket claim and the issuance of the serving certificate are checked
+	pollInterval = 10 * time.Second
+
+	finalizerName = "di.sap-cop.redhat.com/sdiregistry-cleanup"
+	kind          = "SDIRegistry"
+)
+
+var (
+	defaultVolumeSize = resource.MustParse("120Gi")
+
+	objectBucketClaimGVK = schema.GroupVersionKind{
+		Group:   "objectbucket.io",
+		Version: "v1alpha1",
+		Kind:    "ObjectBucketClaim",
+	}
+)
+
+// Reconciler reconciles SDIRegistry objects.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// the cluster does not serve the object bucket claim API; detected when set up with the manager
+	noBucketAPI bool
+	// the cluster does not serve the route API; detected when set up with the manager
+	noRouteAPI bool
+	// the cluster does not serve the PrometheusRule API; detected when set up with the manager
+	noMonitoringAPI bool
+	// the namespaces to publish the credentials to unless set in the spec
+	defaultNamespaces []string
+}
+
+// NewReconciler returns a reconciler publishing the registry credentials to the given namespaces by default,
+// meant to be the SDI and SLCB namespaces.
+func NewReconciler(client client.Client, scheme *runtime.Scheme, defaultNamespaces ...string) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme, defaultNamespaces: defaultNamespaces}
+}
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiregistries,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiregistries/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdiregistries/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=objectbucket.io,resources=objectbucketclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows,verbs=get;list;watch
+
+// Reconcile deploys the container image registry with the desired storage backend and publishes its
+// credential. The registry components live in the namespace of the SDIRegistry and are garbage collected with it,
+// including the stored images; the credential secrets published to other namespaces are removed by the
+// finalizer.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	reg := &sdiv1alpha1.SDIRegistry{}
+	if err = r.Get(ctx, req.NamespacedName, reg); err != nil {
+		return rs, client.IgnoreNotFound(err)
+	}
+
+	if reg.DeletionTimestamp != nil {
+		if !controllerutil.ContainsFinalizer(reg, finalizerName) {
+			return
+		}
+		if err = r.cleanup(ctx, reg); err != nil {
+			return
+		}
+		return rs, updates.Object(ctx, r.Client, reg, func() (bool, error) {
+			if !controllerutil.ContainsFinalizer(reg, finalizerName) {
+				return false, nil
+			}
+			controllerutil.RemoveFinalizer(reg, finalizerName)
+			return true, nil
+		})
+	}
+	var finalizerErr error
+	if !controllerutil.ContainsFinalizer(reg, finalizerName) {
+		finalizerErr = updates.Object(ctx, r.Client, reg, func() (bool, error) {
+			if controllerutil.ContainsFinalizer(reg, finalizerName) {
+				return false, nil
+			}
+			controllerutil.AddFinalizer(reg, finalizerName)
+			return true, nil
+		})
+		if finalizerErr != nil && !updates.IsPermanent(finalizerErr) {
+			return rs, finalizerErr
+		}
+	}
+
+	reg.Status.ObservedGeneration = reg.Generation
+	reg.Status.ServiceAddress = fmt.Sprintf("%s.%s.svc:%d", componentName, reg.Namespace, registryPort)
+
+	rs.RequeueAfter, err = r.manageComponents(ctx, reg)
+	if finalizerErr != nil {
+		updates.SetDegraded(&reg.Status.Conditions, reg.Generation, "the finalizer", finalizerErr)
+	}
+	status := reg.Status.DeepCopy()
+	updErr := updates.Status(ctx, r.Client, reg, func() (bool, error) {
+		reg.Status = *status
+		return true, nil
+	})
+	if updates.IsPermanent(updErr) {
+		// retrying would not help
+		tracer.Error(updErr, "SDIRegistry status update has been refused")
+	} else if updErr != nil {
+		tracer.Error(updErr, "failed to update SDIRegistry status")
+		if err == nil {
+			err = updErr
+		}
+	}
+	return rs, err
+}
+
+func setCondition(reg *sdiv1alpha1.SDIRegistry, cType string, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&reg.Status.Conditions, metav1.Condition{
+		Type:               cType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: reg.Generation,
+	})
+}
+
+func setFailedConditions(reg *sdiv1alpha1.SDIRegistry, reason string, err error) {
+	setCondition(reg, "Ready", metav1.ConditionFalse, reason, err.Error())
+	setCondition(reg, "Degraded", metav1.ConditionTrue, reason, err.Error())
+}
+
+func getDesiredBackend(reg *sdiv1alpha1.SDIRegistry) string {
+	if len(reg.Spec.Storage.Backend) > 0 {
+		return reg.Spec.Storage.Backend
+	}
+	return sdiv1alpha1.SDIRegistryStorageBackendPVC
+}
+
+// getBackend returns the storage backend to deploy the registry with. A change of the backend of a deployed
+// registry is held back unless the migration is allowed because the stored images would disappear.
+func getBackend(reg *sdiv1alpha1.SDIRegistry) string {
+	desired := getDesiredBackend(reg)
+	deployed := reg.Status.StorageBackend
+	if len(deployed) == 0 || deployed == desired {
+		setCondition(reg, sdiv1alpha1.ConditionStorageMigrationBlocked, metav1.ConditionFalse,
+			sdiv1alpha1.ConditionReasonAsExpected, "the registry uses the desired storage backend "+desired)
+		return desired
+	}
+	if reg.Spec.Storage.AllowMigration {
+		setCondition(reg, sdiv1alpha1.ConditionStorageMigrationBlocked, metav1.ConditionFalse, "Migrating",
+			fmt.Sprintf("switching the storage backend from %s to %s; the stored images need to be mirrored again",
+				deployed, desired))
+		return desired
+	}
+	setCondition(reg, sdiv1alpha1.ConditionStorageMigrationBlocked, metav1.ConditionTrue, "MigrationNotAllowed",
+		fmt.Sprintf("the registry keeps using the storage backend %s; set spec.storage.allowMigration to switch to"+
+			" %s, the stored images are not carried over", deployed, desired))
+	return deployed
+}
+
+// apply creates or updates the registry component owned by the SDIRegistry. Updates of the disruptive
+// components are deferred until a maintenance window is open.
+func (r *Reconciler) apply(
+	ctx context.Context,
+	reg *sdiv1alpha1.SDIRegistry,
+	desc string,
+	obj client.Object,
+	mutate func() error,
+	disruptive bool,
+) (controllerutil.OperationResult, maintenance.Result, error) {
+	tracer := log.FromContext(ctx)
+	f := func() error {
+		if err := mutate(); err != nil {
+			return err
+		}
+		primaryresource.SetManagedBy(obj)
+		return controllerutil.SetControllerReference(reg, obj, r.Scheme)
+	}
+	var op controllerutil.OperationResult
+	var window maintenance.Result
+	var err error
+	if disruptive {
+		op, window, err = maintenance.CreateOrUpdate(ctx, r.Client, reg.Namespace, obj, f, false)
+	} else {
+		op, err = controllerutil.CreateOrUpdate(ctx, r.Client, obj, f)
+	}
+	if err != nil {
+		tracer.Error(err, "failed to manage "+desc)
+		return op, window, fmt.Errorf("failed to manage %s: %v", desc, err)
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed "+desc, "name", obj.GetName(), "operation", op)
+	}
+	return op, window, nil
+}
+
+// manageComponents applies the registry components. Updates of the deployment restart the registry and are
+// deferred until a maintenance window is open in which case a non-zero requeue delay is returned.
+func (r *Reconciler) manageComponents(ctx context.Context, reg *sdiv1alpha1.SDIRegistry) (time.Duration, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	backend := getBackend(reg)
+	objMeta := metav1.ObjectMeta{Namespace: reg.Namespace, Name: componentName}
+	labels := map[string]string{appLabelKey: componentName}
+
+	var disabled []string
+	if route := reg.Spec.Route; !route.SkipClusterTrust && !r.noRouteAPI &&
+		!reExplicitlyUnmanaged.MatchString(route.ManagementState) &&
+		!reRemoved.MatchString(route.ManagementState) {
+		// the cluster image config is not updated
+		disabled = append(disabled, "cluster trust of the route")
+	}
+	rbacscope.SetCondition(&reg.Status.Conditions, reg.Generation, disabled)
+
+	if _, _, err := r.apply(ctx, reg, "service account", &corev1.ServiceAccount{ObjectMeta: objMeta},
+		func() error { return nil }, false); err != nil {
+		setFailedConditions(reg, "FailedApply", err)
+		return 0, err
+	}
+
+	creds, err := r.manageCredentials(ctx, reg)
+	if err != nil {
+		setFailedConditions(reg, "FailedCredentials", err)
+		return 0, err
+	}
+	reg.Status.HtpasswdSecretName = creds.secretName
+
+	// the service CA signs the serving certificate of the registry and the in-cluster bucket endpoints
+	ca := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: serviceCAName}}
+	if _, _, err := r.apply(ctx, reg, "service CA config map", ca, func() error {
+		if ca.Annotations == nil {
+			ca.Annotations = make(map[string]string)
+		}
+		ca.Annotations[injectCABundleAnnotationKey] = "true"
+		return nil
+	}, false); err != nil {
+		setFailedConditions(reg, "FailedApply", err)
+		return 0, err
+	}
+
+	svc := &corev1.Service{ObjectMeta: objMeta}
+	if _, _, err := r.apply(ctx, reg, "service", svc, func() error {
+		if svc.Annotations == nil {
+			svc.Annotations = make(map[string]string)
+		}
+		svc.Annotations[servingCertAnnotationKey] = servingSecretName
+		svc.Labels = labels
+		svc.Spec.Selector = labels
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:       registryPortName,
+			Protocol:   corev1.ProtocolTCP,
+			Port:       registryPort,
+			TargetPort: intstr.FromString(registryPortName),
+		}}
+		return nil
+	}, false); err != nil {
+		setFailedConditions(reg, "FailedApply", err)
+		return 0, err
+	}
+
+	servingSecret := &corev1.Secret{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: servingSecretName}, servingSecret)
+	if errors.IsNotFound(err) {
+		setCondition(reg, "Ready", metav1.ConditionFalse, "ServingCertPending",
+			"waiting for the service CA to issue the serving certificate")
+		setCondition(reg, "Progressing", metav1.ConditionTrue, "ServingCertPending",
+			"waiting for the service CA to issue the serving certificate")
+		return pollInterval, nil
+	}
+	if err != nil {
+		setFailedConditions(reg, "FailedGet", err)
+		return 0, err
+	}
+
+	var recreate bool
+	var s3Checksum string
+	var claim *corev1.PersistentVolumeClaim
+	switch backend {
+	case sdiv1alpha1.SDIRegistryStorageBackendS3:
+		var invalid string
+		if s3Checksum, invalid, err = r.manageS3(ctx, reg); err != nil {
+			setFailedConditions(reg, "FailedStorage", err)
+			return 0, err
+		}
+		if len(invalid) > 0 {
+			setCondition(reg, "Ready", metav1.ConditionFalse, "InvalidStorage", invalid)
+			setCondition(reg, "Degraded", metav1.ConditionTrue, "InvalidStorage", invalid)
+			return 0, nil
+		}
+	case sdiv1alpha1.SDIRegistryStorageBackendObjectBucketClaim:
+		bound, err := r.manageBucket(ctx, reg)
+		if err != nil {
+			setFailedConditions(reg, "FailedStorage", err)
+			return 0, err
+		}
+		if !bound {
+			setCondition(reg, "Ready", metav1.ConditionFalse, "BucketPending",
+				"waiting for the object bucket claim to be bound")
+			setCondition(reg, "Progressing", metav1.ConditionTrue, "BucketPending",
+				"waiting for the object bucket claim to be bound")
+			return pollInterval, nil
+		}
+	default:
+		pvc := &corev1.PersistentVolumeClaim{ObjectMeta: objMeta}
+		if _, _, err := r.apply(ctx, reg, "persistent volume claim", pvc, func() error {
+			mutateClaim(pvc, reg)
+			return nil
+		}, false); err != nil {
+			setFailedConditions(reg, "FailedStorage", err)
+			return 0, err
+		}
+		for _, mode := range pvc.Spec.AccessModes {
+			recreate = recreate || mode == corev1.ReadWriteOnce
+		}
+		claim = pvc
+	}
+	recordStorage(reg, claim)
+
+	readOnly, err := r.manageGarbageCollection(ctx, reg, backend, recreate)
+	if err != nil {
+		setFailedConditions(reg, "FailedGarbageCollection", err)
+		return 0, err
+	}
+	var pending []string
+	deploy := &appsv1.Deployment{ObjectMeta: objMeta}
+	// switching the read-only mode for the garbage collection is not deferred, the cron job is scheduled
+	// explicitly
+	disruptive := true
+	if err := r.Get(ctx, client.ObjectKeyFromObject(deploy), deploy); err == nil {
+		disruptive = isReadOnly(deploy) == readOnly
+	} else if !errors.IsNotFound(err) {
+		setFailedConditions(reg, "FailedGet", err)
+		return 0, err
+	}
+	op, window, err := r.apply(ctx, reg, "deployment", deploy, func() error {
+		mutateDeployment(deploy, reg, backend, recreate, readOnly, creds, labels)
+		// the registry loads the certificate on start, it is restarted when the service CA renews it
+		deploy.Spec.Template.Annotations[servingCertChecksumAnnotationKey] =
+			checksum(servingSecret.Data[corev1.TLSCertKey])
+		// the credentials are read from the environment on start only
+		if len(s3Checksum) > 0 {
+			deploy.Spec.Template.Annotations[s3ChecksumAnnotationKey] = s3Checksum
+		} else {
+			delete(deploy.Spec.Template.Annotations, s3ChecksumAnnotationKey)
+		}
+		return nil
+	}, disruptive)
+	if err != nil {
+		setFailedConditions(reg, "FailedApply", err)
+		return 0, err
+	}
+	if op == maintenance.OperationResultDeferred {
+		pending = append(pending, "update of the deployment")
+	} else {
+		reg.Status.StorageBackend = backend
+	}
+	reg.Status.ReadOnly = isReadOnly(deploy)
+	recordReadOnly(reg)
+	// the garbage collection waits for the registry to be restarted in read-only mode
+	gcReady := readOnly && op != maintenance.OperationResultDeferred && reg.Status.ReadOnly &&
+		isRolledOut(deploy) && deploy.Status.AvailableReplicas > 0
+	if err := r.setGarbageCollectionReady(ctx, reg, gcReady); err != nil {
+		setFailedConditions(reg, "FailedGarbageCollection", err)
+		return 0, err
+	}
+	maintenance.SetPendingCondition(&reg.Status.Conditions, reg.Generation, len(pending) > 0, window,
+		strings.Join(pending, ", "))
+
+	routeReady, err := r.manageRoute(ctx, reg, ca.Data[serviceCAKey])
+	if err != nil {
+		setFailedConditions(reg, "FailedRoute", err)
+		return 0, err
+	}
+	if err := r.manageAlerts(ctx, reg, backend); err != nil {
+		setFailedConditions(reg, "FailedAlerts", err)
+		return 0, err
+	}
+
+	// the deployment has been read back or updated by the apply
+	accepted := op != maintenance.OperationResultDeferred && isRolledOut(deploy) &&
+		deploy.Status.AvailableReplicas > 0 && deploy.Spec.Template.Annotations[checksumAnnotationKey] == creds.checksum
+	published, err := r.publishCredentials(ctx, reg, creds, accepted)
+	if err != nil {
+		setFailedConditions(reg, "FailedPublish", err)
+		return 0, err
+	}
+	if published && creds.trimmable {
+		// all the clients have the current credential, the former ones can be revoked
+		if err := r.trimHtpasswd(ctx, reg, creds); err != nil {
+			setFailedConditions(reg, "FailedCredentials", err)
+			return 0, err
+		}
+	}
+
+	if op != maintenance.OperationResultDeferred {
+		setDeploymentConditions(reg, deploy, routeReady)
+		if deploy.Status.AvailableReplicas > 0 {
+			recordRepositories(ctx, reg, creds, ca.Data[serviceCAKey])
+		}
+	}
+	if len(pending) > 0 {
+		return window.RequeueAfter(), nil
+	}
+	// refresh the metrics
+	return metricsInterval, nil
+}
+
+// mutateClaim sets the spec of a new claim. Only the size of an existing claim can be changed and only
+// increased.
+func mutateClaim(pvc *corev1.PersistentVolumeClaim, reg *sdiv1alpha1.SDIRegistry) {
+	spec := reg.Spec.Storage.PVC
+	size := defaultVolumeSize
+	if spec.Size != nil {
+		size = *spec.Size
+	}
+	if !pvc.CreationTimestamp.IsZero() {
+		if current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; size.Cmp(current) > 0 {
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		}
+		return
+	}
+	accessMode := spec.AccessMode
+	if len(accessMode) == 0 {
+		accessMode = corev1.ReadWriteOnce
+	}
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{accessMode}
+	pvc.Spec.StorageClassName = spec.StorageClassName
+	pvc.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: size}
+}
+
+// manageBucket applies the object bucket claim. It returns true once the claim is bound and its config map and
+// secret exist.
+func (r *Reconciler) manageBucket(ctx context.Context, reg *sdiv1alpha1.SDIRegistry) (bool, error) {
+	if r.noBucketAPI {
+		return false, fmt.Errorf("the cluster does not serve the %s API, install OpenShift Data Foundation or"+
+			" use the PVC storage backend", objectBucketClaimGVK.GroupKind())
+	}
+
+	obc := &unstructured.Unstructured{}
+	obc.SetGroupVersionKind(objectBucketClaimGVK)
+	obc.SetNamespace(reg.Namespace)
+	obc.SetName(componentName)
+	storageClass := reg.Spec.Storage.ObjectBucketClaim.StorageClassName
+	if len(storageClass) == 0 {
+		storageClass = "openshift-storage.noobaa.io"
+	}
+	if _, _, err := r.apply(ctx, reg, "object bucket claim", obc, func() error {
+		// the spec of a bound claim is immutable
+		if len(obc.GetUID()) == 0 {
+			_ = unstructured.SetNestedField(obc.Object, componentName, "spec", "generateBucketName")
+			_ = unstructured.SetNestedField(obc.Object, storageClass, "spec", "storageClassName")
+		}
+		return nil
+	}, false); err != nil {
+		return false, err
+	}
+	phase, _, _ := unstructured.NestedString(obc.Object, "status", "phase")
+	if phase != "Bound" {
+		log.FromContext(ctx).Info("the object bucket claim is not bound yet", "phase", phase)
+		return false, nil
+	}
+	return true, nil
+}
+
+func mutateDeployment(
+	deploy *appsv1.Deployment,
+	reg *sdiv1alpha1.SDIRegistry,
+	backend string,
+	recreate bool,
+	readOnly bool,
+	creds *credentials,
+	labels map[string]string,
+) {
+	var replicas int32 = 1
+	deploy.Labels = labels
+	deploy.Spec.Replicas = &replicas
+	deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+	if recreate {
+		// a ReadWriteOnce volume cannot be attached to two nodes at once
+		deploy.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	} else {
+		quarter := intstr.FromString("25%")
+		// the defaults are spelled out to avoid needless updates
+		deploy.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type:          appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &quarter, MaxSurge: &quarter},
+		}
+	}
+	deploy.Spec.Template.Labels = labels
+	if deploy.Spec.Template.Annotations == nil {
+		deploy.Spec.Template.Annotations = make(map[string]string)
+	}
+	// restart the registry when the htpasswd file changes
+	deploy.Spec.Template.Annotations[checksumAnnotationKey] = creds.checksum
+	probe := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   "/",
+				Port:   intstr.FromString(registryPortName),
+				Scheme: corev1.URISchemeHTTPS,
+			},
+		},
+		TimeoutSeconds:   5,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	podSpec := &deploy.Spec.Template.Spec
+	podSpec.ServiceAccountName = componentName
+	if len(podSpec.Containers) != 1 {
+		podSpec.Containers = []corev1.Container{{}}
+	}
+	container := &podSpec.Containers[0]
+	container.Name = containerName
+	container.Image = images.Registry.Resolve(reg.Spec.Image)
+	container.Ports = []corev1.ContainerPort{{
+		Name:          registryPortName,
+		ContainerPort: registryPort,
+		Protocol:      corev1.ProtocolTCP,
+	}}
+	container.ReadinessProbe = probe
+	container.LivenessProbe = probe.DeepCopy()
+	container.Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("768Mi"),
+		},
+	}
+
+	setStorage(podSpec, container, reg, backend)
+	podSpec.Affinity = nil
+	if readOnly {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  readOnlyEnvName,
+			Value: `{"enabled":true}`,
+		})
+		if recreate {
+			// the volume is attached to the node of the garbage collection pod
+			podSpec.Affinity = getColocationAffinity(gcComponentName)
+		}
+	}
+	container.Env = append(container.Env,
+		corev1.EnvVar{Name: "REGISTRY_HTTP_TLS_CERTIFICATE", Value: tlsMountDir + "/" + corev1.TLSCertKey},
+		corev1.EnvVar{Name: "REGISTRY_HTTP_TLS_KEY", Value: tlsMountDir + "/" + corev1.TLSPrivateKeyKey},
+		corev1.EnvVar{Name: "REGISTRY_AUTH", Value: "htpasswd"},
+		corev1.EnvVar{Name: "REGISTRY_AUTH_HTPASSWD_REALM", Value: "basic-realm"},
+		corev1.EnvVar{Name: "REGISTRY_AUTH_HTPASSWD_PATH", Value: htpasswdMountDir + "/" + htpasswdKey})
+	container.VolumeMounts = append(container.VolumeMounts,
+		corev1.VolumeMount{Name: htpasswdVolume, MountPath: htpasswdMountDir, ReadOnly: true},
+		corev1.VolumeMount{Name: tlsVolume, MountPath: tlsMountDir, ReadOnly: true})
+	secretMode := corev1.SecretVolumeSourceDefaultMode
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: htpasswdVolume,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+			SecretName:  creds.secretName,
+			Items:       []corev1.KeyToPath{{Key: htpasswdKey, Path: htpasswdKey}},
+			DefaultMode: &secretMode,
+		}},
+	}, corev1.Volume{
+		Name: tlsVolume,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+			SecretName:  servingSecretName,
+			DefaultMode: &secretMode,
+		}},
+	})
+}
+
+// setStorage sets the environment and the volumes of the container and its pod to the storage backend. The
+// former environment and volumes are replaced.
+func setStorage(
+	podSpec *corev1.PodSpec,
+	container *corev1.Container,
+	reg *sdiv1alpha1.SDIRegistry,
+	backend string,
+) {
+	switch backend {
+	case sdiv1alpha1.SDIRegistryStorageBackendS3:
+		container.Env = getS3Env(&reg.Spec.Storage.S3)
+		container.VolumeMounts = nil
+		podSpec.Volumes = nil
+		if len(strings.TrimSpace(reg.Spec.Storage.S3.CACertificate)) > 0 {
+			container.VolumeMounts = []corev1.VolumeMount{{Name: s3CAVolume, MountPath: s3CAMount, ReadOnly: true}}
+			defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+			podSpec.Volumes = []corev1.Volume{{
+				Name: s3CAVolume,
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: s3CAName},
+					DefaultMode:          &defaultMode,
+				}},
+			}}
+		}
+	case sdiv1alpha1.SDIRegistryStorageBackendObjectBucketClaim:
+		container.Env = getBucketEnv()
+		container.VolumeMounts = []corev1.VolumeMount{
+			{Name: serviceCAVolume, MountPath: serviceCAMount, ReadOnly: true},
+		}
+		defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+		podSpec.Volumes = []corev1.Volume{{
+			Name: serviceCAVolume,
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: serviceCAName},
+				DefaultMode:          &defaultMode,
+			}},
+		}}
+	default:
+		container.Env = nil
+		container.VolumeMounts = []corev1.VolumeMount{{Name: storageVolumeName, MountPath: storageMountPath}}
+		podSpec.Volumes = []corev1.Volume{{
+			Name: storageVolumeName,
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: componentName,
+			}},
+		}}
+	}
+}
+
+// getBucketEnv configures the S3 driver of the registry from the config map and the secret the bucket
+// provisioner creates along with the bound claim.
+func getBucketEnv() []corev1.EnvVar {
+	fromConfigMap := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: componentName},
+				Key:                  key,
+			},
+		}}
+	}
+	fromSecret := func(name, key string) corev1.EnvVar {
+		return envFromSecret(name, componentName, key)
+	}
+	return []corev1.EnvVar{
+		fromConfigMap("BUCKET_HOST", "BUCKET_HOST"),
+		fromConfigMap("BUCKET_PORT", "BUCKET_PORT"),
+		{Name: "REGISTRY_STORAGE", Value: "s3"},
+		fromConfigMap("REGISTRY_STORAGE_S3_BUCKET", "BUCKET_NAME"),
+		{Name: "REGISTRY_STORAGE_S3_REGIONENDPOINT", Value: "https://$(BUCKET_HOST):$(BUCKET_PORT)"},
+		{Name: "REGISTRY_STORAGE_S3_REGION", Value: defaultS3Region},
+		fromSecret("REGISTRY_STORAGE_S3_ACCESSKEY", "AWS_ACCESS_KEY_ID"),
+		fromSecret("REGISTRY_STORAGE_S3_SECRETKEY", "AWS_SECRET_ACCESS_KEY"),
+		// the in-cluster endpoint is signed by the service CA, the system roots are kept
+		{Name: "SSL_CERT_DIR", Value: "/etc/ssl/certs:" + serviceCAMount},
+	}
+}
+
+func envFromSecret(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+			Key:                  key,
+		},
+	}}
+}
+
+func isRolledOut(deploy *appsv1.Deployment) bool {
+	return deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas == deploy.Status.Replicas
+}
+
+func setDeploymentConditions(reg *sdiv1alpha1.SDIRegistry, deploy *appsv1.Deployment, routeReady bool) {
+	if isRolledOut(deploy) {
+		setCondition(reg, "Progressing", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected,
+			"the registry deployment is rolled out")
+	} else {
+		setCondition(reg, "Progressing", metav1.ConditionTrue, "RollingOut",
+			"the registry deployment is being rolled out")
+	}
+	switch {
+	case deploy.Status.AvailableReplicas < 1:
+		setCondition(reg, "Ready", metav1.ConditionFalse, "Unavailable", "the registry is not available yet")
+	case !routeReady:
+		setCondition(reg, "Ready", metav1.ConditionFalse, sdiv1alpha1.ConditionRouteNotAdmitted,
+			"the registry route has not been admitted yet")
+	default:
+		setCondition(reg, "Ready", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+			"the registry is available")
+	}
+	setCondition(reg, "Degraded", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected,
+		"all the registry components are deployed")
+}
+
+// cleanup removes the credential secrets that cannot be garbage collected using owner references.
+func (r *Reconciler) cleanup(ctx context.Context, reg *sdiv1alpha1.SDIRegistry) error {
+	defer λ.Leave(λ.Enter(log.FromContext(ctx)))
+	forgetMetrics(reg)
+	keys := reg.Status.PublishedCredentials
+	for _, ns := range r.getCredentialNamespaces(reg) {
+		if key := ns + "/" + getCredentialSecretName(reg); !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return r.deletePublishedCredentials(ctx, reg, keys)
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&sdiv1alpha1.SDIRegistry{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&corev1.Secret{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.Service{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&batchv1.CronJob{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, primaryresource.EnqueueRequestsForOwner(kind)).
+		// the serving secret is owned by the service
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.mapServingSecret)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.mapS3Secret)).
+		// the garbage collection jobs are owned by the cron job
+		Watches(&source.Kind{Type: &batchv1.Job{}}, handler.EnqueueRequestsFromMapFunc(r.mapGarbageCollectionJob))
+	r.noRouteAPI = !routeapi.IsServed(mgr.GetRESTMapper())
+	if r.noRouteAPI {
+		mgr.GetLogger().Info("the route API is not served, the registry routes are not managed")
+	} else {
+		b = b.Owns(&routev1.Route{})
+	}
+	_, err := mgr.GetRESTMapper().RESTMapping(objectBucketClaimGVK.GroupKind(), objectBucketClaimGVK.Version)
+	r.noBucketAPI = meta.IsNoMatchError(err)
+	if r.noBucketAPI {
+		mgr.GetLogger().Info("the object bucket claim API is not served, the ObjectBucketClaim storage backend" +
+			" is not available")
+	} else {
+		obc := &unstructured.Unstructured{}
+		obc.SetGroupVersionKind(objectBucketClaimGVK)
+		b = b.Owns(obc)
+	}
+	_, err = mgr.GetRESTMapper().RESTMapping(prometheusRuleGVK.GroupKind(), prometheusRuleGVK.Version)
+	r.noMonitoringAPI = meta.IsNoMatchError(err)
+	if r.noMonitoringAPI {
+		mgr.GetLogger().Info("the PrometheusRule API is not served, the registry alerts are not managed")
+	} else {
+		rule := &unstructured.Unstructured{}
+		rule.SetGroupVersionKind(prometheusRuleGVK)
+		b = b.Owns(rule)
+	}
+	return b.Complete(r)
+}
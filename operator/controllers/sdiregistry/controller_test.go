@@ -0,0 +1,305 @@
+package sdiregistry_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	testroutes "github.com/redhat-sap/sap-data-intelligence/operator/test/routes"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/htpasswd"
+)
+
+const (
+	timeout  = time.Second * 5
+	interval = time.Millisecond * 100
+
+	registryImage      = "registry.example.ltd/ubi8/registry:2"
+	componentName      = "container-image-registry"
+	htpasswdSecretName = componentName + "-htpasswd"
+	credentialName     = componentName + "-credentials"
+)
+
+// the owned objects are not garbage collected in the test environment, each spec gets new namespaces
+var namespaceIndex int
+
+func waitForRegistry(reg *sdiv1alpha1.SDIRegistry, assert func(g Gomega, reg *sdiv1alpha1.SDIRegistry)) {
+	EventuallyWithOffset(1, func(g Gomega) {
+		g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(reg), reg)).ToNot(HaveOccurred())
+		assert(g, reg)
+	}, timeout, interval).Should(Succeed())
+}
+
+func haveCondition(g Gomega, reg *sdiv1alpha1.SDIRegistry, cType string, status metav1.ConditionStatus, reason string) {
+	c := meta.FindStatusCondition(reg.Status.Conditions, cType)
+	g.Ω(c).WithOffset(1).NotTo(BeNil())
+	g.Ω(c.Status).WithOffset(1).To(Equal(status))
+	g.Ω(c.Reason).WithOffset(1).To(Equal(reason))
+}
+
+func waitForObject(obj client.Object) {
+	EventuallyWithOffset(1, func() error {
+		return k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+	}, timeout, interval).Should(Succeed())
+}
+
+func waitForDeletion(obj client.Object) {
+	EventuallyWithOffset(1, func() bool {
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+		return errors.IsNotFound(err)
+	}, timeout, interval).Should(BeTrue())
+}
+
+var _ = Describe("SDIRegistry controller", func() {
+	var reg *sdiv1alpha1.SDIRegistry
+	var namespace string
+
+	clientsNamespace := func() string {
+		return namespace + "-clients"
+	}
+	registryDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: componentName}}
+	}
+	registryRoute := func() *routev1.Route {
+		return &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: componentName}}
+	}
+	publishedSecret := func() *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: clientsNamespace(), Name: credentialName}}
+	}
+
+	// issueServingCert plays the service CA operator
+	issueServingCert := func() {
+		Ω(k8sClient.Create(context.Background(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: componentName + "-tls"},
+			Type:       corev1.SecretTypeTLS,
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       []byte(testroutes.VSystemCABundle),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+			},
+		})).NotTo(HaveOccurred())
+	}
+
+	// injectServiceCA plays the service CA operator
+	injectServiceCA := func() {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: componentName + "-service-ca"}}
+		waitForObject(cm)
+		Ω(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cm), cm); err != nil {
+				return err
+			}
+			cm.Data = map[string]string{"service-ca.crt": testroutes.VSystemCABundle}
+			return k8sClient.Update(context.TODO(), cm)
+		})).NotTo(HaveOccurred())
+	}
+
+	// rollOut plays the deployment controller
+	rollOut := func() {
+		deploy := registryDeployment()
+		waitForObject(deploy)
+		Ω(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(deploy), deploy); err != nil {
+				return err
+			}
+			deploy.Status.ObservedGeneration = deploy.Generation
+			deploy.Status.Replicas = 1
+			deploy.Status.UpdatedReplicas = 1
+			deploy.Status.ReadyReplicas = 1
+			deploy.Status.AvailableReplicas = 1
+			return k8sClient.Status().Update(context.TODO(), deploy)
+		})).NotTo(HaveOccurred())
+	}
+
+	updateRegistry := func(update func(reg *sdiv1alpha1.SDIRegistry)) {
+		Ω(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(reg), reg); err != nil {
+				return err
+			}
+			update(reg)
+			return k8sClient.Update(context.TODO(), reg)
+		})).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		namespaceIndex++
+		namespace = fmt.Sprintf("registry-%d", namespaceIndex)
+		for _, nm := range []string{namespace, clientsNamespace()} {
+			Ω(k8sClient.Create(context.Background(), &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: nm},
+			})).NotTo(HaveOccurred())
+		}
+
+		reg = &sdiv1alpha1.SDIRegistry{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      "registry",
+			},
+			Spec: sdiv1alpha1.SDIRegistrySpec{
+				Image: registryImage,
+				Storage: sdiv1alpha1.SDIRegistrySpecStorage{
+					Backend: sdiv1alpha1.SDIRegistryStorageBackendPVC,
+				},
+				Auth: sdiv1alpha1.SDIRegistrySpecAuth{
+					CredentialNamespaces: []string{clientsNamespace()},
+				},
+			},
+		}
+		Ω(k8sClient.Create(context.Background(), reg)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(context.Background(), reg)
+		if !errors.IsNotFound(err) {
+			Ω(err).NotTo(HaveOccurred())
+		}
+		waitForDeletion(reg)
+	})
+
+	Context("When an SDIRegistry is created with the PVC backend", func() {
+		It("Should wait for the serving certificate", func() {
+			waitForRegistry(reg, func(g Gomega, reg *sdiv1alpha1.SDIRegistry) {
+				g.Ω(reg.Status.HtpasswdSecretName).To(Equal(htpasswdSecretName))
+				haveCondition(g, reg, "Ready", metav1.ConditionFalse, "ServingCertPending")
+			})
+			pvc := &corev1.PersistentVolumeClaim{}
+			err := k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: componentName}, pvc)
+			Ω(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should deploy the registry on a persistent volume", func() {
+			issueServingCert()
+
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: componentName}}
+			waitForObject(pvc)
+			Ω(pvc.Spec.AccessModes).To(Equal([]corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}))
+			Ω(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(resource.MustParse("120Gi")))
+			Ω(metav1.IsControlledBy(pvc, reg)).To(BeTrue())
+
+			deploy := registryDeployment()
+			waitForObject(deploy)
+			// a ReadWriteOnce volume cannot be attached to two nodes at once
+			Ω(deploy.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+			Ω(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal(registryImage))
+			var claimName string
+			for _, v := range deploy.Spec.Template.Spec.Volumes {
+				if v.PersistentVolumeClaim != nil {
+					claimName = v.PersistentVolumeClaim.ClaimName
+				}
+			}
+			Ω(claimName).To(Equal(componentName))
+
+			waitForRegistry(reg, func(g Gomega, reg *sdiv1alpha1.SDIRegistry) {
+				g.Ω(reg.Status.StorageBackend).To(Equal(sdiv1alpha1.SDIRegistryStorageBackendPVC))
+				g.Ω(reg.Status.ServiceAddress).To(Equal(componentName + "." + namespace + ".svc:5000"))
+				haveCondition(g, reg, "Ready", metav1.ConditionFalse, "Unavailable")
+				haveCondition(g, reg, "Degraded", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected)
+			})
+		})
+
+		It("Should publish the credential once the registry is available", func() {
+			issueServingCert()
+			rollOut()
+
+			secret := publishedSecret()
+			waitForObject(secret)
+			Ω(secret.Type).To(Equal(corev1.SecretTypeDockerConfigJson))
+			Ω(secret.Data).To(HaveKey(corev1.DockerConfigJsonKey))
+			waitForRegistry(reg, func(g Gomega, reg *sdiv1alpha1.SDIRegistry) {
+				g.Ω(reg.Status.PublishedCredentials).To(Equal([]string{clientsNamespace() + "/" + credentialName}))
+				haveCondition(g, reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionTrue,
+					sdiv1alpha1.ConditionReasonAsExpected)
+			})
+
+			By("removing the published credential once deleted")
+			Ω(k8sClient.Delete(context.Background(), reg)).NotTo(HaveOccurred())
+			waitForDeletion(reg)
+			waitForDeletion(secret)
+		})
+	})
+
+	Context("When the route is Removed", func() {
+		It("Should delete the route", func() {
+			issueServingCert()
+			injectServiceCA()
+
+			route := registryRoute()
+			waitForObject(route)
+			Ω(route.Spec.TLS).NotTo(BeNil())
+			Ω(route.Spec.TLS.Termination).To(Equal(routev1.TLSTerminationReencrypt))
+			Ω(route.Spec.TLS.DestinationCACertificate).To(Equal(testroutes.VSystemCABundle))
+
+			updateRegistry(func(reg *sdiv1alpha1.SDIRegistry) {
+				reg.Spec.Route.ManagementState = sdiv1alpha1.RouteManagementStateRemoved
+			})
+			waitForDeletion(route)
+			waitForRegistry(reg, func(g Gomega, reg *sdiv1alpha1.SDIRegistry) {
+				g.Ω(reg.Status.ObservedGeneration).To(Equal(reg.Generation))
+				g.Ω(reg.Status.Hostname).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("When the credential is rotated", func() {
+		It("Should publish the new credential once the registry accepts it", func() {
+			issueServingCert()
+			rollOut()
+			secret := publishedSecret()
+			waitForObject(secret)
+			formerConfig := secret.Data[corev1.DockerConfigJsonKey]
+
+			htpasswdSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace,
+				Name: htpasswdSecretName}}
+			waitForObject(htpasswdSecret)
+			formerUsers := htpasswd.Users(string(htpasswdSecret.Data["htpasswd"]))
+			Ω(formerUsers).To(HaveLen(1))
+
+			updateRegistry(func(reg *sdiv1alpha1.SDIRegistry) {
+				reg.Spec.Auth.RotateCredentials = "1"
+			})
+
+			By("accepting both credentials until the registry is restarted")
+			Eventually(func(g Gomega) {
+				g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(htpasswdSecret),
+					htpasswdSecret)).ToNot(HaveOccurred())
+				g.Ω(htpasswd.Users(string(htpasswdSecret.Data["htpasswd"]))).To(HaveLen(2))
+			}, timeout, interval).Should(Succeed())
+			waitForRegistry(reg, func(g Gomega, reg *sdiv1alpha1.SDIRegistry) {
+				haveCondition(g, reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionFalse,
+					"WaitingForRollout")
+			})
+			Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).ToNot(HaveOccurred())
+			Ω(secret.Data[corev1.DockerConfigJsonKey]).To(Equal(formerConfig))
+
+			By("revoking the former credential once the new one is published")
+			rollOut()
+			Eventually(func(g Gomega) {
+				g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).ToNot(HaveOccurred())
+				g.Ω(secret.Data[corev1.DockerConfigJsonKey]).NotTo(Equal(formerConfig))
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(htpasswdSecret),
+					htpasswdSecret)).ToNot(HaveOccurred())
+				users := htpasswd.Users(string(htpasswdSecret.Data["htpasswd"]))
+				g.Ω(users).To(HaveLen(1))
+				g.Ω(users).NotTo(Equal(formerUsers))
+			}, timeout, interval).Should(Succeed())
+			waitForRegistry(reg, func(g Gomega, reg *sdiv1alpha1.SDIRegistry) {
+				haveCondition(g, reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionTrue,
+					sdiv1alpha1.ConditionReasonAsExpected)
+			})
+		})
+	})
+})
@@ -0,0 +1,322 @@
+package sdiregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/htpasswd"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// the names and keys of the secret are the same as of deploy-registry.sh
+	htpasswdSecretName = componentName + "-htpasswd"
+	htpasswdKey        = "htpasswd"
+	rawCredentialKey   = ".htpasswd.raw"
+
+	htpasswdVolume   = "htpasswd"
+	htpasswdMountDir = "/etc/docker-distribution/auth"
+
+	defaultCredentialSecretName = componentName + "-credentials"
+
+	// the value of spec.auth.rotateCredentials the generated credential corresponds to
+	rotationAnnotationKey = "di.sap-cop.redhat.com/rotate-credentials"
+	// the checksum of the htpasswd file the registry pods run with
+	checksumAnnotationKey = "di.sap-cop.redhat.com/htpasswd-checksum"
+)
+
+// credentials describe the htpasswd file mounted by the registry.
+type credentials struct {
+	secretName string
+	checksum   string
+	// the credential to publish; nil if unknown
+	current *htpasswd.Credential
+	// the generated htpasswd file still accepts the former credentials
+	trimmable bool
+}
+
+func checksum(data []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// manageCredentials returns the htpasswd file provided by the user or the generated one.
+func (r *Reconciler) manageCredentials(ctx context.Context, reg *sdiv1alpha1.SDIRegistry) (*credentials, error) {
+	if ref := reg.Spec.Auth.HtpasswdSecretRef; ref != nil && len(ref.Name) > 0 {
+		return r.getProvidedCredentials(ctx, reg, ref.Name)
+	}
+	return r.manageHtpasswd(ctx, reg)
+}
+
+func (r *Reconciler) getProvidedCredentials(
+	ctx context.Context,
+	reg *sdiv1alpha1.SDIRegistry,
+	name string,
+) (*credentials, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get htpasswd secret %s: %v", name, err)
+	}
+	file := secret.Data[htpasswdKey]
+	if len(htpasswd.Users(string(file))) == 0 {
+		return nil, fmt.Errorf("secret %s has no htpasswd entries under the %s key", name, htpasswdKey)
+	}
+	creds := &credentials{secretName: name, checksum: checksum(file)}
+	raw, ok := secret.Data[rawCredentialKey]
+	if !ok {
+		return creds, nil
+	}
+	c, err := htpasswd.ParseCredential(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s of secret %s: %v", rawCredentialKey, name, err)
+	}
+	if !htpasswd.Verify(string(file), c) {
+		return nil, fmt.Errorf("the htpasswd file of secret %s does not accept the credential of %s", name,
+			c.Username)
+	}
+	creds.current = &c
+	return creds, nil
+}
+
+// manageHtpasswd generates the htpasswd secret unless it exists. A new credential is generated for each new
+// value of spec.auth.rotateCredentials; the entries of the former ones are kept until trimmed.
+func (r *Reconciler) manageHtpasswd(ctx context.Context, reg *sdiv1alpha1.SDIRegistry) (*credentials, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: htpasswdSecretName}}
+	if _, _, err := r.apply(ctx, reg, "htpasswd secret", secret, func() error {
+		trigger := reg.Spec.Auth.RotateCredentials
+		file := string(secret.Data[htpasswdKey])
+		c, err := htpasswd.ParseCredential(string(secret.Data[rawCredentialKey]))
+		if err == nil && containsString(htpasswd.Users(file), c.Username) &&
+			secret.Annotations[rotationAnnotationKey] == trigger {
+			return nil
+		}
+		next, err := htpasswd.Generate()
+		if err != nil {
+			return err
+		}
+		if fips.Enforced() {
+			// a known exception of the FIPS mode, the registry accepts no other hash
+			tracer.Info("hashing the registry credential with bcrypt, which is not FIPS approved")
+		}
+		entry, err := next.Entry()
+		if err != nil {
+			return err
+		}
+		if len(file) > 0 {
+			tracer.Info("rotating the registry credential", "user", next.Username)
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[rotationAnnotationKey] = trigger
+		secret.Data = map[string][]byte{
+			// the former credential stays valid until the published secrets are updated
+			htpasswdKey:      []byte(htpasswd.Join(file, entry)),
+			rawCredentialKey: []byte(next.String()),
+		}
+		return nil
+	}, false); err != nil {
+		return nil, err
+	}
+
+	c, err := htpasswd.ParseCredential(string(secret.Data[rawCredentialKey]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s of secret %s: %v", rawCredentialKey, htpasswdSecretName, err)
+	}
+	return &credentials{
+		secretName: htpasswdSecretName,
+		checksum:   checksum(secret.Data[htpasswdKey]),
+		current:    &c,
+		trimmable:  len(htpasswd.Users(string(secret.Data[htpasswdKey]))) > 1,
+	}, nil
+}
+
+// trimHtpasswd removes the entries of the former credentials from the generated htpasswd file.
+func (r *Reconciler) trimHtpasswd(ctx context.Context, reg *sdiv1alpha1.SDIRegistry, creds *credentials) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: htpasswdSecretName}}
+	_, _, err := r.apply(ctx, reg, "htpasswd secret", secret, func() error {
+		c, err := htpasswd.ParseCredential(string(secret.Data[rawCredentialKey]))
+		if err != nil || c.Username != creds.current.Username {
+			// rotated meanwhile
+			return nil
+		}
+		secret.Data[htpasswdKey] = []byte(htpasswd.Keep(string(secret.Data[htpasswdKey]), c.Username))
+		return nil
+	}, false)
+	return err
+}
+
+func (r *Reconciler) getCredentialNamespaces(reg *sdiv1alpha1.SDIRegistry) []string {
+	namespaces := reg.Spec.Auth.CredentialNamespaces
+	if len(namespaces) == 0 {
+		namespaces = r.defaultNamespaces
+	}
+	var unique []string
+	for _, ns := range namespaces {
+		if len(ns) > 0 && !containsString(unique, ns) {
+			unique = append(unique, ns)
+		}
+	}
+	return unique
+}
+
+func getCredentialSecretName(reg *sdiv1alpha1.SDIRegistry) string {
+	if len(reg.Spec.Auth.CredentialSecretName) > 0 {
+		return reg.Spec.Auth.CredentialSecretName
+	}
+	return defaultCredentialSecretName
+}
+
+// publishCredentials keeps the credential secrets in the desired namespaces up to date and returns true if all
+// of them carry the current credential. The secrets are updated only once the registry accepts the credential,
+// so that the clients never hold a credential that is rejected.
+func (r *Reconciler) publishCredentials(
+	ctx context.Context,
+	reg *sdiv1alpha1.SDIRegistry,
+	creds *credentials,
+	accepted bool,
+) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	name := getCredentialSecretName(reg)
+	var desired []string
+	if creds.current != nil {
+		for _, ns := range r.getCredentialNamespaces(reg) {
+			desired = append(desired, ns+"/"+name)
+		}
+	}
+	var stale []string
+	for _, key := range reg.Status.PublishedCredentials {
+		if !containsString(desired, key) {
+			stale = append(stale, key)
+		}
+	}
+	if err := r.deletePublishedCredentials(ctx, reg, stale); err != nil {
+		return false, err
+	}
+	reg.Status.PublishedCredentials = nil
+	if creds.current == nil {
+		setCondition(reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionFalse, "NoCredential",
+			fmt.Sprintf("secret %s has no %s key, no credential is published", creds.secretName, rawCredentialKey))
+		return false, nil
+	}
+	if len(desired) == 0 {
+		setCondition(reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionFalse, "NoNamespaces",
+			"no namespaces to publish the credential to, set spec.auth.credentialNamespaces")
+		return false, nil
+	}
+
+	registries := []string{reg.Status.ServiceAddress}
+	if len(reg.Status.Hostname) > 0 {
+		registries = append(registries, reg.Status.Hostname)
+	}
+	data, err := htpasswd.DockerConfigJSON(*creds.current, registries...)
+	if err != nil {
+		return false, err
+	}
+	upToDate := true
+	for _, key := range desired {
+		parts := strings.SplitN(key, "/", 2)
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: parts[0], Name: parts[1]}}
+		err := r.Get(ctx, types.NamespacedName{Namespace: parts[0], Name: parts[1]}, secret)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if err == nil && !primaryresource.IsOwnedBy(secret, reg, kind) {
+			err = fmt.Errorf("secret %s exists and is not managed by the SDIRegistry", key)
+			setCondition(reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionFalse, "Conflict",
+				err.Error())
+			return false, err
+		}
+		if err == nil {
+			reg.Status.PublishedCredentials = append(reg.Status.PublishedCredentials, key)
+			if bytes.Equal(secret.Data[corev1.DockerConfigJsonKey], data) {
+				continue
+			}
+		}
+		if !accepted {
+			upToDate = false
+			continue
+		}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+			primaryresource.Set(secret, reg, kind)
+			secret.Type = corev1.SecretTypeDockerConfigJson
+			secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: data}
+			return nil
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to publish credential secret %s: %v", key, err)
+			setCondition(reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionFalse, "FailedPublish",
+				err.Error())
+			return false, err
+		}
+		tracer.Info("published registry credential", "secret", key, "operation", op)
+		if op == controllerutil.OperationResultCreated {
+			reg.Status.PublishedCredentials = append(reg.Status.PublishedCredentials, key)
+		}
+	}
+	if !upToDate {
+		setCondition(reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionFalse, "WaitingForRollout",
+			"the credential is published once the registry accepts it")
+		return false, nil
+	}
+	setCondition(reg, sdiv1alpha1.ConditionCredentialsPublished, metav1.ConditionTrue,
+		sdiv1alpha1.ConditionReasonAsExpected, "the credential of "+creds.current.Username+" is published to "+
+			strings.Join(desired, ", "))
+	return true, nil
+}
+
+// deletePublishedCredentials deletes the given credential secrets published by the SDIRegistry.
+func (r *Reconciler) deletePublishedCredentials(
+	ctx context.Context,
+	reg *sdiv1alpha1.SDIRegistry,
+	keys []string,
+) error {
+	for _, key := range keys {
+		parts := strings.SplitN(key, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Namespace: parts[0], Name: parts[1]}, secret)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !primaryresource.IsOwnedBy(secret, reg, kind) {
+			continue
+		}
+		log.FromContext(ctx).Info("deleting published registry credential", "secret", key)
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package sdiregistry
+
+import (
+	"context"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/images"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+)
+
+const (
+	gcComponentName = componentName + "-gc"
+	// the key of the config map telling the garbage collection pod whether the registry is read-only
+	gcReadyKey      = "readOnly"
+	gcStateVolume   = "gc-state"
+	gcStateMountDir = "/etc/registry/gc"
+	// the configuration file of the registry image, overridden by the environment
+	registryConfigPath = "/etc/docker/registry/config.yml"
+	readOnlyEnvName    = "REGISTRY_STORAGE_MAINTENANCE_READONLY"
+	// limits the time the registry stays read-only if the garbage collection hangs
+	gcActiveDeadlineSeconds int64 = 3 * 60 * 60
+)
+
+// manageGarbageCollection applies the garbage collection cron job or deletes it if no schedule is set. It
+// returns true while a garbage collection job is running and the registry shall be read-only.
+func (r *Reconciler) manageGarbageCollection(
+	ctx context.Context,
+	reg *sdiv1alpha1.SDIRegistry,
+	backend string,
+	recreate bool,
+) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: gcComponentName}}
+	if len(reg.Spec.GarbageCollection.Schedule) == 0 {
+		// the running jobs are deleted along with the cron job
+		propagation := metav1.DeletePropagationBackground
+		if err := r.Delete(ctx, cronJob, &client.DeleteOptions{PropagationPolicy: &propagation}); err == nil {
+			tracer.Info("deleted the garbage collection cron job")
+		} else if !errors.IsNotFound(err) {
+			return false, err
+		}
+		recordGarbageCollection(reg, nil)
+		return false, nil
+	}
+	if _, _, err := r.apply(ctx, reg, "garbage collection cron job", cronJob, func() error {
+		mutateCronJob(cronJob, reg, backend, recreate)
+		return nil
+	}, false); err != nil {
+		return false, err
+	}
+	reg.Status.LastGarbageCollectionTime = cronJob.Status.LastSuccessfulTime
+
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(reg.Namespace),
+		client.MatchingLabels{appLabelKey: gcComponentName}); err != nil {
+		return false, err
+	}
+	recordGarbageCollection(reg, jobs.Items)
+	for i := range jobs.Items {
+		if job := &jobs.Items[i]; job.DeletionTimestamp.IsZero() && !isJobFinished(job) {
+			tracer.V(1).Info("garbage collection is running", "job", job.Name)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setGarbageCollectionReady lets the waiting garbage collection pod proceed once the registry is read-only. The
+// config map is deleted with the cron job.
+func (r *Reconciler) setGarbageCollectionReady(ctx context.Context, reg *sdiv1alpha1.SDIRegistry, ready bool) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: gcComponentName}}
+	if len(reg.Spec.GarbageCollection.Schedule) == 0 {
+		if err := r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	_, _, err := r.apply(ctx, reg, "garbage collection config map", cm, func() error {
+		cm.Labels = map[string]string{appLabelKey: gcComponentName}
+		cm.Data = map[string]string{gcReadyKey: strconv.FormatBool(ready)}
+		return nil
+	}, false)
+	return err
+}
+
+// mutateCronJob sets the spec of the garbage collection cron job. The job waits in an init container until the
+// registry runs in read-only mode, otherwise the blobs of the images being pushed could be deleted.
+func mutateCronJob(cronJob *batchv1.CronJob, reg *sdiv1alpha1.SDIRegistry, backend string, recreate bool) {
+	labels := map[string]string{appLabelKey: gcComponentName}
+	var historyLimit int32 = 1
+	var backoffLimit int32 = 1
+	deadline := gcActiveDeadlineSeconds
+	cronJob.Labels = labels
+	cronJob.Spec.Schedule = reg.Spec.GarbageCollection.Schedule
+	cronJob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronJob.Spec.SuccessfulJobsHistoryLimit = &historyLimit
+	cronJob.Spec.FailedJobsHistoryLimit = &historyLimit
+	jobSpec := &cronJob.Spec.JobTemplate.Spec
+	cronJob.Spec.JobTemplate.Labels = labels
+	jobSpec.BackoffLimit = &backoffLimit
+	jobSpec.ActiveDeadlineSeconds = &deadline
+	jobSpec.Template.Labels = labels
+
+	podSpec := &jobSpec.Template.Spec
+	podSpec.ServiceAccountName = componentName
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	image := images.Registry.Resolve(reg.Spec.Image)
+	if len(podSpec.InitContainers) != 1 {
+		podSpec.InitContainers = []corev1.Container{{}}
+	}
+	initContainer := &podSpec.InitContainers[0]
+	initContainer.Name = "wait-for-read-only"
+	initContainer.Image = image
+	initContainer.Command = []string{"/bin/sh", "-c",
+		"until grep -qx true " + gcStateMountDir + "/" + gcReadyKey + "; do sleep 5; done"}
+	initContainer.VolumeMounts = []corev1.VolumeMount{{Name: gcStateVolume, MountPath: gcStateMountDir}}
+
+	if len(podSpec.Containers) != 1 {
+		podSpec.Containers = []corev1.Container{{}}
+	}
+	container := &podSpec.Containers[0]
+	container.Name = "garbage-collect"
+	container.Image = image
+	container.Command = []string{"registry", "garbage-collect"}
+	if reg.Spec.GarbageCollection.DeleteUntagged {
+		container.Command = append(container.Command, "--delete-untagged")
+	}
+	container.Command = append(container.Command, registryConfigPath)
+	setStorage(podSpec, container, reg, backend)
+	defaultMode := corev1.ConfigMapVolumeSourceDefaultMode
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: gcStateVolume,
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: gcComponentName},
+			DefaultMode:          &defaultMode,
+		}},
+	})
+	podSpec.Affinity = nil
+	if recreate {
+		// a ReadWriteOnce volume can only be mounted on the node of the registry
+		podSpec.Affinity = getColocationAffinity(componentName)
+	}
+}
+
+// getColocationAffinity requires the pod to be scheduled on the node of a pod of the app.
+func getColocationAffinity(app string) *corev1.Affinity {
+	return &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{appLabelKey: app}},
+			TopologyKey:   corev1.LabelHostname,
+		}},
+	}}
+}
+
+func isJobFinished(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// isReadOnly returns true if the registry pods of the deployment run in read-only mode.
+func isReadOnly(deploy *appsv1.Deployment) bool {
+	for _, container := range deploy.Spec.Template.Spec.Containers {
+		if container.Name != containerName {
+			continue
+		}
+		for _, env := range container.Env {
+			if env.Name == readOnlyEnvName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapGarbageCollectionJob enqueues the registries of the namespace of the garbage collection job. The job is
+// owned by the cron job, not by the registry.
+func (r *Reconciler) mapGarbageCollectionJob(job client.Object) []reconcile.Request {
+	if job.GetLabels()[appLabelKey] != gcComponentName {
+		return nil
+	}
+	return r.enqueueNamespace(job.GetNamespace())
+}
@@ -0,0 +1,208 @@
+package sdiregistry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+const (
+	metricsNamespace       = "sdi_observer"
+	registryNamespaceLabel = "registry_namespace"
+	registryLabel          = "registry"
+	claimLabel             = "persistentvolumeclaim"
+
+	// how often the metrics are refreshed in the absence of events
+	metricsInterval = 5 * time.Minute
+	catalogPageSize = 1000
+	catalogTimeout  = 10 * time.Second
+)
+
+var (
+	registryStorageCapacity = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "registry_storage_capacity_bytes",
+		Help:      "Capacity of the persistent volume claim of the registry. The usage is reported by the kubelet.",
+	}, []string{registryNamespaceLabel, registryLabel, claimLabel})
+	registryRepositories = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "registry_repositories",
+		Help:      "Number of the repositories in the catalog of the registry.",
+	}, []string{registryNamespaceLabel, registryLabel})
+	registryReadOnly = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "registry_read_only",
+		Help:      "Whether the registry runs in read-only mode for the garbage collection (1) or not (0).",
+	}, []string{registryNamespaceLabel, registryLabel})
+	registryGCLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "registry_gc_last_success_timestamp_seconds",
+		Help:      "Completion time of the last successful garbage collection of the registry.",
+	}, []string{registryNamespaceLabel, registryLabel})
+	registryGCLastFailed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "registry_gc_last_failed",
+		Help:      "Whether the last finished garbage collection job of the registry failed (1) or not (0).",
+	}, []string{registryNamespaceLabel, registryLabel})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		registryStorageCapacity,
+		registryRepositories,
+		registryReadOnly,
+		registryGCLastSuccess,
+		registryGCLastFailed,
+	)
+}
+
+func registryLabels(reg *sdiv1alpha1.SDIRegistry) prometheus.Labels {
+	return prometheus.Labels{registryNamespaceLabel: reg.Namespace, registryLabel: reg.Name}
+}
+
+func boolGauge(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// recordStorage updates the capacity gauge of the claim. A nil claim removes the gauge.
+func recordStorage(reg *sdiv1alpha1.SDIRegistry, pvc *corev1.PersistentVolumeClaim) {
+	labels := registryLabels(reg)
+	labels[claimLabel] = componentName
+	var capacity corev1.ResourceList
+	if pvc != nil {
+		capacity = pvc.Status.Capacity
+	}
+	storage, ok := capacity[corev1.ResourceStorage]
+	if !ok {
+		registryStorageCapacity.Delete(labels)
+		return
+	}
+	registryStorageCapacity.With(labels).Set(float64(storage.Value()))
+}
+
+func recordReadOnly(reg *sdiv1alpha1.SDIRegistry) {
+	registryReadOnly.With(registryLabels(reg)).Set(boolGauge(reg.Status.ReadOnly))
+}
+
+// recordGarbageCollection updates the gauges of the garbage collection from its jobs. Without a schedule, the
+// gauges are removed.
+func recordGarbageCollection(reg *sdiv1alpha1.SDIRegistry, jobs []batchv1.Job) {
+	labels := registryLabels(reg)
+	if len(reg.Spec.GarbageCollection.Schedule) == 0 {
+		registryGCLastSuccess.Delete(labels)
+		registryGCLastFailed.Delete(labels)
+		return
+	}
+	if t := reg.Status.LastGarbageCollectionTime; t != nil {
+		registryGCLastSuccess.With(labels).Set(float64(t.Unix()))
+	}
+	var latest *batchv1.Job
+	for i := range jobs {
+		job := &jobs[i]
+		if isJobFinished(job) && (latest == nil || latest.CreationTimestamp.Before(&job.CreationTimestamp)) {
+			latest = job
+		}
+	}
+	if latest == nil {
+		return
+	}
+	registryGCLastFailed.With(labels).Set(boolGauge(latest.Status.Succeeded == 0))
+}
+
+// forgetMetrics removes the gauges of a deleted registry.
+func forgetMetrics(reg *sdiv1alpha1.SDIRegistry) {
+	labels := registryLabels(reg)
+	for _, g := range []*prometheus.GaugeVec{
+		registryRepositories,
+		registryReadOnly,
+		registryGCLastSuccess,
+		registryGCLastFailed,
+	} {
+		g.Delete(labels)
+	}
+	labels[claimLabel] = componentName
+	registryStorageCapacity.Delete(labels)
+}
+
+var reNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// recordRepositories counts the repositories of the registry catalog. The registry is queried through its
+// service trusting the service CA. Failures remove the gauge.
+func recordRepositories(ctx context.Context, reg *sdiv1alpha1.SDIRegistry, creds *credentials, serviceCA string) {
+	labels := registryLabels(reg)
+	count, err := countRepositories(ctx, reg, creds, serviceCA)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("failed to count the registry repositories", "error", err)
+		registryRepositories.Delete(labels)
+		return
+	}
+	registryRepositories.With(labels).Set(float64(count))
+}
+
+func countRepositories(
+	ctx context.Context,
+	reg *sdiv1alpha1.SDIRegistry,
+	creds *credentials,
+	serviceCA string,
+) (int, error) {
+	if creds.current == nil {
+		return 0, fmt.Errorf("the credential of the registry is unknown")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(serviceCA)) {
+		return 0, fmt.Errorf("no service CA certificate")
+	}
+	client := &http.Client{
+		Timeout: catalogTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}
+	base := "https://" + reg.Status.ServiceAddress
+	next := fmt.Sprintf("/v2/_catalog?n=%d", catalogPageSize)
+	count := 0
+	for len(next) > 0 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+next, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.SetBasicAuth(creds.current.Username, creds.current.Password)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		var catalog struct {
+			Repositories []string `json:"repositories"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&catalog)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("the catalog request failed with %s", resp.Status)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode the catalog: %v", err)
+		}
+		count += len(catalog.Repositories)
+		next = ""
+		if m := reNextLink.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			next = m[1]
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,124 @@
+package sdiregistry
+
+import (
+	"context"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/argocd"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+)
+
+// manageRoute exposes the registry through a reencrypt route. The router verifies the serving certificate of
+// the registry against the service CA which is copied to the route whenever the service CA is rotated. It
+// returns true if the route is not managed or has been admitted.
+func (r *Reconciler) manageRoute(ctx context.Context, reg *sdiv1alpha1.SDIRegistry, serviceCA string) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	routeapi.SetCondition(&reg.Status.Conditions, reg.Generation, !r.noRouteAPI)
+	spec := reg.Spec.Route
+	if r.noRouteAPI || reExplicitlyUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("registry route is not managed")
+		reg.Status.Hostname = ""
+		return true, nil
+	}
+
+	route := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: componentName}}
+	if reRemoved.MatchString(spec.ManagementState) {
+		reg.Status.Hostname = ""
+		if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if len(serviceCA) == 0 {
+		// the router would refuse the serving certificate of the registry
+		tracer.Info("waiting for the service CA to be injected", "configMap", serviceCAName)
+		return false, nil
+	}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
+		if err := controllerutil.SetControllerReference(reg, route, r.Scheme); err != nil {
+			return err
+		}
+		route.Labels = map[string]string{appLabelKey: componentName}
+		primaryresource.SetManagedBy(route)
+		route.Spec.To = routev1.RouteTargetReference{Kind: "Service", Name: componentName}
+		route.Spec.Port = &routev1.RoutePort{TargetPort: intstr.FromString(registryPortName)}
+		route.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationReencrypt,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			DestinationCACertificate:      serviceCA,
+		}
+		// avoid updating the defaulted fields back and forth
+		argocd.NormalizeRoute(&route.Spec)
+		argocd.Annotate(route)
+		if len(spec.Hostname) > 0 {
+			delete(route.Annotations, "openshift.io/host.generated")
+			route.Spec.Host = spec.Hostname
+		}
+		return nil
+	})
+	// the host of an existing route cannot be changed by a user without the custom-host permission
+	if errors.IsInvalid(err) {
+		tracer.Info("route update has been refused, replacing instead...", "error", err)
+		if err = r.Delete(ctx, route); err == nil || errors.IsNotFound(err) {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed registry route", "operation", op)
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		for _, c := range ingress.Conditions {
+			if c.Type == routev1.RouteAdmitted && c.Status == corev1.ConditionTrue {
+				reg.Status.Hostname = ingress.Host
+				return true, nil
+			}
+		}
+	}
+	reg.Status.Hostname = ""
+	return false, nil
+}
+
+// mapServingSecret enqueues the registries of the namespace of the serving secret. The secret is owned by the
+// service, not by the registry.
+func (r *Reconciler) mapServingSecret(secret client.Object) []reconcile.Request {
+	if secret.GetName() != servingSecretName {
+		return nil
+	}
+	return r.enqueueNamespace(secret.GetNamespace())
+}
+
+// enqueueNamespace returns the requests of all the registries of the namespace.
+func (r *Reconciler) enqueueNamespace(namespace string) []reconcile.Request {
+	regs := &sdiv1alpha1.SDIRegistryList{}
+	if err := r.List(context.Background(), regs, client.InNamespace(namespace)); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(regs.Items))
+	for _, reg := range regs.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name},
+		})
+	}
+	return requests
+}
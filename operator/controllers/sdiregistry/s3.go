@@ -0,0 +1,119 @@
+package sdiregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+)
+
+const (
+	s3CAName   = componentName + "-s3-ca"
+	s3CAKey    = "ca-bundle.crt"
+	s3CAVolume = "s3-ca"
+	s3CAMount  = "/etc/registry/s3-ca"
+	// the keys of the credentials secret, the same as of the secret of an object bucket claim
+	s3AccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	s3SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	// the checksum of the S3 credentials the registry pods run with
+	s3ChecksumAnnotationKey = "di.sap-cop.redhat.com/s3-credentials-checksum"
+)
+
+// manageS3 validates the S3 spec and applies the config map with its CA certificates. It returns the checksum of
+// the credentials or a message explaining why the spec is not usable.
+func (r *Reconciler) manageS3(ctx context.Context, reg *sdiv1alpha1.SDIRegistry) (string, string, error) {
+	spec := reg.Spec.Storage.S3
+	switch {
+	case len(spec.Endpoint) == 0:
+		return "", "spec.storage.s3.endpoint must be set", nil
+	case len(spec.Bucket) == 0:
+		return "", "spec.storage.s3.bucket must be set", nil
+	case spec.CredentialsSecretRef == nil:
+		return "", "spec.storage.s3.credentialsSecretRef must be set", nil
+	}
+	ca := strings.TrimSpace(spec.CACertificate)
+	if err := fips.CheckCertificates("S3 CA certificate", ca); err != nil {
+		return "", err.Error(), nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: reg.Namespace, Name: spec.CredentialsSecretRef.Name}, secret)
+	if errors.IsNotFound(err) {
+		return "", fmt.Sprintf("secret %s does not exist", spec.CredentialsSecretRef.Name), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	for _, key := range []string{s3AccessKeyIDKey, s3SecretAccessKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return "", fmt.Sprintf("secret %s has no %s key", secret.Name, key), nil
+		}
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: reg.Namespace, Name: s3CAName}}
+	if len(ca) == 0 {
+		if err := r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			return "", "", err
+		}
+	} else if _, _, err := r.apply(ctx, reg, "S3 CA config map", cm, func() error {
+		cm.Data = map[string]string{s3CAKey: ca + "\n"}
+		return nil
+	}, false); err != nil {
+		return "", "", err
+	}
+	return checksum(append(append([]byte{}, secret.Data[s3AccessKeyIDKey]...),
+		secret.Data[s3SecretAccessKeyKey]...)), "", nil
+}
+
+// getS3Env configures the S3 driver of the registry with the bucket of the spec.
+func getS3Env(spec *sdiv1alpha1.SDIRegistrySpecS3) []corev1.EnvVar {
+	region := spec.Region
+	if len(region) == 0 {
+		region = defaultS3Region
+	}
+	endpoint := strings.TrimSuffix(spec.Endpoint, "/")
+	env := []corev1.EnvVar{
+		{Name: "REGISTRY_STORAGE", Value: "s3"},
+		{Name: "REGISTRY_STORAGE_S3_BUCKET", Value: spec.Bucket},
+		{Name: "REGISTRY_STORAGE_S3_REGIONENDPOINT", Value: endpoint},
+		{Name: "REGISTRY_STORAGE_S3_REGION", Value: region},
+		envFromSecret("REGISTRY_STORAGE_S3_ACCESSKEY", spec.CredentialsSecretRef.Name, s3AccessKeyIDKey),
+		envFromSecret("REGISTRY_STORAGE_S3_SECRETKEY", spec.CredentialsSecretRef.Name, s3SecretAccessKeyKey),
+	}
+	if strings.HasPrefix(endpoint, "http://") {
+		env = append(env, corev1.EnvVar{Name: "REGISTRY_STORAGE_S3_SECURE", Value: "false"})
+	} else if spec.InsecureSkipVerify {
+		env = append(env, corev1.EnvVar{Name: "REGISTRY_STORAGE_S3_SKIPVERIFY", Value: "true"})
+	}
+	if len(strings.TrimSpace(spec.CACertificate)) > 0 {
+		// the system roots are kept
+		env = append(env, corev1.EnvVar{Name: "SSL_CERT_DIR", Value: "/etc/ssl/certs:" + s3CAMount})
+	}
+	return env
+}
+
+// mapS3Secret enqueues the registries of the namespace storing the images in a bucket accessed with the secret.
+func (r *Reconciler) mapS3Secret(secret client.Object) []reconcile.Request {
+	regs := &sdiv1alpha1.SDIRegistryList{}
+	if err := r.List(context.Background(), regs, client.InNamespace(secret.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, reg := range regs.Items {
+		if ref := reg.Spec.Storage.S3.CredentialsSecretRef; ref != nil && ref.Name == secret.GetName() {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: reg.Namespace, Name: reg.Name},
+			})
+		}
+	}
+	return requests
+}
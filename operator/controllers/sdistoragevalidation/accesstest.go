@@ -0,0 +1,224 @@
+package sdistoragevalidation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/images"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/s3"
+)
+
+const (
+	accessTestContainerName = "probe"
+	// the operator binary in the image and its flag running the probe
+	accessTestCommand = "/manager"
+	accessTestFlag    = "--probe-checkpoint-store"
+)
+
+func accessTestJobName(sv *sdiv1alpha1.SDIStorageValidation) string {
+	return sv.Name + "-checkpoint-probe"
+}
+
+// validateCheckpointStoreAccess runs the job testing the permissions on the checkpoint store with the
+// credentials referenced by the spec. The credentials are passed to the job from the secrets and are never read
+// into its spec.
+func (r *Reconciler) validateCheckpointStoreAccess(
+	ctx context.Context,
+	sv *sdiv1alpha1.SDIStorageValidation,
+	timedOut bool,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := sv.Spec.CheckpointStore
+	if spec == nil || spec.AccessTest == nil {
+		setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionTrue,
+			sdiv1alpha1.ConditionReasonNotConfigured, "no checkpoint store access test requested")
+		return nil
+	}
+	if c := meta.FindStatusCondition(sv.Status.Conditions, conditionCheckpointStoreAccessValidated); c != nil &&
+		c.Status != metav1.ConditionUnknown {
+		return nil
+	}
+	if sv.Status.CheckpointStore == nil {
+		sv.Status.CheckpointStore = &sdiv1alpha1.SDIStorageValidationCheckpointStoreStatus{}
+	}
+	sv.Status.CheckpointStore.AccessTestJobName = accessTestJobName(sv)
+	failedStatus := metav1.ConditionUnknown
+	if timedOut {
+		failedStatus = metav1.ConditionFalse
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: sv.Namespace, Name: accessTestJobName(sv)}, job)
+	if errors.IsNotFound(err) {
+		job, err = r.createAccessTestJob(ctx, sv)
+	}
+	if err != nil {
+		setCondition(sv, conditionCheckpointStoreAccessValidated, failedStatus, "FailedCreate",
+			fmt.Sprintf("failed to create the access test job: %v", err))
+		return err
+	}
+	if job.DeletionTimestamp != nil {
+		setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionUnknown,
+			sdiv1alpha1.ConditionReasonValidating, "waiting for the job of the previous round to be deleted")
+		return nil
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue || (c.Type != batchv1.JobComplete && c.Type != batchv1.JobFailed) {
+			continue
+		}
+		res, err := r.getAccessTestResult(ctx, job)
+		if err != nil {
+			setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionFalse, "InvalidProbeResult",
+				fmt.Sprintf("the access test job %s: %v", strings.ToLower(string(c.Type)), err))
+			return nil
+		}
+		setAccessTestResult(sv, res)
+		return nil
+	}
+	if timedOut {
+		setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionFalse,
+			sdiv1alpha1.ConditionReasonTimeout, "the access test job has not finished in time")
+		return nil
+	}
+	setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionUnknown,
+		sdiv1alpha1.ConditionReasonValidating, "waiting for the access test job")
+	return nil
+}
+
+func setAccessTestResult(sv *sdiv1alpha1.SDIStorageValidation, res *s3.ProbeResult) {
+	sv.Status.CheckpointStore.PassedOperations = res.Passed
+	sv.Status.CheckpointStore.Versioning = res.Versioning
+	bucket := sv.Spec.CheckpointStore.Bucket
+	if len(res.Failed) == 0 {
+		versioning := res.Versioning
+		if len(versioning) == 0 {
+			versioning = "never enabled"
+		}
+		setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionTrue,
+			sdiv1alpha1.ConditionReasonAsExpected, fmt.Sprintf("objects can be written, read and deleted in the"+
+				" bucket %q (versioning: %s)", bucket, versioning))
+		return
+	}
+	reason := "AccessTestFailed"
+	switch res.StatusCode {
+	case http.StatusForbidden, http.StatusUnauthorized:
+		reason = sdiv1alpha1.ConditionReasonForbidden
+	case http.StatusNotFound:
+		reason = sdiv1alpha1.ConditionReasonNotFound
+	}
+	setCondition(sv, conditionCheckpointStoreAccessValidated, metav1.ConditionFalse, reason,
+		fmt.Sprintf("the %s operation on the bucket %q failed: %s", res.Failed, bucket, res.Error))
+}
+
+// getAccessTestResult parses the termination message of the most recent finished job pod.
+func (r *Reconciler) getAccessTestResult(ctx context.Context, job *batchv1.Job) (*s3.ProbeResult, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return nil, err
+	}
+	var latest *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			continue
+		}
+		if latest == nil || latest.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			latest = pod
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no finished pod found")
+	}
+	return s3.ParseProbeResult(getTerminationMessage(latest))
+}
+
+func secretKeyRef(ref sdiv1alpha1.SecretKeySelector, defaultKey string) *corev1.EnvVarSource {
+	key := ref.Key
+	if len(key) == 0 {
+		key = defaultKey
+	}
+	return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: ref.Name},
+		Key:                  key,
+	}}
+}
+
+func (r *Reconciler) createAccessTestJob(ctx context.Context, sv *sdiv1alpha1.SDIStorageValidation) (*batchv1.Job, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := sv.Spec.CheckpointStore
+	env := []corev1.EnvVar{
+		{Name: s3.EnvEndpoint, Value: spec.Endpoint},
+		{Name: s3.EnvBucket, Value: spec.Bucket},
+		{Name: s3.EnvRegion, Value: spec.Region},
+		{Name: s3.EnvInsecureSkipTLSVerify, Value: fmt.Sprintf("%t", spec.InsecureSkipTLSVerify)},
+		{Name: s3.EnvAccessKeyID, ValueFrom: secretKeyRef(spec.AccessKeyIDSecretRef, defaultAccessKeyID)},
+		{Name: s3.EnvSecretAccessKey, ValueFrom: secretKeyRef(spec.SecretAccessKeySecretRef, defaultSecretKey)},
+	}
+	// the job reaches the endpoint the same way as the operator
+	proxy, err := clusterproxy.Get(ctx, r.apiReader)
+	if err != nil {
+		tracer.Info("cannot read the cluster proxy, the job relies on the defaults", "error", err)
+	}
+	if proxy != nil {
+		for _, e := range []struct{ name, value string }{
+			{"HTTP_PROXY", proxy.HTTPProxy},
+			{"HTTPS_PROXY", proxy.HTTPSProxy},
+			{"NO_PROXY", proxy.NoProxy},
+		} {
+			if len(e.value) > 0 {
+				env = append(env, corev1.EnvVar{Name: e.name, Value: e.value})
+			}
+		}
+	}
+
+	var backoffLimit int32
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: sv.Namespace,
+			Name:      accessTestJobName(sv),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     accessTestContainerName,
+						Image:                    images.CheckpointProbe.Resolve(spec.AccessTest.Image),
+						Command:                  []string{accessTestCommand, accessTestFlag},
+						Env:                      env,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+					}},
+				},
+			},
+		},
+	}
+	primaryresource.SetManagedBy(job)
+	if err := controllerutil.SetControllerReference(sv, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	tracer.Info("creating checkpoint store access test job", "job", job.Name)
+	return job, r.Create(ctx, job)
+}
@@ -0,0 +1,513 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sdistoragevalidation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/clusterproxy"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/images"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/s3"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+const (
+	defaultTimeout     = time.Minute * 5
+	defaultVolumeSize  = "1Gi"
+	pollInterval       = time.Second * 5
+	probeMountPath     = "/data"
+	defaultAccessKeyID = "AWS_ACCESS_KEY_ID"
+	defaultSecretKey   = "AWS_SECRET_ACCESS_KEY"
+
+	conditionVolumeValidated                = "VolumeValidated"
+	conditionCheckpointStoreValidated       = "CheckpointStoreValidated"
+	conditionCheckpointStoreAccessValidated = "CheckpointStoreAccessValidated"
+)
+
+// probeScript writes and reads a file on the mounted volume and reports the durations in nanoseconds to
+// the termination log so that the controller can pick them up from the pod status.
+const probeScript = `set -eu
+f="` + probeMountPath + `/sdi-storage-probe"
+start="$(date +%s%N)"
+dd if=/dev/urandom of="$f" bs=1M count=16 conv=fsync 2>/dev/null
+written="$(date +%s%N)"
+dd if="$f" of=/dev/null bs=1M 2>/dev/null
+read="$(date +%s%N)"
+rm -f "$f"
+printf 'write=%d read=%d' "$((written - start))" "$((read - written))" >/dev/termination-log
+`
+
+// Reconciler reconciles SDIStorageValidation objects.
+type Reconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	apiReader client.Reader
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme, apiReader client.Reader) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme, apiReader: apiReader}
+}
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdistoragevalidations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdistoragevalidations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdistoragevalidations/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+//+kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get
+// the default storage class is verified by the check subcommand
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list
+
+// Reconcile runs a single validation round for each generation of the SDIStorageValidation spec.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	sv := &sdiv1alpha1.SDIStorageValidation{}
+	if err = r.Get(ctx, req.NamespacedName, sv); err != nil {
+		return rs, client.IgnoreNotFound(err)
+	}
+
+	if sv.Status.ObservedGeneration != sv.Generation {
+		tracer.Info("starting a new validation round", "generation", sv.Generation)
+		now := metav1.Now()
+		sv.Status = sdiv1alpha1.SDIStorageValidationStatus{
+			ObservedGeneration: sv.Generation,
+			StartTime:          &now,
+		}
+		if err = r.deleteTestObjects(ctx, sv); err != nil {
+			return
+		}
+	}
+
+	if isFinished(sv) {
+		if !sv.Spec.KeepTestObjects {
+			err = r.deleteTestObjects(ctx, sv)
+		}
+		return
+	}
+
+	timeout := defaultTimeout
+	if sv.Spec.Timeout != nil && sv.Spec.Timeout.Duration > 0 {
+		timeout = sv.Spec.Timeout.Duration
+	}
+	timedOut := sv.Status.StartTime != nil && time.Since(sv.Status.StartTime.Time) > timeout
+
+	if err = r.validateVolume(ctx, sv, timedOut); err != nil {
+		tracer.Error(err, "failed to validate volume")
+	}
+	var storeErr error
+	if c := meta.FindStatusCondition(sv.Status.Conditions, conditionCheckpointStoreValidated); c == nil ||
+		c.Status == metav1.ConditionUnknown {
+		storeErr = r.validateCheckpointStore(ctx, sv, timedOut)
+	}
+	if err = r.validateCheckpointStoreAccess(ctx, sv, timedOut); err != nil {
+		tracer.Error(err, "failed to validate checkpoint store access")
+	}
+	if err == nil && !timedOut {
+		// the transient failures are retried with the backoff of the rate limiter
+		err = storeErr
+	}
+	setReadyCondition(sv)
+
+	if isFinished(sv) {
+		now := metav1.Now()
+		sv.Status.LastValidationTime = &now
+	} else {
+		rs.RequeueAfter = pollInterval
+	}
+	status := sv.Status.DeepCopy()
+	updErr := updates.Status(ctx, r.Client, sv, func() (bool, error) {
+		sv.Status = *status
+		return true, nil
+	})
+	if updates.IsPermanent(updErr) {
+		// retrying would not help
+		tracer.Error(updErr, "SDIStorageValidation status update has been refused")
+	} else if updErr != nil {
+		tracer.Error(updErr, "failed to update SDIStorageValidation status")
+		return rs, updErr
+	}
+	return rs, err
+}
+
+func isFinished(sv *sdiv1alpha1.SDIStorageValidation) bool {
+	c := meta.FindStatusCondition(sv.Status.Conditions, "Ready")
+	return c != nil && c.Status != metav1.ConditionUnknown && c.ObservedGeneration == sv.Generation
+}
+
+func claimName(sv *sdiv1alpha1.SDIStorageValidation) string {
+	return sv.Name + "-test"
+}
+
+func probePodName(sv *sdiv1alpha1.SDIStorageValidation) string {
+	return sv.Name + "-probe"
+}
+
+func setCondition(sv *sdiv1alpha1.SDIStorageValidation, cType string, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&sv.Status.Conditions, metav1.Condition{
+		Type:               cType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: sv.Generation,
+	})
+}
+
+func (r *Reconciler) validateVolume(ctx context.Context, sv *sdiv1alpha1.SDIStorageValidation, timedOut bool) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := sv.Spec.Volume
+	if spec == nil {
+		setCondition(sv, conditionVolumeValidated, metav1.ConditionTrue, sdiv1alpha1.ConditionReasonNotConfigured,
+			"no volume validation requested")
+		return nil
+	}
+	if c := meta.FindStatusCondition(sv.Status.Conditions, conditionVolumeValidated); c != nil &&
+		c.Status != metav1.ConditionUnknown {
+		return nil
+	}
+	if sv.Status.Volume == nil {
+		sv.Status.Volume = &sdiv1alpha1.SDIStorageValidationVolumeStatus{ClaimName: claimName(sv)}
+	}
+	// transient errors are retried until the timeout
+	failedStatus := metav1.ConditionUnknown
+	if timedOut {
+		failedStatus = metav1.ConditionFalse
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: sv.Namespace, Name: claimName(sv)}, pvc)
+	if errors.IsNotFound(err) {
+		pvc, err = r.createClaim(ctx, sv)
+	}
+	if err != nil {
+		setCondition(sv, conditionVolumeValidated, failedStatus, "FailedCreate",
+			fmt.Sprintf("failed to provision the test volume: %v", err))
+		return err
+	}
+
+	pod := &corev1.Pod{}
+	err = r.Get(ctx, types.NamespacedName{Namespace: sv.Namespace, Name: probePodName(sv)}, pod)
+	if errors.IsNotFound(err) {
+		// the pod is needed to get the claim bound with WaitForFirstConsumer volume binding mode
+		pod, err = r.createProbePod(ctx, sv)
+	}
+	if err != nil {
+		setCondition(sv, conditionVolumeValidated, failedStatus, "FailedCreate",
+			fmt.Sprintf("failed to create the probe pod: %v", err))
+		return err
+	}
+
+	if pvc.DeletionTimestamp != nil || pod.DeletionTimestamp != nil {
+		setCondition(sv, conditionVolumeValidated, metav1.ConditionUnknown, sdiv1alpha1.ConditionReasonValidating,
+			"waiting for the test objects of the previous round to be deleted")
+		return nil
+	}
+
+	if pvc.Status.Phase == corev1.ClaimBound && sv.Status.Volume.BindDuration == nil {
+		sv.Status.Volume.BindDuration = &metav1.Duration{Duration: time.Since(pvc.CreationTimestamp.Time)}
+	}
+
+	switch pod.Status.Phase {
+	case corev1.PodSucceeded:
+		write, read, err := parseProbeResult(pod)
+		if err != nil {
+			setCondition(sv, conditionVolumeValidated, metav1.ConditionFalse, "InvalidProbeResult", err.Error())
+			return nil
+		}
+		sv.Status.Volume.WriteDuration = &metav1.Duration{Duration: write}
+		sv.Status.Volume.ReadDuration = &metav1.Duration{Duration: read}
+		setCondition(sv, conditionVolumeValidated, metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+			fmt.Sprintf("the volume has been bound, written in %s and read in %s", write, read))
+	case corev1.PodFailed:
+		setCondition(sv, conditionVolumeValidated, metav1.ConditionFalse, "ProbeFailed",
+			fmt.Sprintf("the probe pod failed: %s", getTerminationMessage(pod)))
+	default:
+		if timedOut {
+			msg := fmt.Sprintf("the test volume has not been validated in time (claim phase: %s, pod phase: %s)",
+				pvc.Status.Phase, pod.Status.Phase)
+			setCondition(sv, conditionVolumeValidated, metav1.ConditionFalse, sdiv1alpha1.ConditionReasonTimeout, msg)
+			return nil
+		}
+		setCondition(sv, conditionVolumeValidated, metav1.ConditionUnknown, sdiv1alpha1.ConditionReasonValidating,
+			fmt.Sprintf("waiting for the probe (claim phase: %s, pod phase: %s)", pvc.Status.Phase, pod.Status.Phase))
+	}
+	return nil
+}
+
+func (r *Reconciler) createClaim(
+	ctx context.Context,
+	sv *sdiv1alpha1.SDIStorageValidation,
+) (*corev1.PersistentVolumeClaim, error) {
+	spec := sv.Spec.Volume
+	size := resource.MustParse(defaultVolumeSize)
+	if spec.Size != nil {
+		size = *spec.Size
+	}
+	accessMode := spec.AccessMode
+	if len(accessMode) == 0 {
+		accessMode = corev1.ReadWriteMany
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: sv.Namespace,
+			Name:      claimName(sv),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{accessMode},
+			StorageClassName: spec.StorageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	primaryresource.SetManagedBy(pvc)
+	if err := controllerutil.SetControllerReference(sv, pvc, r.Scheme); err != nil {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("creating test volume claim", "claim", pvc.Name)
+	return pvc, r.Create(ctx, pvc)
+}
+
+func (r *Reconciler) createProbePod(ctx context.Context, sv *sdiv1alpha1.SDIStorageValidation) (*corev1.Pod, error) {
+	image := images.StorageProbe.Resolve(sv.Spec.Volume.Image)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: sv.Namespace,
+			Name:      probePodName(sv),
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{{
+				Name:                     "probe",
+				Image:                    image,
+				Command:                  []string{"/bin/sh", "-c", probeScript},
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				VolumeMounts: []corev1.VolumeMount{{
+					Name:      "data",
+					MountPath: probeMountPath,
+				}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName(sv)},
+				},
+			}},
+		},
+	}
+	primaryresource.SetManagedBy(pod)
+	if err := controllerutil.SetControllerReference(sv, pod, r.Scheme); err != nil {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("creating probe pod", "pod", pod.Name)
+	return pod, r.Create(ctx, pod)
+}
+
+func getTerminationMessage(pod *corev1.Pod) string {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Terminated != nil && len(cs.State.Terminated.Message) > 0 {
+			return cs.State.Terminated.Message
+		}
+	}
+	return pod.Status.Message
+}
+
+func parseProbeResult(pod *corev1.Pod) (write, read time.Duration, err error) {
+	var writeNs, readNs int64
+	msg := getTerminationMessage(pod)
+	if _, err = fmt.Sscanf(msg, "write=%d read=%d", &writeNs, &readNs); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse the probe result %q: %v", msg, err)
+	}
+	return time.Duration(writeNs), time.Duration(readNs), nil
+}
+
+// validateCheckpointStore checks that the bucket exists and is accessible. Only a definite answer of the
+// endpoint fails the validation; the unreachable endpoint and the unexpected responses leave it Unknown and are
+// returned as an error to be retried until the timeout.
+func (r *Reconciler) validateCheckpointStore(
+	ctx context.Context,
+	sv *sdiv1alpha1.SDIStorageValidation,
+	timedOut bool,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := sv.Spec.CheckpointStore
+	if spec == nil {
+		setCondition(sv, conditionCheckpointStoreValidated, metav1.ConditionTrue,
+			sdiv1alpha1.ConditionReasonNotConfigured, "no checkpoint store validation requested")
+		return nil
+	}
+	transientStatus := metav1.ConditionUnknown
+	if timedOut {
+		transientStatus = metav1.ConditionFalse
+	}
+
+	creds, err := r.getCredentials(ctx, sv.Namespace, spec)
+	if err != nil {
+		setCondition(sv, conditionCheckpointStoreValidated, transientStatus, "FailedGet",
+			fmt.Sprintf("failed to get the checkpoint store credentials: %v", err))
+		return nil
+	}
+	s3Client, err := s3.NewClient(spec.Endpoint, spec.Region, *creds, spec.InsecureSkipTLSVerify)
+	if err != nil {
+		setCondition(sv, conditionCheckpointStoreValidated, metav1.ConditionFalse, "InvalidEndpoint", err.Error())
+		return nil
+	}
+	proxy, err := clusterproxy.Get(ctx, r.apiReader)
+	if err != nil {
+		tracer.Info("cannot read the cluster proxy, relying on the environment", "error", err)
+	}
+	transport := proxy.Transport(nil)
+	transport.TLSClientConfig.InsecureSkipVerify = spec.InsecureSkipTLSVerify //nolint:gosec
+	s3Client.HTTPClient.Transport = transport
+	resp, err := s3Client.HeadBucket(ctx, spec.Bucket)
+	if err != nil {
+		tracer.Info("checkpoint store is unreachable", "endpoint", spec.Endpoint, "error", err)
+		setCondition(sv, conditionCheckpointStoreValidated, transientStatus,
+			sdiv1alpha1.ConditionReasonUnreachable, fmt.Sprintf("failed to reach the checkpoint store: %v", err))
+		return fmt.Errorf("failed to reach the checkpoint store %s: %w", spec.Endpoint, err)
+	}
+	if sv.Status.CheckpointStore == nil {
+		sv.Status.CheckpointStore = &sdiv1alpha1.SDIStorageValidationCheckpointStoreStatus{}
+	}
+	sv.Status.CheckpointStore.Latency = &metav1.Duration{Duration: resp.Latency}
+	sv.Status.CheckpointStore.HTTPStatusCode = resp.StatusCode
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		setCondition(sv, conditionCheckpointStoreValidated, metav1.ConditionTrue,
+			sdiv1alpha1.ConditionReasonAsExpected, fmt.Sprintf("the bucket %q is accessible (latency: %s)",
+				spec.Bucket, resp.Latency))
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized:
+		// a signature mismatch is refused with 403 as well
+		setCondition(sv, conditionCheckpointStoreValidated, metav1.ConditionFalse,
+			sdiv1alpha1.ConditionReasonForbidden, fmt.Sprintf("access to the bucket %q has been denied", spec.Bucket))
+	case resp.StatusCode == http.StatusNotFound:
+		setCondition(sv, conditionCheckpointStoreValidated, metav1.ConditionFalse,
+			sdiv1alpha1.ConditionReasonNotFound, fmt.Sprintf("the bucket %q does not exist", spec.Bucket))
+	default:
+		err = fmt.Errorf("unexpected response from the checkpoint store: %d", resp.StatusCode)
+		setCondition(sv, conditionCheckpointStoreValidated, transientStatus, "UnexpectedResponse", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (r *Reconciler) getSecretValue(ctx context.Context, namespace string, ref sdiv1alpha1.SecretKeySelector, defaultKey string) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return "", err
+	}
+	key := ref.Key
+	if len(key) == 0 {
+		key = defaultKey
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("failed to find key %q in secret %q", key, ref.Name)
+	}
+	return string(value), nil
+}
+
+func (r *Reconciler) getCredentials(
+	ctx context.Context,
+	namespace string,
+	spec *sdiv1alpha1.SDIStorageValidationSpecCheckpointStore,
+) (*s3.Credentials, error) {
+	keyID, err := r.getSecretValue(ctx, namespace, spec.AccessKeyIDSecretRef, defaultAccessKeyID)
+	if err != nil {
+		return nil, err
+	}
+	secretKey, err := r.getSecretValue(ctx, namespace, spec.SecretAccessKeySecretRef, defaultSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &s3.Credentials{AccessKeyID: keyID, SecretAccessKey: secretKey}, nil
+}
+
+func setReadyCondition(sv *sdiv1alpha1.SDIStorageValidation) {
+	status := metav1.ConditionTrue
+	reason := sdiv1alpha1.ConditionReasonAsExpected
+	msg := "all the storage validations passed"
+	for _, cType := range []string{
+		conditionVolumeValidated,
+		conditionCheckpointStoreValidated,
+		conditionCheckpointStoreAccessValidated,
+	} {
+		c := meta.FindStatusCondition(sv.Status.Conditions, cType)
+		switch {
+		case c == nil || c.Status == metav1.ConditionUnknown:
+			if status == metav1.ConditionTrue {
+				status, reason, msg = metav1.ConditionUnknown, sdiv1alpha1.ConditionReasonValidating,
+					"validation in progress"
+			}
+		case c.Status == metav1.ConditionFalse:
+			if status != metav1.ConditionFalse {
+				status, reason, msg = metav1.ConditionFalse, c.Reason, c.Message
+			}
+		}
+	}
+	setCondition(sv, "Ready", status, reason, msg)
+}
+
+func (r *Reconciler) deleteTestObjects(ctx context.Context, sv *sdiv1alpha1.SDIStorageValidation) error {
+	defer λ.Leave(λ.Enter(log.FromContext(ctx)))
+	for _, obj := range []client.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: sv.Namespace, Name: probePodName(sv)}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: sv.Namespace, Name: claimName(sv)}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: sv.Namespace, Name: accessTestJobName(sv)}},
+	} {
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+			!errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&sdiv1alpha1.SDIStorageValidation{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.Pod{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
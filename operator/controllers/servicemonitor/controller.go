@@ -0,0 +1,199 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicemonitor
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	metricsServiceName = "sdi-observer-metrics"
+	serviceMonitorName = "sdi-observer"
+	// the secure metrics endpoint of the manager authorizing the scraping requests
+	metricsPortName = "https"
+	metricsPort     = 8443
+	// distinguishes the metrics service from the other managed services in the operator namespace, e.g. of a
+	// registry
+	componentLabelKey   = "app.kubernetes.io/component"
+	componentLabelValue = "metrics"
+
+	// the ServiceMonitor API may be installed later; the objects are checked periodically
+	resyncInterval = time.Minute * 10
+)
+
+// podLabels select the operator pod.
+var podLabels = map[string]string{"control-plane": "controller-manager"}
+
+var serviceMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "ServiceMonitor",
+}
+
+// Reconciler maintains the metrics Service of the operator and a ServiceMonitor for the user workload
+// monitoring to scrape it. When disabled, the objects created by the operator are removed.
+type Reconciler struct {
+	client.Client
+	// Enabled selects whether the objects are ensured or cleaned up.
+	Enabled bool
+	// Namespace of the operator.
+	Namespace string
+}
+
+// NewReconciler returns a reconciler using an uncached client.
+func NewReconciler(mgr manager.Manager, enabled bool, namespace string) (*Reconciler, error) {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return &Reconciler{Client: dryrun.Wrap(c), Enabled: enabled, Namespace: namespace}, nil
+}
+
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;patch;delete
+
+func (r *Reconciler) newServiceMonitor() *unstructured.Unstructured {
+	sm := &unstructured.Unstructured{}
+	sm.SetGroupVersionKind(serviceMonitorGVK)
+	sm.SetNamespace(r.Namespace)
+	sm.SetName(serviceMonitorName)
+	return sm
+}
+
+// cleanUp deletes the objects labeled as managed by the operator.
+func (r *Reconciler) cleanUp(ctx context.Context) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	for _, obj := range []client.Object{
+		r.newServiceMonitor(),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: r.Namespace, Name: metricsServiceName}},
+	} {
+		err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if obj.GetLabels()[primaryresource.ManagedByLabelKey] != primaryresource.ManagedByLabelValue {
+			continue
+		}
+		tracer.Info("deleting metrics object", "name", obj.GetName())
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile ensures or removes the metrics Service and the ServiceMonitor.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	if !r.Enabled {
+		return r.cleanUp(ctx)
+	}
+
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: r.Namespace, Name: metricsServiceName}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		primaryresource.SetManagedBy(svc)
+		svc.Labels[componentLabelKey] = componentLabelValue
+		svc.Spec.Selector = podLabels
+		svc.Spec.Ports = []corev1.ServicePort{{
+			Name:       metricsPortName,
+			Port:       metricsPort,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromString(metricsPortName),
+		}}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed metrics service", "name", svc.Name, "operation", op)
+	}
+
+	sm := r.newServiceMonitor()
+	op, err = controllerutil.CreateOrUpdate(ctx, r.Client, sm, func() error {
+		primaryresource.SetManagedBy(sm)
+		return unstructured.SetNestedField(sm.Object, map[string]interface{}{
+			"endpoints": []interface{}{
+				map[string]interface{}{
+					"path":            "/metrics",
+					"port":            metricsPortName,
+					"scheme":          "https",
+					"bearerTokenFile": "/var/run/secrets/kubernetes.io/serviceaccount/token",
+					"tlsConfig":       map[string]interface{}{"insecureSkipVerify": true},
+				},
+			},
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{
+					primaryresource.ManagedByLabelKey: primaryresource.ManagedByLabelValue,
+					componentLabelKey:                 componentLabelValue,
+				},
+			},
+		}, "spec")
+	})
+	if meta.IsNoMatchError(err) {
+		tracer.V(1).Info("ServiceMonitor API is not available, is the Prometheus operator installed?")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed service monitor", "name", sm.GetName(), "operation", op)
+	}
+	return nil
+}
+
+// Start reconciles the objects periodically until the context is done.
+func (r *Reconciler) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("servicemonitor").WithValues(λ.ComponentKey, "servicemonitor")
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.Reconcile(log.IntoContext(ctx, logger)); err != nil {
+			logger.Error(err, "failed to reconcile the metrics service monitor")
+		}
+	}, resyncInterval)
+	return nil
+}
+
+// SetupWithManager adds the reconciler to the manager. It runs only on the leader.
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(r)
+}
@@ -0,0 +1,566 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slcbridge
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/argocd"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/legacy"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+// the management states of the route and the external service
+var (
+	reUnmanaged           = regexp.MustCompile(`^(\s*|(?i)Unmanaged)$`)
+	reExplicitlyUnmanaged = regexp.MustCompile(`^(?i)Unmanaged$`)
+	reRemoved             = regexp.MustCompile(`^(?i)removed?$`)
+)
+
+const (
+	defaultNamespace   = "sap-slcbridge"
+	serviceAccountName = "sap-slcbridge"
+	deploymentName     = "slcbridgebase"
+	serviceName        = "slcbridgebase-service"
+	routeName          = "sap-slcbridge"
+	containerName      = "slcbridgebase"
+	bridgePortName     = "https"
+	bridgePortNumber   = 9000
+	bridgeUIPath       = "/docs/index.html"
+	appLabelKey        = "app"
+	appLabelValue      = "slcbridge"
+
+	routeAnnotationTimeoutKey   = "haproxy.router.openshift.io/timeout"
+	routeAnnotationTimeoutValue = "10m"
+
+	finalizerName = "di.sap-cop.redhat.com/slcbridge-cleanup"
+	kind          = "SLCBridge"
+)
+
+// Reconciler reconciles SLCBridge objects.
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// the cluster does not serve the route API; detected when set up with the manager
+	noRouteAPI bool
+}
+
+func NewReconciler(client client.Client, scheme *runtime.Scheme) *Reconciler {
+	return &Reconciler{Client: client, Scheme: scheme}
+}
+
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=slcbridges,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=slcbridges/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=slcbridges/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=cluster-admin
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes;routes/custom-host,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=config.openshift.io,resources=ingresses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=di.sap-cop.redhat.com,resources=sdimaintenancewindows,verbs=get;list;watch
+
+// Reconcile deploys the SLC Bridge components to the desired namespace and publishes the bridge URL.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (rs ctrl.Result, err error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	bridge := &sdiv1alpha1.SLCBridge{}
+	if err = r.Get(ctx, req.NamespacedName, bridge); err != nil {
+		return rs, client.IgnoreNotFound(err)
+	}
+
+	if bridge.DeletionTimestamp != nil {
+		if !controllerutil.ContainsFinalizer(bridge, finalizerName) {
+			return
+		}
+		if err = r.cleanup(ctx, bridge); err != nil {
+			return
+		}
+		return rs, updates.Object(ctx, r.Client, bridge, func() (bool, error) {
+			if !controllerutil.ContainsFinalizer(bridge, finalizerName) {
+				return false, nil
+			}
+			controllerutil.RemoveFinalizer(bridge, finalizerName)
+			return true, nil
+		})
+	}
+	var finalizerErr error
+	if !controllerutil.ContainsFinalizer(bridge, finalizerName) {
+		finalizerErr = updates.Object(ctx, r.Client, bridge, func() (bool, error) {
+			if controllerutil.ContainsFinalizer(bridge, finalizerName) {
+				return false, nil
+			}
+			controllerutil.AddFinalizer(bridge, finalizerName)
+			return true, nil
+		})
+		if finalizerErr != nil && !updates.IsPermanent(finalizerErr) {
+			return rs, finalizerErr
+		}
+	}
+
+	namespace := getNamespace(bridge)
+	if len(bridge.Status.Namespace) > 0 && bridge.Status.Namespace != namespace {
+		tracer.Info("bridge namespace changed, removing the old deployment", "old", bridge.Status.Namespace,
+			"new", namespace)
+		if err = r.deleteComponents(ctx, bridge, bridge.Status.Namespace); err != nil {
+			return
+		}
+	}
+	bridge.Status.Namespace = namespace
+	bridge.Status.ObservedGeneration = bridge.Generation
+
+	rs.RequeueAfter, err = r.manageComponents(ctx, bridge, namespace)
+	if finalizerErr != nil {
+		updates.SetDegraded(&bridge.Status.Conditions, bridge.Generation, "the finalizer", finalizerErr)
+	}
+	status := bridge.Status.DeepCopy()
+	updErr := updates.Status(ctx, r.Client, bridge, func() (bool, error) {
+		bridge.Status = *status
+		return true, nil
+	})
+	if updates.IsPermanent(updErr) {
+		// retrying would not help
+		tracer.Error(updErr, "SLCBridge status update has been refused")
+	} else if updErr != nil {
+		tracer.Error(updErr, "failed to update SLCBridge status")
+		if err == nil {
+			err = updErr
+		}
+	}
+	return rs, err
+}
+
+func getNamespace(bridge *sdiv1alpha1.SLCBridge) string {
+	if len(bridge.Spec.Namespace) > 0 {
+		return bridge.Spec.Namespace
+	}
+	return defaultNamespace
+}
+
+func clusterRoleBindingName(namespace string) string {
+	return "slcbridge-" + namespace
+}
+
+func setCondition(bridge *sdiv1alpha1.SLCBridge, cType string, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&bridge.Status.Conditions, metav1.Condition{
+		Type:               cType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: bridge.Generation,
+	})
+}
+
+func setFailedConditions(bridge *sdiv1alpha1.SLCBridge, reason string, err error) {
+	setCondition(bridge, "Ready", metav1.ConditionFalse, reason, err.Error())
+	setCondition(bridge, "Degraded", metav1.ConditionTrue, reason, err.Error())
+}
+
+// manageComponents applies the bridge components. Updates of the deployment restart the bridge and are
+// deferred until a maintenance window is open in which case a non-zero requeue delay is returned.
+func (r *Reconciler) manageComponents(
+	ctx context.Context,
+	bridge *sdiv1alpha1.SLCBridge,
+	namespace string,
+) (time.Duration, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	var disabled []string
+	if rbacscope.Namespaced() {
+		// the namespace must be created by the administrator and the bridge be bound to cluster-admin manually
+		disabled = append(disabled, "namespace creation", "cluster-admin binding of the bridge")
+	} else if err := r.ensureNamespace(ctx, bridge, namespace); err != nil {
+		setFailedConditions(bridge, "FailedNamespace", err)
+		return 0, err
+	}
+	rbacscope.SetCondition(&bridge.Status.Conditions, bridge.Generation, disabled)
+
+	labels := map[string]string{appLabelKey: appLabelValue}
+	var pending []string
+	var window maintenance.Result
+	for _, c := range []struct {
+		desc       string
+		obj        client.Object
+		mutate     func(client.Object)
+		disruptive bool
+	}{
+		{"service account", &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: serviceAccountName},
+		}, func(client.Object) {}, false},
+		{"cluster role binding", &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterRoleBindingName(namespace)},
+		}, func(obj client.Object) {
+			crb := obj.(*rbacv1.ClusterRoleBinding)
+			// the bridge installs SAP DI including its CRDs and cluster-scoped RBAC
+			crb.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"}
+			crb.Subjects = []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: namespace,
+				Name:      serviceAccountName,
+			}}
+		}, false},
+		{"deployment", &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: deploymentName},
+		}, func(obj client.Object) { mutateDeployment(obj.(*appsv1.Deployment), bridge, labels) }, true},
+		{"service", &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: serviceName},
+		}, func(obj client.Object) {
+			svc := obj.(*corev1.Service)
+			svc.Labels = labels
+			svc.Spec.Selector = labels
+			svc.Spec.Ports = []corev1.ServicePort{{
+				Name:       bridgePortName,
+				Protocol:   corev1.ProtocolTCP,
+				Port:       bridgePortNumber,
+				TargetPort: intstr.FromString(bridgePortName),
+			}}
+			// leave the families assigned by the cluster alone unless configured
+			if len(bridge.Spec.Service.IPFamilies) > 0 {
+				svc.Spec.IPFamilies = bridge.Spec.Service.IPFamilies
+			}
+			if bridge.Spec.Service.IPFamilyPolicy != nil {
+				svc.Spec.IPFamilyPolicy = bridge.Spec.Service.IPFamilyPolicy
+			}
+		}, false},
+	} {
+		c := c
+		if _, ok := c.obj.(*rbacv1.ClusterRoleBinding); ok && rbacscope.Namespaced() {
+			continue
+		}
+		mutate := func() error {
+			c.mutate(c.obj)
+			primaryresource.Set(c.obj, bridge, kind)
+			return nil
+		}
+		var op controllerutil.OperationResult
+		var err error
+		if c.disruptive {
+			op, window, err = maintenance.CreateOrUpdate(ctx, r.Client, bridge.Namespace, c.obj, mutate, false)
+		} else {
+			op, err = controllerutil.CreateOrUpdate(ctx, r.Client, c.obj, mutate)
+		}
+		if err != nil {
+			tracer.Error(err, "failed to manage "+c.desc)
+			setFailedConditions(bridge, "FailedApply", fmt.Errorf("failed to manage %s: %v", c.desc, err))
+			return 0, err
+		}
+		if op == maintenance.OperationResultDeferred {
+			pending = append(pending, "update of the "+c.desc)
+		}
+		if op != controllerutil.OperationResultNone {
+			tracer.Info("managed "+c.desc, "name", c.obj.GetName(), "operation", op)
+		}
+	}
+	maintenance.SetPendingCondition(&bridge.Status.Conditions, bridge.Generation, len(pending) > 0, window,
+		strings.Join(pending, ", "))
+
+	routeReady, err := r.manageRoute(ctx, bridge, namespace)
+	if err != nil {
+		setFailedConditions(bridge, "FailedRoute", err)
+		return 0, err
+	}
+	if err := r.manageExternalService(ctx, bridge, namespace); err != nil {
+		setFailedConditions(bridge, "FailedExternalService", err)
+		return 0, err
+	}
+
+	deploy := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deploymentName}, deploy); err != nil {
+		setFailedConditions(bridge, "FailedGet", err)
+		return 0, err
+	}
+	setDeploymentConditions(bridge, deploy, routeReady)
+	if len(pending) > 0 {
+		return window.RequeueAfter(), nil
+	}
+	return 0, nil
+}
+
+func (r *Reconciler) ensureNamespace(ctx context.Context, bridge *sdiv1alpha1.SLCBridge, namespace string) error {
+	ns := &corev1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns)
+	if err == nil {
+		if ns.DeletionTimestamp != nil {
+			return fmt.Errorf("namespace %q is being deleted", namespace)
+		}
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	primaryresource.Set(ns, bridge, kind)
+	log.FromContext(ctx).Info("creating slcbridge namespace", "namespace", namespace)
+	return r.Create(ctx, ns)
+}
+
+func mutateDeployment(deploy *appsv1.Deployment, bridge *sdiv1alpha1.SLCBridge, labels map[string]string) {
+	var replicas int32 = 1
+	deploy.Labels = labels
+	deploy.Spec.Replicas = &replicas
+	deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+	// two bridge instances must never run side by side
+	deploy.Spec.Strategy = appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	deploy.Spec.Template.Labels = labels
+	pullPolicy := bridge.Spec.ImagePullPolicy
+	if len(pullPolicy) == 0 {
+		pullPolicy = corev1.PullIfNotPresent
+	}
+	probe := &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(bridgePortName)},
+		},
+		// the defaults are spelled out to avoid needless updates
+		TimeoutSeconds:   1,
+		PeriodSeconds:    10,
+		SuccessThreshold: 1,
+		FailureThreshold: 3,
+	}
+	podSpec := &deploy.Spec.Template.Spec
+	podSpec.ServiceAccountName = serviceAccountName
+	podSpec.ImagePullSecrets = bridge.Spec.ImagePullSecrets
+	if len(podSpec.Containers) != 1 {
+		podSpec.Containers = []corev1.Container{{}}
+	}
+	container := &podSpec.Containers[0]
+	container.Name = containerName
+	container.Image = bridge.Spec.Image
+	container.ImagePullPolicy = pullPolicy
+	container.Ports = []corev1.ContainerPort{{
+		Name:          bridgePortName,
+		ContainerPort: bridgePortNumber,
+		Protocol:      corev1.ProtocolTCP,
+	}}
+	container.ReadinessProbe = probe
+}
+
+// manageRoute exposes the bridge service and returns true if the route is admitted or not desired.
+func (r *Reconciler) manageRoute(ctx context.Context, bridge *sdiv1alpha1.SLCBridge, namespace string) (bool, error) {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	routeapi.SetCondition(&bridge.Status.Conditions, bridge.Generation, !r.noRouteAPI)
+	spec := bridge.Spec.Route
+	if r.noRouteAPI || reExplicitlyUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("slcbridge route is not managed")
+		bridge.Status.URL = ""
+		return true, nil
+	}
+
+	route := &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: routeName}}
+	if reRemoved.MatchString(spec.ManagementState) {
+		bridge.Status.URL = ""
+		if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		return true, nil
+	}
+
+	hostname := spec.Hostname
+	if len(hostname) == 0 {
+		hostname = r.getDefaultHostname(ctx, namespace)
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
+		// the route created by the legacy sdi-observer is updated in place
+		if legacy.Adopt(route) {
+			tracer.Info("adopting the slcbridge route created by the legacy sdi-observer")
+		}
+		route.Labels = map[string]string{appLabelKey: appLabelValue}
+		primaryresource.Set(route, bridge, kind)
+		route.Annotations[routeAnnotationTimeoutKey] = routeAnnotationTimeoutValue
+		route.Spec.To = routev1.RouteTargetReference{Kind: "Service", Name: serviceName}
+		route.Spec.Port = &routev1.RoutePort{TargetPort: intstr.FromString(bridgePortName)}
+		route.Spec.TLS = &routev1.TLSConfig{
+			Termination:                   routev1.TLSTerminationPassthrough,
+			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+		}
+		// avoid updating the defaulted fields back and forth
+		argocd.NormalizeRoute(&route.Spec)
+		argocd.Annotate(route)
+		if len(hostname) > 0 {
+			delete(route.Annotations, "openshift.io/host.generated")
+			route.Spec.Host = hostname
+		}
+		return nil
+	})
+	// the host of an existing route cannot be changed by a user without the custom-host permission
+	if errors.IsInvalid(err) {
+		tracer.Info("route update has been refused, replacing instead...", "error", err)
+		if err = r.Delete(ctx, route); err == nil || errors.IsNotFound(err) {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed slcbridge route", "operation", op)
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		for _, c := range ingress.Conditions {
+			if c.Type == routev1.RouteAdmitted && c.Status == corev1.ConditionTrue {
+				bridge.Status.URL = fmt.Sprintf("https://%s%s", ingress.Host, bridgeUIPath)
+				return true, nil
+			}
+		}
+	}
+	bridge.Status.URL = ""
+	return false, nil
+}
+
+// getDefaultHostname returns <namespace>.<cluster apps domain> or an empty string if the domain cannot be
+// determined, e.g. in the namespace-scoped RBAC mode, in which case the router generates the hostname.
+func (r *Reconciler) getDefaultHostname(ctx context.Context, namespace string) string {
+	if rbacscope.Namespaced() {
+		return ""
+	}
+	ingress := &configv1.Ingress{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "cluster"}, ingress); err != nil {
+		log.FromContext(ctx).Info("failed to determine cluster apps domain, the route hostname will be generated",
+			"error", err)
+		return ""
+	}
+	if len(ingress.Spec.Domain) == 0 {
+		return ""
+	}
+	return strings.Join([]string{namespace, ingress.Spec.Domain}, ".")
+}
+
+func setDeploymentConditions(bridge *sdiv1alpha1.SLCBridge, deploy *appsv1.Deployment, routeReady bool) {
+	rolledOut := deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.UpdatedReplicas == deploy.Status.Replicas
+	if rolledOut {
+		setCondition(bridge, "Progressing", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected,
+			"the bridge deployment is rolled out")
+	} else {
+		setCondition(bridge, "Progressing", metav1.ConditionTrue, "RollingOut",
+			"the bridge deployment is being rolled out")
+	}
+
+	switch {
+	case deploy.Status.AvailableReplicas < 1:
+		setCondition(bridge, "Ready", metav1.ConditionFalse, "Unavailable", "the bridge is not available yet")
+	case !routeReady:
+		setCondition(bridge, "Ready", metav1.ConditionFalse, sdiv1alpha1.ConditionRouteNotAdmitted,
+			"the bridge route has not been admitted yet")
+	default:
+		setCondition(bridge, "Ready", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected,
+			"the bridge is available")
+	}
+	setCondition(bridge, "Degraded", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected,
+		"all the bridge components are deployed")
+}
+
+// cleanup removes the bridge components that cannot be garbage collected using owner references.
+func (r *Reconciler) cleanup(ctx context.Context, bridge *sdiv1alpha1.SLCBridge) error {
+	defer λ.Leave(λ.Enter(log.FromContext(ctx)))
+	namespace := bridge.Status.Namespace
+	if len(namespace) == 0 {
+		namespace = getNamespace(bridge)
+	}
+	return r.deleteComponents(ctx, bridge, namespace)
+}
+
+func (r *Reconciler) deleteComponents(ctx context.Context, bridge *sdiv1alpha1.SLCBridge, namespace string) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	for _, obj := range []client.Object{
+		&routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: routeName}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: externalServiceName}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: serviceName}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: deploymentName}},
+		&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleBindingName(namespace)}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: serviceAccountName}},
+		// the namespace is deleted only if it has been created by the bridge
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+	} {
+		if len(obj.GetNamespace()) == 0 && rbacscope.Namespaced() {
+			continue
+		}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if !primaryresource.IsOwnedBy(obj, bridge, kind) {
+			continue
+		}
+		tracer.Info("deleting slcbridge component", "kind", fmt.Sprintf("%T", obj), "name", obj.GetName())
+		if err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil &&
+			!errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr).For(&sdiv1alpha1.SLCBridge{})
+	watched := []client.Object{
+		&corev1.ServiceAccount{},
+		&corev1.Service{},
+		&appsv1.Deployment{},
+	}
+	if !rbacscope.Namespaced() {
+		watched = append(watched, &corev1.Namespace{}, &rbacv1.ClusterRoleBinding{})
+	}
+	r.noRouteAPI = !routeapi.IsServed(mgr.GetRESTMapper())
+	if r.noRouteAPI {
+		mgr.GetLogger().Info("the route API is not served, the slcbridge routes are not managed")
+	} else {
+		watched = append(watched, &routev1.Route{})
+	}
+	for _, obj := range watched {
+		b = b.Watches(&source.Kind{Type: obj}, primaryresource.EnqueueRequestsForOwner(kind))
+	}
+	return b.Complete(r)
+}
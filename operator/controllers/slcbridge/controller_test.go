@@ -0,0 +1,236 @@
+package slcbridge_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	testroutes "github.com/redhat-sap/sap-data-intelligence/operator/test/routes"
+)
+
+const (
+	timeout  = time.Second * 5
+	interval = time.Millisecond * 100
+
+	bridgeImage = "registry.example.ltd/com.sap.sl.cbpod/slcbridgebase:1.1.72"
+)
+
+// the bridge namespaces cannot be deleted in the test environment, each spec gets a new one
+var namespaceIndex int
+
+func waitForBridge(bridge *sdiv1alpha1.SLCBridge, assert func(g Gomega, bridge *sdiv1alpha1.SLCBridge)) {
+	EventuallyWithOffset(1, func(g Gomega) {
+		g.Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(bridge), bridge)).ToNot(HaveOccurred())
+		assert(g, bridge)
+	}, timeout, interval).Should(Succeed())
+}
+
+func haveCondition(g Gomega, bridge *sdiv1alpha1.SLCBridge, cType string, status metav1.ConditionStatus, reason string) {
+	c := meta.FindStatusCondition(bridge.Status.Conditions, cType)
+	g.Ω(c).WithOffset(1).NotTo(BeNil())
+	g.Ω(c.Status).WithOffset(1).To(Equal(status))
+	g.Ω(c.Reason).WithOffset(1).To(Equal(reason))
+}
+
+func waitForObject(obj client.Object) {
+	EventuallyWithOffset(1, func() error {
+		return k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+	}, timeout, interval).Should(Succeed())
+}
+
+func waitForDeletion(obj client.Object) {
+	EventuallyWithOffset(1, func() bool {
+		err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj)
+		return errors.IsNotFound(err)
+	}, timeout, interval).Should(BeTrue())
+}
+
+var _ = Describe("SLCBridge controller", func() {
+	var bridge *sdiv1alpha1.SLCBridge
+	var namespace string
+	var routeState string
+
+	bridgeRoute := func() *routev1.Route {
+		return &routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "sap-slcbridge"}}
+	}
+	bridgeDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "slcbridgebase"}}
+	}
+
+	BeforeEach(func() {
+		namespaceIndex++
+		namespace = fmt.Sprintf("slcb-%d", namespaceIndex)
+		routeState = sdiv1alpha1.RouteManagementStateManaged
+	})
+
+	JustBeforeEach(func() {
+		bridge = &sdiv1alpha1.SLCBridge{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "sdi-observer",
+				Name:      "bridge",
+			},
+			Spec: sdiv1alpha1.SLCBridgeSpec{
+				Namespace: namespace,
+				Image:     bridgeImage,
+				Route: sdiv1alpha1.SDIObserverSpecRoute{
+					ManagementState: routeState,
+				},
+			},
+		}
+		Ω(k8sClient.Create(context.Background(), bridge)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := k8sClient.Delete(context.Background(), bridge)
+		if !errors.IsNotFound(err) {
+			Ω(err).NotTo(HaveOccurred())
+		}
+		waitForDeletion(bridge)
+	})
+
+	Context("When an SLCBridge is created", func() {
+		It("Should deploy the bridge components", func() {
+			waitForObject(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+			waitForObject(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace, Name: "sap-slcbridge"}})
+			crb := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "slcbridge-" + namespace}}
+			waitForObject(crb)
+			Ω(crb.RoleRef.Name).To(Equal("cluster-admin"))
+
+			deploy := bridgeDeployment()
+			waitForObject(deploy)
+			Ω(deploy.Spec.Template.Spec.Containers).To(HaveLen(1))
+			Ω(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal(bridgeImage))
+			Ω(deploy.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "slcbridgebase-service"}}
+			waitForObject(svc)
+			Ω(svc.Spec.Selector).To(Equal(map[string]string{"app": "slcbridge"}))
+
+			route := bridgeRoute()
+			waitForObject(route)
+			Ω(route.Spec.To.Name).To(Equal("slcbridgebase-service"))
+			Ω(route.Spec.TLS).NotTo(BeNil())
+			Ω(route.Spec.TLS.Termination).To(Equal(routev1.TLSTerminationPassthrough))
+		})
+
+		It("Should report the progress of the bridge", func() {
+			waitForBridge(bridge, func(g Gomega, bridge *sdiv1alpha1.SLCBridge) {
+				g.Ω(bridge.Status.Namespace).To(Equal(namespace))
+				g.Ω(bridge.Status.ObservedGeneration).To(Equal(bridge.Generation))
+				g.Ω(bridge.Status.URL).To(BeEmpty())
+				haveCondition(g, bridge, "Ready", metav1.ConditionFalse, "Unavailable")
+				haveCondition(g, bridge, "Degraded", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected)
+			})
+		})
+
+		It("Should report the URL once the bridge is available and its route admitted", func() {
+			deploy := bridgeDeployment()
+			waitForObject(deploy)
+			Ω(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(deploy), deploy); err != nil {
+					return err
+				}
+				deploy.Status.ObservedGeneration = deploy.Generation
+				deploy.Status.Replicas = 1
+				deploy.Status.UpdatedReplicas = 1
+				deploy.Status.ReadyReplicas = 1
+				deploy.Status.AvailableReplicas = 1
+				return k8sClient.Status().Update(context.TODO(), deploy)
+			})).NotTo(HaveOccurred())
+			route := bridgeRoute()
+			waitForObject(route)
+			Ω(testroutes.AdmitRoute(k8sClient, route)).NotTo(HaveOccurred())
+
+			waitForBridge(bridge, func(g Gomega, bridge *sdiv1alpha1.SLCBridge) {
+				g.Ω(bridge.Status.URL).To(Equal("https://foo.example.ltd/docs/index.html"))
+				haveCondition(g, bridge, "Ready", metav1.ConditionTrue, sdiv1alpha1.ConditionReasonAsExpected)
+				haveCondition(g, bridge, "Progressing", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected)
+			})
+		})
+
+		It("Should remove the owned components once deleted", func() {
+			deploy := bridgeDeployment()
+			waitForObject(deploy)
+			route := bridgeRoute()
+			waitForObject(route)
+
+			Ω(k8sClient.Delete(context.Background(), bridge)).NotTo(HaveOccurred())
+			waitForDeletion(bridge)
+			waitForDeletion(deploy)
+			waitForDeletion(route)
+			waitForDeletion(&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "slcbridge-" + namespace}})
+		})
+	})
+
+	Context("When the route is Unmanaged", func() {
+		BeforeEach(func() {
+			routeState = sdiv1alpha1.RouteManagementStateUnmanaged
+			Ω(k8sClient.Create(context.Background(), &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: namespace},
+			})).NotTo(HaveOccurred())
+			route := bridgeRoute()
+			route.Spec.To = routev1.RouteTargetReference{Kind: "Service", Name: "custom"}
+			Ω(k8sClient.Create(context.Background(), route)).NotTo(HaveOccurred())
+		})
+
+		It("Should leave the existing route alone", func() {
+			waitForBridge(bridge, func(g Gomega, bridge *sdiv1alpha1.SLCBridge) {
+				g.Ω(bridge.Status.ObservedGeneration).To(Equal(bridge.Generation))
+				haveCondition(g, bridge, "Degraded", metav1.ConditionFalse, sdiv1alpha1.ConditionReasonAsExpected)
+			})
+			route := bridgeRoute()
+			Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(route), route)).NotTo(HaveOccurred())
+			Ω(route.Spec.To.Name).To(Equal("custom"))
+			Ω(route.Spec.TLS).To(BeNil())
+			Ω(bridge.Status.URL).To(BeEmpty())
+
+			By("keeping the route once the bridge is deleted")
+			Ω(k8sClient.Delete(context.Background(), bridge)).NotTo(HaveOccurred())
+			waitForDeletion(bridge)
+			Ω(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(route), route)).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When the route is Removed", func() {
+		It("Should delete the route", func() {
+			route := bridgeRoute()
+			waitForObject(route)
+
+			Ω(retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				if err := k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(bridge), bridge); err != nil {
+					return err
+				}
+				bridge.Spec.Route.ManagementState = sdiv1alpha1.RouteManagementStateRemoved
+				return k8sClient.Update(context.TODO(), bridge)
+			})).NotTo(HaveOccurred())
+
+			waitForDeletion(route)
+			waitForBridge(bridge, func(g Gomega, bridge *sdiv1alpha1.SLCBridge) {
+				g.Ω(bridge.Status.ObservedGeneration).To(Equal(bridge.Generation))
+				g.Ω(bridge.Status.URL).To(BeEmpty())
+			})
+			Consistently(func() bool {
+				err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace,
+					Name: "sap-slcbridge"}, &routev1.Route{})
+				return errors.IsNotFound(err)
+			}, time.Second, interval).Should(BeTrue())
+		})
+	})
+})
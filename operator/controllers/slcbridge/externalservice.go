@@ -0,0 +1,153 @@
+package slcbridge
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const externalServiceName = "slcbridgebase-external"
+
+// mutateExternalService copies the selector and ports of the bridge service to the external service. The
+// configured node port is assigned to the bridge port, the node ports of the other ports allocated by the
+// cluster are kept.
+func mutateExternalService(ext, svc *corev1.Service, spec sdiv1alpha1.SLCBridgeSpecExternalService) {
+	svcType := spec.Type
+	if len(svcType) == 0 {
+		svcType = corev1.ServiceTypeNodePort
+	}
+	allocated := make(map[string]int32, len(ext.Spec.Ports))
+	for _, p := range ext.Spec.Ports {
+		allocated[p.Name] = p.NodePort
+	}
+
+	if ext.Annotations == nil {
+		ext.Annotations = make(map[string]string)
+	}
+	for key, value := range spec.Annotations {
+		ext.Annotations[key] = value
+	}
+	ext.Labels = map[string]string{appLabelKey: appLabelValue}
+	ext.Spec.Type = svcType
+	ext.Spec.Selector = svc.Spec.Selector
+	ext.Spec.Ports = make([]corev1.ServicePort, 0, len(svc.Spec.Ports))
+	for i, p := range svc.Spec.Ports {
+		port := corev1.ServicePort{
+			Name:       p.Name,
+			Protocol:   p.Protocol,
+			Port:       p.Port,
+			TargetPort: p.TargetPort,
+			NodePort:   allocated[p.Name],
+		}
+		if spec.NodePort != 0 && (p.Name == bridgePortName || (i == 0 && len(svc.Spec.Ports) == 1)) {
+			port.NodePort = spec.NodePort
+		}
+		ext.Spec.Ports = append(ext.Spec.Ports, port)
+	}
+	if len(svc.Spec.IPFamilies) > 0 {
+		ext.Spec.IPFamilies = svc.Spec.IPFamilies
+	}
+	if svc.Spec.IPFamilyPolicy != nil {
+		ext.Spec.IPFamilyPolicy = svc.Spec.IPFamilyPolicy
+	}
+	if svcType == corev1.ServiceTypeLoadBalancer {
+		ext.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+	} else {
+		ext.Spec.LoadBalancerSourceRanges = nil
+	}
+}
+
+// getExternalServiceStatus returns the address of the bridge port of the external service.
+func getExternalServiceStatus(ext *corev1.Service) *sdiv1alpha1.SDIObserverRouteServiceStatus {
+	status := &sdiv1alpha1.SDIObserverRouteServiceStatus{Name: ext.Name, Type: ext.Spec.Type}
+	for i, p := range ext.Spec.Ports {
+		if p.Name == bridgePortName || i == 0 {
+			status.Port, status.NodePort = p.Port, p.NodePort
+		}
+	}
+	if ext.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range ext.Status.LoadBalancer.Ingress {
+			if len(ingress.Hostname) > 0 {
+				status.Hosts = append(status.Hosts, ingress.Hostname)
+			} else if len(ingress.IP) > 0 {
+				status.Hosts = append(status.Hosts, ingress.IP)
+			}
+		}
+	}
+	return status
+}
+
+// manageExternalService publishes the bridge service with a NodePort or LoadBalancer service so that slcb can
+// reach the bridge without a route.
+func (r *Reconciler) manageExternalService(
+	ctx context.Context,
+	bridge *sdiv1alpha1.SLCBridge,
+	namespace string,
+) error {
+	tracer := λ.Enter(log.FromContext(ctx))
+	defer λ.Leave(tracer)
+
+	spec := bridge.Spec.Service.External
+	if reUnmanaged.MatchString(spec.ManagementState) {
+		tracer.V(2).Info("slcbridge external service is not managed")
+		bridge.Status.ExternalService = nil
+		return nil
+	}
+	ext := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: externalServiceName}}
+	if reRemoved.MatchString(spec.ManagementState) {
+		bridge.Status.ExternalService = nil
+		return r.deleteExternalService(ctx, bridge, ext)
+	}
+
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: serviceName}, svc); err != nil {
+		return fmt.Errorf("failed to get the bridge service: %v", err)
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, ext, func() error {
+		if !ext.CreationTimestamp.IsZero() && !primaryresource.IsOwnedBy(ext, bridge, kind) {
+			return fmt.Errorf("service %s exists and is not managed by this SLCBridge", ext.Name)
+		}
+		mutateExternalService(ext, svc, spec)
+		primaryresource.Set(ext, bridge, kind)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to manage the external service: %v", err)
+	}
+	if op != controllerutil.OperationResultNone {
+		tracer.Info("managed slcbridge external service", "type", ext.Spec.Type, "operation", op)
+	}
+	bridge.Status.ExternalService = getExternalServiceStatus(ext)
+	return nil
+}
+
+func (r *Reconciler) deleteExternalService(
+	ctx context.Context,
+	bridge *sdiv1alpha1.SLCBridge,
+	ext *corev1.Service,
+) error {
+	if err := r.Get(ctx, types.NamespacedName{Namespace: ext.Namespace, Name: ext.Name}, ext); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !primaryresource.IsOwnedBy(ext, bridge, kind) {
+		return nil
+	}
+	log.FromContext(ctx).Info("deleting slcbridge external service", "name", ext.Name)
+	if err := r.Delete(ctx, ext); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -17,33 +17,117 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"embed"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
 
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 	routev1 "github.com/openshift/api/route/v1"
 
 	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/acmeroute"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/imageconfig"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/machineconfig"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/orphans"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/protection"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiimagemirror"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdimaintenancewindow"
 	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiobserver"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiobserver/namespaced"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdiregistry"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/sdistoragevalidation"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/servicemonitor"
+	"github.com/redhat-sap/sap-data-intelligence/operator/controllers/slcbridge"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/cacheselector"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/manifests"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/pprof"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/preflight"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/ratelimit"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/s3"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/securemetrics"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/upgradeable"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/version"
 	//+kubebuilder:scaffold:imports
 )
 
 const (
-	namespaceEnvVar     = "NAMESPACE"
-	sdiNamespaceEnvVar  = "SDI_NAMESPACE"
-	slcbNamespaceEnvVar = "SLCB_NAMESPACE"
+	namespaceEnvVar       = "NAMESPACE"
+	sdiNamespaceEnvVar    = "SDI_NAMESPACE"
+	slcbNamespaceEnvVar   = "SLCB_NAMESPACE"
+	acmeIssuerEnvVar      = "ACME_ISSUER"
+	kernelModulesEnvVar   = "MANAGE_KERNEL_MODULES"
+	sdiNodeRoleEnvVar     = "SDI_NODE_ROLE"
+	serviceMonitorEnvVar  = "MANAGE_SERVICE_MONITOR"
+	logModeEnvVar         = "LOG_MODE"
+	pprofAddrEnvVar       = "PPROF_BIND_ADDRESS"
+	discoveryEnvVar       = "DISCOVER_DATAHUBS"
+	fipsModeEnvVar        = "FIPS_MODE"
+	dryRunEnvVar          = "DRY_RUN"
+	namespacedRBACEnvVar  = "NAMESPACED_RBAC"
+	webhooksEnvVar        = "ENABLE_WEBHOOKS"
+	protectionEnvVar      = "MANAGED_OBJECTS_PROTECTION"
+	webhookCertEnvVar     = "MANAGE_WEBHOOK_CERT"
+	webhookServiceEnvVar  = "WEBHOOK_SERVICE_NAME"
+	orphanGCEnvVar        = "ORPHAN_GC_INTERVAL"
+	secureMetricsEnvVar   = "METRICS_SECURE_BIND_ADDRESS"
+	leaderNamespaceEnvVar = "LEADER_ELECT_NAMESPACE"
+	leaderLockEnvVar      = "LEADER_ELECT_RESOURCE_LOCK"
+
+	defaultAcmeIssuer  = "ClusterIssuer/letsencrypt"
+	defaultSDINodeRole = "sdi"
+	// the name of the webhook service with the prefix of the default kustomization
+	defaultWebhookService = "operator-webhook-service"
+	// the defaults of controller-runtime
+	defaultSyncPeriod         = time.Hour * 10
+	defaultLeaseDuration      = time.Second * 15
+	defaultRenewDeadline      = time.Second * 10
+	defaultRetryPeriod        = time.Second * 2
+	defaultLeaderElectionLock = resourcelock.ConfigMapsLeasesResourceLock
+
+	logModeDevelopment = "development"
+	logModeProduction  = "production"
+
+	terminationLogPath = "/dev/termination-log"
 )
 
 var (
@@ -56,6 +140,8 @@ func init() {
 
 	utilruntime.Must(sdiv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(routev1.AddToScheme(scheme))
+	utilruntime.Must(configv1.AddToScheme(scheme))
+	utilruntime.Must(operatorv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -63,16 +149,429 @@ func mkOverride(varName string) string {
 	return fmt.Sprintf("Overrides %s environment variable.", varName)
 }
 
+func getEnvOrDefault(varName, defaultValue string) string {
+	if value := os.Getenv(varName); len(value) > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// render prints the objects managed for the SDIObserver read from the path to stdout.
+func render(path, namespace string) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	obs := &sdiv1alpha1.SDIObserver{}
+	if err := yaml.UnmarshalStrict(data, obs); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if len(obs.Kind) > 0 && obs.Kind != "SDIObserver" {
+		return fmt.Errorf("expected an SDIObserver, not %s", obs.Kind)
+	}
+	if len(obs.Namespace) == 0 {
+		obs.Namespace = namespace
+	}
+	if len(obs.Namespace) == 0 {
+		return fmt.Errorf("the namespace of the SDIObserver is unknown, please set the NAMESPACE variable")
+	}
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	return namespaced.Render(ctrl.SetupSignalHandler(), cfg, scheme, obs, os.Stdout)
+}
+
+// reconcileOnce reconciles all the SDIObservers once without starting the manager. The events are recorded as
+// by the long-running operator.
+func reconcileOnce() error {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	broadcaster := record.NewBroadcaster()
+	defer broadcaster.Shutdown()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme, corev1.EventSource{Component: "sdi-observer"})
+	return sdiobserver.ReconcileOnce(ctrl.SetupSignalHandler(), cfg, scheme, recorder)
+}
+
+// probeCheckpointStore runs the checkpoint store access test configured by the environment for the job of an
+// SDIStorageValidation. The result is written to the termination log of the pod.
+func probeCheckpointStore() error {
+	c, bucket, err := s3.NewClientFromEnv(os.Getenv)
+	res := &s3.ProbeResult{}
+	if err == nil {
+		res = c.RunProbe(ctrl.SetupSignalHandler(), bucket)
+	} else {
+		res.Error = err.Error()
+	}
+	msg := res.Encode()
+	fmt.Println(msg)
+	if err := os.WriteFile(terminationLogPath, []byte(msg), 0644); err != nil {
+		setupLog.Info("failed to write the termination log", "error", err)
+	}
+	if len(res.Error) > 0 {
+		return fmt.Errorf("%s", res.Error)
+	}
+	return nil
+}
+
+// check runs the preflight checks against the cluster of the current kubeconfig and prints the report. It
+// returns false if any prerequisite is not fulfilled.
+func check(args []string) (bool, error) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	kubeconfig := fs.String("kubeconfig", "", "Path to the kubeconfig. Defaults to the KUBECONFIG variable or "+
+		"the in-cluster configuration.")
+	sdiNamespace := fs.String("sdi-namespace", os.Getenv(sdiNamespaceEnvVar),
+		"The SDI namespace whose DataHub determines the SDI version. "+mkOverride(sdiNamespaceEnvVar))
+	sdiVersion := fs.String("sdi-version", "",
+		"The SDI version to check instead of the installed one, e.g. before the installation.")
+	sdiNodeRole := fs.String("sdi-node-role", getEnvOrDefault(sdiNodeRoleEnvVar, defaultSDINodeRole),
+		"The node role of the SDI compute nodes. "+mkOverride(sdiNodeRoleEnvVar))
+	output := fs.String("output", "text", "The format of the report, text or json.")
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+	if *output != "text" && *output != "json" {
+		return false, fmt.Errorf("invalid output %q, expected text or json", *output)
+	}
+
+	var cfg *rest.Config
+	var err error
+	if len(*kubeconfig) > 0 {
+		cfg, err = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	} else {
+		cfg, err = ctrl.GetConfig()
+	}
+	if err != nil {
+		return false, err
+	}
+	mapper, err := apiutil.NewDynamicRESTMapper(cfg)
+	if err != nil {
+		return false, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme, Mapper: mapper})
+	if err != nil {
+		return false, err
+	}
+	ctx := ctrl.SetupSignalHandler()
+	if len(*sdiVersion) == 0 && len(*sdiNamespace) > 0 {
+		dhc, err := namespaced.NewDHClient(cfg)
+		if err != nil {
+			return false, err
+		}
+		dh, err := dhc.Get(ctx, *sdiNamespace)
+		switch {
+		case err == nil:
+			*sdiVersion = namespaced.GetDataHubVersion(dh)
+		case !errors.IsNotFound(err):
+			return false, fmt.Errorf("failed to get the DataHub: %v", err)
+		}
+	}
+
+	report := preflight.Run(ctx, &preflight.Environment{
+		Reader:      c,
+		Mapper:      mapper,
+		SDIVersion:  *sdiVersion,
+		SDINodeRole: *sdiNodeRole,
+	})
+	if *output == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	return report.Passed, err
+}
+
+// printVersion prints the build of the operator and the releases it supports.
+func printVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	output := fs.String("output", "text", "The format of the version, text or json.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch *output {
+	case "text":
+		return version.Get().WriteText(os.Stdout)
+	case "json":
+		return version.Get().WriteJSON(os.Stdout)
+	}
+	return fmt.Errorf("invalid output %q, expected text or json", *output)
+}
+
+// doctor diagnoses the exposure of vsystem of the SDI namespace and prints the first broken link. It returns
+// false if any link is broken.
+func doctor(args []string) (bool, error) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	kubeconfig := fs.String("kubeconfig", "", "Path to the kubeconfig. Defaults to the KUBECONFIG variable or "+
+		"the in-cluster configuration.")
+	sdiNamespace := fs.String("sdi-namespace", os.Getenv(sdiNamespaceEnvVar),
+		"The SDI namespace exposing vsystem. "+mkOverride(sdiNamespaceEnvVar))
+	output := fs.String("output", "text", "The format of the report, text or json.")
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+	if *output != "text" && *output != "json" {
+		return false, fmt.Errorf("invalid output %q, expected text or json", *output)
+	}
+	if len(*sdiNamespace) == 0 {
+		return false, fmt.Errorf("the SDI namespace is unknown, please set --sdi-namespace")
+	}
+
+	var cfg *rest.Config
+	var err error
+	if len(*kubeconfig) > 0 {
+		cfg, err = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	} else {
+		cfg, err = ctrl.GetConfig()
+	}
+	if err != nil {
+		return false, err
+	}
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return false, err
+	}
+	report := namespaced.Doctor(ctrl.SetupSignalHandler(), c, *sdiNamespace)
+	if *output == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	return report.Broken == nil, err
+}
+
+// diff prints the changes the operator would make to the objects managed for the SDIObserver. It returns true
+// if there are any.
+func diff(args []string) (bool, error) {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	kubeconfig := fs.String("kubeconfig", "", "Path to the kubeconfig. Defaults to the KUBECONFIG variable or "+
+		"the in-cluster configuration.")
+	namespace := fs.String("namespace", os.Getenv(namespaceEnvVar),
+		"The namespace of the SDIObserver. "+mkOverride(namespaceEnvVar))
+	output := fs.String("output", "text", "The format of the differences, text or json.")
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+	if fs.NArg() != 1 {
+		return false, fmt.Errorf("expected the name of the SDIObserver")
+	}
+	if *output != "text" && *output != "json" {
+		return false, fmt.Errorf("invalid output %q, expected text or json", *output)
+	}
+	if len(*namespace) == 0 {
+		return false, fmt.Errorf("the namespace of the SDIObserver is unknown, please set --namespace")
+	}
+
+	var cfg *rest.Config
+	var err error
+	if len(*kubeconfig) > 0 {
+		cfg, err = clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	} else {
+		cfg, err = ctrl.GetConfig()
+	}
+	if err != nil {
+		return false, err
+	}
+	diffs, err := namespaced.Diff(ctrl.SetupSignalHandler(), cfg, scheme,
+		types.NamespacedName{Namespace: *namespace, Name: fs.Arg(0)})
+	if err != nil {
+		return false, err
+	}
+	if *output == "json" {
+		err = namespaced.WriteDiffJSON(os.Stdout, diffs)
+	} else {
+		err = namespaced.WriteDiffText(os.Stdout, diffs)
+	}
+	return len(diffs) > 0, err
+}
+
+// the manifests rendered by the generate subcommand
+//
+//go:embed config/crd/bases/*.yaml config/rbac/*.yaml config/manager/manager.yaml
+var configFS embed.FS
+
+// generate writes the kustomize base of the operator installation to the output directory.
+func generate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	opts := manifests.Options{}
+	outputDir := fs.String("output-dir", "sdi-operator", "The directory to write the kustomize base to.")
+	fs.StringVar(&opts.Namespace, "namespace", "sdi-operator", "The namespace of the operator and the SDIObserver.")
+	fs.StringVar(&opts.SDINamespace, "sdi-namespace", "sdi", "The namespace of SAP Data Intelligence.")
+	fs.StringVar(&opts.SLCBNamespace, "slcb-namespace", "sap-slcbridge", "The namespace of the SLC Bridge.")
+	fs.StringVar(&opts.Image, "image", "quay.io/miminar/sdi-operator:latest", "The image of the operator.")
+	fs.StringVar(&opts.SDINodeRole, "sdi-node-role", defaultSDINodeRole, "The node role of the SDI compute nodes.")
+	fs.BoolVar(&opts.ManageKernelModules, "manage-kernel-modules", false,
+		"Render the MachineConfig loading the kernel modules on the SDI nodes.")
+	fs.BoolVar(&opts.ManageServiceMonitor, "manage-service-monitor", true,
+		"Create the metrics service and a ServiceMonitor for the user workload monitoring.")
+	fs.BoolVar(&opts.NamespacedRBAC, "namespaced-rbac", false,
+		"Grant the operator Roles in the operator, SDI and SLCB namespaces instead of the ClusterRole.")
+	fs.StringVar(&opts.ObserverName, "observer-name", "",
+		`The name of the SDIObserver. Defaults to sdiobserver-<sdi-namespace>. Set to "-" to leave it out.`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files, err := manifests.Generate(configFS, opts)
+	if err != nil {
+		return err
+	}
+	return manifests.Write(*outputDir, files)
+}
+
+var leaderElectionLocks = []string{
+	resourcelock.LeasesResourceLock,
+	resourcelock.ConfigMapsLeasesResourceLock,
+	resourcelock.EndpointsLeasesResourceLock,
+	resourcelock.ConfigMapsResourceLock,
+	resourcelock.EndpointsResourceLock,
+}
+
+// validateLeaderElection applies the constraints of the client-go leader elector early.
+func validateLeaderElection(
+	leaseDuration, renewDeadline, retryPeriod time.Duration,
+	lock, namespace string,
+) error {
+	if errs := validation.IsDNS1123Label(namespace); len(namespace) > 0 && len(errs) > 0 {
+		return fmt.Errorf("invalid leader election namespace %q: %s", namespace, strings.Join(errs, ", "))
+	}
+	switch {
+	case retryPeriod <= 0:
+		return fmt.Errorf("the retry period must be positive, not %s", retryPeriod)
+	case leaseDuration <= renewDeadline:
+		return fmt.Errorf("the lease duration (%s) must be longer than the renew deadline (%s)", leaseDuration,
+			renewDeadline)
+	case renewDeadline <= time.Duration(leaderelection.JitterFactor*float64(retryPeriod)):
+		return fmt.Errorf("the renew deadline (%s) must be longer than %g times the retry period (%s)",
+			renewDeadline, leaderelection.JitterFactor, retryPeriod)
+	}
+	for _, l := range leaderElectionLocks {
+		if l == lock {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown resource lock %q, expected one of %s", lock, strings.Join(leaderElectionLocks, ", "))
+}
+
 func main() {
-	var metricsAddr string
+	if len(os.Args) > 1 && os.Args[1] == "version" {
+		if err := printVersion(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "version failed: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		passed, err := check(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "check failed: %v\n", err)
+			os.Exit(2)
+		}
+		if !passed {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		healthy, err := doctor(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "doctor failed: %v\n", err)
+			os.Exit(2)
+		}
+		if !healthy {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		// the exit codes of diff(1)
+		differ, err := diff(os.Args[2:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "diff failed: %v\n", err)
+			os.Exit(2)
+		}
+		if differ {
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := generate(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "generate failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var metricsAddr, secureMetricsAddr, metricsCertDir string
 	var enableLeaderElection bool
 	var probeAddr string
 	var namespace, sdiNamespace, slcbNamespace string
-	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	var acmeIssuer string
+	var manageKernelModules bool
+	var sdiNodeRole string
+	var manageServiceMonitor bool
+	var discoverDataHubs bool
+	var renderPath string
+	var once bool
+	var runCheckpointProbe bool
+	var fipsMode bool
+	var dryRun bool
+	var namespacedRBAC bool
+	var enableWebhooks bool
+	var protectionMode string
+	var manageWebhookCert bool
+	var webhookService string
+	var orphanGCInterval time.Duration
+	var logMode string
+	var pprofAddr string
+	rateLimiter := ratelimit.DefaultOptions()
+	var syncPeriod time.Duration
+	var leaseDuration, renewDeadline, retryPeriod time.Duration
+	var leaderElectionNamespace, leaderElectionLock string
+	syncTimes := namespaced.DefaultSyncTimes()
+	var maxConcurrentReconciles, namespacedMaxConcurrentReconciles int
+	cacheSelectors := cacheselector.New()
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080",
+		"The address the metric endpoint binds to. Set to 0 to serve the metrics only on the secure endpoint.")
+	flag.StringVar(&secureMetricsAddr, "metrics-secure-bind-address", os.Getenv(secureMetricsEnvVar),
+		"The address the TLS metrics endpoint binds to, e.g. :8443. The requests must carry a bearer token of a user"+
+			" allowed to get the /metrics non-resource URL, verified with the token and subject access reviews."+
+			" Disabled unless specified. "+mkOverride(secureMetricsEnvVar))
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", securemetrics.DefaultCertDir,
+		"The directory with the tls.crt and tls.key of the secure metrics endpoint.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", os.Getenv(pprofAddrEnvVar),
+		"The address the net/http/pprof endpoint binds to, e.g. localhost:6060. Disabled unless specified. "+
+			mkOverride(pprofAddrEnvVar))
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", defaultLeaseDuration,
+		"The duration the non-leader candidates wait before forcing to acquire the leadership. Shorter"+
+			" durations speed up the failover at the cost of more API requests.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", defaultRenewDeadline,
+		"The duration the leader retries refreshing the leadership before giving up.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", defaultRetryPeriod,
+		"The duration the candidates wait between the tries of the leadership actions.")
+	flag.StringVar(&leaderElectionNamespace, "leader-elect-namespace", os.Getenv(leaderNamespaceEnvVar),
+		"The namespace of the leader election resource. Defaults to the namespace of the operator pod. "+
+			mkOverride(leaderNamespaceEnvVar))
+	flag.StringVar(&leaderElectionLock, "leader-elect-resource-lock",
+		getEnvOrDefault(leaderLockEnvVar, defaultLeaderElectionLock),
+		"The type of the leader election resource, one of "+strings.Join(leaderElectionLocks, ", ")+". "+
+			mkOverride(leaderLockEnvVar))
 	flag.StringVar(&namespace, "namespace", os.Getenv(namespaceEnvVar),
 		"The k8s namespace where the operator runs. "+mkOverride(namespaceEnvVar))
 	flag.StringVar(&sdiNamespace, "sdi-namespace", os.Getenv(sdiNamespaceEnvVar),
@@ -81,43 +580,390 @@ func main() {
 	flag.StringVar(&slcbNamespace, "slcb-namespace", os.Getenv(slcbNamespaceEnvVar),
 		"K8s namespace where SAP Software Lifecycle Container Bridge runs."+
 			" Unless specified, all namespaces will be watched. "+mkOverride(slcbNamespaceEnvVar))
-	opts := zap.Options{
-		Development: true,
+	flag.StringVar(&acmeIssuer, "acme-issuer", getEnvOrDefault(acmeIssuerEnvVar, defaultAcmeIssuer),
+		"Cert-manager issuer in the form [Kind/]name used for routes annotated with "+
+			acmeroute.AnnotationTLSAcme+" (e.g. the SDI Registry). "+mkOverride(acmeIssuerEnvVar))
+	enableKernelModules, _ := strconv.ParseBool(os.Getenv(kernelModulesEnvVar))
+	flag.BoolVar(&manageKernelModules, "manage-kernel-modules", enableKernelModules,
+		"Manage the MachineConfig loading the kernel modules needed by SAP DI on the SDI nodes. When disabled,"+
+			" the MachineConfig created by the operator is removed. "+mkOverride(kernelModulesEnvVar))
+	flag.StringVar(&sdiNodeRole, "sdi-node-role", getEnvOrDefault(sdiNodeRoleEnvVar, defaultSDINodeRole),
+		"The role of the MachineConfigPool of the SDI nodes. "+mkOverride(sdiNodeRoleEnvVar))
+	enableServiceMonitor, err := strconv.ParseBool(getEnvOrDefault(serviceMonitorEnvVar, "true"))
+	flag.BoolVar(&manageServiceMonitor, "manage-service-monitor", err != nil || enableServiceMonitor,
+		"Manage the metrics Service of the operator and a ServiceMonitor for the user workload monitoring."+
+			" Disable on clusters without the Prometheus operator. "+mkOverride(serviceMonitorEnvVar))
+	enableDiscovery, _ := strconv.ParseBool(os.Getenv(discoveryEnvVar))
+	flag.BoolVar(&discoverDataHubs, "discover-datahubs", enableDiscovery,
+		"Watch the DataHub resources across the cluster and create a copy of each SDIObserver with the discovery"+
+			" Managed for every namespace with a DataHub. Requires the sdi-namespace to be unset. "+
+			mkOverride(discoveryEnvVar))
+	flag.StringVar(&renderPath, "render", "",
+		"Print the objects the operator would create or patch for the SDIObserver read from the file (- for stdin)"+
+			" as YAML and exit. The cluster is only read. The namespace defaults to the namespace argument.")
+	flag.BoolVar(&once, "once", false,
+		"Reconcile every SDIObserver once and exit. The exit code is non-zero if any reconciliation fails or"+
+			" leaves the SDIObserver degraded.")
+	flag.BoolVar(&runCheckpointProbe, "probe-checkpoint-store", false,
+		"Put, get and delete a test object in the bucket configured by the S3_* and AWS_* variables, read its"+
+			" versioning and exit. Run by the access test job of SDIStorageValidation.")
+	enableFIPS, err := strconv.ParseBool(os.Getenv(fipsModeEnvVar))
+	flag.BoolVar(&fipsMode, "fips-mode", (err == nil && enableFIPS) || (err != nil && fips.Detect()),
+		"Request and accept only the certificates and keys of the FIPS approved algorithms and limit the TLS"+
+			" of the outbound connections accordingly. Enabled by default on nodes booted in the FIPS mode and"+
+			" always enforced by the binaries built with the fips tag. "+mkOverride(fipsModeEnvVar))
+	enableNamespacedRBAC, _ := strconv.ParseBool(os.Getenv(namespacedRBACEnvVar))
+	flag.BoolVar(&namespacedRBAC, "namespaced-rbac", enableNamespacedRBAC,
+		"Run with the Roles in the operator, SDI and SLCB namespaces only. The features needing cluster-scoped"+
+			" permissions are disabled and reported in the ClusterScopeAvailable conditions. Requires sdi-namespace"+
+			" and slcb-namespace. "+mkOverride(namespacedRBACEnvVar))
+	enableWebhooksByEnv, _ := strconv.ParseBool(os.Getenv(webhooksEnvVar))
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", enableWebhooksByEnv,
+		"Serve the admission webhooks on port 9443. The serving certificate is read from the default directory of"+
+			" controller-runtime. "+mkOverride(webhooksEnvVar))
+	flag.StringVar(&protectionMode, "managed-objects-protection",
+		getEnvOrDefault(protectionEnvVar, protection.ModeWarn),
+		"Either "+protection.ModeWarn+" to admit the manual changes of the objects managed by the operator with a"+
+			" warning or "+protection.ModeDeny+" to reject them. The objects annotated with "+
+			protection.OverrideAnnotationKey+"=true are not protected. "+mkOverride(protectionEnvVar))
+	enableWebhookCert, _ := strconv.ParseBool(os.Getenv(webhookCertEnvVar))
+	flag.BoolVar(&manageWebhookCert, "manage-webhook-cert", enableWebhookCert,
+		"Issue and rotate the serving certificate of the webhooks from a self-signed CA kept in the "+
+			protection.DefaultCertSecretName+" secret and inject the CA bundle into the webhook configurations."+
+			" Use on clusters without the service CA operator. "+mkOverride(webhookCertEnvVar))
+	flag.StringVar(&webhookService, "webhook-service-name",
+		getEnvOrDefault(webhookServiceEnvVar, defaultWebhookService),
+		"The name of the service of the webhooks in the operator namespace. "+mkOverride(webhookServiceEnvVar))
+	defaultOrphanGCInterval := orphans.DefaultInterval
+	if value, err := time.ParseDuration(os.Getenv(orphanGCEnvVar)); err == nil {
+		defaultOrphanGCInterval = value
 	}
+	flag.DurationVar(&orphanGCInterval, "orphan-gc-interval", defaultOrphanGCInterval,
+		"The period of the deletion of the managed objects in other namespaces and the cluster-scoped ones whose"+
+			" owner is gone. Zero disables the collection. "+mkOverride(orphanGCEnvVar))
+	enableDryRun, _ := strconv.ParseBool(os.Getenv(dryRunEnvVar))
+	flag.BoolVar(&dryRun, "dry-run", enableDryRun,
+		"Run the reconciliation without persisting any change. The writes are sent to the API server as dry runs,"+
+			" logged and described in the events of the SDIObserver. Only the status of the operator's resources"+
+			" is updated. "+mkOverride(dryRunEnvVar))
+	flag.StringVar(&logMode, "log-mode", getEnvOrDefault(logModeEnvVar, logModeDevelopment),
+		"Either "+logModeDevelopment+" for human readable logs with debug messages or "+logModeProduction+
+			" for JSON logs suitable for the cluster log forwarding. The zap flags take precedence. "+
+			mkOverride(logModeEnvVar))
+	flag.DurationVar(&rateLimiter.BaseDelay, "reconcile-base-delay", ratelimit.DefaultBaseDelay,
+		"The delay of the first retry of a failed SDIObserver reconciliation. It doubles with each failure.")
+	flag.DurationVar(&rateLimiter.MaxDelay, "reconcile-max-delay", ratelimit.DefaultMaxDelay,
+		"The longest delay of the retries of a failed SDIObserver reconciliation.")
+	flag.IntVar(&rateLimiter.MaxRetries, "reconcile-max-retries", 0,
+		"The number of the retries of a failing SDIObserver reconciliation with the growing delay. Further"+
+			" retries wait for the max delay. Unlimited if 0.")
+	flag.Float64Var(&rateLimiter.QPS, "reconcile-qps", ratelimit.DefaultQPS,
+		"The overall rate of the SDIObserver reconciliations per second of each controller.")
+	flag.IntVar(&rateLimiter.Burst, "reconcile-burst", ratelimit.DefaultBurst,
+		"The burst of the SDIObserver reconciliations of each controller.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of the SDIObserver resources reconciled concurrently by the parent controller.")
+	flag.IntVar(&namespacedMaxConcurrentReconciles, "namespaced-max-concurrent-reconciles", 1,
+		"The number of the concurrent reconciliations of each controller managing an SDI namespace. The"+
+			" spec.maxConcurrentReconciles of the SDIObserver takes precedence.")
+	flag.DurationVar(&syncPeriod, "sync-period", defaultSyncPeriod,
+		"The resync period of the objects cached by the manager.")
+	flag.DurationVar(&syncTimes.DataHub, "datahub-sync-period", namespaced.DefaultDHSyncTime,
+		"The resync period of the watched DataHub resources.")
+	flag.DurationVar(&syncTimes.Route, "route-sync-period", namespaced.DefaultRouteSyncTime,
+		"The resync period of the routes watched in the SDI namespaces.")
+	flag.DurationVar(&syncTimes.Core, "core-sync-period", namespaced.DefaultCoreSyncTime,
+		"The resync period of the services, secrets, workloads and role bindings watched in the SDI namespaces.")
+	flag.Var(cacheSelectors.LabelFlag(), "cache-label-selector",
+		"Cache only the objects of the kind matching the label selector, e.g. Service:datahub.sap.com/app=vsystem."+
+			" Repeatable for the kinds "+strings.Join(cacheselector.Kinds(), ", ")+". The filtered out objects"+
+			" are invisible to the operator, so the selectors must match all the objects the enabled features read.")
+	flag.Var(cacheSelectors.FieldFlag(), "cache-field-selector",
+		"Cache only the objects of the kind matching the field selector, e.g. Secret:metadata.name=ca-bundle.pem."+
+			" Repeatable like --cache-label-selector.")
+	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	switch logMode {
+	case logModeDevelopment:
+		opts.Development = true
+	case logModeProduction:
+		opts.Development = false
+	default:
+		fmt.Fprintf(os.Stderr, "invalid log-mode %q, expected %s or %s\n", logMode, logModeDevelopment,
+			logModeProduction)
+		os.Exit(1)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "zap-devel" {
+			opts.Development = f.Value.(flag.Getter).Get().(bool)
+		}
+	})
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	fips.SetEnforced(fipsMode)
+	if fips.Enforced() {
+		setupLog.Info("running in the FIPS mode")
+	}
+	dryrun.SetEnabled(dryRun)
+	if dryrun.Enabled() {
+		setupLog.Info("running in the dry-run mode, no change will be persisted")
+	}
+	rbacscope.SetNamespaced(namespacedRBAC)
+
+	if runCheckpointProbe {
+		if err := probeCheckpointStore(); err != nil {
+			setupLog.Error(err, "checkpoint store access test failed")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(renderPath) > 0 {
+		if err := render(renderPath, namespace); err != nil {
+			setupLog.Error(err, "failed to render the managed objects")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if once {
+		if err := reconcileOnce(); err != nil {
+			setupLog.Error(err, "one-shot reconciliation failed")
+			os.Exit(1)
+		}
+		return
+	}
 
 	if len(namespace) == 0 {
 		setupLog.Error(fmt.Errorf("missing namespace argument, please set at least the NAMESPACE variable"), "fatal")
 		os.Exit(1)
 	}
 
+	if discoverDataHubs && len(sdiNamespace) > 0 {
+		setupLog.Error(fmt.Errorf("the discovery needs to watch all namespaces"),
+			"discover-datahubs cannot be combined with sdi-namespace")
+		os.Exit(1)
+	}
+
+	if namespacedRBAC && (len(sdiNamespace) == 0 || len(slcbNamespace) == 0) {
+		setupLog.Error(fmt.Errorf("the Roles are granted only in the operator, SDI and SLCB namespaces"),
+			"namespaced-rbac requires sdi-namespace and slcb-namespace")
+		os.Exit(1)
+	}
+	if namespacedRBAC {
+		setupLog.Info("running with the namespace-scoped RBAC, the cluster-scoped features are disabled")
+	}
+
+	defaultIssuer, err := acmeroute.ParseIssuer(acmeIssuer)
+	if err != nil {
+		setupLog.Error(err, "invalid acme-issuer argument")
+		os.Exit(1)
+	}
+	if err := protection.ValidateMode(protectionMode); err != nil {
+		setupLog.Error(err, "invalid managed-objects-protection argument")
+		os.Exit(1)
+	}
+	if err := rateLimiter.Validate(); err != nil {
+		setupLog.Error(err, "invalid rate limiter arguments")
+		os.Exit(1)
+	}
+	if err := validateLeaderElection(leaseDuration, renewDeadline, retryPeriod, leaderElectionLock,
+		leaderElectionNamespace); err != nil {
+		setupLog.Error(err, "invalid leader election arguments")
+		os.Exit(1)
+	}
+	if maxConcurrentReconciles < 1 || namespacedMaxConcurrentReconciles < 1 {
+		setupLog.Error(fmt.Errorf("the concurrency must be at least 1"), "invalid max-concurrent-reconciles argument")
+		os.Exit(1)
+	}
+	if syncPeriod < namespaced.MinSyncTime {
+		setupLog.Error(fmt.Errorf("the sync period %s is shorter than %s", syncPeriod, namespaced.MinSyncTime),
+			"invalid sync-period argument")
+		os.Exit(1)
+	}
+	if err := syncTimes.Validate(); err != nil {
+		setupLog.Error(err, "invalid resync period arguments")
+		os.Exit(1)
+	}
+
 	var mgrCache cache.NewCacheFunc
-	if len(sdiNamespace) == 0 || len(slcbNamespace) == 0 {
+	if len(sdiNamespace) == 0 || len(slcbNamespace) == 0 || namespacedRBAC {
 		mgrCache = cache.MultiNamespacedCacheBuilder([]string{namespace, sdiNamespace, slcbNamespace})
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "225c8f26.sap-cop.redhat.com",
-		NewCache:               mgrCache,
+	var certDir string
+	if manageWebhookCert {
+		// the default directory is the mount point of the secret of the service CA operator
+		certDir = filepath.Join(os.TempDir(), "sdi-observer-webhook", "serving-certs")
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:                     scheme,
+		MetricsBindAddress:         metricsAddr,
+		Port:                       9443,
+		CertDir:                    certDir,
+		HealthProbeBindAddress:     probeAddr,
+		LeaderElection:             enableLeaderElection,
+		LeaderElectionID:           "225c8f26.sap-cop.redhat.com",
+		LeaderElectionNamespace:    leaderElectionNamespace,
+		LeaderElectionResourceLock: leaderElectionLock,
+		LeaseDuration:              &leaseDuration,
+		RenewDeadline:              &renewDeadline,
+		RetryPeriod:                &retryPeriod,
+		NewCache:                   cacheSelectors.NewCacheFunc(mgrCache),
+		SyncPeriod:                 &syncPeriod,
+		NewClient: func(
+			cache cache.Cache,
+			config *rest.Config,
+			options client.Options,
+			uncachedObjects ...client.Object,
+		) (client.Client, error) {
+			c, err := cluster.DefaultNewClient(cache, config, options, uncachedObjects...)
+			if err != nil {
+				return nil, err
+			}
+			return dryrun.Wrap(c), nil
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	// the OperatorCondition lives in the operator namespace
+	upgradeGate := upgradeable.NewGate(mgr.GetClient(), namespace)
+	if err := mgr.Add(upgradeGate); err != nil {
+		setupLog.Error(err, "unable to set up the upgradeable gate")
+		os.Exit(1)
+	}
+
 	r := sdiobserver.NewReconciler(mgr.GetClient(), mgr.GetScheme(), mgr)
+	r.RateLimiter = &rateLimiter
+	r.SyncTimes = syncTimes
+	r.MaxConcurrentReconciles = maxConcurrentReconciles
+	r.NamespacedMaxConcurrentReconciles = namespacedMaxConcurrentReconciles
+	r.DiscoverDataHubs = discoverDataHubs
+	r.UpgradeGate = upgradeGate
 	if err := r.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SDIObserver")
 		os.Exit(1)
 	}
+	if err := sdistoragevalidation.NewReconciler(mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader()).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SDIStorageValidation")
+		os.Exit(1)
+	}
+	if err := slcbridge.NewReconciler(mgr.GetClient(), mgr.GetScheme()).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SLCBridge")
+		os.Exit(1)
+	}
+	if err := sdiimagemirror.NewReconciler(mgr.GetClient(), mgr.GetScheme()).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SDIImageMirror")
+		os.Exit(1)
+	}
+	if err := sdimaintenancewindow.NewReconciler(mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader()).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SDIMaintenanceWindow")
+		os.Exit(1)
+	}
+	if err := sdiregistry.NewReconciler(mgr.GetClient(), mgr.GetScheme(), sdiNamespace, slcbNamespace).
+		SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SDIRegistry")
+		os.Exit(1)
+	}
+	if !routeapi.IsServed(mgr.GetRESTMapper()) {
+		setupLog.Info("the route API is not served, the route components are disabled")
+	} else if err := acmeroute.NewReconciler(mgr.GetClient(), mgr.GetScheme(), defaultIssuer).
+		SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ACMERoute")
+		os.Exit(1)
+	}
+	if namespacedRBAC {
+		setupLog.Info("the cluster image config and the machine configs are not managed in the namespace-scoped" +
+			" RBAC mode")
+	} else {
+		if err := imageconfig.NewReconciler(mgr.GetClient(), mgr.GetScheme(), mgr.GetAPIReader()).
+			SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ImageConfig")
+			os.Exit(1)
+		}
+		mcr, err := machineconfig.NewReconciler(mgr, manageKernelModules, sdiNodeRole)
+		if err == nil {
+			mcr.UpgradeGate = upgradeGate
+			err = mcr.SetupWithManager(mgr)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "MachineConfig")
+			os.Exit(1)
+		}
+	}
+	if namespacedRBAC || orphanGCInterval <= 0 {
+		setupLog.Info("the orphaned managed objects are not collected")
+	} else if err := (&orphans.Collector{
+		Client:   mgr.GetClient(),
+		Reader:   mgr.GetAPIReader(),
+		Interval: orphanGCInterval,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the orphan collector")
+		os.Exit(1)
+	}
+	smr, err := servicemonitor.NewReconciler(mgr, manageServiceMonitor, namespace)
+	if err == nil {
+		err = smr.SetupWithManager(mgr)
+	}
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ServiceMonitor")
+		os.Exit(1)
+	}
+	if enableWebhooks && manageWebhookCert {
+		c, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create the webhook certificate client")
+			os.Exit(1)
+		}
+		rotator := &protection.CertRotator{
+			Client:      c,
+			Namespace:   namespace,
+			ServiceName: webhookService,
+			SecretName:  protection.DefaultCertSecretName,
+			CertDir:     certDir,
+		}
+		// the webhook server fails to start without the certificate
+		if err := rotator.Ensure(ctrl.LoggerInto(context.Background(), setupLog)); err != nil {
+			setupLog.Error(err, "unable to issue the webhook serving certificate")
+			os.Exit(1)
+		}
+		if err := rotator.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up the webhook certificate rotation")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		mgr.GetWebhookServer().Register(protection.Path, &webhook.Admission{
+			Handler: &protection.Validator{Namespace: namespace, Mode: protectionMode},
+		})
+	}
+	if len(secureMetricsAddr) > 0 {
+		// the reviews must reach the API server even in the dry run
+		reviewClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+		if err == nil {
+			err = (&securemetrics.Server{
+				Addr:    secureMetricsAddr,
+				CertDir: metricsCertDir,
+				Client:  reviewClient,
+			}).SetupWithManager(mgr)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to set up the secure metrics server")
+			os.Exit(1)
+		}
+	}
+	if len(pprofAddr) > 0 {
+		if err := (&pprof.Server{Addr: pprofAddr}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to set up the pprof server")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
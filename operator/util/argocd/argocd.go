@@ -0,0 +1,62 @@
+// Package argocd keeps the objects managed by the operator from being reported as out of sync or pruned by Argo
+// CD when their owners are deployed with GitOps.
+package argocd
+
+import (
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	CompareOptionsAnnotationKey = "argocd.argoproj.io/compare-options"
+	SyncOptionsAnnotationKey    = "argocd.argoproj.io/sync-options"
+
+	// the object is not in git, so it must not make the application out of sync
+	ignoreExtraneous = "IgnoreExtraneous"
+	// the object must survive the sync of the application it got tracked in
+	noPrune = "Prune=false"
+
+	// the weight set by the API server if unspecified
+	defaultRouteWeight = 100
+)
+
+// addOption appends the option to the comma-separated options of the annotation unless present.
+func addOption(annotations map[string]string, key, option string) {
+	var options []string
+	for _, o := range strings.Split(annotations[key], ",") {
+		o = strings.TrimSpace(o)
+		if o == option {
+			return
+		}
+		if len(o) > 0 {
+			options = append(options, o)
+		}
+	}
+	annotations[key] = strings.Join(append(options, option), ",")
+}
+
+// Annotate marks the object as generated by the operator so that Argo CD neither diffs nor prunes it. The
+// options set by the users are kept.
+func Annotate(obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	addOption(annotations, CompareOptionsAnnotationKey, ignoreExtraneous)
+	addOption(annotations, SyncOptionsAnnotationKey, noPrune)
+	obj.SetAnnotations(annotations)
+}
+
+// NormalizeRoute sets the fields of the route spec defaulted by the API server so that the desired and the live
+// routes do not differ.
+func NormalizeRoute(spec *routev1.RouteSpec) {
+	if spec.To.Weight == nil {
+		weight := int32(defaultRouteWeight)
+		spec.To.Weight = &weight
+	}
+	if len(spec.WildcardPolicy) == 0 {
+		spec.WildcardPolicy = routev1.WildcardPolicyNone
+	}
+}
@@ -0,0 +1,162 @@
+// Package cacheselector restricts the objects cached by the manager to the ones matching the label and field
+// selectors given per kind.
+package cacheselector
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the kinds that may be restricted; the operator reads only a few of them in the SDI namespaces
+var kinds = map[string]func() client.Object{
+	"secret":  func() client.Object { return &corev1.Secret{} },
+	"service": func() client.Object { return &corev1.Service{} },
+	"route":   func() client.Object { return &routev1.Route{} },
+}
+
+// Kinds returns the names of the kinds accepted by the selectors.
+func Kinds() []string {
+	var names []string
+	for name := range kinds {
+		names = append(names, strings.Title(name))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Selectors are the label and field selectors keyed by the lowercase kind.
+type Selectors struct {
+	labels map[string]labels.Selector
+	fields map[string]fields.Selector
+}
+
+// New returns empty selectors letting the cache hold all the objects.
+func New() *Selectors {
+	return &Selectors{
+		labels: make(map[string]labels.Selector),
+		fields: make(map[string]fields.Selector),
+	}
+}
+
+// splitKind parses the "<Kind>:<selector>" value of a flag.
+func splitKind(value string) (string, string, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("expected <Kind>:<selector>, not %q", value)
+	}
+	kind := strings.ToLower(strings.TrimSpace(parts[0]))
+	if _, ok := kinds[kind]; !ok {
+		return "", "", fmt.Errorf("unsupported kind %q, expected one of %s", parts[0], strings.Join(Kinds(), ", "))
+	}
+	return kind, parts[1], nil
+}
+
+type labelFlag struct{ s *Selectors }
+
+func (f labelFlag) String() string {
+	if f.s == nil {
+		return ""
+	}
+	var values []string
+	for kind, sel := range f.s.labels {
+		values = append(values, strings.Title(kind)+":"+sel.String())
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+func (f labelFlag) Set(value string) error {
+	kind, selector, err := splitKind(value)
+	if err != nil {
+		return err
+	}
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return fmt.Errorf("invalid label selector %q: %v", selector, err)
+	}
+	f.s.labels[kind] = sel
+	return nil
+}
+
+type fieldFlag struct{ s *Selectors }
+
+func (f fieldFlag) String() string {
+	if f.s == nil {
+		return ""
+	}
+	var values []string
+	for kind, sel := range f.s.fields {
+		values = append(values, strings.Title(kind)+":"+sel.String())
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+func (f fieldFlag) Set(value string) error {
+	kind, selector, err := splitKind(value)
+	if err != nil {
+		return err
+	}
+	sel, err := fields.ParseSelector(selector)
+	if err != nil {
+		return fmt.Errorf("invalid field selector %q: %v", selector, err)
+	}
+	f.s.fields[kind] = sel
+	return nil
+}
+
+// LabelFlag returns a repeatable flag value setting the label selector of a kind, e.g.
+// "Service:datahub.sap.com/app=vsystem".
+func (s *Selectors) LabelFlag() flag.Value { return &labelFlag{s: s} }
+
+// FieldFlag returns a repeatable flag value setting the field selector of a kind, e.g.
+// "Secret:metadata.name=ca-bundle.pem".
+func (s *Selectors) FieldFlag() flag.Value { return &fieldFlag{s: s} }
+
+// IsEmpty is true if no selector is set.
+func (s *Selectors) IsEmpty() bool {
+	return len(s.labels) == 0 && len(s.fields) == 0
+}
+
+// ByObject returns the selectors in the form understood by the cache.
+func (s *Selectors) ByObject() cache.SelectorsByObject {
+	byObject := make(cache.SelectorsByObject)
+	for kind, newObject := range kinds {
+		l, hasLabel := s.labels[kind]
+		f, hasField := s.fields[kind]
+		if !hasLabel && !hasField {
+			continue
+		}
+		// the type of the value is internal to controller-runtime
+		sel := byObject[nil]
+		sel.Label = l
+		sel.Field = f
+		byObject[newObject()] = sel
+	}
+	return byObject
+}
+
+// NewCacheFunc wraps the cache constructor so that the caches it creates apply the selectors. A nil
+// newCache stands for the cluster-wide cache.
+func (s *Selectors) NewCacheFunc(newCache cache.NewCacheFunc) cache.NewCacheFunc {
+	if newCache == nil {
+		newCache = cache.New
+	}
+	if s.IsEmpty() {
+		return newCache
+	}
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		opts.SelectorsByObject = s.ByObject()
+		return newCache(config, opts)
+	}
+}
@@ -0,0 +1,132 @@
+package certmanager
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	routev1 "github.com/openshift/api/route/v1"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+)
+
+const (
+	DefaultIssuerKind  = "Issuer"
+	DefaultIssuerGroup = "cert-manager.io"
+
+	// the key of the CA certificate in the secret produced by cert-manager
+	caCertificateKey = "ca.crt"
+)
+
+// CertificateGVK identifies the cert-manager Certificate kind.
+var CertificateGVK = schema.GroupVersionKind{
+	Group:   "cert-manager.io",
+	Version: "v1",
+	Kind:    "Certificate",
+}
+
+// IssuerRef references a cert-manager Issuer or ClusterIssuer.
+type IssuerRef struct {
+	Name  string
+	Kind  string
+	Group string
+}
+
+// NewCertificate returns an empty Certificate object with the given key.
+func NewCertificate(namespace, name string) *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(CertificateGVK)
+	cert.SetNamespace(namespace)
+	cert.SetName(name)
+	return cert
+}
+
+// SetCertificateSpec sets the desired spec of a Certificate for the given hostname. In the FIPS mode, an RSA key
+// of an approved size is requested and regenerated on each issuance.
+func SetCertificateSpec(cert *unstructured.Unstructured, secretName, hostname string, issuerRef IssuerRef) {
+	if len(issuerRef.Kind) == 0 {
+		issuerRef.Kind = DefaultIssuerKind
+	}
+	if len(issuerRef.Group) == 0 {
+		issuerRef.Group = DefaultIssuerGroup
+	}
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"commonName": hostname,
+		"dnsNames":   []interface{}{hostname},
+		"issuerRef": map[string]interface{}{
+			"name":  issuerRef.Name,
+			"kind":  issuerRef.Kind,
+			"group": issuerRef.Group,
+		},
+	}
+	if fips.Enforced() {
+		spec["privateKey"] = map[string]interface{}{
+			"algorithm":      "RSA",
+			"size":           int64(fips.GeneratedRSAKeySize),
+			"rotationPolicy": "Always",
+		}
+	}
+	cert.Object["spec"] = spec
+}
+
+// ErrNotIssued is returned while cert-manager has not yet issued the certificate.
+type ErrNotIssued struct {
+	Reason string
+}
+
+func (e *ErrNotIssued) Error() string {
+	return e.Reason
+}
+
+// Issued holds the PEM encoded data issued by cert-manager.
+type Issued struct {
+	Certificate   string
+	Key           string
+	CACertificate string
+}
+
+// GetIssued reads the certificate issued by cert-manager from the given secret. ErrNotIssued is returned
+// if the secret does not exist or is not populated yet.
+func GetIssued(ctx context.Context, c client.Reader, namespace, secretName string) (*Issued, error) {
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, secret)
+	if errors.IsNotFound(err) {
+		return nil, &ErrNotIssued{fmt.Sprintf("waiting for cert-manager to issue the certificate into secret %s",
+			secretName)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, &ErrNotIssued{fmt.Sprintf("secret %s does not contain the certificate yet", secretName)}
+	}
+	return &Issued{
+		Certificate:   string(secret.Data[corev1.TLSCertKey]),
+		Key:           string(secret.Data[corev1.TLSPrivateKeyKey]),
+		CACertificate: string(secret.Data[caCertificateKey]),
+	}, nil
+}
+
+// Check verifies in the FIPS mode that the issued certificate chain and key use the approved algorithms. The
+// issuer may be configured to sign with a weak algorithm regardless of the requested key.
+func (i *Issued) Check(secretName string) error {
+	what := fmt.Sprintf("the certificate issued into secret %s", secretName)
+	if err := fips.CheckCertificates(what, i.Certificate+"\n"+i.CACertificate); err != nil {
+		return err
+	}
+	return fips.CheckPrivateKey(what, i.Key)
+}
+
+// ApplyToRoute sets the issued certificate as the serving certificate of the route.
+func (i *Issued) ApplyToRoute(tls *routev1.TLSConfig) {
+	tls.Certificate = i.Certificate
+	tls.Key = i.Key
+	tls.CACertificate = i.CACertificate
+}
@@ -0,0 +1,111 @@
+// Package clusterproxy builds the transports of the operator's own outbound HTTP requests according to the
+// cluster-wide proxy configuration. The requests are sent through the proxy unless matching the no-proxy list
+// and the trusted CA bundle of the proxy is added to the system certificates.
+package clusterproxy
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+const (
+	proxyName = "cluster"
+	// the config map referenced by the proxy as the trusted CA lives in this namespace
+	trustedCANamespace = "openshift-config"
+	trustedCAKey       = "ca-bundle.crt"
+)
+
+// Settings of the cluster-wide proxy. The methods of nil Settings fall back to the proxy environment variables
+// of the operator and the system certificates.
+type Settings struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+	// PEM encoded CA bundle of the proxy
+	TrustedCA []byte
+}
+
+// Get reads the cluster-wide proxy. The status is preferred over the spec because the cluster network operator
+// completes the no-proxy list with the cluster networks and service domains. Empty settings are returned if
+// the cluster has no proxy configuration or if it cannot be read in the namespace-scoped RBAC mode.
+func Get(ctx context.Context, reader client.Reader) (*Settings, error) {
+	proxy := &configv1.Proxy{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: proxyName}, proxy); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) || rbacscope.IsForbidden(err) {
+			return &Settings{}, nil
+		}
+		return nil, fmt.Errorf("failed to get the cluster proxy: %v", err)
+	}
+	settings := &Settings{
+		HTTPProxy:  proxy.Status.HTTPProxy,
+		HTTPSProxy: proxy.Status.HTTPSProxy,
+		NoProxy:    proxy.Status.NoProxy,
+	}
+	if len(proxy.Spec.TrustedCA.Name) > 0 {
+		cm := &corev1.ConfigMap{}
+		key := types.NamespacedName{Namespace: trustedCANamespace, Name: proxy.Spec.TrustedCA.Name}
+		if err := reader.Get(ctx, key, cm); err != nil {
+			return nil, fmt.Errorf("failed to get the trusted CA of the cluster proxy: %v", err)
+		}
+		settings.TrustedCA = []byte(cm.Data[trustedCAKey])
+	}
+	return settings, nil
+}
+
+// configured returns true if the settings define a proxy.
+func (s *Settings) configured() bool {
+	return s != nil && (len(s.HTTPProxy) > 0 || len(s.HTTPSProxy) > 0)
+}
+
+// ProxyFunc returns the proxy to use for the request or nil if the request shall be sent directly.
+func (s *Settings) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if !s.configured() {
+		return http.ProxyFromEnvironment
+	}
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  s.HTTPProxy,
+		HTTPSProxy: s.HTTPSProxy,
+		NoProxy:    s.NoProxy,
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+}
+
+// CertPool returns the system certificates extended with the trusted CA bundle of the proxy.
+func (s *Settings) CertPool() *x509.CertPool {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if s != nil && len(s.TrustedCA) > 0 {
+		pool.AppendCertsFromPEM(s.TrustedCA)
+	}
+	return pool
+}
+
+// Transport returns a transport sending the requests through the proxy. The server certificates are verified
+// against the given pool or, if nil, against the CertPool.
+func (s *Settings) Transport(rootCAs *x509.CertPool) *http.Transport {
+	if rootCAs == nil {
+		rootCAs = s.CertPool()
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = s.ProxyFunc()
+	transport.TLSClientConfig = fips.TLSConfig(rootCAs)
+	return transport
+}
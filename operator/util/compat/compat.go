@@ -0,0 +1,164 @@
+// Package compat knows the OpenShift releases supported by the SAP Data Intelligence releases. The operator
+// patches SDI assuming a supported combination; on any other, the patches may be wrong.
+package compat
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/rbacscope"
+)
+
+const (
+	// ReasonSupported is the reason of VersionSkewDetected=False.
+	ReasonSupported = "Supported"
+	// ReasonUnsupportedOpenShift is the reason of VersionSkewDetected=True.
+	ReasonUnsupportedOpenShift = "UnsupportedOpenShift"
+	// ReasonUnknownVersion is the reason of VersionSkewDetected=Unknown.
+	ReasonUnknownVersion = "UnknownVersion"
+
+	clusterVersionName = "version"
+)
+
+// Range of the OpenShift minor releases, inclusive.
+type Range struct {
+	Min string
+	Max string
+}
+
+// Matrix maps the SDI minor releases to the supported OpenShift releases as documented in the SDI on OCP
+// installation guide (https://access.redhat.com/articles/5100521).
+var Matrix = map[string]Range{
+	"3.0": {Min: "4.4", Max: "4.6"},
+	"3.1": {Min: "4.6", Max: "4.8"},
+	"3.2": {Min: "4.8", Max: "4.10"},
+	"3.3": {Min: "4.10", Max: "4.12"},
+}
+
+// minorVersion returns the major and minor numbers of the version (e.g. 4 and 10 of 4.10.23).
+func minorVersion(version string) (int, int, error) {
+	parts := strings.SplitN(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid version %q", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid version %q", version)
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid version %q", version)
+	}
+	return major, minor, nil
+}
+
+// CompareReleases compares the minor releases of the versions. The result is negative if a is older than b,
+// zero if they belong to the same release and positive otherwise.
+func CompareReleases(a, b string) (int, error) {
+	aMajor, aMinor, err := minorVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bMajor, bMinor, err := minorVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	if aMajor != bMajor {
+		return aMajor - bMajor, nil
+	}
+	return aMinor - bMinor, nil
+}
+
+// SupportedReleases returns the SDI releases of the Matrix from the oldest and the range of the OpenShift
+// releases supported by any of them.
+func SupportedReleases() ([]string, Range) {
+	releases := make([]string, 0, len(Matrix))
+	var openShift Range
+	for release, r := range Matrix {
+		releases = append(releases, release)
+		if c, err := CompareReleases(r.Min, openShift.Min); len(openShift.Min) == 0 || (err == nil && c < 0) {
+			openShift.Min = r.Min
+		}
+		if c, err := CompareReleases(r.Max, openShift.Max); len(openShift.Max) == 0 || (err == nil && c > 0) {
+			openShift.Max = r.Max
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		c, _ := CompareReleases(releases[i], releases[j])
+		return c < 0
+	})
+	return releases, openShift
+}
+
+// Evaluate returns the VersionSkewDetected condition for the versions. The status is Unknown if either
+// version is unknown or the SDI release is missing in the Matrix.
+func Evaluate(openShiftVersion, sdiVersion string) metav1.Condition {
+	unknown := func(msg string) metav1.Condition {
+		return metav1.Condition{Status: metav1.ConditionUnknown, Reason: ReasonUnknownVersion, Message: msg}
+	}
+	if len(openShiftVersion) == 0 {
+		return unknown("the OpenShift version is unknown")
+	}
+	if len(sdiVersion) == 0 {
+		return unknown("the SDI version is unknown")
+	}
+	major, minor, err := minorVersion(sdiVersion)
+	if err != nil {
+		return unknown(err.Error())
+	}
+	release := fmt.Sprintf("%d.%d", major, minor)
+	supported, ok := Matrix[release]
+	if !ok {
+		return unknown(fmt.Sprintf("the supported OpenShift releases of SDI %s are not known", release))
+	}
+	low, err := CompareReleases(openShiftVersion, supported.Min)
+	if err != nil {
+		return unknown(err.Error())
+	}
+	high, err := CompareReleases(openShiftVersion, supported.Max)
+	if err != nil {
+		return unknown(err.Error())
+	}
+	if low < 0 || high > 0 {
+		return metav1.Condition{
+			Status: metav1.ConditionTrue,
+			Reason: ReasonUnsupportedOpenShift,
+			Message: fmt.Sprintf("SDI %s is supported on OpenShift %s to %s, not on %s", sdiVersion,
+				supported.Min, supported.Max, openShiftVersion),
+		}
+	}
+	return metav1.Condition{
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonSupported,
+		Message: fmt.Sprintf("SDI %s is supported on OpenShift %s", sdiVersion, openShiftVersion),
+	}
+}
+
+// GetOpenShiftVersion returns the version the cluster has been last completely updated to. An empty string is
+// returned on a cluster without the ClusterVersion or without the permission to read it.
+func GetOpenShiftVersion(ctx context.Context, reader client.Reader) (string, error) {
+	cv := &configv1.ClusterVersion{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: clusterVersionName}, cv); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) || rbacscope.IsForbidden(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	// the history is ordered from the most recent update
+	for _, update := range cv.Status.History {
+		if update.State == configv1.CompletedUpdate {
+			return update.Version, nil
+		}
+	}
+	return cv.Status.Desired.Version, nil
+}
@@ -0,0 +1,40 @@
+package compat_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/compat"
+)
+
+var _ = Describe("Version compatibility", func() {
+	It("Should accept a supported combination", func() {
+		cond := compat.Evaluate("4.10.23", "3.2.45")
+		Ω(cond.Status).To(Equal(metav1.ConditionFalse))
+		Ω(cond.Reason).To(Equal(compat.ReasonSupported))
+	})
+
+	It("Should compare the minor releases numerically", func() {
+		Ω(compat.Evaluate("4.9.0", "3.3.14").Status).To(Equal(metav1.ConditionTrue))
+		Ω(compat.Evaluate("4.12.1", "3.3.14").Status).To(Equal(metav1.ConditionFalse))
+	})
+
+	It("Should detect a newer OpenShift", func() {
+		cond := compat.Evaluate("4.12.0", "3.1.13")
+		Ω(cond.Status).To(Equal(metav1.ConditionTrue))
+		Ω(cond.Reason).To(Equal(compat.ReasonUnsupportedOpenShift))
+	})
+
+	It("Should not judge unknown releases", func() {
+		Ω(compat.Evaluate("4.10.0", "").Status).To(Equal(metav1.ConditionUnknown))
+		Ω(compat.Evaluate("", "3.2.1").Status).To(Equal(metav1.ConditionUnknown))
+		Ω(compat.Evaluate("4.14.0", "4.0.1").Status).To(Equal(metav1.ConditionUnknown))
+	})
+
+	It("Should list the supported releases in order", func() {
+		releases, openShift := compat.SupportedReleases()
+		Ω(releases).To(Equal([]string{"3.0", "3.1", "3.2", "3.3"}))
+		Ω(openShift).To(Equal(compat.Range{Min: "4.4", Max: "4.12"}))
+	})
+})
@@ -0,0 +1,128 @@
+// Package dryrun turns the writes of the controllers into server-side dry runs. The API server validates and
+// admits the changes as usual but does not persist them, so the operator can be pointed at a production SDI
+// namespace to see what it would change.
+package dryrun
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+// ReasonDryRun is the reason of the events describing the changes not made in the dry-run mode.
+const ReasonDryRun = "DryRun"
+
+var enabled int32
+
+// SetEnabled enables or disables the dry-run mode. It must be called before the clients are wrapped.
+func SetEnabled(enable bool) {
+	var value int32
+	if enable {
+		value = 1
+	}
+	atomic.StoreInt32(&enabled, value)
+}
+
+// Enabled returns true in the dry-run mode.
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+// Wrap returns the client unchanged unless the dry-run mode is enabled. Otherwise, every write of the returned
+// client is logged and sent as a dry run. The status of the resources of the operator is still updated to
+// report the state observed.
+func Wrap(c client.Client) client.Client {
+	if !Enabled() {
+		return c
+	}
+	return &dryRunClient{Client: c}
+}
+
+type dryRunClient struct {
+	client.Client
+}
+
+// describe returns the lowercase kind and the namespaced name of the object.
+func describe(obj client.Object, c client.Client) (string, string) {
+	kind := "object"
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = strings.ToLower(gvk.Kind)
+	}
+	name := obj.GetName()
+	if len(obj.GetNamespace()) > 0 {
+		name = obj.GetNamespace() + "/" + name
+	}
+	return kind, name
+}
+
+func (c *dryRunClient) log(ctx context.Context, verb string, obj client.Object) {
+	kind, name := describe(obj, c.Client)
+	log.FromContext(ctx).Info("dry run: would "+verb, "kind", kind, "name", name)
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.log(ctx, "create", obj)
+	return c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.log(ctx, "update", obj)
+	return c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.log(ctx, "patch", obj)
+	return c.Client.Patch(ctx, obj, patch, append(opts, client.DryRunAll)...)
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.log(ctx, "delete", obj)
+	return c.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.log(ctx, "delete all of", obj)
+	return c.Client.DeleteAllOf(ctx, obj, append(opts, client.DryRunAll)...)
+}
+
+func (c *dryRunClient) Status() client.StatusWriter {
+	return &dryRunStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type dryRunStatusWriter struct {
+	client.StatusWriter
+	c *dryRunClient
+}
+
+// isOwnResource returns true for the resources of the operator's API.
+func (w *dryRunStatusWriter) isOwnResource(obj client.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, w.c.Scheme())
+	return err == nil && gvk.Group == sdiv1alpha1.GroupVersion.Group
+}
+
+func (w *dryRunStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if !w.isOwnResource(obj) {
+		w.c.log(ctx, "update the status of", obj)
+		opts = append(opts, client.DryRunAll)
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *dryRunStatusWriter) Patch(
+	ctx context.Context,
+	obj client.Object,
+	patch client.Patch,
+	opts ...client.PatchOption,
+) error {
+	if !w.isOwnResource(obj) {
+		w.c.log(ctx, "patch the status of", obj)
+		opts = append(opts, client.DryRunAll)
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
@@ -0,0 +1,74 @@
+package dryrun_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/dryrun"
+)
+
+var _ = Describe("Dry-run client", func() {
+	ctx := context.Background()
+	var c client.Client
+	obs := &sdiv1alpha1.SDIObserver{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi-observer", Name: "sdi"}}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "existing"},
+		Data: map[string]string{"key": "original"}}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Ω(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Ω(sdiv1alpha1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(obs.DeepCopy(), cm.DeepCopy()).Build()
+	})
+
+	AfterEach(func() {
+		dryrun.SetEnabled(false)
+	})
+
+	It("Should not wrap the client by default", func() {
+		Ω(dryrun.Wrap(c)).To(BeIdenticalTo(c))
+	})
+
+	It("Should not persist the writes", func() {
+		dryrun.SetEnabled(true)
+		dc := dryrun.Wrap(c)
+
+		created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "sdi", Name: "new"}}
+		Ω(dc.Create(ctx, created)).To(Succeed())
+		Ω(errors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(created), &corev1.ConfigMap{}))).To(BeTrue())
+
+		updated := &corev1.ConfigMap{}
+		Ω(dc.Get(ctx, client.ObjectKeyFromObject(cm), updated)).To(Succeed())
+		patch := client.MergeFrom(updated.DeepCopy())
+		updated.Data["key"] = "changed"
+		Ω(dc.Patch(ctx, updated, patch)).To(Succeed())
+		Ω(dc.Update(ctx, updated)).To(Succeed())
+
+		current := &corev1.ConfigMap{}
+		Ω(c.Get(ctx, client.ObjectKeyFromObject(cm), current)).To(Succeed())
+		Ω(current.Data).To(Equal(map[string]string{"key": "original"}))
+	})
+
+	It("Should update the status of the operator's resources", func() {
+		dryrun.SetEnabled(true)
+		dc := dryrun.Wrap(c)
+
+		current := &sdiv1alpha1.SDIObserver{}
+		Ω(dc.Get(ctx, client.ObjectKeyFromObject(obs), current)).To(Succeed())
+		current.Status.Profile = "legacy"
+		Ω(dc.Status().Update(ctx, current)).To(Succeed())
+
+		Ω(c.Get(ctx, client.ObjectKeyFromObject(obs), current)).To(Succeed())
+		Ω(current.Status.Profile).To(Equal("legacy"))
+	})
+})
@@ -0,0 +1,7 @@
+//go:build !fips
+// +build !fips
+
+package fips
+
+// builtForFIPS forces the enforcement regardless of the runtime detection.
+const builtForFIPS = false
@@ -0,0 +1,191 @@
+// Package fips restricts the cryptographic material handled by the operator to the FIPS 140 approved
+// algorithms. The restrictions apply only in the FIPS mode, which is enabled when the operator is built with the
+// fips tag or runs on a node booted in the FIPS mode, unless overridden by the --fips-mode flag.
+package fips
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// ReasonNonCompliant is the condition reason of the material rejected in the FIPS mode.
+	ReasonNonCompliant = "NonCompliantCertificate"
+
+	// MinRSAKeySize is the smallest RSA modulus accepted in the FIPS mode.
+	MinRSAKeySize = 2048
+	// GeneratedRSAKeySize is the size of the RSA keys requested in the FIPS mode.
+	GeneratedRSAKeySize = 3072
+
+	kernelFIPSPath = "/proc/sys/crypto/fips_enabled"
+)
+
+var enforced int32
+
+// Detect returns true if the operator is built for FIPS or the kernel runs in the FIPS mode.
+func Detect() bool {
+	if builtForFIPS {
+		return true
+	}
+	data, err := os.ReadFile(kernelFIPSPath)
+	return err == nil && strings.TrimSpace(string(data)) == "1"
+}
+
+// SetEnforced enables or disables the FIPS mode. A binary built with the fips tag cannot leave the mode.
+func SetEnforced(enabled bool) {
+	var value int32
+	if enabled || builtForFIPS {
+		value = 1
+	}
+	atomic.StoreInt32(&enforced, value)
+}
+
+// Enforced returns true in the FIPS mode.
+func Enforced() bool {
+	return atomic.LoadInt32(&enforced) == 1
+}
+
+// NonCompliantError describes the material rejected in the FIPS mode.
+type NonCompliantError struct {
+	What   string
+	Reason string
+}
+
+func (e *NonCompliantError) Error() string {
+	return fmt.Sprintf("%s is not FIPS compliant: %s", e.What, e.Reason)
+}
+
+// IsNonCompliant returns true if the error is a NonCompliantError.
+func IsNonCompliant(err error) bool {
+	_, ok := err.(*NonCompliantError)
+	return ok
+}
+
+func checkPublicKey(key interface{}) string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < MinRSAKeySize {
+			return fmt.Sprintf("RSA key of %d bits is shorter than %d bits", k.N.BitLen(), MinRSAKeySize)
+		}
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Sprintf("ECDSA curve %s is not approved", k.Curve.Params().Name)
+		}
+	default:
+		return fmt.Sprintf("public key algorithm %T is not approved", key)
+	}
+	return ""
+}
+
+func isApprovedSignature(algorithm x509.SignatureAlgorithm) bool {
+	switch algorithm {
+	case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS,
+		x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+		return true
+	}
+	return false
+}
+
+// CheckCertificates verifies the keys and signatures of the PEM encoded certificates. The signature of a
+// self-signed certificate is not verified by the peers and hence not checked. The check passes outside of the
+// FIPS mode.
+func CheckCertificates(what, data string) error {
+	if !Enforced() {
+		return nil
+	}
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return &NonCompliantError{What: what, Reason: fmt.Sprintf("failed to parse a certificate: %v", err)}
+		}
+		if reason := checkPublicKey(cert.PublicKey); len(reason) > 0 {
+			return &NonCompliantError{What: what, Reason: fmt.Sprintf("certificate %q: %s", cert.Subject, reason)}
+		}
+		if !bytes.Equal(cert.RawIssuer, cert.RawSubject) && !isApprovedSignature(cert.SignatureAlgorithm) {
+			return &NonCompliantError{What: what, Reason: fmt.Sprintf("certificate %q is signed with %s",
+				cert.Subject, cert.SignatureAlgorithm)}
+		}
+	}
+}
+
+// CheckPrivateKey verifies the algorithm and size of the PEM encoded private key. The check passes outside of
+// the FIPS mode.
+func CheckPrivateKey(what, data string) error {
+	if !Enforced() {
+		return nil
+	}
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return &NonCompliantError{What: what, Reason: "no PEM encoded private key found"}
+	}
+	var public interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return &NonCompliantError{What: what, Reason: fmt.Sprintf("failed to parse the private key: %v", err)}
+		}
+		public = &key.PublicKey
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return &NonCompliantError{What: what, Reason: fmt.Sprintf("failed to parse the private key: %v", err)}
+		}
+		public = &key.PublicKey
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return &NonCompliantError{What: what, Reason: fmt.Sprintf("failed to parse the private key: %v", err)}
+		}
+		if signer, ok := key.(crypto.Signer); ok {
+			public = signer.Public()
+		} else {
+			public = key
+		}
+	default:
+		return &NonCompliantError{What: what, Reason: fmt.Sprintf("unsupported private key type %q", block.Type)}
+	}
+	if reason := checkPublicKey(public); len(reason) > 0 {
+		return &NonCompliantError{What: what, Reason: reason}
+	}
+	return nil
+}
+
+// TLSConfig returns the client TLS configuration trusting the pool. In the FIPS mode, the protocol versions,
+// cipher suites and curves are limited to the approved ones.
+func TLSConfig(rootCAs *x509.CertPool) *tls.Config {
+	config := &tls.Config{RootCAs: rootCAs}
+	if Enforced() {
+		config.MinVersion = tls.VersionTLS12
+		config.CipherSuites = []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		}
+		config.CurvePreferences = []tls.CurveID{tls.CurveP256, tls.CurveP384, tls.CurveP521}
+	}
+	return config
+}
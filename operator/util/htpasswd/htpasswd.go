@@ -0,0 +1,166 @@
+// Package htpasswd generates the credentials and the htpasswd files of the registries deployed by the operator.
+// The docker distribution registry accepts only bcrypt hashes, which are thus generated even in the FIPS mode.
+package htpasswd
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	usernamePrefix = "user-"
+	usernameLength = 6
+	passwordLength = 32
+
+	lowerAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	alphabet      = lowerAlphabet + "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// Credential is a plain text user name and password.
+type Credential struct {
+	Username string
+	Password string
+}
+
+func randomString(alphabet string, length int) (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(alphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// Generate returns a random credential in the form of deploy-registry.sh, e.g. user-62hsyd.
+func Generate() (Credential, error) {
+	user, err := randomString(lowerAlphabet, usernameLength)
+	if err != nil {
+		return Credential{}, fmt.Errorf("failed to generate a user name: %v", err)
+	}
+	password, err := randomString(alphabet, passwordLength)
+	if err != nil {
+		return Credential{}, fmt.Errorf("failed to generate a password: %v", err)
+	}
+	return Credential{Username: usernamePrefix + user, Password: password}, nil
+}
+
+// String returns the credential as <user>:<password>.
+func (c Credential) String() string {
+	return c.Username + ":" + c.Password
+}
+
+// ParseCredential parses the first non-empty line of <user>:<password> lines.
+func ParseCredential(raw string) (Credential, error) {
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return Credential{}, fmt.Errorf("expected <user>:<password>")
+		}
+		return Credential{Username: parts[0], Password: parts[1]}, nil
+	}
+	return Credential{}, fmt.Errorf("no credential found")
+}
+
+// Entry returns the htpasswd line of the credential with a bcrypt hash of the password. bcrypt is not FIPS
+// approved; the callers shall warn about it in the FIPS mode.
+func (c Credential) Entry() (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(c.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return c.Username + ":" + string(hash), nil
+}
+
+// parse returns the hashes of the htpasswd file keyed by the user names.
+func parse(htpasswd string) map[string]string {
+	entries := make(map[string]string)
+	for _, line := range strings.Split(htpasswd, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if parts := strings.SplitN(line, ":", 2); len(parts) == 2 {
+			entries[parts[0]] = parts[1]
+		}
+	}
+	return entries
+}
+
+// Users returns the sorted user names of the htpasswd file.
+func Users(htpasswd string) []string {
+	var users []string
+	for user := range parse(htpasswd) {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Verify returns true if the htpasswd file accepts the credential.
+func Verify(htpasswd string, c Credential) bool {
+	hash, ok := parse(htpasswd)[c.Username]
+	return ok && bcrypt.CompareHashAndPassword([]byte(hash), []byte(c.Password)) == nil
+}
+
+// Join returns an htpasswd file of the given entries, the latter entries of the same user win.
+func Join(entries ...string) string {
+	merged := make(map[string]string)
+	for _, entry := range entries {
+		for user, hash := range parse(entry) {
+			merged[user] = hash
+		}
+	}
+	users := make([]string, 0, len(merged))
+	for user := range merged {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	var sb strings.Builder
+	for _, user := range users {
+		sb.WriteString(user + ":" + merged[user] + "\n")
+	}
+	return sb.String()
+}
+
+// Keep returns the htpasswd file with the entries of the given user only.
+func Keep(htpasswd, user string) string {
+	hash, ok := parse(htpasswd)[user]
+	if !ok {
+		return ""
+	}
+	return user + ":" + hash + "\n"
+}
+
+// DockerConfigJSON returns the content of a kubernetes.io/dockerconfigjson secret authenticating to the given
+// registries with the credential.
+func DockerConfigJSON(c Credential, registries ...string) ([]byte, error) {
+	type auth struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	auths := make(map[string]auth, len(registries))
+	for _, registry := range registries {
+		auths[registry] = auth{
+			Username: c.Username,
+			Password: c.Password,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(c.String())),
+		}
+	}
+	return json.Marshal(map[string]interface{}{"auths": auths})
+}
@@ -0,0 +1,13 @@
+package htpasswd_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHtpasswd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Htpasswd Suite")
+}
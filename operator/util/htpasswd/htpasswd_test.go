@@ -0,0 +1,65 @@
+package htpasswd_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/htpasswd"
+)
+
+var _ = Describe("Htpasswd", func() {
+	It("Should generate distinct credentials", func() {
+		a, err := htpasswd.Generate()
+		Ω(err).NotTo(HaveOccurred())
+		b, err := htpasswd.Generate()
+		Ω(err).NotTo(HaveOccurred())
+		Ω(a.Username).To(MatchRegexp(`^user-[a-z0-9]{6}$`))
+		Ω(a.Password).To(HaveLen(32))
+		Ω(a).NotTo(Equal(b))
+	})
+
+	It("Should parse the raw credential", func() {
+		c, err := htpasswd.ParseCredential("\nuser-62hsyd:2JFqD8SJ:qYe\nother:pass\n")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(c).To(Equal(htpasswd.Credential{Username: "user-62hsyd", Password: "2JFqD8SJ:qYe"}))
+		_, err = htpasswd.ParseCredential("user-62hsyd")
+		Ω(err).To(HaveOccurred())
+		_, err = htpasswd.ParseCredential("")
+		Ω(err).To(HaveOccurred())
+	})
+
+	It("Should keep the former credential valid until trimmed", func() {
+		old := htpasswd.Credential{Username: "user-old", Password: "secret"}
+		current := htpasswd.Credential{Username: "user-new", Password: "secret2"}
+		oldEntry, err := old.Entry()
+		Ω(err).NotTo(HaveOccurred())
+		currentEntry, err := current.Entry()
+		Ω(err).NotTo(HaveOccurred())
+
+		file := htpasswd.Join(oldEntry, currentEntry)
+		Ω(htpasswd.Users(file)).To(Equal([]string{"user-new", "user-old"}))
+		Ω(htpasswd.Verify(file, old)).To(BeTrue())
+		Ω(htpasswd.Verify(file, current)).To(BeTrue())
+		Ω(htpasswd.Verify(file, htpasswd.Credential{Username: "user-new", Password: "secret"})).To(BeFalse())
+
+		file = htpasswd.Keep(file, current.Username)
+		Ω(htpasswd.Users(file)).To(Equal([]string{"user-new"}))
+		Ω(htpasswd.Verify(file, old)).To(BeFalse())
+		Ω(htpasswd.Verify(file, current)).To(BeTrue())
+	})
+
+	It("Should render the docker config", func() {
+		data, err := htpasswd.DockerConfigJSON(htpasswd.Credential{Username: "user", Password: "pass"},
+			"registry.example.com:5000")
+		Ω(err).NotTo(HaveOccurred())
+		var config map[string]map[string]map[string]string
+		Ω(json.Unmarshal(data, &config)).To(Succeed())
+		Ω(config["auths"]["registry.example.com:5000"]).To(Equal(map[string]string{
+			"username": "user",
+			"password": "pass",
+			"auth":     "dXNlcjpwYXNz",
+		}))
+	})
+})
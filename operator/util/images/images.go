@@ -0,0 +1,55 @@
+// Package images resolves the auxiliary images deployed by the operator. The built-in defaults can be replaced
+// by the RELATED_IMAGE_* environment variables of the operator, which OLM sets from the relatedImages of the
+// bundle pinned by digest, and by the spec of the custom resources. Only the images pulled by digest are
+// redirected to a local registry by an ImageContentSourcePolicy in a disconnected cluster.
+package images
+
+import "os"
+
+// Image is an auxiliary image with its environment variable.
+type Image struct {
+	// EnvVar overrides the default.
+	EnvVar string
+	// Default is used unless overridden.
+	Default string
+}
+
+var (
+	// NodeConfigurator runs the node configurator DaemonSet. It needs to provide chroot.
+	NodeConfigurator = Image{
+		EnvVar:  "RELATED_IMAGE_NODE_CONFIGURATOR",
+		Default: "registry.access.redhat.com/ubi8/ubi-minimal:latest",
+	}
+	// StorageProbe runs the pod measuring the latency of the validated volume.
+	StorageProbe = Image{
+		EnvVar:  "RELATED_IMAGE_STORAGE_PROBE",
+		Default: "registry.access.redhat.com/ubi8/ubi-minimal:latest",
+	}
+	// CheckpointProbe runs the job testing the access to the checkpoint store. It needs to provide the
+	// operator binary.
+	CheckpointProbe = Image{
+		EnvVar:  "RELATED_IMAGE_CHECKPOINT_PROBE",
+		Default: "quay.io/miminar/sdi-operator:latest",
+	}
+	// Mirror runs the skopeo job mirroring the SAP images.
+	Mirror = Image{
+		EnvVar:  "RELATED_IMAGE_MIRROR",
+		Default: "quay.io/skopeo/stable:latest",
+	}
+	// Registry runs the container image registry deployed by SDIRegistry.
+	Registry = Image{
+		EnvVar:  "RELATED_IMAGE_REGISTRY",
+		Default: "docker.io/library/registry:2",
+	}
+)
+
+// Resolve returns the image set in the spec if any, the one of the environment variable or the default.
+func (i Image) Resolve(specImage string) string {
+	if len(specImage) > 0 {
+		return specImage
+	}
+	if image := os.Getenv(i.EnvVar); len(image) > 0 {
+		return image
+	}
+	return i.Default
+}
@@ -0,0 +1,89 @@
+// Package insights summarizes the state observed by the operator for the Red Hat support. The Insights
+// Operator gathers the SAP DataHub resources, so the summary is attached to them as an annotation. Only the
+// versions, the health and the condition reasons are included; the messages may contain host names,
+// addresses or user names and are left out.
+package insights
+
+import (
+	"encoding/json"
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/version"
+)
+
+// AnnotationKey holds the JSON encoded Report on the DataHub resource.
+const AnnotationKey = "di.sap-cop.redhat.com/insights-report"
+
+// a reason not looking like an identifier may come from the user input
+var reReason = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+
+// Condition is a condition stripped of the message and timestamps.
+type Condition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Report is the summary of an SDIObserver. It must not change unless the observed state changes.
+type Report struct {
+	OperatorVersion string `json:"operatorVersion"`
+	SDIVersion      string `json:"sdiVersion,omitempty"`
+	FIPSMode        bool   `json:"fipsMode,omitempty"`
+	Health          string `json:"health,omitempty"`
+	DataHubState    string `json:"dataHubState,omitempty"`
+	ReadyComponents int32  `json:"readyComponents,omitempty"`
+	TotalComponents int32  `json:"totalComponents,omitempty"`
+	// the number of the applications failing the vsystem health check
+	UnhealthyApplications int         `json:"unhealthyApplications,omitempty"`
+	Conditions            []Condition `json:"conditions,omitempty"`
+	VSystemRoute          []Condition `json:"vsystemRoute,omitempty"`
+	SLCBRoute             []Condition `json:"slcbRoute,omitempty"`
+	VSystemHealth         []Condition `json:"vsystemHealth,omitempty"`
+}
+
+func summarize(conditions []metav1.Condition) []Condition {
+	var summary []Condition
+	for _, c := range conditions {
+		reason := c.Reason
+		if !reReason.MatchString(reason) {
+			reason = "Other"
+		}
+		summary = append(summary, Condition{Type: c.Type, Status: string(c.Status), Reason: reason})
+	}
+	return summary
+}
+
+// NewReport summarizes the status of the SDIObserver.
+func NewReport(obs *sdiv1alpha1.SDIObserver) *Report {
+	report := &Report{
+		OperatorVersion: version.Version,
+		FIPSMode:        fips.Enforced(),
+		Conditions:      summarize(obs.Status.Conditions),
+		VSystemRoute:    summarize(obs.Status.VSystemRoute.Conditions),
+		SLCBRoute:       summarize(obs.Status.SLCBRoute.Conditions),
+	}
+	if obs.Status.Health != nil {
+		report.Health = obs.Status.Health.Status
+	}
+	if dh := obs.Status.DataHub; dh != nil {
+		report.SDIVersion = dh.Version
+		report.DataHubState = dh.State
+		report.ReadyComponents = dh.ReadyComponents
+		report.TotalComponents = dh.TotalComponents
+	}
+	if vh := obs.Status.VSystemHealth; vh != nil {
+		report.VSystemHealth = summarize(vh.Conditions)
+		report.UnhealthyApplications = len(vh.UnhealthyApplications)
+	}
+	return report
+}
+
+// Encode returns the value of the annotation.
+func (r *Report) Encode() (string, error) {
+	data, err := json.Marshal(r)
+	return string(data), err
+}
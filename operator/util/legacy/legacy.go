@@ -0,0 +1,58 @@
+// Package legacy recognizes the objects created by the template-based sdi-observer that preceded the operator.
+// The operator adopts them in place instead of creating duplicates or fighting over their fields.
+package legacy
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+const (
+	// the label of the objects created by the legacy observer
+	CreatedByLabelKey   = "created-by"
+	CreatedByLabelValue = "sdi-observer"
+	// the prefix of the annotations set by the legacy observer
+	AnnotationPrefix = "sdi-observer/"
+)
+
+// IsLegacy returns true if the object has been created by the legacy observer and is not owned by the operator.
+func IsLegacy(obj metav1.Object) bool {
+	if _, owned := obj.GetAnnotations()[primaryresource.AnnotationKey]; owned {
+		return false
+	}
+	if obj.GetLabels()[CreatedByLabelKey] == CreatedByLabelValue {
+		return true
+	}
+	for k := range obj.GetAnnotations() {
+		if strings.HasPrefix(k, AnnotationPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Adopt strips the marks of the legacy observer off the object and returns true if it was a legacy object. The
+// managed fields are reset so that the fields set by the legacy observer do not stay owned by its field
+// manager; the next apply of the operator takes over the fields it manages. The caller marks the object as
+// owned and updates it.
+func Adopt(obj metav1.Object) bool {
+	if !IsLegacy(obj) {
+		return false
+	}
+	labels := obj.GetLabels()
+	delete(labels, CreatedByLabelKey)
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	for k := range annotations {
+		if strings.HasPrefix(k, AnnotationPrefix) {
+			delete(annotations, k)
+		}
+	}
+	obj.SetAnnotations(annotations)
+	// a single empty entry clears the managed fields on update
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{}})
+	return true
+}
@@ -0,0 +1,13 @@
+package legacy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestLegacy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Legacy Suite")
+}
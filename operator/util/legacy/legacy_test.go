@@ -0,0 +1,52 @@
+package legacy_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/legacy"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/primaryresource"
+)
+
+var _ = Describe("Adopt", func() {
+	newObject := func(labels, annotations map[string]string) *metav1.ObjectMeta {
+		return &metav1.ObjectMeta{
+			Name:          "vsystem",
+			Labels:        labels,
+			Annotations:   annotations,
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "oc", Operation: metav1.ManagedFieldsOperationUpdate}},
+		}
+	}
+
+	It("strips the marks of the legacy observer", func() {
+		obj := newObject(
+			map[string]string{legacy.CreatedByLabelKey: legacy.CreatedByLabelValue, "app": "vsystem"},
+			map[string]string{legacy.AnnotationPrefix + "updated": "true", "haproxy.router.openshift.io/timeout": "2m"})
+		Ω(legacy.Adopt(obj)).Should(BeTrue())
+		Ω(obj.Labels).Should(Equal(map[string]string{"app": "vsystem"}))
+		Ω(obj.Annotations).Should(Equal(map[string]string{"haproxy.router.openshift.io/timeout": "2m"}))
+		Ω(obj.ManagedFields).Should(Equal([]metav1.ManagedFieldsEntry{{}}))
+		Ω(legacy.IsLegacy(obj)).Should(BeFalse())
+	})
+
+	It("recognizes the legacy annotations alone", func() {
+		obj := newObject(nil, map[string]string{legacy.AnnotationPrefix + "created": "true"})
+		Ω(legacy.Adopt(obj)).Should(BeTrue())
+		Ω(obj.Annotations).Should(BeEmpty())
+	})
+
+	It("leaves the objects owned by the operator alone", func() {
+		obj := newObject(map[string]string{legacy.CreatedByLabelKey: legacy.CreatedByLabelValue},
+			map[string]string{primaryresource.AnnotationKey: "sdi-observer/sdi"})
+		Ω(legacy.Adopt(obj)).Should(BeFalse())
+		Ω(obj.Labels).Should(HaveKey(legacy.CreatedByLabelKey))
+		Ω(obj.ManagedFields).Should(HaveLen(1))
+	})
+
+	It("leaves the other objects alone", func() {
+		obj := newObject(map[string]string{"app": "vsystem"}, nil)
+		Ω(legacy.Adopt(obj)).Should(BeFalse())
+		Ω(obj.ManagedFields).Should(HaveLen(1))
+	})
+})
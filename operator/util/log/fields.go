@@ -0,0 +1,12 @@
+package log
+
+// Keys of the structured fields shared by the controllers so that the log records can be filtered uniformly
+// once forwarded from the cluster.
+const (
+	// ComponentKey names the controller or runnable emitting the record.
+	ComponentKey = "component"
+	// SDINamespaceKey holds the SDI namespace the record relates to.
+	SDINamespaceKey = "sdiNamespace"
+	// ObjectKey holds the namespace/name of the reconciled object.
+	ObjectKey = "object"
+)
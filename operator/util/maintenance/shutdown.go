@@ -0,0 +1,41 @@
+package maintenance
+
+import (
+	"time"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+// ShutdownSchedule is the result of the evaluation of the shutdown windows.
+type ShutdownSchedule struct {
+	// SDI shall be stopped now.
+	Stopped bool
+	// The next run level change. Empty if none is scheduled.
+	NextAction sdiv1alpha1.SDIShutdownAction
+	// When the next run level change is due.
+	NextTime time.Time
+}
+
+// EvaluateShutdown determines whether SDI shall be stopped at the given time and when the run level changes next.
+// The shutdown windows are evaluated in the time zone of the maintenance window and regardless of its suspension.
+func EvaluateShutdown(spec *sdiv1alpha1.SDIMaintenanceWindowSpec, now time.Time) (ShutdownSchedule, error) {
+	var sched ShutdownSchedule
+	if spec.Shutdown == nil {
+		return sched, nil
+	}
+	res, err := Evaluate(&sdiv1alpha1.SDIMaintenanceWindowSpec{
+		TimeZone: spec.TimeZone,
+		Windows:  spec.Shutdown.Windows,
+	}, now)
+	if err != nil {
+		return sched, err
+	}
+	sched.Stopped = res.Allowed
+	switch {
+	case res.Allowed && !res.CurrentEnd.IsZero():
+		sched.NextAction, sched.NextTime = sdiv1alpha1.SDIShutdownActionStart, res.CurrentEnd
+	case !res.Allowed && !res.NextStart.IsZero():
+		sched.NextAction, sched.NextTime = sdiv1alpha1.SDIShutdownActionStop, res.NextStart
+	}
+	return sched, nil
+}
@@ -0,0 +1,62 @@
+package maintenance_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+)
+
+var _ = Describe("Shutdown schedule", func() {
+	// weeknights 20:00 - 06:00 Europe/Prague (UTC+1 in March)
+	spec := sdiv1alpha1.SDIMaintenanceWindowSpec{
+		TimeZone: "Europe/Prague",
+		Windows: []sdiv1alpha1.SDIMaintenanceWindowSpecWindow{{
+			Start:    "02:00",
+			Duration: metav1.Duration{Duration: time.Hour},
+		}},
+		Shutdown: &sdiv1alpha1.SDIMaintenanceWindowSpecShutdown{
+			Windows: []sdiv1alpha1.SDIMaintenanceWindowSpecWindow{{
+				Days:     []sdiv1alpha1.SDIMaintenanceWindowDay{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+				Start:    "20:00",
+				Duration: metav1.Duration{Duration: time.Hour * 10},
+			}},
+		},
+	}
+
+	It("Should stop SDI during the night", func() {
+		sched, err := maintenance.EvaluateShutdown(&spec, time.Date(2022, 3, 7, 23, 0, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(sched.Stopped).To(BeTrue())
+		Ω(sched.NextAction).To(Equal(sdiv1alpha1.SDIShutdownActionStart))
+		Ω(sched.NextTime).To(BeTemporally("==", time.Date(2022, 3, 8, 5, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should schedule the stop during the day", func() {
+		sched, err := maintenance.EvaluateShutdown(&spec, time.Date(2022, 3, 7, 12, 0, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(sched.Stopped).To(BeFalse())
+		Ω(sched.NextAction).To(Equal(sdiv1alpha1.SDIShutdownActionStop))
+		Ω(sched.NextTime).To(BeTemporally("==", time.Date(2022, 3, 7, 19, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should keep SDI running from Saturday morning until Monday evening", func() {
+		sched, err := maintenance.EvaluateShutdown(&spec, time.Date(2022, 3, 12, 12, 0, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(sched.Stopped).To(BeFalse())
+		Ω(sched.NextTime).To(BeTemporally("==", time.Date(2022, 3, 14, 19, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should not schedule anything without shutdown windows", func() {
+		spec := spec
+		spec.Shutdown = nil
+		sched, err := maintenance.EvaluateShutdown(&spec, time.Date(2022, 3, 7, 23, 0, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(sched.Stopped).To(BeFalse())
+		Ω(sched.NextAction).To(BeEmpty())
+	})
+})
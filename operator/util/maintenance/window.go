@@ -0,0 +1,185 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+	// do not rely on the time zone database of the base image
+	_ "time/tzdata"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+// Result of the evaluation of the maintenance windows.
+type Result struct {
+	// Disruptive changes are allowed now.
+	Allowed bool
+	// When the current window closes. Zero if no window is open or the window does not close.
+	CurrentEnd time.Time
+	// When the next window opens. Zero if no window is scheduled.
+	NextStart time.Time
+}
+
+// Evaluate determines whether the maintenance window is open at the given time.
+func Evaluate(spec *sdiv1alpha1.SDIMaintenanceWindowSpec, now time.Time) (Result, error) {
+	var res Result
+	if spec.Suspend {
+		res.Allowed = true
+		return res, nil
+	}
+	loc := time.UTC
+	if len(spec.TimeZone) > 0 {
+		var err error
+		if loc, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return res, fmt.Errorf("invalid time zone %q: %v", spec.TimeZone, err)
+		}
+	}
+	local := now.In(loc)
+	for _, w := range spec.Windows {
+		var hour, minute int
+		if _, err := fmt.Sscanf(w.Start, "%d:%d", &hour, &minute); err != nil {
+			return res, fmt.Errorf("invalid window start %q: %v", w.Start, err)
+		}
+		days := make(map[string]bool, len(w.Days))
+		for _, d := range w.Days {
+			days[string(d)] = true
+		}
+		// a window may last up to a week, look at the previous week as well
+		for offset := -7; offset <= 7; offset++ {
+			date := local.AddDate(0, 0, offset)
+			if len(days) > 0 && !days[date.Weekday().String()] {
+				continue
+			}
+			start := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+			end := start.Add(w.Duration.Duration)
+			if !now.Before(start) && now.Before(end) {
+				res.Allowed = true
+				if end.After(res.CurrentEnd) {
+					res.CurrentEnd = end
+				}
+			}
+			if start.After(now) && (res.NextStart.IsZero() || start.Before(res.NextStart)) {
+				res.NextStart = start
+			}
+		}
+	}
+	return res, nil
+}
+
+// Check evaluates all the maintenance windows in the given namespace. Disruptive changes are allowed if
+// there is no window or if any of the windows is open.
+func Check(ctx context.Context, c client.Reader, namespace string) (Result, error) {
+	windows := &sdiv1alpha1.SDIMaintenanceWindowList{}
+	if err := c.List(ctx, windows, client.InNamespace(namespace)); err != nil {
+		return Result{}, err
+	}
+	if len(windows.Items) == 0 {
+		return Result{Allowed: true}, nil
+	}
+	var combined Result
+	now := time.Now()
+	for i := range windows.Items {
+		res, err := Evaluate(&windows.Items[i].Spec, now)
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to evaluate maintenance window %q: %v", windows.Items[i].Name, err)
+		}
+		if res.Allowed {
+			combined.Allowed = true
+		}
+		if !res.NextStart.IsZero() && (combined.NextStart.IsZero() || res.NextStart.Before(combined.NextStart)) {
+			combined.NextStart = res.NextStart
+		}
+	}
+	return combined, nil
+}
+
+// RequeueAfter returns the delay until the next window opens or zero if no window is scheduled.
+func (r Result) RequeueAfter() time.Duration {
+	if r.NextStart.IsZero() {
+		return 0
+	}
+	// give the clocks a little slack
+	return time.Until(r.NextStart) + time.Second
+}
+
+// SetPendingCondition records on the given conditions whether a disruptive change is deferred.
+func SetPendingCondition(
+	conditions *[]metav1.Condition,
+	generation int64,
+	pending bool,
+	res Result,
+	change string,
+) {
+	if !pending {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               sdiv1alpha1.ConditionMaintenancePending,
+			Status:             metav1.ConditionFalse,
+			Reason:             sdiv1alpha1.ConditionReasonAsExpected,
+			Message:            "no disruptive changes are pending",
+			ObservedGeneration: generation,
+		})
+		return
+	}
+	msg := fmt.Sprintf("%s is deferred until the next maintenance window", change)
+	if !res.NextStart.IsZero() {
+		msg += " starting at " + res.NextStart.UTC().Format(time.RFC3339)
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               sdiv1alpha1.ConditionMaintenancePending,
+		Status:             metav1.ConditionTrue,
+		Reason:             sdiv1alpha1.ConditionReasonOutsideMaintenanceWindow,
+		Message:            msg,
+		ObservedGeneration: generation,
+	})
+}
+
+// OperationResultDeferred is returned by CreateOrUpdate when the change is deferred until the next
+// maintenance window.
+const OperationResultDeferred controllerutil.OperationResult = "deferred"
+
+// CreateOrUpdate works like controllerutil.CreateOrUpdate except that it defers the update of the object
+// until a maintenance window in the given namespace is open. If disruptiveCreate is true, the creation of
+// the object is deferred as well.
+func CreateOrUpdate(
+	ctx context.Context,
+	c client.Client,
+	windowNamespace string,
+	obj client.Object,
+	f controllerutil.MutateFn,
+	disruptiveCreate bool,
+) (controllerutil.OperationResult, Result, error) {
+	key := client.ObjectKeyFromObject(obj)
+	err := c.Get(ctx, key, obj)
+	if err != nil && !errors.IsNotFound(err) {
+		return controllerutil.OperationResultNone, Result{}, err
+	}
+	exists := err == nil
+	existing := obj.DeepCopyObject()
+	if err := f(); err != nil {
+		return controllerutil.OperationResultNone, Result{}, err
+	}
+	if exists && equality.Semantic.DeepEqual(existing, obj) {
+		return controllerutil.OperationResultNone, Result{Allowed: true}, nil
+	}
+
+	res := Result{Allowed: true}
+	if exists || disruptiveCreate {
+		if res, err = Check(ctx, c, windowNamespace); err != nil {
+			return controllerutil.OperationResultNone, res, err
+		}
+		if !res.Allowed {
+			return OperationResultDeferred, res, nil
+		}
+	}
+	if !exists {
+		return controllerutil.OperationResultCreated, res, c.Create(ctx, obj)
+	}
+	return controllerutil.OperationResultUpdated, res, c.Update(ctx, obj)
+}
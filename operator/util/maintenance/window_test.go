@@ -0,0 +1,62 @@
+package maintenance_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/maintenance"
+)
+
+var _ = Describe("Maintenance window", func() {
+	// Saturday 22:00 - Sunday 04:00 UTC
+	spec := sdiv1alpha1.SDIMaintenanceWindowSpec{
+		Windows: []sdiv1alpha1.SDIMaintenanceWindowSpecWindow{{
+			Days:     []sdiv1alpha1.SDIMaintenanceWindowDay{"Saturday"},
+			Start:    "22:00",
+			Duration: metav1.Duration{Duration: time.Hour * 6},
+		}},
+	}
+
+	It("Should be open past midnight", func() {
+		res, err := maintenance.Evaluate(&spec, time.Date(2022, 3, 6, 1, 30, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(res.Allowed).To(BeTrue())
+		Ω(res.CurrentEnd).To(BeTemporally("==", time.Date(2022, 3, 6, 4, 0, 0, 0, time.UTC)))
+		Ω(res.NextStart).To(BeTemporally("==", time.Date(2022, 3, 12, 22, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should be closed outside of the window", func() {
+		res, err := maintenance.Evaluate(&spec, time.Date(2022, 3, 7, 12, 0, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(res.Allowed).To(BeFalse())
+		Ω(res.NextStart).To(BeTemporally("==", time.Date(2022, 3, 12, 22, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should respect the time zone", func() {
+		spec := spec
+		spec.TimeZone = "Europe/Prague"
+		res, err := maintenance.Evaluate(&spec, time.Date(2022, 3, 5, 21, 30, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(res.Allowed).To(BeTrue())
+		Ω(res.CurrentEnd).To(BeTemporally("==", time.Date(2022, 3, 6, 3, 0, 0, 0, time.UTC)))
+	})
+
+	It("Should be open when suspended", func() {
+		spec := spec
+		spec.Suspend = true
+		res, err := maintenance.Evaluate(&spec, time.Date(2022, 3, 7, 12, 0, 0, 0, time.UTC))
+		Ω(err).NotTo(HaveOccurred())
+		Ω(res.Allowed).To(BeTrue())
+	})
+
+	It("Should refuse an unknown time zone", func() {
+		spec := spec
+		spec.TimeZone = "Mars/Olympus"
+		_, err := maintenance.Evaluate(&spec, time.Now())
+		Ω(err).To(HaveOccurred())
+	})
+})
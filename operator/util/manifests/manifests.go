@@ -0,0 +1,410 @@
+// Package manifests renders the operator installation as a kustomize base. The base is made of the same
+// manifests as the kustomize configuration of the repository (the CRDs, the RBAC and the manager Deployment)
+// with the namespaces and options resolved, plus an SDIObserver, so that the installation can be committed to
+// Git and applied by a GitOps tool without the OLM bundle.
+package manifests
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// the prefix of the names of the operator resources, the same as of config/default
+	namePrefix = "operator-"
+
+	crdDir          = "config/crd/bases"
+	managerManifest = "config/manager/manager.yaml"
+	rbacDir         = "config/rbac"
+)
+
+// the RBAC manifests of config/rbac needed by the manager; the auth proxy is left out
+var rbacManifests = []string{
+	"service_account.yaml",
+	"role.yaml",
+	"role_binding.yaml",
+	"leader_election_role.yaml",
+	"leader_election_role_binding.yaml",
+}
+
+// the resources of the manager ClusterRole that cannot be granted by the Roles of the namespace-scoped RBAC mode:
+// the cluster-scoped ones and the ones living in the namespaces of other operators; nil stands for all the
+// resources of the group; the use of the SecurityContextConstraints is granted per namespace and kept
+var clusterScopedResources = map[string][]string{
+	"":                                  {"namespaces", "nodes"},
+	"admissionregistration.k8s.io":      nil,
+	"apiextensions.k8s.io":              {"customresourcedefinitions"},
+	"authorization.k8s.io":              {"subjectaccessreviews"},
+	"compliance.openshift.io":           nil,
+	"config.openshift.io":               nil,
+	"machineconfiguration.openshift.io": nil,
+	"operator.openshift.io":             {"imagecontentsourcepolicies"},
+	"rbac.authorization.k8s.io":         {"clusterrolebindings", "clusterroles"},
+	"scheduling.k8s.io":                 {"priorityclasses"},
+	"storage.k8s.io":                    {"storageclasses"},
+	"tuned.openshift.io":                nil,
+	"velero.io":                         nil,
+}
+
+// Options parameterize the generated base.
+type Options struct {
+	// the namespace of the operator and the SDIObserver
+	Namespace     string
+	SDINamespace  string
+	SLCBNamespace string
+	// the image of the operator
+	Image                string
+	SDINodeRole          string
+	ManageKernelModules  bool
+	ManageServiceMonitor bool
+	// grant the manager Roles in the operator, SDI and SLCB namespaces instead of the ClusterRole
+	NamespacedRBAC bool
+	// the name of the SDIObserver; defaults to sdiobserver-<SDINamespace>; no SDIObserver is generated if "-"
+	ObserverName string
+}
+
+// File is a file of the generated base.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Generate renders the base from the config directory of the repository found in fsys. The kustomization.yaml
+// comes last.
+func Generate(fsys fs.FS, opts Options) ([]File, error) {
+	if len(opts.Namespace) == 0 {
+		return nil, fmt.Errorf("the operator namespace must be set")
+	}
+	if len(opts.SDINamespace) == 0 && len(opts.ObserverName) == 0 {
+		return nil, fmt.Errorf("the SDI namespace or the name of the SDIObserver must be set")
+	}
+	if opts.NamespacedRBAC && (len(opts.SDINamespace) == 0 || len(opts.SLCBNamespace) == 0) {
+		return nil, fmt.Errorf("the SDI and SLCB namespaces must be set with the namespace-scoped RBAC")
+	}
+	var files []File
+
+	crds, err := fs.Glob(fsys, path.Join(crdDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(crds)
+	for _, name := range crds {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{Name: path.Join("crds", path.Base(name)), Data: data})
+	}
+
+	for _, name := range rbacManifests {
+		objs, err := readObjects(fsys, path.Join(rbacDir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			setRBAC(obj, opts.Namespace)
+		}
+		if opts.NamespacedRBAC {
+			objs = toNamespacedRBAC(objs, opts.Namespace, opts.SDINamespace, opts.SLCBNamespace)
+		}
+		file, err := toFile(path.Join("rbac", name), objs)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	objs, err := readObjects(fsys, managerManifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		if err := setManager(obj, &opts); err != nil {
+			return nil, err
+		}
+	}
+	file, err := toFile("manager.yaml", objs)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, file)
+
+	if opts.ObserverName != "-" {
+		file, err = toFile("sdiobserver.yaml", []*unstructured.Unstructured{newObserver(&opts)})
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	resources := make([]string, 0, len(files))
+	for _, f := range files {
+		resources = append(resources, f.Name)
+	}
+	data, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(files, File{Name: "kustomization.yaml", Data: data}), nil
+}
+
+// Write writes the files to the directory.
+func Write(dir string, files []File) error {
+	for _, f := range files {
+		name := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, f.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func readObjects(fsys fs.FS, name string) ([]*unstructured.Unstructured, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var objs []*unstructured.Unstructured
+	for _, doc := range bytes.Split(data, []byte("\n---")) {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(doc, &obj.Object); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+func toFile(name string, objs []*unstructured.Unstructured) (File, error) {
+	var buf bytes.Buffer
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return File{}, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return File{Name: name, Data: buf.Bytes()}, nil
+}
+
+// setRBAC prefixes the names of the RBAC objects and their references and moves them to the namespace.
+func setRBAC(obj *unstructured.Unstructured, namespace string) {
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	obj.SetName(namePrefix + obj.GetName())
+	switch obj.GetKind() {
+	case "ServiceAccount", "Role", "RoleBinding":
+		obj.SetNamespace(namespace)
+	}
+	if name, ok, _ := unstructured.NestedString(obj.Object, "roleRef", "name"); ok {
+		_ = unstructured.SetNestedField(obj.Object, namePrefix+name, "roleRef", "name")
+	}
+	subjects, _, _ := unstructured.NestedSlice(obj.Object, "subjects")
+	for _, s := range subjects {
+		subject, ok := s.(map[string]interface{})
+		if !ok || subject["kind"] != "ServiceAccount" {
+			continue
+		}
+		if name, _ := subject["name"].(string); !strings.HasPrefix(name, namePrefix) {
+			subject["name"] = namePrefix + name
+		}
+		subject["namespace"] = namespace
+	}
+	if subjects != nil {
+		_ = unstructured.SetNestedSlice(obj.Object, subjects, "subjects")
+	}
+}
+
+// toNamespacedRBAC replaces the ClusterRoles and the ClusterRoleBindings with Roles and RoleBindings in each of
+// the namespaces. The rules granting the cluster-scoped resources are left out.
+func toNamespacedRBAC(objs []*unstructured.Unstructured, namespaces ...string) []*unstructured.Unstructured {
+	var result []*unstructured.Unstructured
+	for _, obj := range objs {
+		var kind string
+		switch obj.GetKind() {
+		case "ClusterRole":
+			kind = "Role"
+			rules, _, _ := unstructured.NestedSlice(obj.Object, "rules")
+			_ = unstructured.SetNestedSlice(obj.Object, filterRules(rules), "rules")
+		case "ClusterRoleBinding":
+			kind = "RoleBinding"
+			if ref, ok, _ := unstructured.NestedString(obj.Object, "roleRef", "kind"); ok && ref == "ClusterRole" {
+				_ = unstructured.SetNestedField(obj.Object, "Role", "roleRef", "kind")
+			}
+		default:
+			result = append(result, obj)
+			continue
+		}
+		seen := make(map[string]bool, len(namespaces))
+		for _, namespace := range namespaces {
+			if seen[namespace] {
+				continue
+			}
+			seen[namespace] = true
+			namespaced := obj.DeepCopy()
+			namespaced.SetKind(kind)
+			namespaced.SetNamespace(namespace)
+			result = append(result, namespaced)
+		}
+	}
+	return result
+}
+
+// filterRules removes the cluster-scoped resources from the rules and drops the rules left without resources.
+func filterRules(rules []interface{}) []interface{} {
+	var result []interface{}
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		groups, _, _ := unstructured.NestedStringSlice(rule, "apiGroups")
+		resources, _, _ := unstructured.NestedStringSlice(rule, "resources")
+		var kept []interface{}
+		for _, resource := range resources {
+			if !isClusterScoped(groups, resource) {
+				kept = append(kept, resource)
+			}
+		}
+		if len(kept) == 0 {
+			continue
+		}
+		rule["resources"] = kept
+		result = append(result, rule)
+	}
+	return result
+}
+
+func isClusterScoped(groups []string, resource string) bool {
+	// the subresources share the scope of their resource
+	resource = strings.SplitN(resource, "/", 2)[0]
+	for _, group := range groups {
+		excluded, ok := clusterScopedResources[group]
+		if !ok {
+			continue
+		}
+		if excluded == nil {
+			return true
+		}
+		for _, r := range excluded {
+			if r == resource {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setManager sets the namespace and the options of the manager Deployment.
+func setManager(obj *unstructured.Unstructured, opts *Options) error {
+	if obj.GetKind() == "Namespace" {
+		obj.SetName(opts.Namespace)
+		return nil
+	}
+	obj.SetName(namePrefix + obj.GetName())
+	obj.SetNamespace(opts.Namespace)
+	if obj.GetKind() != "Deployment" {
+		return nil
+	}
+	podSpec := []string{"spec", "template", "spec"}
+	if sa, ok, _ := unstructured.NestedString(obj.Object, append(podSpec, "serviceAccountName")...); ok {
+		_ = unstructured.SetNestedField(obj.Object, namePrefix+sa, append(podSpec, "serviceAccountName")...)
+	}
+	containers, _, _ := unstructured.NestedSlice(obj.Object, append(podSpec, "containers")...)
+	found := false
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok || container["name"] != "manager" {
+			continue
+		}
+		found = true
+		if len(opts.Image) > 0 {
+			container["image"] = opts.Image
+		}
+		setEnv(container, map[string]string{
+			"SDI_NAMESPACE":          opts.SDINamespace,
+			"SLCB_NAMESPACE":         opts.SLCBNamespace,
+			"SDI_NODE_ROLE":          opts.SDINodeRole,
+			"MANAGE_KERNEL_MODULES":  strconv.FormatBool(opts.ManageKernelModules),
+			"MANAGE_SERVICE_MONITOR": strconv.FormatBool(opts.ManageServiceMonitor),
+			"NAMESPACED_RBAC":        strconv.FormatBool(opts.NamespacedRBAC),
+		})
+	}
+	if !found {
+		return fmt.Errorf("no manager container in deployment %s", obj.GetName())
+	}
+	return unstructured.SetNestedSlice(obj.Object, containers, append(podSpec, "containers")...)
+}
+
+// setEnv sets the values of the environment variables of the container, appending the missing ones.
+func setEnv(container map[string]interface{}, values map[string]string) {
+	env, _ := container["env"].([]interface{})
+	set := make(map[string]bool, len(values))
+	for _, e := range env {
+		variable, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := variable["name"].(string)
+		if value, ok := values[name]; ok {
+			variable["value"] = value
+			delete(variable, "valueFrom")
+			set[name] = true
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !set[name] {
+			env = append(env, map[string]interface{}{"name": name, "value": values[name]})
+		}
+	}
+	container["env"] = env
+}
+
+// newObserver returns the SDIObserver of the SDI namespace managing the routes. The rest of the spec is left to
+// the defaults of the CRD.
+func newObserver(opts *Options) *unstructured.Unstructured {
+	name := opts.ObserverName
+	if len(name) == 0 {
+		name = "sdiobserver-" + opts.SDINamespace
+	}
+	obs := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "di.sap-cop.redhat.com/v1alpha1",
+		"kind":       "SDIObserver",
+		"spec": map[string]interface{}{
+			"sdiNamespace":  opts.SDINamespace,
+			"slcbNamespace": opts.SLCBNamespace,
+			"vsystemRoute":  map[string]interface{}{"managementState": "Managed"},
+			"slcbRoute":     map[string]interface{}{"managementState": "Managed"},
+		},
+	}}
+	obs.SetName(name)
+	obs.SetNamespace(opts.Namespace)
+	return obs
+}
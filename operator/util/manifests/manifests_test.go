@@ -0,0 +1,118 @@
+package manifests_test
+
+import (
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/manifests"
+)
+
+var _ = Describe("Generate", func() {
+	// the root of the operator with the config directory
+	fsys := os.DirFS("../..")
+
+	opts := func() manifests.Options {
+		return manifests.Options{
+			Namespace:     "gitops-sdi",
+			SDINamespace:  "prod-sdi",
+			SLCBNamespace: "prod-slcb",
+			Image:         "registry.example.com/sdi-operator:1.0",
+			SDINodeRole:   "worker",
+		}
+	}
+
+	find := func(files []manifests.File, name string) string {
+		for _, f := range files {
+			if f.Name == name {
+				return string(f.Data)
+			}
+		}
+		Fail("missing file " + name)
+		return ""
+	}
+
+	It("renders the base with the options", func() {
+		files, err := manifests.Generate(fsys, opts())
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(files[len(files)-1].Name).Should(Equal("kustomization.yaml"))
+		kustomization := files[len(files)-1].Data
+		for _, f := range files[:len(files)-1] {
+			Ω(string(kustomization)).Should(ContainSubstring("- " + f.Name + "\n"))
+		}
+		Ω(find(files, "crds/di.sap-cop.redhat.com_sdiobservers.yaml")).Should(ContainSubstring("kind: SDIObserver"))
+
+		manager := find(files, "manager.yaml")
+		Ω(manager).Should(ContainSubstring("name: gitops-sdi\n"))
+		Ω(manager).Should(ContainSubstring("namespace: gitops-sdi\n"))
+		Ω(manager).Should(ContainSubstring("image: registry.example.com/sdi-operator:1.0\n"))
+		Ω(manager).Should(ContainSubstring("- name: SDI_NAMESPACE\n          value: prod-sdi\n"))
+		Ω(manager).Should(ContainSubstring("- name: SDI_NODE_ROLE\n          value: worker\n"))
+		Ω(manager).Should(ContainSubstring("serviceAccountName: operator-controller-manager\n"))
+
+		binding := find(files, "rbac/role_binding.yaml")
+		Ω(binding).Should(ContainSubstring("name: operator-manager-role\n"))
+		Ω(binding).Should(ContainSubstring("namespace: gitops-sdi\n"))
+		Ω(binding).ShouldNot(ContainSubstring("sdi-operator"))
+		Ω(find(files, "rbac/leader_election_role_binding.yaml")).ShouldNot(ContainSubstring("system"))
+
+		observer := find(files, "sdiobserver.yaml")
+		Ω(observer).Should(ContainSubstring("name: sdiobserver-prod-sdi\n"))
+		Ω(observer).Should(ContainSubstring("sdiNamespace: prod-sdi\n"))
+	})
+
+	It("leaves out the SDIObserver", func() {
+		o := opts()
+		o.ObserverName = "-"
+		files, err := manifests.Generate(fsys, o)
+		Ω(err).ShouldNot(HaveOccurred())
+		for _, f := range files {
+			Ω(f.Name).ShouldNot(Equal("sdiobserver.yaml"))
+			Ω(strings.Contains(string(f.Data), "sdiobserver.yaml")).Should(BeFalse())
+		}
+	})
+
+	It("grants Roles in the namespaces with the namespace-scoped RBAC", func() {
+		o := opts()
+		o.NamespacedRBAC = true
+		files, err := manifests.Generate(fsys, o)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		role := find(files, "rbac/role.yaml")
+		Ω(role).ShouldNot(ContainSubstring("kind: ClusterRole"))
+		Ω(strings.Count(role, "kind: Role\n")).Should(Equal(3))
+		for _, ns := range []string{"gitops-sdi", "prod-sdi", "prod-slcb"} {
+			Ω(role).Should(ContainSubstring("namespace: " + ns + "\n"))
+		}
+		Ω(role).Should(ContainSubstring("- sdiregistries\n"))
+		Ω(role).Should(ContainSubstring("- securitycontextconstraints\n"))
+		for _, resource := range []string{"namespaces", "nodes", "clusterrolebindings", "priorityclasses",
+			"machineconfigs", "proxies", "customresourcedefinitions", "imagecontentsourcepolicies"} {
+			Ω(role).ShouldNot(ContainSubstring("- " + resource + "\n"))
+		}
+
+		binding := find(files, "rbac/role_binding.yaml")
+		Ω(binding).ShouldNot(ContainSubstring("ClusterRole"))
+		Ω(strings.Count(binding, "kind: RoleBinding\n")).Should(Equal(3))
+		Ω(binding).Should(ContainSubstring("namespace: prod-slcb\n"))
+
+		Ω(find(files, "manager.yaml")).Should(ContainSubstring("- name: NAMESPACED_RBAC\n          value: \"true\"\n"))
+	})
+
+	It("requires the SDI and SLCB namespaces with the namespace-scoped RBAC", func() {
+		o := opts()
+		o.NamespacedRBAC = true
+		o.SLCBNamespace = ""
+		_, err := manifests.Generate(fsys, o)
+		Ω(err).Should(HaveOccurred())
+	})
+
+	It("requires the operator namespace", func() {
+		o := opts()
+		o.Namespace = ""
+		_, err := manifests.Generate(fsys, o)
+		Ω(err).Should(HaveOccurred())
+	})
+})
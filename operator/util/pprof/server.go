@@ -0,0 +1,66 @@
+// Package pprof serves the runtime profiling data of the operator.
+package pprof
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const shutdownTimeout = time.Second * 5
+
+// Server serves the net/http/pprof handlers on the given address until the manager stops.
+type Server struct {
+	Addr string
+}
+
+var _ manager.Runnable = &Server{}
+var _ manager.LeaderElectionRunnable = &Server{}
+
+// NeedLeaderElection returns false so that the standby replicas can be profiled as well.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the profiles until the context is done.
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("pprof")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "failed to shut down the pprof server")
+		}
+	}()
+
+	logger.Info("serving the profiles", "address", listener.Addr().String())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager adds the server to the manager.
+func (s *Server) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(s)
+}
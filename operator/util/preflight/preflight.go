@@ -0,0 +1,184 @@
+// Package preflight verifies the cluster prerequisites of SAP Data Intelligence. The checks reuse the evaluation
+// of the controllers so that the ad-hoc report of the check subcommand matches the conditions set in the cluster.
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/compat"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/routeapi"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+// The outcomes of the checks. Only Failed fails the report.
+const (
+	StatusPassed  Status = "Passed"
+	StatusWarning Status = "Warning"
+	StatusFailed  Status = "Failed"
+)
+
+const (
+	// the minimum number of the SDI compute nodes required by SAP
+	minSDINodes = 3
+
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+)
+
+// Environment is the cluster and the SDI installation checked.
+type Environment struct {
+	Reader client.Reader
+	Mapper meta.RESTMapper
+	// the version of the installed SDI; empty before the installation
+	SDIVersion string
+	// the value of the node-role.kubernetes.io label of the SDI compute nodes
+	SDINodeRole string
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// Report lists the results of all the checks.
+type Report struct {
+	Results []Result `json:"results"`
+	Passed  bool     `json:"passed"`
+}
+
+// Check verifies a single prerequisite.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, env *Environment) Result
+}
+
+// Checks are the checks run by Run in order.
+var Checks = []Check{
+	{"openshift-release", checkOpenShiftRelease},
+	{"route-api", checkRouteAPI},
+	{"default-storage-class", checkDefaultStorageClass},
+	{"sdi-nodes", checkSDINodes},
+}
+
+// Run runs all the Checks. The report passes unless any check fails.
+func Run(ctx context.Context, env *Environment) *Report {
+	report := &Report{Passed: true}
+	for _, check := range Checks {
+		res := check.Run(ctx, env)
+		res.Name = check.Name
+		if res.Status == StatusFailed {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+// WriteText writes the report as a table.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMESSAGE")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", res.Name, res.Status, res.Message)
+	}
+	verdict := "all prerequisites are fulfilled"
+	if !r.Passed {
+		verdict = "some prerequisites are not fulfilled"
+	}
+	fmt.Fprintf(tw, "\n%s\n", verdict)
+	return tw.Flush()
+}
+
+// WriteJSON writes the report as an indented JSON document.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func checkOpenShiftRelease(ctx context.Context, env *Environment) Result {
+	ocpVersion, err := compat.GetOpenShiftVersion(ctx, env.Reader)
+	if err != nil {
+		return Result{Status: StatusFailed, Message: "failed to get the OpenShift version: " + err.Error()}
+	}
+	if len(ocpVersion) == 0 {
+		return Result{Status: StatusFailed, Message: "the cluster is not OpenShift"}
+	}
+	if len(env.SDIVersion) == 0 {
+		return Result{Status: StatusPassed, Message: fmt.Sprintf("OpenShift %s, SDI is not installed yet", ocpVersion)}
+	}
+	cond := compat.Evaluate(ocpVersion, env.SDIVersion)
+	switch cond.Status {
+	case metav1.ConditionFalse:
+		return Result{Status: StatusPassed, Message: cond.Message}
+	case metav1.ConditionTrue:
+		return Result{Status: StatusFailed, Message: cond.Message}
+	}
+	return Result{Status: StatusWarning, Message: cond.Message}
+}
+
+func checkRouteAPI(_ context.Context, env *Environment) Result {
+	if !routeapi.IsServed(env.Mapper) {
+		return Result{Status: StatusWarning, Message: "the route API is not served, the operator will not manage routes"}
+	}
+	return Result{Status: StatusPassed, Message: "the route API is served"}
+}
+
+func checkDefaultStorageClass(ctx context.Context, env *Environment) Result {
+	classes := &storagev1.StorageClassList{}
+	if err := env.Reader.List(ctx, classes); err != nil {
+		return Result{Status: StatusFailed, Message: "failed to list storage classes: " + err.Error()}
+	}
+	var defaults []string
+	for _, sc := range classes.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" ||
+			sc.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			defaults = append(defaults, sc.Name)
+		}
+	}
+	switch len(defaults) {
+	case 0:
+		return Result{Status: StatusFailed, Message: "there is no default storage class"}
+	case 1:
+		return Result{Status: StatusPassed, Message: "the default storage class is " + defaults[0]}
+	}
+	return Result{Status: StatusWarning, Message: fmt.Sprintf("multiple default storage classes: %v", defaults)}
+}
+
+func checkSDINodes(ctx context.Context, env *Environment) Result {
+	label := "node-role.kubernetes.io/" + env.SDINodeRole
+	nodes := &corev1.NodeList{}
+	if err := env.Reader.List(ctx, nodes, client.HasLabels{label}); err != nil {
+		return Result{Status: StatusFailed, Message: "failed to list nodes: " + err.Error()}
+	}
+	ready := 0
+	for _, node := range nodes.Items {
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue && !node.Spec.Unschedulable {
+				ready++
+			}
+		}
+	}
+	msg := fmt.Sprintf("%d of %d nodes labeled %s are ready and schedulable", ready, len(nodes.Items), label)
+	switch {
+	case len(nodes.Items) == 0:
+		return Result{Status: StatusFailed, Message: "no node is labeled " + label}
+	case ready < minSDINodes:
+		return Result{Status: StatusWarning, Message: fmt.Sprintf("%s, SAP requires at least %d", msg, minSDINodes)}
+	}
+	return Result{Status: StatusPassed, Message: msg}
+}
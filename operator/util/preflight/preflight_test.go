@@ -0,0 +1,110 @@
+package preflight_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/preflight"
+)
+
+func sdiNode(name string, ready bool) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node-role.kubernetes.io/sdi": ""}},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+	}
+}
+
+var _ = Describe("Preflight checks", func() {
+	var scheme *runtime.Scheme
+	var mapper *meta.DefaultRESTMapper
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Ω(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Ω(configv1.AddToScheme(scheme)).To(Succeed())
+		mapper = meta.NewDefaultRESTMapper(nil)
+	})
+
+	run := func(sdiVersion string, objs ...client.Object) *preflight.Report {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		return preflight.Run(context.Background(), &preflight.Environment{
+			Reader:      c,
+			Mapper:      mapper,
+			SDIVersion:  sdiVersion,
+			SDINodeRole: "sdi",
+		})
+	}
+	statuses := func(r *preflight.Report) map[string]preflight.Status {
+		res := make(map[string]preflight.Status)
+		for _, r := range r.Results {
+			res[r.Name] = r.Status
+		}
+		return res
+	}
+	cv := &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Status: configv1.ClusterVersionStatus{History: []configv1.UpdateHistory{
+			{State: configv1.CompletedUpdate, Version: "4.10.23"},
+		}},
+	}
+	defaultClass := &storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ocs-storagecluster-ceph-rbd",
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		},
+		Provisioner: "openshift-storage.rbd.csi.ceph.com",
+	}
+
+	It("Should pass on a prepared cluster", func() {
+		mapper.Add(routev1.GroupVersion.WithKind("Route"), meta.RESTScopeNamespace)
+		report := run("3.2.45", cv, defaultClass, sdiNode("a", true), sdiNode("b", true), sdiNode("c", true))
+		Ω(report.Passed).To(BeTrue())
+		for _, res := range report.Results {
+			Ω(res.Status).To(Equal(preflight.StatusPassed), res.Name+": "+res.Message)
+		}
+	})
+
+	It("Should fail on the missing prerequisites", func() {
+		report := run("3.1.13", cv, sdiNode("a", true), sdiNode("b", false))
+		Ω(report.Passed).To(BeFalse())
+		Ω(statuses(report)).To(Equal(map[string]preflight.Status{
+			"openshift-release":     preflight.StatusFailed,
+			"route-api":             preflight.StatusWarning,
+			"default-storage-class": preflight.StatusFailed,
+			"sdi-nodes":             preflight.StatusWarning,
+		}))
+	})
+
+	It("Should render the report", func() {
+		report := run("", cv, defaultClass)
+		Ω(statuses(report)["sdi-nodes"]).To(Equal(preflight.StatusFailed))
+
+		var text bytes.Buffer
+		Ω(report.WriteText(&text)).To(Succeed())
+		Ω(text.String()).To(ContainSubstring("some prerequisites are not fulfilled"))
+
+		var data bytes.Buffer
+		Ω(report.WriteJSON(&data)).To(Succeed())
+		parsed := &preflight.Report{}
+		Ω(json.Unmarshal(data.Bytes(), parsed)).To(Succeed())
+		Ω(parsed).To(Equal(report))
+	})
+})
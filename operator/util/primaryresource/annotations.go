@@ -0,0 +1,88 @@
+package primaryresource
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+// Annotations for owned resources in other namespaces or cluster-scoped resources where ownerReferences
+// cannot be used.
+const (
+	// expected value: {metadata.namespace}/{metadata.name}
+	AnnotationKey = "operator-sdk/primary-resource"
+	// expected value: {kind}.{group}
+	TypeAnnotationKey = "operator-sdk/primary-resource-type"
+	// ManagedLabelKey labels the annotated objects so that they can be listed by the orphan collector.
+	ManagedLabelKey   = "di.sap-cop.redhat.com/managed"
+	ManagedLabelValue = "true"
+	// ManagedByLabelKey labels all the objects managed by the operator. The protection webhook selects the
+	// objects by it.
+	ManagedByLabelKey   = "app.kubernetes.io/managed-by"
+	ManagedByLabelValue = "sdi-observer"
+)
+
+func typeOf(kind string) string {
+	return schema.GroupKind{Group: sdiv1alpha1.GroupVersion.Group, Kind: kind}.String()
+}
+
+// Set marks the object as owned by the given owner of the given kind. The object is labeled as managed as well.
+func Set(obj metav1.Object, owner metav1.Object, kind string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationKey] = fmt.Sprintf("%s/%s", owner.GetNamespace(), owner.GetName())
+	annotations[TypeAnnotationKey] = typeOf(kind)
+	obj.SetAnnotations(annotations)
+	setLabels(obj, ManagedLabelKey, ManagedLabelValue, ManagedByLabelKey, ManagedByLabelValue)
+}
+
+// SetManagedBy labels the object as managed by the operator. Used for the objects owned through the owner
+// references.
+func SetManagedBy(obj metav1.Object) {
+	setLabels(obj, ManagedByLabelKey, ManagedByLabelValue)
+}
+
+// setLabels sets the key-value pairs on a copy of the labels; the labels tend to be shared with the selectors.
+func setLabels(obj metav1.Object, keyValues ...string) {
+	labels := make(map[string]string, len(obj.GetLabels())+len(keyValues)/2)
+	for key, value := range obj.GetLabels() {
+		labels[key] = value
+	}
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		labels[keyValues[i]] = keyValues[i+1]
+	}
+	obj.SetLabels(labels)
+}
+
+// IsOwnedBy returns true if the object is annotated as owned by the given owner.
+func IsOwnedBy(obj metav1.Object, owner metav1.Object, kind string) bool {
+	annotations := obj.GetAnnotations()
+	return annotations[TypeAnnotationKey] == typeOf(kind) &&
+		annotations[AnnotationKey] == fmt.Sprintf("%s/%s", owner.GetNamespace(), owner.GetName())
+}
+
+// EnqueueRequestsForOwner returns a handler mapping the annotated objects back to their owner of the given
+// kind.
+func EnqueueRequestsForOwner(kind string) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		annotations := obj.GetAnnotations()
+		if annotations[TypeAnnotationKey] != typeOf(kind) {
+			return nil
+		}
+		parts := strings.SplitN(annotations[AnnotationKey], "/", 2)
+		if len(parts) != 2 {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: parts[0], Name: parts[1]}}}
+	})
+}
@@ -0,0 +1,70 @@
+// Package ratelimit configures the rate limiters of the controller workqueues.
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// The defaults of controller-runtime.
+const (
+	DefaultBaseDelay = time.Millisecond * 5
+	DefaultMaxDelay  = time.Second * 1000
+	DefaultQPS       = 10
+	DefaultBurst     = 100
+)
+
+// Options of the workqueue rate limiter. A failed request is retried after a delay growing exponentially from
+// BaseDelay up to MaxDelay. The overall rate of the retries is limited by QPS and Burst.
+type Options struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// The number of the retries of a failing request with the exponential backoff. Further retries wait for
+	// MaxDelay. Zero means no limit.
+	MaxRetries int
+	QPS        float64
+	Burst      int
+}
+
+// DefaultOptions returns the options equivalent to the rate limiter of controller-runtime.
+func DefaultOptions() Options {
+	return Options{
+		BaseDelay: DefaultBaseDelay,
+		MaxDelay:  DefaultMaxDelay,
+		QPS:       DefaultQPS,
+		Burst:     DefaultBurst,
+	}
+}
+
+// Validate checks the options for sane values.
+func (o Options) Validate() error {
+	switch {
+	case o.BaseDelay <= 0:
+		return fmt.Errorf("the base delay must be positive, not %s", o.BaseDelay)
+	case o.MaxDelay < o.BaseDelay:
+		return fmt.Errorf("the max delay (%s) must not be shorter than the base delay (%s)", o.MaxDelay, o.BaseDelay)
+	case o.MaxRetries < 0:
+		return fmt.Errorf("the max retries must not be negative, not %d", o.MaxRetries)
+	case o.QPS <= 0:
+		return fmt.Errorf("the qps must be positive, not %g", o.QPS)
+	case o.Burst <= 0:
+		return fmt.Errorf("the burst must be positive, not %d", o.Burst)
+	}
+	return nil
+}
+
+// New returns a rate limiter for a single workqueue. The rate limiters track the failures per item and must
+// not be shared among the controllers.
+func (o Options) New() workqueue.RateLimiter {
+	limiters := []workqueue.RateLimiter{
+		workqueue.NewItemExponentialFailureRateLimiter(o.BaseDelay, o.MaxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(o.QPS), o.Burst)},
+	}
+	if o.MaxRetries > 0 {
+		limiters = append(limiters, workqueue.NewItemFastSlowRateLimiter(o.BaseDelay, o.MaxDelay, o.MaxRetries))
+	}
+	return workqueue.NewMaxOfRateLimiter(limiters...)
+}
@@ -0,0 +1,62 @@
+// Package rbacscope implements the namespace-scoped RBAC mode. In the mode, the operator is granted Roles only in
+// its own, the SDI and the SLCB namespaces. The features needing cluster-scoped objects or objects in other
+// namespaces are disabled and reported in the ClusterScopeAvailable condition instead of failing on the
+// forbidden requests.
+package rbacscope
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+// ReasonNamespacedRBAC is the reason of ClusterScopeAvailable=False.
+const ReasonNamespacedRBAC = "NamespacedRBAC"
+
+var namespaced int32
+
+// SetNamespaced enables or disables the namespace-scoped RBAC mode. It must be called before the controllers
+// are set up.
+func SetNamespaced(enable bool) {
+	var value int32
+	if enable {
+		value = 1
+	}
+	atomic.StoreInt32(&namespaced, value)
+}
+
+// Namespaced returns true in the namespace-scoped RBAC mode.
+func Namespaced() bool {
+	return atomic.LoadInt32(&namespaced) == 1
+}
+
+// IsForbidden returns true if the error is a refusal expected in the namespace-scoped RBAC mode.
+func IsForbidden(err error) bool {
+	return Namespaced() && errors.IsForbidden(err)
+}
+
+// SetCondition sets the ClusterScopeAvailable condition in the namespace-scoped RBAC mode and removes it
+// otherwise. The disabled features requested by the spec are listed in the message.
+func SetCondition(conditions *[]metav1.Condition, generation int64, disabled []string) {
+	if !Namespaced() {
+		meta.RemoveStatusCondition(conditions, sdiv1alpha1.ConditionClusterScopeAvailable)
+		return
+	}
+	msg := "the operator runs with the namespace-scoped RBAC; no requested feature needs cluster-scoped permissions"
+	if len(disabled) > 0 {
+		msg = "the operator runs with the namespace-scoped RBAC, the features needing cluster-scoped permissions" +
+			" are disabled: " + strings.Join(disabled, ", ")
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               sdiv1alpha1.ConditionClusterScopeAvailable,
+		Status:             metav1.ConditionFalse,
+		Reason:             ReasonNamespacedRBAC,
+		Message:            msg,
+		ObservedGeneration: generation,
+	})
+}
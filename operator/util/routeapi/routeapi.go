@@ -0,0 +1,41 @@
+// Package routeapi detects the OpenShift route API. On the Kubernetes distributions lacking it, the operator runs
+// with the route components disabled instead of failing to start the route watches.
+package routeapi
+
+import (
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
+)
+
+const (
+	// ReasonServed is the reason of RouteAPIAvailable=True.
+	ReasonServed = "Served"
+	// ReasonNotServed is the reason of RouteAPIAvailable=False.
+	ReasonNotServed = "NotServed"
+)
+
+// IsServed returns false if the API server does not serve the Route kind.
+func IsServed(mapper meta.RESTMapper) bool {
+	_, err := mapper.RESTMapping(routev1.GroupVersion.WithKind("Route").GroupKind(), routev1.GroupVersion.Version)
+	return !meta.IsNoMatchError(err)
+}
+
+// SetCondition sets the RouteAPIAvailable condition.
+func SetCondition(conditions *[]metav1.Condition, generation int64, served bool) {
+	cond := metav1.Condition{
+		Type:               sdiv1alpha1.ConditionRouteAPIAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonServed,
+		ObservedGeneration: generation,
+	}
+	if !served {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = ReasonNotServed
+		cond.Message = "the cluster does not serve the " + routev1.GroupName +
+			" API, the routes are not managed; expose the services by other means"
+	}
+	meta.SetStatusCondition(conditions, cond)
+}
@@ -0,0 +1,238 @@
+// Package s3 contains a minimal client for S3 compatible object storages. It supports just the requests
+// needed to validate the checkpoint store of SAP DI.
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	serviceName      = "s3"
+	amzDateFormat    = "20060102T150405Z"
+	shortDateFormat  = "20060102"
+	// EmptyPayloadHash is the SHA256 hash of an empty request body.
+	EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Credentials used to sign the requests.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Client sends signature version 4 signed requests to an S3 compatible endpoint using path-style
+// addressing.
+type Client struct {
+	Endpoint    *url.URL
+	Region      string
+	Credentials Credentials
+	HTTPClient  *http.Client
+	// now allows to override the time source in tests
+	now func() time.Time
+}
+
+// NewClient parses the endpoint and returns a new client.
+func NewClient(endpoint, region string, creds Credentials, insecureSkipTLSVerify bool) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse endpoint %q: %v", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme of endpoint %q", endpoint)
+	}
+	if len(region) == 0 {
+		region = "us-east-1"
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecureSkipTLSVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+	return &Client{
+		Endpoint:    u,
+		Region:      region,
+		Credentials: creds,
+		HTTPClient:  &http.Client{Transport: transport, Timeout: time.Second * 30},
+		now:         time.Now,
+	}, nil
+}
+
+// Response summarizes the result of a request.
+type Response struct {
+	StatusCode int
+	Latency    time.Duration
+	Body       []byte
+}
+
+// HeadBucket verifies that the bucket exists and is accessible with the credentials.
+func (c *Client) HeadBucket(ctx context.Context, bucket string) (*Response, error) {
+	return c.Do(ctx, http.MethodHead, bucket, "", nil, nil)
+}
+
+// Do sends a signed request for the given bucket and object key. The key may be empty.
+func (c *Client) Do(
+	ctx context.Context,
+	method, bucket, key string,
+	query url.Values,
+	body []byte,
+) (*Response, error) {
+	req, err := c.newRequest(ctx, method, bucket, key, query, body)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	res := &Response{
+		StatusCode: resp.StatusCode,
+		Latency:    time.Since(start),
+		Body:       data,
+	}
+	return res, err
+}
+
+func (c *Client) newRequest(
+	ctx context.Context,
+	method, bucket, key string,
+	query url.Values,
+	body []byte,
+) (*http.Request, error) {
+	u := *c.Endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket
+	if len(key) > 0 {
+		u.Path += "/" + strings.TrimPrefix(key, "/")
+	}
+	u.RawQuery = query.Encode()
+
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = strings.NewReader(string(body))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := EmptyPayloadHash
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	Sign(req, c.Credentials, c.Region, serviceName, payloadHash, c.now())
+	return req, nil
+}
+
+// Sign adds the signature version 4 Authorization header to the request.
+func Sign(req *http.Request, creds Credentials, region, service, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format(shortDateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if service == serviceName {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	host := req.Host
+	if len(host) == 0 {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for k, vs := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "authorization" {
+			continue
+		}
+		trimmed := make([]string, 0, len(vs))
+		for _, v := range vs {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+		}
+		headers[lk] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, n := range names {
+		canonicalHeaders.WriteString(n + ":" + headers[n] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, region, service, "aws4_request"}, "/")
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(crHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), shortDate)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalURI(u *url.URL) string {
+	p := u.EscapedPath()
+	if len(p) == 0 {
+		return "/"
+	}
+	return p
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string{}, values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,60 @@
+package s3_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/s3"
+)
+
+var _ = Describe("Signature version 4", func() {
+	creds := s3.Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	It("Should match the get-vanilla example of the AWS test suite", func() {
+		req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+		Ω(err).NotTo(HaveOccurred())
+		s3.Sign(req, creds, "us-east-1", "service", s3.EmptyPayloadHash,
+			time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+		Ω(req.Header.Get("X-Amz-Date")).To(Equal("20150830T123600Z"))
+		Ω(req.Header.Get("Authorization")).To(Equal(
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, " +
+				"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"))
+	})
+
+	It("Should send signed path-style requests", func() {
+		var got *http.Request
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		c, err := s3.NewClient(srv.URL, "", creds, false)
+		Ω(err).NotTo(HaveOccurred())
+		resp, err := c.HeadBucket(context.Background(), "checkpoints")
+		Ω(err).NotTo(HaveOccurred())
+		Ω(resp.StatusCode).To(Equal(http.StatusOK))
+		Ω(got).NotTo(BeNil())
+		Ω(got.Method).To(Equal(http.MethodHead))
+		Ω(got.URL.Path).To(Equal("/checkpoints"))
+		Ω(got.Header.Get("X-Amz-Content-Sha256")).To(Equal(s3.EmptyPayloadHash))
+		Ω(strings.HasPrefix(got.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/")).To(BeTrue())
+		Ω(got.Header.Get("Authorization")).To(ContainSubstring("/us-east-1/s3/aws4_request"))
+	})
+
+	It("Should refuse unsupported endpoints", func() {
+		_, err := s3.NewClient("ftp://example.com", "", creds, false)
+		Ω(err).To(HaveOccurred())
+	})
+})
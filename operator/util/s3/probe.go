@@ -0,0 +1,130 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// The environment variables configuring RunProbe.
+const (
+	EnvEndpoint              = "S3_ENDPOINT"
+	EnvBucket                = "S3_BUCKET"
+	EnvRegion                = "S3_REGION"
+	EnvAccessKeyID           = "AWS_ACCESS_KEY_ID"
+	EnvSecretAccessKey       = "AWS_SECRET_ACCESS_KEY"
+	EnvInsecureSkipTLSVerify = "S3_INSECURE_SKIP_TLS_VERIFY"
+)
+
+// The operations verified by the probe in order.
+const (
+	OperationPut        = "put"
+	OperationGet        = "get"
+	OperationDelete     = "delete"
+	OperationVersioning = "versioning"
+)
+
+// ProbeResult is the outcome of RunProbe. It is small enough for the termination message of a pod.
+type ProbeResult struct {
+	// the operations that succeeded
+	Passed []string `json:"passed,omitempty"`
+	// the first failed operation
+	Failed     string `json:"failed,omitempty"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// the versioning status of the bucket; empty if it has never been enabled
+	Versioning string `json:"versioning,omitempty"`
+}
+
+// Encode returns the JSON encoded result.
+func (r *ProbeResult) Encode() string {
+	data, _ := json.Marshal(r)
+	return string(data)
+}
+
+// ParseProbeResult decodes the result encoded by Encode.
+func ParseProbeResult(data string) (*ProbeResult, error) {
+	res := &ProbeResult{}
+	if err := json.Unmarshal([]byte(data), res); err != nil {
+		return nil, fmt.Errorf("failed to parse the probe result %q: %v", data, err)
+	}
+	return res, nil
+}
+
+type versioningConfiguration struct {
+	Status string `xml:"Status"`
+}
+
+// RunProbe writes, reads back and deletes a test object in the bucket and reads the versioning configuration
+// of the bucket. It stops at the first failed operation.
+func (c *Client) RunProbe(ctx context.Context, bucket string) *ProbeResult {
+	res := &ProbeResult{}
+	key := fmt.Sprintf("sdi-checkpoint-probe-%d", time.Now().UnixNano())
+	payload := []byte("SAP Data Intelligence checkpoint store probe\n")
+
+	for _, op := range []struct {
+		name   string
+		method string
+		key    string
+		query  url.Values
+		body   []byte
+		check  func(*Response) error
+	}{
+		{OperationPut, http.MethodPut, key, nil, payload, nil},
+		{OperationGet, http.MethodGet, key, nil, nil, func(resp *Response) error {
+			if !bytes.Equal(resp.Body, payload) {
+				return fmt.Errorf("the object read back differs from the written one")
+			}
+			return nil
+		}},
+		{OperationDelete, http.MethodDelete, key, nil, nil, nil},
+		{OperationVersioning, http.MethodGet, "", url.Values{"versioning": []string{""}}, nil,
+			func(resp *Response) error {
+				cfg := &versioningConfiguration{}
+				if err := xml.Unmarshal(resp.Body, cfg); err != nil {
+					return fmt.Errorf("failed to parse the versioning configuration: %v", err)
+				}
+				res.Versioning = cfg.Status
+				return nil
+			}},
+	} {
+		resp, err := c.Do(ctx, op.method, bucket, op.key, op.query, op.body)
+		if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			res.StatusCode = resp.StatusCode
+			err = fmt.Errorf("unexpected response: %d", resp.StatusCode)
+		}
+		if err == nil && op.check != nil {
+			err = op.check(resp)
+		}
+		if err != nil {
+			res.Failed = op.name
+			res.Error = err.Error()
+			return res
+		}
+		res.Passed = append(res.Passed, op.name)
+	}
+	return res
+}
+
+// NewClientFromEnv creates the client configured by the Env* variables read by the getenv function.
+func NewClientFromEnv(getenv func(string) string) (*Client, string, error) {
+	insecure, _ := strconv.ParseBool(getenv(EnvInsecureSkipTLSVerify))
+	c, err := NewClient(getenv(EnvEndpoint), getenv(EnvRegion), Credentials{
+		AccessKeyID:     getenv(EnvAccessKeyID),
+		SecretAccessKey: getenv(EnvSecretAccessKey),
+	}, insecure)
+	if err != nil {
+		return nil, "", err
+	}
+	bucket := getenv(EnvBucket)
+	if len(bucket) == 0 {
+		return nil, "", fmt.Errorf("missing %s", EnvBucket)
+	}
+	return c, bucket, nil
+}
@@ -0,0 +1,90 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/s3"
+)
+
+// fakeBucket serves a single bucket from memory. A method listed in deny is refused.
+type fakeBucket struct {
+	mu         sync.Mutex
+	objects    map[string][]byte
+	versioning string
+	deny       map[string]bool
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.deny[r.Method] {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if _, ok := r.URL.Query()["versioning"]; ok {
+		body := `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`
+		if len(b.versioning) > 0 {
+			body += "<Status>" + b.versioning + "</Status>"
+		}
+		_, _ = io.WriteString(w, body+"</VersioningConfiguration>")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		b.objects[r.URL.Path] = data
+	case http.MethodGet:
+		data, ok := b.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(b.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+var _ = Describe("Checkpoint store probe", func() {
+	creds := s3.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+	run := func(bucket *fakeBucket) *s3.ProbeResult {
+		srv := httptest.NewServer(bucket)
+		defer srv.Close()
+		c, err := s3.NewClient(srv.URL, "", creds, false)
+		Ω(err).NotTo(HaveOccurred())
+		return c.RunProbe(context.Background(), "checkpoints")
+	}
+
+	It("Should pass all the operations and report the versioning", func() {
+		bucket := &fakeBucket{objects: map[string][]byte{}, versioning: "Enabled"}
+		res := run(bucket)
+		Ω(res.Failed).To(BeEmpty())
+		Ω(res.Passed).To(Equal([]string{s3.OperationPut, s3.OperationGet, s3.OperationDelete,
+			s3.OperationVersioning}))
+		Ω(res.Versioning).To(Equal("Enabled"))
+		Ω(bucket.objects).To(BeEmpty())
+	})
+
+	It("Should stop at the first refused operation", func() {
+		res := run(&fakeBucket{objects: map[string][]byte{}, deny: map[string]bool{http.MethodDelete: true}})
+		Ω(res.Passed).To(Equal([]string{s3.OperationPut, s3.OperationGet}))
+		Ω(res.Failed).To(Equal(s3.OperationDelete))
+		Ω(res.StatusCode).To(Equal(http.StatusForbidden))
+	})
+
+	It("Should round-trip the result", func() {
+		res := &s3.ProbeResult{Passed: []string{s3.OperationPut}, Failed: s3.OperationGet, Error: "boom"}
+		parsed, err := s3.ParseProbeResult(res.Encode())
+		Ω(err).NotTo(HaveOccurred())
+		Ω(parsed).To(Equal(res))
+	})
+})
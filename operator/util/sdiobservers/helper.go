@@ -7,15 +7,49 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
 	sdiv1alpha1 "github.com/redhat-sap/sap-data-intelligence/operator/api/v1alpha1"
 	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
 )
 
+// The health statuses understood by Argo CD.
+const (
+	HealthHealthy     = "Healthy"
+	HealthProgressing = "Progressing"
+	HealthDegraded    = "Degraded"
+	HealthSuspended   = "Suspended"
+)
+
+// SetHealth derives the health from the Backup, Paused, Degraded and Ready conditions.
+func SetHealth(obs *sdiv1alpha1.SDIObserver) {
+	describe := func(c *metav1.Condition) string {
+		if len(c.Message) > 0 {
+			return c.Message
+		}
+		return c.Reason
+	}
+	health := &sdiv1alpha1.SDIObserverHealth{Status: HealthProgressing}
+	if c := meta.FindStatusCondition(obs.Status.Conditions, "Backup"); c != nil && c.Status == metav1.ConditionTrue {
+		health = &sdiv1alpha1.SDIObserverHealth{Status: HealthSuspended, Message: describe(c)}
+	} else if c := meta.FindStatusCondition(obs.Status.Conditions, sdiv1alpha1.ConditionPaused); c != nil &&
+		c.Status == metav1.ConditionTrue {
+		health = &sdiv1alpha1.SDIObserverHealth{Status: HealthSuspended, Message: describe(c)}
+	} else if c := meta.FindStatusCondition(obs.Status.Conditions, "Degraded"); c != nil &&
+		c.Status == metav1.ConditionTrue {
+		health = &sdiv1alpha1.SDIObserverHealth{Status: HealthDegraded, Message: describe(c)}
+	} else if c := meta.FindStatusCondition(obs.Status.Conditions, "Ready"); c != nil {
+		health.Message = describe(c)
+		if c.Status == metav1.ConditionTrue {
+			health.Status = HealthHealthy
+		}
+	}
+	obs.Status.Health = health
+}
+
 func IsBackup(obs *sdiv1alpha1.SDIObserver) bool {
 	return IsStatusInCondition(obs, "Backup")
 }
@@ -73,6 +107,7 @@ func SetBackup(
 		Reason:             degradedReason,
 		ObservedGeneration: obs.Generation,
 	})
+	SetHealth(obs)
 	return true
 }
 
@@ -86,7 +121,6 @@ func SetBackupAndUpdate(
 	tracer := λ.Enter(log.FromContext(ctx))
 	defer λ.Leave(tracer)
 
-	firstTry := true
 	if IsBackup(obs) == backup {
 		stateDescription := "active"
 		if backup {
@@ -96,18 +130,8 @@ func SetBackupAndUpdate(
 			"instance", client.ObjectKeyFromObject(obs))
 		return nil
 	}
-	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		if !firstTry {
-			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obs), obs); err != nil {
-				return err
-			}
-		}
-		firstTry = false
-
-		if !SetBackup(ctx, k8sClient, obs, backup, activeInstance) {
-			return nil
-		}
-		return k8sClient.Status().Update(ctx, obs)
+	return updates.Status(ctx, k8sClient, obs, func() (bool, error) {
+		return SetBackup(ctx, k8sClient, obs, backup, activeInstance), nil
 	})
 }
 
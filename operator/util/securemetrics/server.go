@@ -0,0 +1,197 @@
+// Package securemetrics serves the metrics of the operator over TLS to the clients authorized to get the /metrics
+// non-resource URL. The bearer token of the request is verified with a TokenReview and its user is authorized
+// with a SubjectAccessReview like the kube-rbac-proxy sidecar does.
+package securemetrics
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/fips"
+)
+
+const (
+	// DefaultCertDir is the mount point of the serving certificate issued by the service CA operator.
+	DefaultCertDir = "/tmp/k8s-metrics-server/serving-certs"
+
+	metricsPath     = "/metrics"
+	shutdownTimeout = time.Second * 5
+	// how long the review results are reused; Prometheus scrapes with the same token every few seconds
+	reviewCacheTTL = time.Minute
+)
+
+// Server serves the metrics registry of controller-runtime on the given address until the manager stops.
+type Server struct {
+	Addr string
+	// The directory with the tls.crt and tls.key files. The files are reloaded on change.
+	CertDir string
+	// Creates the token and subject access reviews. It must not be wrapped by the dry run.
+	Client client.Client
+
+	mu      sync.Mutex
+	reviews map[[sha256.Size]byte]review
+}
+
+type review struct {
+	status  int
+	expires time.Time
+}
+
+var _ manager.Runnable = &Server{}
+var _ manager.LeaderElectionRunnable = &Server{}
+
+// NeedLeaderElection returns false so that the standby replicas are scraped as well.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start serves the metrics until the context is done.
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("metrics")
+
+	certDir := s.CertDir
+	if len(certDir) == 0 {
+		certDir = DefaultCertDir
+	}
+	watcher, err := certwatcher.New(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			logger.Error(err, "failed to watch the metrics serving certificate")
+		}
+	}()
+
+	tlsConfig := fips.TLSConfig(nil)
+	if tlsConfig.MinVersion < tls.VersionTLS12 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	tlsConfig.GetCertificate = watcher.GetCertificate
+	listener, err := tls.Listen("tcp", s.Addr, tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, s.authorize(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	})))
+	srv := &http.Server{Handler: mux}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "failed to shut down the metrics server")
+		}
+	}()
+
+	logger.Info("serving the authorized metrics", "address", listener.Addr().String())
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager adds the server to the manager.
+func (s *Server) SetupWithManager(mgr manager.Manager) error {
+	return mgr.Add(s)
+}
+
+// authorize passes only the requests whose bearer token belongs to a user allowed to get the metrics.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		auth := req.Header.Get("Authorization")
+		token := strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+		if !strings.HasPrefix(auth, "Bearer ") || len(token) == 0 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		status := s.review(req.Context(), token, req.URL.Path, strings.ToLower(req.Method))
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// review returns the HTTP status of the request authenticated with the token.
+func (s *Server) review(ctx context.Context, token, path, verb string) int {
+	key := sha256.Sum256([]byte(verb + " " + path + " " + token))
+	now := time.Now()
+	s.mu.Lock()
+	if r, ok := s.reviews[key]; ok && now.Before(r.expires) {
+		s.mu.Unlock()
+		return r.status
+	}
+	s.mu.Unlock()
+
+	status := s.doReview(ctx, token, path, verb)
+	// the failed reviews are retried with the next request
+	if status == http.StatusInternalServerError {
+		return status
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reviews == nil {
+		s.reviews = make(map[[sha256.Size]byte]review)
+	}
+	for k, r := range s.reviews {
+		if !now.Before(r.expires) {
+			delete(s.reviews, k)
+		}
+	}
+	s.reviews[key] = review{status: status, expires: now.Add(reviewCacheTTL)}
+	return status
+}
+
+func (s *Server) doReview(ctx context.Context, token, path, verb string) int {
+	logger := log.FromContext(ctx).WithName("metrics")
+	tr := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := s.Client.Create(ctx, tr); err != nil {
+		logger.Error(err, "failed to review the token of a metrics request")
+		return http.StatusInternalServerError
+	}
+	if !tr.Status.Authenticated {
+		return http.StatusUnauthorized
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(tr.Status.User.Extra))
+	for k, v := range tr.Status.User.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	sar := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		User:                  tr.Status.User.Username,
+		Groups:                tr.Status.User.Groups,
+		UID:                   tr.Status.User.UID,
+		Extra:                 extra,
+		NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: path, Verb: verb},
+	}}
+	if err := s.Client.Create(ctx, sar); err != nil {
+		logger.Error(err, "failed to authorize a metrics request", "user", tr.Status.User.Username)
+		return http.StatusInternalServerError
+	}
+	if !sar.Status.Allowed {
+		logger.V(1).Info("refused a metrics request", "user", tr.Status.User.Username, "reason", sar.Status.Reason)
+		return http.StatusForbidden
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,98 @@
+// Package updates writes the objects and their status on behalf of the reconcilers. The conflicting writes are
+// retried with a jittered exponential backoff while the permanent failures are told apart so that they can be
+// reported instead of being retried forever.
+package updates
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReasonUpdateRejected is the reason of the Degraded condition set for a permanently failing update.
+const ReasonUpdateRejected = "UpdateRejected"
+
+// Backoff of the retries of the conflicting updates. The jitter spreads the retries of the controllers racing
+// for the same object.
+var Backoff = wait.Backoff{
+	Steps:    6,
+	Duration: time.Millisecond * 10,
+	Factor:   2.0,
+	Jitter:   0.5,
+}
+
+// PermanentError is an update refused for a reason that a retry cannot fix.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("update refused permanently: %v", e.Err)
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent returns true if the update failed due to missing permissions or an invalid object.
+func IsPermanent(err error) bool {
+	if _, ok := err.(*PermanentError); ok {
+		return true
+	}
+	return errors.IsForbidden(err) || errors.IsInvalid(err) || errors.IsBadRequest(err) ||
+		errors.IsMethodNotSupported(err) || errors.IsRequestEntityTooLargeError(err) ||
+		errors.IsUnsupportedMediaType(err)
+}
+
+// MutateFn modifies the object and returns false if no update is needed.
+type MutateFn func() (bool, error)
+
+func update(ctx context.Context, c client.Client, obj client.Object, mutate MutateFn, write func() error) error {
+	first := true
+	err := retry.OnError(Backoff, errors.IsConflict, func() error {
+		if !first {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+		}
+		first = false
+		if changed, err := mutate(); err != nil || !changed {
+			return err
+		}
+		return write()
+	})
+	if err != nil && IsPermanent(err) {
+		return &PermanentError{Err: err}
+	}
+	return err
+}
+
+// Object mutates and updates the object unless unchanged. On a conflict, the latest version of the object is
+// fetched and mutated again. A permanent failure is returned as a PermanentError.
+func Object(ctx context.Context, c client.Client, obj client.Object, mutate MutateFn) error {
+	return update(ctx, c, obj, mutate, func() error { return c.Update(ctx, obj) })
+}
+
+// Status mutates the object and updates its status unless unchanged. On a conflict, the latest version of the
+// object is fetched and mutated again. A permanent failure is returned as a PermanentError.
+func Status(ctx context.Context, c client.Client, obj client.Object, mutate MutateFn) error {
+	return update(ctx, c, obj, mutate, func() error { return c.Status().Update(ctx, obj) })
+}
+
+// SetDegraded records the permanently failing update in the Degraded condition.
+func SetDegraded(conditions *[]metav1.Condition, generation int64, what string, err error) {
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               "Degraded",
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonUpdateRejected,
+		Message:            fmt.Sprintf("failed to update %s: %v", what, err),
+		ObservedGeneration: generation,
+	})
+}
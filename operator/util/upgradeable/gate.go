@@ -0,0 +1,209 @@
+// Package upgradeable tells OLM through the OperatorCondition of the operator whether it can be upgraded. The
+// upgrade is blocked while a disruptive action started by the operator is in progress.
+package upgradeable
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	λ "github.com/redhat-sap/sap-data-intelligence/operator/util/log"
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/updates"
+)
+
+const (
+	// OperatorConditionNameEnvVar is set by OLM to the name of the OperatorCondition of the operator.
+	OperatorConditionNameEnvVar = "OPERATOR_CONDITION_NAME"
+
+	conditionType = "Upgradeable"
+	// ReasonDisruptiveAction is the reason of Upgradeable=False.
+	ReasonDisruptiveAction = "DisruptiveActionInProgress"
+	// ReasonNoDisruptiveAction is the reason of Upgradeable=True.
+	ReasonNoDisruptiveAction = "NoDisruptiveAction"
+
+	// PollInterval is how often the progress of a disruptive action should be checked so that the upgrade is
+	// unblocked soon after the action completes.
+	PollInterval = time.Second * 30
+)
+
+var operatorConditionGVK = schema.GroupVersionKind{
+	Group:   "operators.coreos.com",
+	Version: "v2",
+	Kind:    "OperatorCondition",
+}
+
+// Gate tracks the disruptive actions in progress and sets the Upgradeable condition of the OperatorCondition
+// accordingly. The methods of a nil Gate do nothing.
+type Gate struct {
+	client client.Client
+	// the OperatorCondition; the name is empty unless the operator is deployed by OLM
+	key types.NamespacedName
+
+	mu sync.Mutex
+	// descriptions of the actions in progress keyed by the action
+	actions map[string]string
+	// the condition last written; nil if it needs to be written
+	written *metav1.Condition
+}
+
+// NewGate returns a gate for the OperatorCondition named by OPERATOR_CONDITION_NAME in the operator namespace.
+// Without the variable, the actions are only tracked.
+func NewGate(c client.Client, namespace string) *Gate {
+	return &Gate{
+		client:  c,
+		key:     types.NamespacedName{Namespace: namespace, Name: os.Getenv(OperatorConditionNameEnvVar)},
+		actions: make(map[string]string),
+	}
+}
+
+// Hold blocks the upgrade until the action is released.
+func (g *Gate) Hold(ctx context.Context, action, description string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.actions[action]; !ok {
+		log.FromContext(ctx).Info("blocking the operator upgrade", "action", action, "description", description)
+	}
+	g.actions[action] = description
+	g.sync(ctx)
+}
+
+// Release unblocks the upgrade unless other actions are in progress.
+func (g *Gate) Release(ctx context.Context, action string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.actions[action]; ok {
+		log.FromContext(ctx).Info("disruptive action completed", "action", action)
+		delete(g.actions, action)
+	}
+	g.sync(ctx)
+}
+
+// Holds returns true if the action is in progress.
+func (g *Gate) Holds(action string) bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.actions[action]
+	return ok
+}
+
+// Start writes the condition left behind by the previous instance of the operator and waits for the context to
+// be done. The actions still in progress are held again by the reconcilers.
+func (g *Gate) Start(ctx context.Context) error {
+	g.mu.Lock()
+	g.sync(ctx)
+	g.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func (g *Gate) desiredCondition() metav1.Condition {
+	if len(g.actions) == 0 {
+		return metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonNoDisruptiveAction,
+			Message: "no disruptive action is in progress",
+		}
+	}
+	var descriptions []string
+	for _, description := range g.actions {
+		descriptions = append(descriptions, description)
+	}
+	sort.Strings(descriptions)
+	return metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  ReasonDisruptiveAction,
+		Message: "the operator must not be replaced until finished: " + strings.Join(descriptions, "; "),
+	}
+}
+
+// sync writes the desired condition to the OperatorCondition unless written already. A failed write is
+// attempted again on the next call. Must be called with the mutex held.
+func (g *Gate) sync(ctx context.Context) {
+	tracer := λ.Enter(log.FromContext(ctx).WithValues(λ.ComponentKey, "upgradeable"))
+	defer λ.Leave(tracer)
+
+	desired := g.desiredCondition()
+	if len(g.key.Name) == 0 || (g.written != nil && g.written.Status == desired.Status &&
+		g.written.Message == desired.Message) {
+		return
+	}
+
+	oc := &unstructured.Unstructured{}
+	oc.SetGroupVersionKind(operatorConditionGVK)
+	if err := g.client.Get(ctx, g.key, oc); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			tracer.V(1).Info("OperatorCondition not found, the operator is not managed by OLM", "name", g.key.Name)
+			g.written = &desired
+			return
+		}
+		tracer.Error(err, "failed to get the OperatorCondition", "name", g.key.Name)
+		return
+	}
+	err := updates.Object(ctx, g.client, oc, func() (bool, error) { return setCondition(oc, desired) })
+	if err != nil {
+		tracer.Error(err, "failed to update the OperatorCondition", "name", g.key.Name)
+		return
+	}
+	tracer.Info("updated the OperatorCondition", "name", g.key.Name, "upgradeable", desired.Status)
+	g.written = &desired
+}
+
+// setCondition sets the condition in the spec of the OperatorCondition where OLM picks it up from.
+func setCondition(oc *unstructured.Unstructured, condition metav1.Condition) (bool, error) {
+	items, _, err := unstructured.NestedSlice(oc.Object, "spec", "conditions")
+	if err != nil {
+		return false, err
+	}
+	conditions := make([]metav1.Condition, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("unexpected condition %v", item)
+		}
+		var c metav1.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &c); err != nil {
+			return false, err
+		}
+		conditions = append(conditions, c)
+	}
+	if c := meta.FindStatusCondition(conditions, condition.Type); c != nil && c.Status == condition.Status &&
+		c.Reason == condition.Reason && c.Message == condition.Message {
+		return false, nil
+	}
+	meta.SetStatusCondition(&conditions, condition)
+
+	items = make([]interface{}, 0, len(conditions))
+	for i := range conditions {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&conditions[i])
+		if err != nil {
+			return false, err
+		}
+		items = append(items, obj)
+	}
+	return true, unstructured.SetNestedSlice(oc.Object, items, "spec", "conditions")
+}
@@ -0,0 +1,77 @@
+// Package version describes the build of the operator for the version subcommand and the build info metric.
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/redhat-sap/sap-data-intelligence/operator/util/compat"
+)
+
+// The build details are set at build time with -ldflags "-X <package>.Version=<version> ...".
+var (
+	Version   = "unknown"
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info describes the build and the releases it supports.
+type Info struct {
+	Version              string   `json:"version"`
+	GitCommit            string   `json:"gitCommit"`
+	BuildDate            string   `json:"buildDate"`
+	GoVersion            string   `json:"goVersion"`
+	SupportedSDIVersions []string `json:"supportedSDIVersions"`
+	MinOpenShiftVersion  string   `json:"minOpenShiftVersion"`
+	MaxOpenShiftVersion  string   `json:"maxOpenShiftVersion"`
+}
+
+// Get returns the info of the running binary.
+func Get() Info {
+	releases, openShift := compat.SupportedReleases()
+	return Info{
+		Version:              Version,
+		GitCommit:            GitCommit,
+		BuildDate:            BuildDate,
+		GoVersion:            runtime.Version(),
+		SupportedSDIVersions: releases,
+		MinOpenShiftVersion:  openShift.Min,
+		MaxOpenShiftVersion:  openShift.Max,
+	}
+}
+
+// WriteText writes the info as "key: value" lines.
+func (i Info) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "version: %s\ngit commit: %s\nbuild date: %s\ngo version: %s\n"+
+		"supported SDI versions: %s\nsupported OpenShift versions: %s to %s\n", i.Version, i.GitCommit, i.BuildDate,
+		i.GoVersion, strings.Join(i.SupportedSDIVersions, ", "), i.MinOpenShiftVersion, i.MaxOpenShiftVersion)
+	return err
+}
+
+// WriteJSON writes the info as an indented JSON document.
+func (i Info) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(i)
+}
+
+var buildInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "sdi_observer",
+	Name:      "build_info",
+	Help: "A metric with a constant 1 value labeled with the build of the operator and the SDI and OpenShift " +
+		"releases it supports.",
+}, []string{"version", "git_commit", "build_date", "go_version", "supported_sdi_versions",
+	"min_openshift_version", "max_openshift_version"})
+
+func init() {
+	metrics.Registry.MustRegister(buildInfo)
+	i := Get()
+	buildInfo.WithLabelValues(i.Version, i.GitCommit, i.BuildDate, i.GoVersion,
+		strings.Join(i.SupportedSDIVersions, ","), i.MinOpenShiftVersion, i.MaxOpenShiftVersion).Set(1)
+}